@@ -0,0 +1,68 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package capture holds the traffic capture state served by the Istio CNI node agent. It has no dependencies, so that
+// clients such as istioctl can decode the state without depending on the node agent.
+package capture
+
+// Status describes the traffic capture state of a single pod, as seen by the node agent on its node.
+type Status struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	UID       string `json:"uid"`
+	Node      string `json:"node,omitempty"`
+	// Enrolled is true if the pod is annotated as enrolled and its netns is tracked by the node agent.
+	Enrolled bool `json:"enrolled"`
+	// ZtunnelConnected is true if a ztunnel instance is connected to the node agent.
+	ZtunnelConnected bool `json:"ztunnelConnected"`
+	// ZtunnelAcknowledged is true if the connected ztunnel has acknowledged the pod.
+	ZtunnelAcknowledged bool            `json:"ztunnelAcknowledged"`
+	Rules               *RuleInspection `json:"rules,omitempty"`
+	// HostProbeSet lists the pod IPs expected in the host probe set, and those which are missing from it.
+	HostProbeSet *AddressSetInspection `json:"hostProbeSet,omitempty"`
+	Errors       []string              `json:"errors,omitempty"`
+}
+
+// Drifted returns true if any installed state differs from the expected state.
+func (c *Status) Drifted() bool {
+	if c.Rules != nil && c.Rules.Drifted() {
+		return true
+	}
+	return c.HostProbeSet != nil && len(c.HostProbeSet.Missing) > 0
+}
+
+// RuleInspection compares the rules installed in a pod network namespace against the rules
+// the traffic manager would generate for the pod.
+type RuleInspection struct {
+	// Backend is the traffic backend which installed the rules, iptables or nftables.
+	Backend   string   `json:"backend"`
+	Expected  []string `json:"expected"`
+	Installed []string `json:"installed"`
+	// Missing are expected rules which are not installed.
+	Missing []string `json:"missing,omitempty"`
+	// Unexpected are installed rules which are not expected.
+	Unexpected []string `json:"unexpected,omitempty"`
+}
+
+// Drifted returns true if the installed rules differ from the expected rules.
+func (r *RuleInspection) Drifted() bool {
+	return len(r.Missing) > 0 || len(r.Unexpected) > 0
+}
+
+// AddressSetInspection compares the entries of a host address set against the expected entries.
+type AddressSetInspection struct {
+	Name     string   `json:"name"`
+	Expected []string `json:"expected"`
+	Missing  []string `json:"missing,omitempty"`
+}
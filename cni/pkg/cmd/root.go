@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -95,6 +96,9 @@ var rootCmd = &cobra.Command{
 
 		installer := install.NewInstaller(&cfg.InstallConfig, installDaemonReady)
 
+		// Ambient pods can only be inspected for repair if the node agent is running
+		var ambientInspector repair.AmbientPodInspector
+
 		if cfg.InstallConfig.AmbientEnabled {
 			// Start ambient controller
 
@@ -163,6 +167,7 @@ var rootCmd = &cobra.Command{
 			}()
 
 			ambientAgent.Start()
+			ambientInspector = ambientAgent
 
 			log.Info("Ambient node agent started, starting installer...")
 
@@ -181,7 +186,7 @@ var rootCmd = &cobra.Command{
 		}
 		// TODO Note that during an "upgrade shutdown" in ambient mode,
 		// repair will (necessarily) be unavailable.
-		repair.StartRepair(ctx, cfg.RepairConfig)
+		repair.StartRepair(ctx, cfg.RepairConfig, ambientInspector)
 
 		// Note that even though we "install" the CNI plugin here *after* we start the node agent,
 		// it will block ambient-enabled pods from starting until `watchServerReady` == true
@@ -261,6 +266,18 @@ func init() {
 		"A set of label selectors in label=value format that will be added to the pod list filters")
 	registerStringParameter(constants.RepairFieldSelectors, "",
 		"A set of field selectors in label=value format that will be added to the pod list filters")
+	registerBooleanParameter(constants.RepairAmbient, false,
+		"Whether to detect ambient pods with missing redirection rules or ztunnel enrollment. Requires ambient to be enabled")
+	registerStringParameter(constants.RepairAmbientAction, "reenroll",
+		"The action taken for broken ambient pods: one of reenroll, restart or taint")
+	registerDurationParameter(constants.RepairAmbientGracePeriod, 30*time.Second,
+		"How long an ambient pod must remain broken before it is repaired")
+	registerDurationParameter(constants.RepairAmbientCheckInterval, time.Minute,
+		"How often ambient pods are re-checked for broken traffic capture")
+	registerIntegerParameter(constants.RepairAmbientRepairsPerMinute, 10,
+		"The maximum number of repair actions taken for ambient pods per minute")
+	registerStringParameter(constants.RepairAmbientTaintKey, "cni.istio.io/capture-broken",
+		"The key of the NoSchedule taint added to the node by the taint action")
 }
 
 func registerStringParameter(name, value, usage string) {
@@ -278,6 +295,11 @@ func registerBooleanParameter(name string, value bool, usage string) {
 	registerEnvironment(name, value, usage)
 }
 
+func registerDurationParameter(name string, value time.Duration, usage string) {
+	rootCmd.Flags().Duration(name, value, usage)
+	registerEnvironment(name, value, usage)
+}
+
 func registerEnvironment[T env.Parseable](name string, defaultValue T, usage string) {
 	envName := strings.Replace(strings.ToUpper(name), "-", "_", -1)
 	// Note: we do not rely on istio env package to retrieve configuration. We relies on viper.
@@ -364,6 +386,13 @@ func constructConfig() (*config.Config, error) {
 		FieldSelectors:      viper.GetString(constants.RepairFieldSelectors),
 		NativeNftables:      viper.GetBool(constants.NativeNftables),
 		ForceIptablesBinary: os.Getenv("FORCE_IPTABLES_BINARY"),
+
+		RepairAmbient:           viper.GetBool(constants.RepairAmbient),
+		AmbientAction:           viper.GetString(constants.RepairAmbientAction),
+		AmbientGracePeriod:      viper.GetDuration(constants.RepairAmbientGracePeriod),
+		AmbientCheckInterval:    viper.GetDuration(constants.RepairAmbientCheckInterval),
+		AmbientRepairsPerMinute: viper.GetInt(constants.RepairAmbientRepairsPerMinute),
+		AmbientTaintKey:         viper.GetString(constants.RepairAmbientTaintKey),
 	}
 
 	return &config.Config{InstallConfig: installCfg, RepairConfig: repairCfg}, nil
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"net/netip"
 	"strings"
+	"time"
 
 	cfg "istio.io/istio/tools/common/config"
 )
@@ -49,6 +50,14 @@ const (
 	PodDNSDisabled
 )
 
+// TrafficBackend identifies the packet filtering backend used to program Ambient redirection rules
+type TrafficBackend string
+
+const (
+	IptablesBackend TrafficBackend = "iptables"
+	NftablesBackend TrafficBackend = "nftables"
+)
+
 // PodLevelOverrides holds runtime/dynamic pod-level config overrides
 // that may need to be taken into account when injecting pod rules
 type PodLevelOverrides struct {
@@ -200,6 +209,24 @@ type RepairConfig struct {
 
 	// Choose which iptables binary to use (legacy or nft)
 	ForceIptablesBinary string
+
+	// Whether to detect ambient pods with broken traffic capture or enrollment
+	RepairAmbient bool
+
+	// Action to take for broken ambient pods: one of reenroll, restart or taint
+	AmbientAction string
+
+	// How long an ambient pod must stay broken before it is repaired
+	AmbientGracePeriod time.Duration
+
+	// How often ambient pods are re-checked, as traffic capture may break without any pod change
+	AmbientCheckInterval time.Duration
+
+	// Maximum number of ambient repair actions per minute
+	AmbientRepairsPerMinute int
+
+	// Key of the taint added to the node by the taint action
+	AmbientTaintKey string
 }
 
 func (c InstallConfig) String() string {
@@ -257,5 +284,11 @@ func (c RepairConfig) String() string {
 	b.WriteString("FieldSelectors: " + c.FieldSelectors + "\n")
 	b.WriteString("NativeNftables: " + fmt.Sprint(c.NativeNftables) + "\n")
 	b.WriteString("ForceIptablesBinary: " + fmt.Sprint(c.ForceIptablesBinary) + "\n")
+	b.WriteString("RepairAmbient: " + fmt.Sprint(c.RepairAmbient) + "\n")
+	b.WriteString("AmbientAction: " + c.AmbientAction + "\n")
+	b.WriteString("AmbientGracePeriod: " + c.AmbientGracePeriod.String() + "\n")
+	b.WriteString("AmbientCheckInterval: " + c.AmbientCheckInterval.String() + "\n")
+	b.WriteString("AmbientRepairsPerMinute: " + fmt.Sprint(c.AmbientRepairsPerMinute) + "\n")
+	b.WriteString("AmbientTaintKey: " + c.AmbientTaintKey + "\n")
 	return b.String()
 }
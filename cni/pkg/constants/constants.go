@@ -63,6 +63,13 @@ const (
 	RepairInitExitCode       = "repair-init-container-exit-code"
 	RepairLabelSelectors     = "repair-label-selectors"
 	RepairFieldSelectors     = "repair-field-selectors"
+
+	RepairAmbient                 = "repair-ambient"
+	RepairAmbientAction           = "repair-ambient-action"
+	RepairAmbientGracePeriod      = "repair-ambient-grace-period"
+	RepairAmbientCheckInterval    = "repair-ambient-check-interval"
+	RepairAmbientRepairsPerMinute = "repair-ambient-repairs-per-minute"
+	RepairAmbientTaintKey         = "repair-ambient-taint-key"
 )
 
 // Internal constants
@@ -81,11 +88,17 @@ const (
 	LivenessEndpoint                   = "/healthz"
 	ReadinessEndpoint                  = "/readyz"
 	ReadinessPort                      = "8000"
+	EnrollmentEndpoint                 = "/debug/enrollment"
+	TrafficBackendsEndpoint            = "/debug/trafficbackends"
+	CaptureEndpoint                    = "/debug/capture"
 	ServiceAccountPath                 = "/var/run/secrets/kubernetes.io/serviceaccount"
 	SelfNetNSPath                      = "/proc/self/ns/net"
 	DefaultIstioOwnedCNIConfigFilename = "02-istio-cni.conflist"
 )
 
+// DebugPort is the port of the node agent debug endpoints, which are only served on localhost
+const DebugPort = 15015
+
 // Exposed for testing "constants"
 var (
 	CNIBinDir     = "/opt/cni/bin"
@@ -17,6 +17,7 @@ package iptables
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"istio.io/istio/cni/pkg/config"
@@ -125,7 +126,121 @@ func (cfg *IptablesConfigurator) DeleteInpodRules(log *istiolog.Scope) error {
 	return errors.Join(inpodErrs...)
 }
 
-func (cfg *IptablesConfigurator) executeDeleteCommands(log *istiolog.Scope) {
+// DeleteInpodTrafficRules removes the in-pod iptables chains, but keeps the loopback route and
+// mark ip rule, which are shared with the nftables backend. As some of the delete commands are
+// expected to fail, depending on the iptables configuration, an error is only returned if any
+// of our chains remain afterwards.
+func (cfg *IptablesConfigurator) DeleteInpodTrafficRules(log *istiolog.Scope) error {
+	log.Debug("deleting iptables traffic rules")
+	errs := cfg.executeDeleteCommands(log)
+	found, err := cfg.HasInpodRules(log)
+	if err != nil {
+		return err
+	}
+	if found {
+		return fmt.Errorf("iptables chains remain after deletion: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// HasInpodRules reports whether any of our in-pod chains exist.
+// NOTE that this expects to be run from within the pod network namespace!
+func (cfg *IptablesConfigurator) HasInpodRules(log *istiolog.Scope) (bool, error) {
+	return cfg.hasChains(log, ChainInpodOutput, ChainInpodPrerouting)
+}
+
+// HasHostRules reports whether our host-level chain exists in the host network namespace.
+func (cfg *IptablesConfigurator) HasHostRules() (bool, error) {
+	var found bool
+	err := util.RunAsHost(func() error {
+		var err error
+		found, err = cfg.hasChains(log.WithLabels("component", "host"), ChainHostPostrouting)
+		return err
+	})
+	return found, err
+}
+
+// InpodRuleState returns the Istio rules this configurator would program for the given overrides,
+// and the Istio rules currently programmed, in iptables-save form prefixed with the IP family and table.
+// NOTE that this expects to be run from within the pod network namespace!
+func (cfg *IptablesConfigurator) InpodRuleState(log *istiolog.Scope, podOverrides config.PodLevelOverrides) ([]string, []string, error) {
+	iptablesBuilder := cfg.AppendInpodRules(podOverrides)
+
+	type family struct {
+		name     string
+		ver      dep.IptablesVersion
+		expected string
+	}
+	families := []family{{"ipv4", cfg.iptV, iptablesBuilder.BuildV4Restore()}}
+	if cfg.cfg.EnableIPv6 && cfg.ipt6V.DetectedBinary != "" {
+		families = append(families, family{"ipv6", cfg.ipt6V, iptablesBuilder.BuildV6Restore()})
+	}
+
+	var expected, installed []string
+	for _, f := range families {
+		expected = append(expected, istioRulesFromState(f.name, iptablesBuilder.GetStateFromSave(f.expected))...)
+		output, err := cfg.ext.Run(log, true, iptablesconstants.IPTablesSave, &f.ver, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		installed = append(installed, istioRulesFromState(f.name, iptablesBuilder.GetStateFromSave(output.String()))...)
+	}
+	return expected, installed, nil
+}
+
+// istioRulesFromState flattens the rules in our chains, and the jumps into them, with whitespace normalized.
+func istioRulesFromState(family string, state map[string]map[string][]string) []string {
+	var rules []string
+	for table, chains := range state {
+		for chain, chainRules := range chains {
+			for _, rule := range chainRules {
+				rule = strings.Join(strings.Fields(rule), " ")
+				if strings.HasPrefix(chain, "ISTIO") || strings.Contains(rule, "-j ISTIO") {
+					rules = append(rules, fmt.Sprintf("%s %s: %s", family, table, rule))
+				}
+			}
+		}
+	}
+	sort.Strings(rules)
+	return rules
+}
+
+// Backend returns the backend used by this configurator.
+func (cfg *IptablesConfigurator) Backend() config.TrafficBackend {
+	return config.IptablesBackend
+}
+
+func (cfg *IptablesConfigurator) hasChains(log *istiolog.Scope, chains ...string) (bool, error) {
+	iptablesVariant := []dep.IptablesVersion{cfg.iptV}
+	if cfg.cfg.EnableIPv6 && cfg.ipt6V.DetectedBinary != "" {
+		iptablesVariant = append(iptablesVariant, cfg.ipt6V)
+	}
+
+	for _, iptVer := range iptablesVariant {
+		output, err := cfg.ext.Run(log, true, iptablesconstants.IPTablesSave, &iptVer, nil)
+		if err != nil {
+			return false, err
+		}
+		if savedStateHasChains(output.String(), chains...) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// savedStateHasChains checks iptables-save output for the declaration of any of the given chains.
+func savedStateHasChains(saved string, chains ...string) bool {
+	for _, line := range strings.Split(saved, "\n") {
+		for _, chain := range chains {
+			if strings.HasPrefix(line, ":"+chain+" ") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (cfg *IptablesConfigurator) executeDeleteCommands(log *istiolog.Scope) []error {
 	deleteCmds := [][]string{
 		{"-t", "mangle", "-D", "PREROUTING", "-j", ChainInpodPrerouting},
 		{"-t", "mangle", "-D", "OUTPUT", "-j", ChainInpodOutput},
@@ -156,11 +271,15 @@ func (cfg *IptablesConfigurator) executeDeleteCommands(log *istiolog.Scope) {
 		iptablesVariant = append(iptablesVariant, cfg.ipt6V)
 	}
 
+	var errs []error
 	for _, iptVer := range iptablesVariant {
 		for _, cmd := range deleteCmds {
-			_, _ = cfg.ext.Run(log, true, iptablesconstants.IPTables, &iptVer, nil, cmd...)
+			if _, err := cfg.ext.Run(log, true, iptablesconstants.IPTables, &iptVer, nil, cmd...); err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", iptVer.DetectedBinary, strings.Join(cmd, " "), err))
+			}
 		}
 	}
+	return errs
 }
 
 // Setup iptables rules for in-pod mode. Ideally this should be an idempotent function.
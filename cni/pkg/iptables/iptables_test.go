@@ -15,15 +15,21 @@
 package iptables
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"net/netip"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
 	"istio.io/istio/cni/pkg/config"
 	"istio.io/istio/cni/pkg/scopes"
 	testutil "istio.io/istio/pilot/test/util"
+	istiolog "istio.io/istio/pkg/log"
 	"istio.io/istio/pkg/test/util/assert"
+	iptablesconstants "istio.io/istio/tools/istio-iptables/pkg/constants"
 	dep "istio.io/istio/tools/istio-iptables/pkg/dependencies"
 )
 
@@ -141,3 +147,76 @@ func constructTestConfig() *config.AmbientConfig {
 		HostProbeV6SNATAddress: probeSNATipv6,
 	}
 }
+
+func TestSavedStateHasChains(t *testing.T) {
+	saved := `# Generated by iptables-save v1.8.10 (nf_tables)
+*nat
+:PREROUTING ACCEPT [0:0]
+:ISTIO_OUTPUT - [0:0]
+-A OUTPUT -j ISTIO_OUTPUT
+COMMIT
+`
+	assert.Equal(t, savedStateHasChains(saved, ChainInpodOutput, ChainInpodPrerouting), true)
+	assert.Equal(t, savedStateHasChains(saved, ChainInpodPrerouting), false)
+	// a jump to a chain is not a declaration of it
+	assert.Equal(t, savedStateHasChains("-A OUTPUT -j ISTIO_PRERT\n", ChainInpodPrerouting), false)
+	assert.Equal(t, savedStateHasChains("", ChainHostPostrouting), false)
+}
+
+func TestDeleteInpodTrafficRules(t *testing.T) {
+	cfg := constructTestConfig()
+	ext := &dep.DependenciesStub{}
+	_, iptConfigurator, _ := NewIptablesConfigurator(cfg, cfg, ext, ext, EmptyNlDeps())
+
+	assert.NoError(t, iptConfigurator.DeleteInpodTrafficRules(scopes.CNIAgent))
+	assert.Equal(t, len(ext.ExecutedNormally), 0)
+	assert.Equal(t, slices.Contains(ext.ExecutedQuietly, "iptables -t nat -X ISTIO_OUTPUT"), true)
+	assert.Equal(t, iptConfigurator.Backend(), config.IptablesBackend)
+
+	found, err := iptConfigurator.HasInpodRules(scopes.CNIAgent)
+	assert.NoError(t, err)
+	assert.Equal(t, found, false)
+}
+
+func TestInpodRuleStateReportsMissingRules(t *testing.T) {
+	cfg := constructTestConfig()
+	ext := &dep.DependenciesStub{}
+	_, iptConfigurator, _ := NewIptablesConfigurator(cfg, cfg, ext, ext, EmptyNlDeps())
+
+	expected, installed, err := iptConfigurator.InpodRuleState(scopes.CNIAgent, config.PodLevelOverrides{})
+	assert.NoError(t, err)
+	// the stub returns an empty iptables-save output
+	assert.Equal(t, len(installed), 0)
+	assert.Equal(t, slices.Contains(expected, "ipv4 nat: -A OUTPUT -j ISTIO_OUTPUT"), true)
+	for _, rule := range expected {
+		assert.Equal(t, strings.HasPrefix(rule, "ipv4 "), true)
+	}
+}
+
+// busyDeps fails to delete chains, as iptables does when a chain is still referenced.
+type busyDeps struct {
+	dep.DependenciesStub
+	saved string
+}
+
+func (d *busyDeps) Run(logger *istiolog.Scope, quietLogging bool, cmd iptablesconstants.IptablesCmd, iptVer *dep.IptablesVersion,
+	stdin io.ReadSeeker, args ...string,
+) (*bytes.Buffer, error) {
+	if cmd == iptablesconstants.IPTablesSave {
+		return bytes.NewBufferString(d.saved), nil
+	}
+	if slices.Contains(args, "-X") {
+		return nil, errors.New("Device or resource busy")
+	}
+	return d.DependenciesStub.Run(logger, quietLogging, cmd, iptVer, stdin, args...)
+}
+
+func TestDeleteInpodTrafficRulesReportsRemainingChains(t *testing.T) {
+	cfg := constructTestConfig()
+	ext := &busyDeps{saved: "*nat\n:ISTIO_OUTPUT - [0:0]\nCOMMIT\n"}
+	_, iptConfigurator, _ := NewIptablesConfigurator(cfg, cfg, ext, ext, EmptyNlDeps())
+
+	err := iptConfigurator.DeleteInpodTrafficRules(scopes.CNIAgent)
+	assert.Error(t, err)
+	assert.Equal(t, strings.Contains(err.Error(), "iptables -t nat -X ISTIO_OUTPUT: Device or resource busy"), true)
+}
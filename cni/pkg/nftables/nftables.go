@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"sigs.k8s.io/knftables"
@@ -50,6 +51,9 @@ const (
 	Counter = "counter"
 )
 
+// ambientTables are the tables programmed in the pod network namespace.
+var ambientTables = []string{AmbientNatTable, AmbientMangleTable, AmbientRawTable}
+
 var log = scopes.CNIAgent
 
 type NftProviderFunc func(family knftables.Family, table string) (builder.NftablesAPI, error)
@@ -131,7 +135,13 @@ func (cfg *NftablesConfigurator) CreateInpodRules(log *istiolog.Scope, podOverri
 }
 
 func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOverrides) (*knftables.Transaction, error) {
-	cfg.ruleBuilder = builder.NewNftablesRuleBuilder(config.GetConfig(cfg.cfg))
+	cfg.ruleBuilder = cfg.buildInpodRules(podOverrides)
+	return cfg.executeCommands()
+}
+
+// buildInpodRules returns the in-pod rules for the given overrides, without programming them.
+func (cfg *NftablesConfigurator) buildInpodRules(podOverrides config.PodLevelOverrides) *builder.NftablesRuleBuilder {
+	ruleBuilder := builder.NewNftablesRuleBuilder(config.GetConfig(cfg.cfg))
 
 	var redirectDNS bool
 
@@ -144,33 +154,33 @@ func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOv
 		redirectDNS = false
 	}
 
-	cfg.ruleBuilder.AppendRule(
+	ruleBuilder.AppendRule(
 		PreroutingChain, AmbientMangleTable,
 		"jump", IstioPreroutingChain,
 	)
 
-	cfg.ruleBuilder.AppendRule(
+	ruleBuilder.AppendRule(
 		OutputChain, AmbientMangleTable,
 		"jump", IstioOutputChain,
 	)
 
-	cfg.ruleBuilder.AppendRule(
+	ruleBuilder.AppendRule(
 		OutputChain, AmbientNatTable,
 		"jump", IstioOutputChain,
 	)
 
 	if redirectDNS {
-		cfg.ruleBuilder.AppendRule(
+		ruleBuilder.AppendRule(
 			PreroutingChain, AmbientRawTable,
 			"jump", IstioPreroutingChain,
 		)
-		cfg.ruleBuilder.AppendRule(
+		ruleBuilder.AppendRule(
 			OutputChain, AmbientRawTable,
 			"jump", IstioOutputChain,
 		)
 	}
 
-	cfg.ruleBuilder.AppendRule(
+	ruleBuilder.AppendRule(
 		PreroutingChain, AmbientNatTable,
 		"jump", IstioPreroutingChain,
 	)
@@ -184,14 +194,14 @@ func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOv
 			// and just shunt it directly to the outbound port of the proxy.
 			// Note that for now this explicitly excludes UDP traffic, as we can't proxy arbitrary UDP stuff,
 			// and this is a difference from the old sidecar `traffic.sidecar.istio.io/kubevirtInterfaces` annotation.
-			cfg.ruleBuilder.AppendRule(IstioPreroutingChain, AmbientNatTable,
+			ruleBuilder.AppendRule(IstioPreroutingChain, AmbientNatTable,
 				"iifname", fmt.Sprint(virtInterface),
 				"meta l4proto tcp", Counter,
 				"redirect to", ":"+fmt.Sprint(config.ZtunnelOutboundPort),
 			)
 
 			// CLI: nft add rule inet istio-ambient-nat istio-prerouting iifname <iface> meta l4proto tcp counter return
-			cfg.ruleBuilder.AppendRule(IstioPreroutingChain, AmbientNatTable,
+			ruleBuilder.AppendRule(IstioPreroutingChain, AmbientNatTable,
 				"iifname", fmt.Sprint(virtInterface),
 				"meta l4proto tcp", Counter,
 				"return",
@@ -202,7 +212,7 @@ func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOv
 	if !podOverrides.IngressMode {
 		// CLI: nft add rule inet istio-ambient-mangle istio-prerouting meta mark & 0xfff == 0x539 counter ct mark set ct mark & 0xfffff000 | 0x111
 		// DESC: If we have a packet mark, set a connmark.
-		cfg.ruleBuilder.AppendRule(IstioPreroutingChain, AmbientMangleTable,
+		ruleBuilder.AppendRule(IstioPreroutingChain, AmbientMangleTable,
 			"meta mark & 0xfff ==",
 			fmt.Sprintf("0x%x", config.InpodMark), Counter, "ct mark set ct mark & 0xfffff000 | ",
 			fmt.Sprintf("0x%x", config.InpodTProxyMark))
@@ -216,7 +226,7 @@ func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOv
 		// CLI: nft add rule inet istio-ambient-nat istio-prerouting meta l4proto tcp ip saddr 169.254.7.127 counter accept
 		//
 		// DESC: If this is one of our node-probe ports and is from our SNAT-ed/"special" hostside IP, short-circuit out here
-		cfg.ruleBuilder.AppendRule(IstioPreroutingChain, AmbientNatTable,
+		ruleBuilder.AppendRule(IstioPreroutingChain, AmbientNatTable,
 			"meta l4proto tcp",
 			"ip saddr", cfg.cfg.HostProbeSNATAddress.String(), Counter,
 			"accept",
@@ -224,7 +234,7 @@ func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOv
 
 		// CLI: nft add rule inet istio-ambient-nat istio-prerouting meta l4proto tcp ip6 saddr
 		// fd16:9254:7127:1337:ffff:ffff:ffff:ffff counter accept
-		cfg.ruleBuilder.AppendRule(IstioPreroutingChain, AmbientNatTable,
+		ruleBuilder.AppendRule(IstioPreroutingChain, AmbientNatTable,
 			"meta l4proto tcp",
 			"ip6 saddr", cfg.cfg.HostProbeV6SNATAddress.String(), Counter,
 			"accept",
@@ -233,14 +243,14 @@ func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOv
 
 	// CLI: nft add rule inet istio-ambient-nat istio-output meta l4proto tcp ip daddr 169.254.7.127 counter accept
 	// DESC: Anything coming BACK from the pod healthcheck port with a dest of our SNAT-ed hostside IP we also short-circuit.
-	cfg.ruleBuilder.AppendRule(IstioOutputChain, AmbientNatTable,
+	ruleBuilder.AppendRule(IstioOutputChain, AmbientNatTable,
 		"meta l4proto tcp",
 		"ip daddr", cfg.cfg.HostProbeSNATAddress.String(), Counter,
 		"accept",
 	)
 
 	// CLI: nft add rule inet istio-ambient-nat istio-output meta l4proto tcp ip6 daddr fd16:9254:7127:1337:ffff:ffff:ffff:ffff counter accept
-	cfg.ruleBuilder.AppendRule(IstioOutputChain, AmbientNatTable,
+	ruleBuilder.AppendRule(IstioOutputChain, AmbientNatTable,
 		"meta l4proto tcp",
 		"ip6 daddr", cfg.cfg.HostProbeV6SNATAddress.String(), Counter,
 		"accept",
@@ -252,14 +262,14 @@ func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOv
 		//
 		// DESC: Anything that is not bound for localhost and does not have the mark, REDIRECT to ztunnel inbound plaintext port <INPLAINPORT>
 		// Skip 15008, which will go direct without redirect needed.
-		cfg.ruleBuilder.AppendRule(IstioPreroutingChain, AmbientNatTable,
+		ruleBuilder.AppendRule(IstioPreroutingChain, AmbientNatTable,
 			"ip daddr", "!=", "127.0.0.1/32",
 			"tcp dport", "!=", fmt.Sprint(config.ZtunnelInboundPort),
 			"mark and 0xfff ", "!=", fmt.Sprintf("0x%x", config.InpodMark), Counter,
 			"redirect to", ":"+fmt.Sprint(config.ZtunnelInboundPlaintextPort),
 		)
 
-		cfg.ruleBuilder.AppendRule(IstioPreroutingChain, AmbientNatTable,
+		ruleBuilder.AppendRule(IstioPreroutingChain, AmbientNatTable,
 			"ip6 daddr", "!=", "::1/128",
 			"tcp dport", "!=", fmt.Sprint(config.ZtunnelInboundPort),
 			"mark and 0xfff", "!=", fmt.Sprintf("0x%x", config.InpodMark), Counter,
@@ -270,7 +280,7 @@ func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOv
 	// CLI: nft add rule inet istio-ambient-mangle istio-output ct mark and 0xfff == 0x111 counter meta mark set ct mark
 	//
 	// DESC: Propagate/restore connmark (if we had one) for outbound
-	cfg.ruleBuilder.AppendRule(
+	ruleBuilder.AppendRule(
 		IstioOutputChain, AmbientMangleTable,
 		"ct mark and", fmt.Sprintf("0x%x", config.InpodTProxyMask),
 		"==", fmt.Sprintf("0x%x", config.InpodTProxyMark), Counter,
@@ -281,7 +291,7 @@ func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOv
 		// CLI: nft add rule inet istio-ambient-nat istio-output oifname != "lo" mark and 0xfff != 0x539 udp dport 53 counter redirect to :15053
 		//
 		// DESC: If this is a UDP DNS request to a non-localhost resolver, send it to ztunnel DNS proxy port
-		cfg.ruleBuilder.AppendRule(
+		ruleBuilder.AppendRule(
 			IstioOutputChain, AmbientNatTable,
 			"oifname", "!=", "lo",
 			"mark and", fmt.Sprintf("0x%x", config.InpodMask),
@@ -291,7 +301,7 @@ func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOv
 		)
 
 		// CLI: nft add rule inet istio-ambient-nat istio-output ip daddr != 127.0.0.1/32 tcp dport 53 mark and 0xfff != 0x539 counter redirect to :15053
-		cfg.ruleBuilder.AppendRule(
+		ruleBuilder.AppendRule(
 			IstioOutputChain, AmbientNatTable,
 			"ip daddr", "!=", "127.0.0.1/32",
 			"tcp dport", "53",
@@ -301,7 +311,7 @@ func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOv
 		)
 
 		// CLI: nft add rule inet istio-ambient-nat istio-output ip6 daddr != ::1/128 tcp dport 53 mark and 0xfff != 0x539 counter redirect to :15053
-		cfg.ruleBuilder.AppendRule(
+		ruleBuilder.AppendRule(
 			IstioOutputChain, AmbientNatTable,
 			"ip6 daddr", "!=", "::1/128",
 			"tcp dport", "53",
@@ -314,7 +324,7 @@ func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOv
 		// See https://github.com/istio/istio/issues/33469
 		// CLI: nft add rule inet istio-ambient-raw istio-output udp dport 53 meta mark and 0xfff == 0x539 counter ct zone set 1
 		// Proxy --> Upstream
-		cfg.ruleBuilder.AppendRule(
+		ruleBuilder.AppendRule(
 			IstioOutputChain, AmbientRawTable,
 			"udp dport", "53",
 			"meta mark and", fmt.Sprintf("0x%x", config.InpodMask),
@@ -324,7 +334,7 @@ func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOv
 
 		// CLI: nft add rule inet istio-ambient-raw istio-prerouting udp sport 53 meta mark and 0xfff != 0x539 counter ct zone set 1
 		// Upstream --> Proxy return packets
-		cfg.ruleBuilder.AppendRule(
+		ruleBuilder.AppendRule(
 			IstioPreroutingChain, AmbientRawTable,
 			"udp sport", "53",
 			"meta mark and", fmt.Sprintf("0x%x", config.InpodMask),
@@ -336,7 +346,7 @@ func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOv
 	// CLI: nft add rule inet istio-ambient-nat istio-output meta l4proto tcp mark and 0xfff == 0x111 counter accept
 	//
 	// DESC: If this is outbound and has our mark, let it go.
-	cfg.ruleBuilder.AppendRule(
+	ruleBuilder.AppendRule(
 		IstioOutputChain, AmbientNatTable,
 		"meta l4proto tcp",
 		"mark and", fmt.Sprintf("0x%x", config.InpodTProxyMask),
@@ -347,7 +357,7 @@ func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOv
 	// Do not redirect app calls to back itself via Ztunnel when using the endpoint address
 	// e.g. appN => appN by lo
 	// CLI: nft add rule inet istio-ambient-nat istio-output oifname "lo" ip daddr != 127.0.0.1 counter accept
-	cfg.ruleBuilder.AppendRule(
+	ruleBuilder.AppendRule(
 		IstioOutputChain, AmbientNatTable,
 		"oifname", "lo",
 		"ip daddr",
@@ -356,7 +366,7 @@ func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOv
 	)
 
 	// CLI: nft add rule inet istio-ambient-nat istio-output oifname "lo" ip6 daddr != ::1/128 counter accept
-	cfg.ruleBuilder.AppendRule(
+	ruleBuilder.AppendRule(
 		IstioOutputChain, AmbientNatTable,
 		"oifname", "lo",
 		"ip6 daddr",
@@ -367,7 +377,7 @@ func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOv
 	// CLI: nft add rule inet istio-ambient-nat istio-output meta l4proto tcp ip daddr != 127.0.0.1 mark and 0xfff != 0x539 counter redirect to :15001
 	//
 	// DESC: If this is outbound, not bound for localhost, and does not have our packet mark, redirect to ztunnel proxy <OUTPORT>
-	cfg.ruleBuilder.AppendRule(
+	ruleBuilder.AppendRule(
 		IstioOutputChain, AmbientNatTable,
 		"meta l4proto tcp",
 		"ip daddr",
@@ -377,7 +387,7 @@ func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOv
 		"redirect to", ":"+fmt.Sprintf("%d", config.ZtunnelOutboundPort),
 	)
 
-	cfg.ruleBuilder.AppendRule(
+	ruleBuilder.AppendRule(
 		IstioOutputChain, AmbientNatTable,
 		"meta l4proto tcp",
 		"ip6 daddr",
@@ -387,13 +397,21 @@ func (cfg *NftablesConfigurator) AppendInpodRules(podOverrides config.PodLevelOv
 		"redirect to", ":"+fmt.Sprintf("%d", config.ZtunnelOutboundPort),
 	)
 
-	return cfg.executeCommands()
+	return ruleBuilder
 }
 
 // DeleteInpodRules removes nftables rules from a pod's network namespace
 func (cfg *NftablesConfigurator) DeleteInpodRules(log *istiolog.Scope) error {
 	log.Info("removing nftables inpod rules")
 
+	var inpodErrs []error
+	inpodErrs = append(inpodErrs, cfg.DeleteInpodTrafficRules(log), cfg.delInpodMarkIPRule(), cfg.delLoopbackRoute())
+	return errors.Join(inpodErrs...)
+}
+
+// DeleteInpodTrafficRules removes the ambient nftables tables from a pod's network namespace, but keeps
+// the loopback route and mark ip rule, which are shared with the iptables backend.
+func (cfg *NftablesConfigurator) DeleteInpodTrafficRules(log *istiolog.Scope) error {
 	nft, err := cfg.nftProvider("", "")
 	if err != nil {
 		return err
@@ -414,13 +432,106 @@ func (cfg *NftablesConfigurator) DeleteInpodRules(log *istiolog.Scope) error {
 
 	if tx.NumOperations() > 0 {
 		if err := nft.Run(context.TODO(), tx); err != nil {
-			log.Errorf("error while trying to delete the ambient nftable rules: %w", err)
+			return fmt.Errorf("failed to delete the ambient nftables tables: %w", err)
 		}
 	}
+	return nil
+}
 
-	var inpodErrs []error
-	inpodErrs = append(inpodErrs, cfg.delInpodMarkIPRule(), cfg.delLoopbackRoute())
-	return errors.Join(inpodErrs...)
+// HasInpodRules reports whether any of the ambient nftables tables contain chains.
+// NOTE that this expects to be run from within the pod network namespace!
+func (cfg *NftablesConfigurator) HasInpodRules(_ *istiolog.Scope) (bool, error) {
+	for _, table := range ambientTables {
+		nft, err := cfg.nftProvider(knftables.InetFamily, table)
+		if err != nil {
+			return false, err
+		}
+		chains, err := nft.List(context.TODO(), "chain")
+		if knftables.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if len(chains) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// InpodRuleState returns a summary of the rules this configurator would program for the given overrides,
+// and of the rules currently programmed. knftables does not return the text of listed rules, so each chain
+// is summarized by its number of rules.
+// NOTE that this expects to be run from within the pod network namespace!
+func (cfg *NftablesConfigurator) InpodRuleState(_ *istiolog.Scope, podOverrides config.PodLevelOverrides) ([]string, []string, error) {
+	ruleBuilder := cfg.buildInpodRules(podOverrides)
+
+	var expected, installed []string
+	for _, table := range ambientTables {
+		counts := map[string]int{}
+		for _, rule := range ruleBuilder.Rules[table] {
+			counts[rule.Chain]++
+		}
+		for chain, n := range counts {
+			expected = append(expected, chainSummary(table, chain, n))
+		}
+
+		nft, err := cfg.nftProvider(knftables.InetFamily, table)
+		if err != nil {
+			return nil, nil, err
+		}
+		chains, err := nft.List(context.TODO(), "chain")
+		if knftables.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, chain := range chains {
+			rules, err := nft.ListRules(context.TODO(), chain)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(rules) > 0 {
+				installed = append(installed, chainSummary(table, chain, len(rules)))
+			}
+		}
+	}
+	sort.Strings(expected)
+	sort.Strings(installed)
+	return expected, installed, nil
+}
+
+func chainSummary(table, chain string, rules int) string {
+	return fmt.Sprintf("%s %s %s: %d rules", knftables.InetFamily, table, chain, rules)
+}
+
+// HasHostRules reports whether the host postrouting chain contains any rules. The table itself
+// is kept around by DeleteHostRules, as it holds the host probe sets.
+func (cfg *NftablesConfigurator) HasHostRules() (bool, error) {
+	var found bool
+	err := util.RunAsHost(func() error {
+		nft, err := cfg.nftProvider(knftables.InetFamily, AmbientNatTable)
+		if err != nil {
+			return err
+		}
+		rules, err := nft.ListRules(context.TODO(), PostroutingChain)
+		if knftables.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		found = len(rules) > 0
+		return nil
+	})
+	return found, err
+}
+
+// Backend returns the backend used by this configurator.
+func (cfg *NftablesConfigurator) Backend() config.TrafficBackend {
+	return config.NftablesBackend
 }
 
 // CreateHostRulesForHealthChecks creates host-level nftables rules for health check handling
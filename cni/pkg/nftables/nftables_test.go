@@ -18,6 +18,7 @@ import (
 	"context"
 	"net/netip"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -28,6 +29,7 @@ import (
 	"istio.io/istio/cni/pkg/iptables"
 	"istio.io/istio/cni/pkg/scopes"
 	testutil "istio.io/istio/pilot/test/util"
+	"istio.io/istio/pkg/test/util/assert"
 	dep "istio.io/istio/tools/istio-iptables/pkg/dependencies"
 	"istio.io/istio/tools/istio-nftables/pkg/builder"
 )
@@ -76,6 +78,21 @@ func (m *MockNftablesCapture) GetCapturedRules() []string {
 	return result
 }
 
+// tableViewProvider returns a provider serving the shared mock for transactions, and table-scoped
+// views of the tables created in it for lookups.
+func tableViewProvider(mock *MockNftablesCapture) NftProviderFunc {
+	return func(family knftables.Family, table string) (builder.NftablesAPI, error) {
+		if table == "" {
+			return mock, nil
+		}
+		view := builder.NewMockNftables(family, table)
+		mock.RLock()
+		view.Table = mock.Tables[family][table]
+		mock.RUnlock()
+		return view, nil
+	}
+}
+
 func TestNftablesPodOverrides(t *testing.T) {
 	cases := GetCommonInPodTestCases()
 
@@ -189,6 +206,70 @@ func TestInvokedTwiceIsIdempotent(t *testing.T) {
 	}
 }
 
+func TestDetectAndDeleteInpodTrafficRules(t *testing.T) {
+	cfg := constructTestConfig()
+	ext := &dep.DependenciesStub{}
+
+	mock := NewMockNftablesCapture()
+	originalProvider := nftProviderVar
+	nftProviderVar = tableViewProvider(mock)
+	defer func() {
+		nftProviderVar = originalProvider
+	}()
+
+	_, podConfigurator, _ := NewNftablesConfigurator(cfg, cfg, ext, ext, iptables.EmptyNlDeps())
+	assert.Equal(t, podConfigurator.Backend(), config.NftablesBackend)
+
+	found, err := podConfigurator.HasInpodRules(scopes.CNIAgent)
+	assert.NoError(t, err)
+	assert.Equal(t, found, false)
+
+	assert.NoError(t, podConfigurator.CreateInpodRules(scopes.CNIAgent, config.PodLevelOverrides{}))
+	found, err = podConfigurator.HasInpodRules(scopes.CNIAgent)
+	assert.NoError(t, err)
+	assert.Equal(t, found, true)
+
+	assert.NoError(t, podConfigurator.DeleteInpodTrafficRules(scopes.CNIAgent))
+	found, err = podConfigurator.HasInpodRules(scopes.CNIAgent)
+	assert.NoError(t, err)
+	assert.Equal(t, found, false)
+}
+
+func TestDetectHostRules(t *testing.T) {
+	cfg := constructTestConfig()
+	ext := &dep.DependenciesStub{}
+
+	mock := NewMockNftablesCapture()
+	originalProvider := nftProviderVar
+	nftProviderVar = tableViewProvider(mock)
+	defer func() {
+		nftProviderVar = originalProvider
+	}()
+
+	hostConfigurator, _, _ := NewNftablesConfigurator(cfg, cfg, ext, ext, iptables.EmptyNlDeps())
+
+	// The table is kept around for the host probe sets, even without any rules.
+	tx := mock.NewTransaction()
+	tx.Add(&knftables.Table{Name: AmbientNatTable, Family: knftables.InetFamily})
+	tx.Add(&knftables.Chain{Name: PostroutingChain, Table: AmbientNatTable, Family: knftables.InetFamily})
+	assert.NoError(t, mock.Run(context.Background(), tx))
+	found, err := hostConfigurator.HasHostRules()
+	assert.NoError(t, err)
+	assert.Equal(t, found, false)
+
+	tx = mock.NewTransaction()
+	tx.Add(&knftables.Rule{Chain: PostroutingChain, Table: AmbientNatTable, Family: knftables.InetFamily, Rule: "meta l4proto tcp counter"})
+	assert.NoError(t, mock.Run(context.Background(), tx))
+	found, err = hostConfigurator.HasHostRules()
+	assert.NoError(t, err)
+	assert.Equal(t, found, true)
+
+	hostConfigurator.DeleteHostRules()
+	found, err = hostConfigurator.HasHostRules()
+	assert.NoError(t, err)
+	assert.Equal(t, found, false)
+}
+
 func ipstr(ipv6 bool) string {
 	if ipv6 {
 		return "ipv6"
@@ -214,3 +295,32 @@ func constructTestConfig() *config.AmbientConfig {
 		HostProbeV6SNATAddress: probeSNATipv6,
 	}
 }
+
+func TestInpodRuleState(t *testing.T) {
+	cfg := constructTestConfig()
+	ext := &dep.DependenciesStub{}
+
+	mock := NewMockNftablesCapture()
+	originalProvider := nftProviderVar
+	nftProviderVar = tableViewProvider(mock)
+	defer func() {
+		nftProviderVar = originalProvider
+	}()
+
+	_, podConfigurator, _ := NewNftablesConfigurator(cfg, cfg, ext, ext, iptables.EmptyNlDeps())
+	expected, installed, err := podConfigurator.InpodRuleState(scopes.CNIAgent, config.PodLevelOverrides{})
+	assert.NoError(t, err)
+	assert.Equal(t, len(installed), 0)
+	assert.Equal(t, len(expected) > 0, true)
+
+	assert.NoError(t, podConfigurator.CreateInpodRules(scopes.CNIAgent, config.PodLevelOverrides{}))
+	expectedAfter, installed, err := podConfigurator.InpodRuleState(scopes.CNIAgent, config.PodLevelOverrides{})
+	assert.NoError(t, err)
+	assert.Equal(t, expectedAfter, expected)
+	assert.Equal(t, installed, expected)
+
+	// an ingress pod has fewer rules than those programmed
+	expected, _, err = podConfigurator.InpodRuleState(scopes.CNIAgent, config.PodLevelOverrides{IngressMode: true})
+	assert.NoError(t, err)
+	assert.Equal(t, slices.Equal(expected, installed), false)
+}
@@ -0,0 +1,51 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeagent
+
+import (
+	"sync/atomic"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"istio.io/istio/cni/pkg/capture"
+)
+
+// currentCaptureInspector inspects the traffic capture state of pods on this node, served by the health server.
+var currentCaptureInspector atomic.Pointer[captureInspector]
+
+// captureInspector looks up pods on this node and inspects their traffic capture state.
+type captureInspector struct {
+	pods    func() []*corev1.Pod
+	inspect func(pod *corev1.Pod) *capture.Status
+}
+
+// Inspect returns the capture status of the named pod, or nil if the pod is not running on this node.
+func (c *captureInspector) Inspect(namespace, name string) *capture.Status {
+	for _, pod := range c.pods() {
+		if pod.Namespace == namespace && pod.Name == name {
+			return c.inspect(pod)
+		}
+	}
+	return nil
+}
+
+// InspectCapture reports the traffic capture state of a pod on this node, or nil if capture
+// inspection is not supported on this platform.
+func (s *Server) InspectCapture(pod *corev1.Pod) *capture.Status {
+	if s.capture == nil {
+		return nil
+	}
+	return s.capture.inspect(pod)
+}
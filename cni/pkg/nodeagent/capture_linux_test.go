@@ -0,0 +1,97 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeagent
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"istio.io/api/annotation"
+	set "istio.io/istio/cni/pkg/addressset"
+	"istio.io/istio/cni/pkg/capture"
+	"istio.io/istio/cni/pkg/config"
+	"istio.io/istio/cni/pkg/ipset"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/test/util/assert"
+	"istio.io/istio/pkg/util/sets"
+)
+
+func TestInspectCapture(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setupLogging()
+	fixture := getTestFixure(ctx)
+	netServer := fixture.netServer
+	netServer.trafficManager = &fakeTrafficManager{
+		backend:        config.IptablesBackend,
+		expectedRules:  []string{"ipv4 nat: -A ISTIO_OUTPUT -j ACCEPT", "ipv4 nat: -A OUTPUT -j ISTIO_OUTPUT"},
+		installedRules: []string{"ipv4 nat: -A OUTPUT -j ISTIO_OUTPUT", "ipv4 nat: -A OUTPUT -j ISTIO_OUTPUT"},
+	}
+
+	fakeIPSetDeps := ipset.FakeNLDeps()
+	fakeIPSetDeps.On("listEntriesByIP", "foo-v4").Return([]netip.Addr{netip.MustParseAddr("2.2.2.2")}, nil)
+	dp := &meshDataplane{
+		netServer:   netServer,
+		hostAddrSet: set.NewIPSetWrapper(ipset.IPSet{V4Name: "foo-v4", Prefix: "foo", Deps: fakeIPSetDeps}),
+	}
+
+	pod := buildConvincingPod(false)
+	pod.Annotations = map[string]string{annotation.AmbientRedirection.Name: constants.AmbientRedirectionEnabled}
+	inspector := &captureInspector{
+		pods:    func() []*corev1.Pod { return []*corev1.Pod{pod} },
+		inspect: dp.inspectCapture,
+	}
+
+	// not tracked yet
+	status := inspector.Inspect(pod.Namespace, pod.Name)
+	assert.Equal(t, status.Enrolled, false)
+	assert.Equal(t, status.Errors, []string{"failed to inspect inpod rules: pod netns is not tracked by the node agent"})
+
+	fixture.podNsMap.UpsertPodCacheWithNetns(string(pod.UID), WorkloadInfo{Workload: podToWorkload(pod), Netns: newFakeNs(inc())})
+	status = inspector.Inspect(pod.Namespace, pod.Name)
+	assert.Equal(t, status.Enrolled, true)
+	assert.Equal(t, status.ZtunnelConnected, false)
+	assert.Equal(t, len(status.Errors), 0)
+	assert.Equal(t, status.Rules, &capture.RuleInspection{
+		Backend:    string(config.IptablesBackend),
+		Expected:   []string{"ipv4 nat: -A ISTIO_OUTPUT -j ACCEPT", "ipv4 nat: -A OUTPUT -j ISTIO_OUTPUT"},
+		Installed:  []string{"ipv4 nat: -A OUTPUT -j ISTIO_OUTPUT", "ipv4 nat: -A OUTPUT -j ISTIO_OUTPUT"},
+		Missing:    []string{"ipv4 nat: -A ISTIO_OUTPUT -j ACCEPT"},
+		Unexpected: []string{"ipv4 nat: -A OUTPUT -j ISTIO_OUTPUT"},
+	})
+	assert.Equal(t, status.HostProbeSet, &capture.AddressSetInspection{
+		Name:     "foo",
+		Expected: []string{"2.2.2.2", "3.3.3.3"},
+		Missing:  []string{"3.3.3.3"},
+	})
+	assert.Equal(t, status.Drifted(), true)
+
+	fixture.ztunnelServer.acked = sets.New(string(pod.UID))
+	status = inspector.Inspect(pod.Namespace, pod.Name)
+	assert.Equal(t, status.ZtunnelConnected, true)
+	assert.Equal(t, status.ZtunnelAcknowledged, true)
+
+	// pods which are not enrolled are not inspected
+	pod.Annotations = nil
+	status = inspector.Inspect(pod.Namespace, pod.Name)
+	assert.Equal(t, status.Enrolled, false)
+	assert.Equal(t, status.Rules, nil)
+	assert.Equal(t, status.Drifted(), false)
+
+	assert.Equal(t, inspector.Inspect(pod.Namespace, "missing"), nil)
+}
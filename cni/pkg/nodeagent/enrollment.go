@@ -0,0 +1,247 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeagent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"istio.io/istio/cni/pkg/util"
+	"istio.io/istio/pkg/maps"
+	"istio.io/istio/pkg/monitoring"
+	"istio.io/istio/pkg/util/sets"
+)
+
+// EnrollmentDiscrepancyType describes how the enrollment state of a pod has drifted.
+type EnrollmentDiscrepancyType string
+
+const (
+	// DiscrepancyNotCached means the pod is annotated as enrolled, but the node agent has no netns for it.
+	DiscrepancyNotCached EnrollmentDiscrepancyType = "NotCached"
+	// DiscrepancyNotAcknowledged means the pod is enrolled and cached, but the connected ztunnel has not acknowledged it.
+	DiscrepancyNotAcknowledged EnrollmentDiscrepancyType = "NotAcknowledged"
+	// DiscrepancyStale means the node agent or ztunnel still holds a pod which should no longer be enrolled.
+	DiscrepancyStale EnrollmentDiscrepancyType = "Stale"
+)
+
+var (
+	discrepancyTypeTag = monitoring.CreateLabel("type")
+	repairResultTag    = monitoring.CreateLabel("result")
+
+	enrollmentDiscrepancies = monitoring.NewGauge(
+		"nodeagent_enrollment_discrepancies",
+		"The number of pods whose enrollment state differs between the informer, the pod cache and ztunnel.",
+	)
+	enrollmentRepairs = monitoring.NewSum(
+		"nodeagent_enrollment_repairs_total",
+		"The total number of enrollment repairs attempted by the node agent.",
+	)
+)
+
+// lastEnrollmentReport holds the result of the latest reconciliation pass, served by the health server.
+var lastEnrollmentReport atomic.Pointer[EnrollmentReport]
+
+// EnrollmentReport is a point-in-time comparison of the pods that should be enrolled on this node
+// against the pod cache and the snapshot acknowledged by ztunnel.
+type EnrollmentReport struct {
+	Time             time.Time               `json:"time"`
+	ZtunnelConnected bool                    `json:"ztunnelConnected"`
+	Expected         int                     `json:"expected"`
+	Cached           int                     `json:"cached"`
+	Acknowledged     int                     `json:"acknowledged"`
+	Discrepancies    []EnrollmentDiscrepancy `json:"discrepancies"`
+}
+
+// EnrollmentDiscrepancy is a single pod whose enrollment state has drifted.
+type EnrollmentDiscrepancy struct {
+	UID       string                    `json:"uid"`
+	Namespace string                    `json:"namespace,omitempty"`
+	Name      string                    `json:"name,omitempty"`
+	Type      EnrollmentDiscrepancyType `json:"type"`
+	// Repaired is set if a repair was attempted during this pass, and reports whether it succeeded.
+	Repaired    *bool  `json:"repaired,omitempty"`
+	RepairError string `json:"repairError,omitempty"`
+}
+
+// enrollmentReconciler periodically compares the informer state, the pod netns cache and the snapshot
+// acknowledged by ztunnel, and optionally repairs any drift between them.
+type enrollmentReconciler struct {
+	pods    func() []*corev1.Pod
+	cache   *podNetnsCache
+	ztunnel ZtunnelServer
+	// reenroll removes the inpod rules of a pod, creates them again and sends the pod to ztunnel.
+	reenroll func(ctx context.Context, pod *corev1.Pod) error
+	// remove removes a pod from the mesh: its host ipset entry, its inpod rules and its ztunnel enrollment.
+	remove func(ctx context.Context, pod *corev1.Pod, isDelete bool) error
+	repair bool
+
+	// previous holds the discrepancies found during the last pass. We only repair discrepancies
+	// observed on two consecutive passes, so enrollments that are still in flight are left alone.
+	previous map[string]EnrollmentDiscrepancyType
+}
+
+func newEnrollmentReconciler(
+	pods func() []*corev1.Pod,
+	cache *podNetnsCache,
+	ztunnel ZtunnelServer,
+	reenroll func(ctx context.Context, pod *corev1.Pod) error,
+	remove func(ctx context.Context, pod *corev1.Pod, isDelete bool) error,
+	repair bool,
+) *enrollmentReconciler {
+	return &enrollmentReconciler{
+		pods:     pods,
+		cache:    cache,
+		ztunnel:  ztunnel,
+		reenroll: reenroll,
+		remove:   remove,
+		repair:   repair,
+		previous: map[string]EnrollmentDiscrepancyType{},
+	}
+}
+
+// Run reconciles enrollment state every interval until the context is cancelled.
+func (r *enrollmentReconciler) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			report := r.reconcile(ctx)
+			lastEnrollmentReport.Store(report)
+		}
+	}
+}
+
+func (r *enrollmentReconciler) reconcile(ctx context.Context) *EnrollmentReport {
+	expected := map[string]*corev1.Pod{}
+	known := map[string]*corev1.Pod{}
+	inFlight := sets.New[string]()
+	for _, pod := range r.pods() {
+		uid := string(pod.UID)
+		known[uid] = pod
+		if util.PodFullyEnrolled(pod) {
+			expected[uid] = pod
+		} else if util.PodPartiallyEnrolled(pod) {
+			// The informer is still retrying this pod, leave it alone.
+			inFlight.Insert(uid)
+		}
+	}
+	cached := r.cache.ReadCurrentPodSnapshot()
+	acked, connected := r.ztunnel.AcknowledgedPods()
+
+	report := &EnrollmentReport{
+		Time:             time.Now(),
+		ZtunnelConnected: connected,
+		Expected:         len(expected),
+		Cached:           len(cached),
+		Acknowledged:     acked.Len(),
+	}
+
+	found := map[string]EnrollmentDiscrepancyType{}
+	for uid := range expected {
+		wl, f := cached[uid]
+		switch {
+		case !f || wl.Netns == nil:
+			found[uid] = DiscrepancyNotCached
+		case connected && !acked.Contains(uid):
+			found[uid] = DiscrepancyNotAcknowledged
+		}
+	}
+	for uid := range sets.New(maps.Keys(cached)...).Union(acked) {
+		if _, f := expected[uid]; f || inFlight.Contains(uid) {
+			continue
+		}
+		found[uid] = DiscrepancyStale
+	}
+
+	for uid, t := range found {
+		d := EnrollmentDiscrepancy{UID: uid, Type: t}
+		if pod := expected[uid]; pod != nil {
+			d.Namespace, d.Name = pod.Namespace, pod.Name
+		}
+		if r.repair && r.previous[uid] == t {
+			err := r.repairPod(ctx, uid, t, known[uid], cached[uid], connected)
+			ok := err == nil
+			d.Repaired = &ok
+			result := "success"
+			if err != nil {
+				d.RepairError = err.Error()
+				result = "failure"
+				log.WithLabels("uid", uid, "ns", d.Namespace, "name", d.Name).Warnf("failed to repair %s enrollment: %v", t, err)
+			}
+			enrollmentRepairs.With(discrepancyTypeTag.Value(string(t)), repairResultTag.Value(result)).Increment()
+		}
+		report.Discrepancies = append(report.Discrepancies, d)
+	}
+	sort.Slice(report.Discrepancies, func(i, j int) bool {
+		return report.Discrepancies[i].UID < report.Discrepancies[j].UID
+	})
+
+	counts := map[EnrollmentDiscrepancyType]int{DiscrepancyNotCached: 0, DiscrepancyNotAcknowledged: 0, DiscrepancyStale: 0}
+	for _, t := range found {
+		counts[t]++
+	}
+	for t, n := range counts {
+		enrollmentDiscrepancies.With(discrepancyTypeTag.Value(string(t))).RecordInt(int64(n))
+	}
+	if len(found) > 0 {
+		log.Infof("found %d pod enrollment discrepancies", len(found))
+	}
+	r.previous = found
+	return report
+}
+
+// repairPod repairs a single discrepancy. pod is the informer's view of the pod, which is nil for stale pods
+// that no longer exist, and wl is the cached workload, if any.
+func (r *enrollmentReconciler) repairPod(
+	ctx context.Context,
+	uid string,
+	t EnrollmentDiscrepancyType,
+	pod *corev1.Pod,
+	wl WorkloadInfo,
+	connected bool,
+) error {
+	log := log.WithLabels("uid", uid)
+	switch t {
+	case DiscrepancyNotCached, DiscrepancyNotAcknowledged:
+		if !connected {
+			return fmt.Errorf("no ztunnel connection")
+		}
+		log.WithLabels("ns", pod.Namespace, "name", pod.Name).Infof("re-enrolling %s pod", t)
+		return r.reenroll(ctx, pod)
+	case DiscrepancyStale:
+		log.Infof("removing stale pod enrollment")
+		// A pod which still exists keeps running outside of the mesh, so its inpod rules must be removed.
+		// Otherwise only the host side and ztunnel are cleaned up.
+		isDelete := pod == nil
+		if isDelete {
+			pod = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{UID: types.UID(uid)}}
+			if wl.Workload != nil {
+				pod.Namespace, pod.Name = wl.Workload.Namespace, wl.Workload.Name
+			}
+		}
+		return r.remove(ctx, pod, isDelete)
+	}
+	return fmt.Errorf("unknown discrepancy type %q", t)
+}
@@ -0,0 +1,168 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeagent
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"istio.io/api/annotation"
+	set "istio.io/istio/cni/pkg/addressset"
+	"istio.io/istio/cni/pkg/ipset"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/ptr"
+	"istio.io/istio/pkg/test/util/assert"
+	"istio.io/istio/pkg/util/sets"
+)
+
+func enrollmentTestPod(uid, redirection string) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "pod-" + uid,
+		Namespace: "test",
+		UID:       types.UID(uid),
+	}}
+	if redirection != "" {
+		pod.Annotations = map[string]string{annotation.AmbientRedirection.Name: redirection}
+	}
+	return pod
+}
+
+func TestEnrollmentReconciler(t *testing.T) {
+	ctx := context.Background()
+	pods := []*corev1.Pod{
+		// enrolled, cached and acknowledged: healthy
+		enrollmentTestPod("healthy", constants.AmbientRedirectionEnabled),
+		// enrolled and cached but ztunnel never acknowledged it
+		enrollmentTestPod("unacked", constants.AmbientRedirectionEnabled),
+		// enrolled, but we lost the netns
+		enrollmentTestPod("uncached", constants.AmbientRedirectionEnabled),
+		// still being retried by the informer
+		enrollmentTestPod("pending", constants.AmbientRedirectionPending),
+	}
+	cache := newPodNetnsCache(openNsTestOverride)
+	for _, uid := range []string{"healthy", "unacked", "pending", "stale"} {
+		_, err := cache.UpsertPodCache(enrollmentTestPod(uid, ""), "/path/"+uid)
+		assert.NoError(t, err)
+	}
+	ztunnel := &fakeZtunnel{acked: sets.New("healthy", "pending", "stale")}
+
+	r := newEnrollmentReconciler(
+		func() []*corev1.Pod { return pods },
+		cache,
+		ztunnel,
+		func(ctx context.Context, pod *corev1.Pod) error { return ztunnel.PodAdded(ctx, pod, newFakeNs(inc())) },
+		func(ctx context.Context, pod *corev1.Pod, isDelete bool) error {
+			cache.Take(string(pod.UID))
+			return ztunnel.PodDeleted(ctx, string(pod.UID))
+		},
+		true,
+	)
+
+	report := r.reconcile(ctx)
+	assert.Equal(t, report.ZtunnelConnected, true)
+	assert.Equal(t, report.Expected, 3)
+	assert.Equal(t, report.Cached, 4)
+	assert.Equal(t, report.Acknowledged, 3)
+	assert.Equal(t, report.Discrepancies, []EnrollmentDiscrepancy{
+		{UID: "stale", Type: DiscrepancyStale},
+		{UID: "unacked", Namespace: "test", Name: "pod-unacked", Type: DiscrepancyNotAcknowledged},
+		{UID: "uncached", Namespace: "test", Name: "pod-uncached", Type: DiscrepancyNotCached},
+	})
+	// Nothing is repaired on the first observation
+	assert.Equal(t, ztunnel.addedPods.Load(), 0)
+	assert.Equal(t, ztunnel.deletedPods.Load(), 0)
+
+	report = r.reconcile(ctx)
+	assert.Equal(t, len(report.Discrepancies), 3)
+	for _, d := range report.Discrepancies {
+		assert.Equal(t, *d.Repaired, true)
+	}
+	assert.Equal(t, ztunnel.addedPods.Load(), 2)
+	assert.Equal(t, ztunnel.deletedPods.Load(), 1)
+	assert.Equal(t, cache.Get("stale"), nil)
+}
+
+func TestEnrollmentReconcilerNoZtunnel(t *testing.T) {
+	pods := []*corev1.Pod{enrollmentTestPod("uncached", constants.AmbientRedirectionEnabled)}
+	ztunnel := &fakeZtunnel{}
+	r := newEnrollmentReconciler(
+		func() []*corev1.Pod { return pods },
+		newPodNetnsCache(openNsTestOverride),
+		ztunnel,
+		func(ctx context.Context, pod *corev1.Pod) error { return ztunnel.PodAdded(ctx, pod, newFakeNs(inc())) },
+		nil,
+		true,
+	)
+
+	r.reconcile(context.Background())
+	report := r.reconcile(context.Background())
+	assert.Equal(t, report.ZtunnelConnected, false)
+	assert.Equal(t, len(report.Discrepancies), 1)
+	assert.Equal(t, *report.Discrepancies[0].Repaired, false)
+	assert.Equal(t, report.Discrepancies[0].RepairError, "no ztunnel connection")
+	assert.Equal(t, ztunnel.addedPods.Load(), 0)
+}
+
+func TestEnrollmentReconcilerRemovesStalePod(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setupLogging()
+	fixture := getTestFixure(ctx)
+	fixture.ztunnelServer.acked = sets.New[string]()
+	nlDeps := fixture.nlDeps
+
+	// The pod is still running but no longer enrolled, while the node agent still holds its netns.
+	pod := buildConvincingPod(false)
+	fixture.podNsMap.UpsertPodCacheWithNetns(string(pod.UID), WorkloadInfo{
+		Workload: podToWorkload(pod),
+		Netns:    newFakeNs(123),
+	})
+
+	fakeIPSetDeps := ipset.FakeNLDeps()
+	setWrapper := set.NewIPSetWrapper(ipset.IPSet{V4Name: "foo-v4", Prefix: "foo", Deps: fakeIPSetDeps})
+	expectPodRemovedFromIPSet(fakeIPSetDeps, string(pod.UID), pod.Status.PodIPs)
+	m := &meshDataplane{
+		kubeClient:  fake.NewClientset(pod),
+		netServer:   fixture.netServer,
+		hostAddrSet: setWrapper,
+	}
+
+	r := newEnrollmentReconciler(
+		func() []*corev1.Pod { return []*corev1.Pod{pod} },
+		fixture.podNsMap,
+		fixture.ztunnelServer,
+		fixture.netServer.reenrollPod,
+		m.RemovePodFromMesh,
+		true,
+	)
+	r.reconcile(ctx)
+	report := r.reconcile(ctx)
+	assert.Equal(t, report.Discrepancies, []EnrollmentDiscrepancy{
+		{UID: string(pod.UID), Type: DiscrepancyStale, Repaired: ptr.Of(true)},
+	})
+
+	// the host ipset entries are removed
+	fakeIPSetDeps.AssertExpectations(t)
+	// the inpod rules are removed
+	assert.Equal(t, nlDeps.DelInpodMarkIPRuleCnt.Load(), 1)
+	assert.Equal(t, nlDeps.DelLoopbackRoutesCnt.Load(), 1)
+	assert.Equal(t, fixture.ztunnelServer.deletedPods.Load(), 1)
+	assert.Equal(t, fixture.podNsMap.Get(string(pod.UID)), nil)
+}
@@ -27,6 +27,9 @@ import (
 
 	"istio.io/istio/cni/pkg/config"
 	"istio.io/istio/cni/pkg/iptables"
+	"istio.io/istio/cni/pkg/trafficmanager"
+	istiolog "istio.io/istio/pkg/log"
+	"istio.io/istio/pkg/util/sets"
 )
 
 //go:embed testdata/cgroupns
@@ -37,6 +40,7 @@ type fakeZtunnel struct {
 	addedPods   atomic.Int32
 	addError    error
 	delError    error
+	acked       sets.String
 }
 
 func (f *fakeZtunnel) Run(ctx context.Context) {
@@ -52,6 +56,10 @@ func (f *fakeZtunnel) PodAdded(ctx context.Context, pod *corev1.Pod, netns Netns
 	return f.addError
 }
 
+func (f *fakeZtunnel) AcknowledgedPods() (sets.String, bool) {
+	return f.acked, f.acked != nil
+}
+
 func (f *fakeZtunnel) Close() error {
 	return nil
 }
@@ -195,3 +203,61 @@ type NoOpPodNetnsProcFinder struct{}
 func (p *NoOpPodNetnsProcFinder) FindNetnsForPods(pods map[types.UID]*corev1.Pod) (PodToNetns, error) {
 	return make(PodToNetns), errors.New("NoOpPodNetnsProcFinder always returns an error")
 }
+
+// fakeTrafficManager is a pod traffic manager recording which rules it was asked to manage.
+type fakeTrafficManager struct {
+	backend          config.TrafficBackend
+	hasRules         bool
+	createErr        error
+	deleteTrafficErr error
+	expectedRules    []string
+	installedRules   []string
+
+	createdCnt        atomic.Int32
+	deletedCnt        atomic.Int32
+	deletedTrafficCnt atomic.Int32
+}
+
+var _ trafficmanager.TrafficRuleManager = &fakeTrafficManager{}
+
+func (f *fakeTrafficManager) CreateInpodRules(*istiolog.Scope, config.PodLevelOverrides) error {
+	f.createdCnt.Add(1)
+	if f.createErr != nil {
+		return f.createErr
+	}
+	f.hasRules = true
+	return nil
+}
+
+func (f *fakeTrafficManager) DeleteInpodRules(*istiolog.Scope) error {
+	f.deletedCnt.Add(1)
+	f.hasRules = false
+	return nil
+}
+
+func (f *fakeTrafficManager) DeleteInpodTrafficRules(*istiolog.Scope) error {
+	f.deletedTrafficCnt.Add(1)
+	if f.deleteTrafficErr != nil {
+		return f.deleteTrafficErr
+	}
+	f.hasRules = false
+	return nil
+}
+
+func (f *fakeTrafficManager) HasInpodRules(*istiolog.Scope) (bool, error) {
+	return f.hasRules, nil
+}
+
+func (f *fakeTrafficManager) CreateHostRulesForHealthChecks() error { return nil }
+
+func (f *fakeTrafficManager) DeleteHostRules() {}
+
+func (f *fakeTrafficManager) HasHostRules() (bool, error) { return false, nil }
+
+func (f *fakeTrafficManager) ReconcileModeEnabled() bool { return false }
+
+func (f *fakeTrafficManager) Backend() config.TrafficBackend { return f.backend }
+
+func (f *fakeTrafficManager) InpodRuleState(*istiolog.Scope, config.PodLevelOverrides) ([]string, []string, error) {
+	return f.expectedRules, f.installedRules, nil
+}
@@ -15,6 +15,8 @@
 package nodeagent
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"sync/atomic"
 
@@ -22,13 +24,20 @@ import (
 )
 
 // StartHealthServer initializes and starts a web server that exposes liveness and readiness endpoints at port 8000.
+// The debug endpoints are served by a separate web server, which only listens on localhost, as the node agent runs in
+// the host network and its debug endpoints are not authenticated.
 func StartHealthServer() (installReady *atomic.Value, watchReady *atomic.Value) {
 	router := http.NewServeMux()
 	installReady, watchReady = initRouter(router)
+	debugRouter := http.NewServeMux()
+	initDebugRouter(debugRouter)
 
 	go func() {
 		_ = http.ListenAndServe(":"+constants.ReadinessPort, router)
 	}()
+	go func() {
+		_ = http.ListenAndServe(fmt.Sprintf("localhost:%d", constants.DebugPort), debugRouter)
+	}()
 
 	return installReady, watchReady
 }
@@ -45,6 +54,12 @@ func initRouter(router *http.ServeMux) (installReady *atomic.Value, watchReady *
 	return installReady, watchReady
 }
 
+func initDebugRouter(router *http.ServeMux) {
+	router.HandleFunc(constants.EnrollmentEndpoint, enrollmentz)
+	router.HandleFunc(constants.TrafficBackendsEndpoint, trafficbackendsz)
+	router.HandleFunc(constants.CaptureEndpoint, capturez)
+}
+
 func healthz(w http.ResponseWriter, _ *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
@@ -58,3 +73,46 @@ func readyz(installReady, watchReady *atomic.Value) http.HandlerFunc {
 		w.WriteHeader(http.StatusOK)
 	}
 }
+
+// enrollmentz serves the latest pod enrollment reconciliation report as JSON.
+func enrollmentz(w http.ResponseWriter, _ *http.Request) {
+	report := lastEnrollmentReport.Load()
+	if report == nil {
+		http.Error(w, "no enrollment report available yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// trafficbackendsz serves the traffic backend of each enrolled pod as JSON.
+func trafficbackendsz(w http.ResponseWriter, _ *http.Request) {
+	backends := currentTrafficBackends.Load()
+	if backends == nil {
+		http.Error(w, "traffic backends are not tracked", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(backends.Report())
+}
+
+// capturez serves the traffic capture state of the pod named by the "namespace" and "name" query parameters as JSON.
+func capturez(w http.ResponseWriter, r *http.Request) {
+	namespace, name := r.URL.Query().Get("namespace"), r.URL.Query().Get("name")
+	if namespace == "" || name == "" {
+		http.Error(w, "namespace and name query parameters are required", http.StatusBadRequest)
+		return
+	}
+	inspector := currentCaptureInspector.Load()
+	if inspector == nil {
+		http.Error(w, "capture inspection is not supported", http.StatusServiceUnavailable)
+		return
+	}
+	status := inspector.Inspect(namespace, name)
+	if status == nil {
+		http.Error(w, "pod "+namespace+"/"+name+" not found on this node", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(status)
+}
@@ -19,6 +19,11 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/cni/pkg/capture"
+	"istio.io/istio/cni/pkg/config"
 	"istio.io/istio/cni/pkg/constants"
 	"istio.io/istio/pkg/test/util/assert"
 )
@@ -52,6 +57,57 @@ func TestServer(t *testing.T) {
 	makeReq(t, server.URL, constants.ReadinessEndpoint, http.StatusServiceUnavailable)
 }
 
+func TestEnrollmentEndpoint(t *testing.T) {
+	router := http.NewServeMux()
+	initDebugRouter(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	lastEnrollmentReport.Store(nil)
+	makeReq(t, server.URL, constants.EnrollmentEndpoint, http.StatusServiceUnavailable)
+
+	lastEnrollmentReport.Store(&EnrollmentReport{Expected: 1})
+	t.Cleanup(func() { lastEnrollmentReport.Store(nil) })
+	makeReq(t, server.URL, constants.EnrollmentEndpoint, http.StatusOK)
+}
+
+func TestTrafficBackendsEndpoint(t *testing.T) {
+	router := http.NewServeMux()
+	initDebugRouter(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	currentTrafficBackends.Store(nil)
+	makeReq(t, server.URL, constants.TrafficBackendsEndpoint, http.StatusServiceUnavailable)
+
+	currentTrafficBackends.Store(newPodTrafficBackends(config.NftablesBackend))
+	t.Cleanup(func() { currentTrafficBackends.Store(nil) })
+	makeReq(t, server.URL, constants.TrafficBackendsEndpoint, http.StatusOK)
+}
+
+func TestCaptureEndpoint(t *testing.T) {
+	router := http.NewServeMux()
+	initDebugRouter(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	currentCaptureInspector.Store(nil)
+	makeReq(t, server.URL, constants.CaptureEndpoint, http.StatusBadRequest)
+	makeReq(t, server.URL, constants.CaptureEndpoint+"?namespace=test&name=pod", http.StatusServiceUnavailable)
+
+	currentCaptureInspector.Store(&captureInspector{
+		pods: func() []*corev1.Pod {
+			return []*corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pod"}}}
+		},
+		inspect: func(pod *corev1.Pod) *capture.Status {
+			return &capture.Status{Namespace: pod.Namespace, Name: pod.Name}
+		},
+	})
+	t.Cleanup(func() { currentCaptureInspector.Store(nil) })
+	makeReq(t, server.URL, constants.CaptureEndpoint+"?namespace=test&name=pod", http.StatusOK)
+	makeReq(t, server.URL, constants.CaptureEndpoint+"?namespace=test&name=other", http.StatusNotFound)
+}
+
 func makeReq(t *testing.T, url, endpoint string, expectedStatusCode int) {
 	t.Helper()
 	res, err := http.Get(url + endpoint)
@@ -45,6 +45,7 @@ var (
 type K8sHandlers interface {
 	GetPodIfAmbientEnabled(podName, podNamespace string) (*corev1.Pod, error)
 	GetActiveAmbientPodSnapshot() []*corev1.Pod
+	GetNodePodSnapshot() []*corev1.Pod
 	Start()
 }
 
@@ -166,6 +167,18 @@ func (s *InformerHandlers) GetActiveAmbientPodSnapshot() []*corev1.Pod {
 	return pods
 }
 
+// GetNodePodSnapshot returns a point-in-time snapshot of all non-terminated pods on this node,
+// excluding ztunnel, regardless of their enrollment status.
+func (s *InformerHandlers) GetNodePodSnapshot() []*corev1.Pod {
+	var pods []*corev1.Pod
+	for _, pod := range s.pods.List(metav1.NamespaceAll, klabels.Everything()) {
+		if !util.IsZtunnelPod(s.systemNamespace, pod) && !kube.CheckPodTerminal(pod) {
+			pods = append(pods, pod)
+		}
+	}
+	return pods
+}
+
 // EnqueueNamespace takes a Namespace and enqueues all Pod objects that make need an update
 // TODO it is sort of pointless/confusing/implicit to populate Old and New with the same reference here
 func (s *InformerHandlers) enqueueNamespace(o controllers.Object) {
@@ -16,6 +16,7 @@ package nodeagent
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/netip"
 
 	"golang.org/x/sys/unix"
@@ -23,6 +24,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 
 	set "istio.io/istio/cni/pkg/addressset"
+	"istio.io/istio/cni/pkg/capture"
 	"istio.io/istio/cni/pkg/trafficmanager"
 	"istio.io/istio/cni/pkg/util"
 	"istio.io/istio/pkg/util/sets"
@@ -310,3 +312,56 @@ func pruneHostAddrSet(expected sets.Set[netip.Addr], hostsideProbeSet set.Addres
 		return nil
 	})
 }
+
+// inspectCapture reports the traffic capture state of a pod: its in-pod rules compared against the
+// expected rules, and whether its IPs are present in the host probe set.
+func (s *meshDataplane) inspectCapture(pod *corev1.Pod) *capture.Status {
+	status := &capture.Status{
+		Namespace: pod.Namespace,
+		Name:      pod.Name,
+		UID:       string(pod.UID),
+		Node:      NodeName,
+	}
+
+	netServer, ok := s.netServer.(*NetServer)
+	if !ok {
+		status.Errors = append(status.Errors, "capture inspection is not supported by this dataplane")
+		return status
+	}
+	if !util.PodFullyEnrolled(pod) {
+		// nothing is expected to be programmed for this pod
+		return status
+	}
+	status.Enrolled = netServer.currentPodSnapshot.Get(string(pod.UID)) != nil
+	acked, connected := netServer.ztunnelServer.AcknowledgedPods()
+	status.ZtunnelConnected = connected
+	status.ZtunnelAcknowledged = acked.Contains(string(pod.UID))
+
+	rules, err := netServer.inspectPodRules(pod)
+	if err != nil {
+		status.Errors = append(status.Errors, fmt.Sprintf("failed to inspect inpod rules: %v", err))
+	} else {
+		status.Rules = rules
+	}
+
+	probeSet := &capture.AddressSetInspection{Name: s.hostAddrSet.GetPrefix()}
+	var entries []netip.Addr
+	err = util.RunAsHost(func() error {
+		var err error
+		entries, err = s.hostAddrSet.ListEntriesByIP()
+		return err
+	})
+	if err != nil {
+		status.Errors = append(status.Errors, fmt.Sprintf("failed to list host probe set: %v", err))
+		return status
+	}
+	present := sets.New(entries...)
+	for _, ip := range util.GetPodIPsIfPresent(pod) {
+		probeSet.Expected = append(probeSet.Expected, ip.String())
+		if !present.Contains(ip) {
+			probeSet.Missing = append(probeSet.Missing, ip.String())
+		}
+	}
+	status.HostProbeSet = probeSet
+	return status
+}
@@ -23,8 +23,11 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 
+	"istio.io/istio/cni/pkg/capture"
 	"istio.io/istio/cni/pkg/trafficmanager"
+	istiolog "istio.io/istio/pkg/log"
 	"istio.io/istio/pkg/slices"
+	"istio.io/istio/pkg/util/sets"
 )
 
 // Adapts CNI to ztunnel server. decoupled from k8s for easier integration testing.
@@ -32,7 +35,11 @@ type NetServer struct {
 	ztunnelServer      ZtunnelServer
 	currentPodSnapshot *podNetnsCache
 	trafficManager     trafficmanager.TrafficRuleManager
-	podNs              PodNetnsFinder
+	// previousTrafficManager, if set, manages the backend which is not configured. Rules it finds in
+	// already-enrolled pods are migrated to trafficManager on startup.
+	previousTrafficManager trafficmanager.TrafficRuleManager
+	backends               *podTrafficBackends
+	podNs                  PodNetnsFinder
 	// allow overriding for tests
 	netnsRunner func(fdable NetnsFd, toRun func() error) error
 }
@@ -59,9 +66,15 @@ func (s *NetServer) ConstructInitialSnapshot(existingAmbientPods []*corev1.Pod)
 		consErr = append(consErr, err)
 	}
 
+	migrated := s.migrateExistingPods(existingAmbientPods)
+
 	if s.trafficManager.ReconcileModeEnabled() {
 		log.Info("inpod reconcile mode enabled")
 		for _, pod := range existingAmbientPods {
+			if migrated.Contains(pod.UID) {
+				// the configured backend's rules were just created during migration
+				continue
+			}
 			log := log.WithLabels("ns", pod.Namespace, "name", pod.Name)
 			log.Debug("upgrading and reconciling inpod rules for already-running pod if necessary")
 			err := s.reconcileExistingPod(pod)
@@ -128,6 +141,7 @@ func (s *NetServer) AddPodToMesh(ctx context.Context, pod *corev1.Pod, podIPs []
 		s.currentPodSnapshot.Take(string(pod.UID))
 		return NewErrNonRetryableAdd(err)
 	}
+	s.backends.Set(pod, PodTrafficBackendStatus{Backend: s.trafficManager.Backend()})
 
 	// For *any* other failures after a successful `CreateInpodRules` call, we must return
 	// the error as-is.
@@ -161,13 +175,23 @@ func (s *NetServer) RemovePodFromMesh(ctx context.Context, pod *corev1.Pod, isDe
 	if openNetns == nil {
 		log.Debug("failed to find pod netns during removal")
 	}
+	migrationFailed := s.migrationFailed(string(pod.UID))
+	s.backends.Delete(string(pod.UID))
 
 	// If the pod is already deleted or terminated, we do not need to clean up the pod network -- only the host side.
 	if !isDelete {
 		if openNetns != nil {
 			// pod is removed from the mesh, but is still running. remove traffic rules
 			log.Debugf("calling DeleteInpodRules")
-			if err := s.netnsRunner(openNetns, func() error { return s.trafficManager.DeleteInpodRules(log) }); err != nil {
+			if err := s.netnsRunner(openNetns, func() error {
+				if migrationFailed {
+					// the pod may still have rules from either backend
+					if err := s.previousTrafficManager.DeleteInpodTrafficRules(log); err != nil {
+						return err
+					}
+				}
+				return s.trafficManager.DeleteInpodRules(log)
+			}); err != nil {
 				return fmt.Errorf("failed to delete inpod rules: %w", err)
 			}
 		} else {
@@ -183,16 +207,132 @@ func (s *NetServer) RemovePodFromMesh(ctx context.Context, pod *corev1.Pod, isDe
 	return nil
 }
 
+// reenrollPod repairs the enrollment of an enrolled pod. The inpod rules of the pod are removed before they are
+// created again, so that no partial or stale rules are left behind, and the pod is sent to ztunnel again.
+func (s *NetServer) reenrollPod(ctx context.Context, pod *corev1.Pod) error {
+	log := log.WithLabels("ns", pod.Namespace, "name", pod.Name)
+	openNetns, err := s.getNetns(pod)
+	if err != nil {
+		return err
+	}
+	migrationFailed := s.migrationFailed(string(pod.UID))
+	podCfg := getPodLevelTrafficOverrides(pod)
+	if err := s.netnsRunner(openNetns, func() error {
+		if migrationFailed {
+			// the pod may still have rules from either backend
+			if err := s.previousTrafficManager.DeleteInpodTrafficRules(log); err != nil {
+				return fmt.Errorf("failed to delete %s inpod rules: %w", s.previousTrafficManager.Backend(), err)
+			}
+		}
+		if err := s.trafficManager.DeleteInpodRules(log); err != nil {
+			return fmt.Errorf("failed to delete inpod rules: %w", err)
+		}
+		return s.trafficManager.CreateInpodRules(log, podCfg)
+	}); err != nil {
+		return err
+	}
+	s.backends.Set(pod, PodTrafficBackendStatus{Backend: s.trafficManager.Backend()})
+	return s.sendPodToZtunnelAndWaitForAck(ctx, pod, openNetns)
+}
+
 func newNetServer(ztunnelServer ZtunnelServer, podNsMap *podNetnsCache, trafficManager trafficmanager.TrafficRuleManager, podNs PodNetnsFinder) *NetServer {
 	return &NetServer{
 		ztunnelServer:      ztunnelServer,
 		currentPodSnapshot: podNsMap,
 		podNs:              podNs,
 		trafficManager:     trafficManager,
+		backends:           newPodTrafficBackends(trafficManager.Backend()),
 		netnsRunner:        NetnsDo,
 	}
 }
 
+// inspectPodRules steps into the netns of an enrolled pod and compares its installed rules against the rules
+// the owning traffic manager would generate. Pods whose netns is not tracked are not inspected.
+func (s *NetServer) inspectPodRules(pod *corev1.Pod) (*capture.RuleInspection, error) {
+	openNetns := s.currentPodSnapshot.Get(string(pod.UID))
+	if openNetns == nil {
+		return nil, fmt.Errorf("pod netns is not tracked by the node agent")
+	}
+
+	trafficManager := s.trafficManager
+	if s.migrationFailed(string(pod.UID)) {
+		trafficManager = s.previousTrafficManager
+	}
+	podCfg := getPodLevelTrafficOverrides(pod)
+	log := log.WithLabels("ns", pod.Namespace, "name", pod.Name)
+
+	var inspection *capture.RuleInspection
+	err := s.netnsRunner(openNetns, func() error {
+		var err error
+		inspection, err = trafficmanager.InspectInpodRules(log, trafficManager, podCfg)
+		return err
+	})
+	return inspection, err
+}
+
+// migrationFailed returns true if the rules of a pod could not be migrated away from the previous backend.
+func (s *NetServer) migrationFailed(uid string) bool {
+	if s.previousTrafficManager == nil {
+		return false
+	}
+	backend, f := s.backends.Get(uid)
+	return f && backend == s.previousTrafficManager.Backend()
+}
+
+// migrateExistingPods is intended to run on node agent startup. For each pod that was already enrolled prior to
+// startup, it detects whether the pod's rules were programmed by the previous backend, and if so migrates them
+// to the configured backend. The backend of every existing pod is recorded.
+//
+// Returns the pods which were successfully migrated.
+func (s *NetServer) migrateExistingPods(existingAmbientPods []*corev1.Pod) sets.Set[types.UID] {
+	migrated := sets.New[types.UID]()
+	for _, pod := range existingAmbientPods {
+		status := PodTrafficBackendStatus{Backend: s.trafficManager.Backend()}
+		if s.previousTrafficManager != nil {
+			log := log.WithLabels("ns", pod.Namespace, "name", pod.Name)
+			found, err := s.migrateExistingPod(log, pod)
+			if found {
+				status.MigratedFrom = s.previousTrafficManager.Backend()
+				result := "success"
+				if err != nil {
+					log.Errorf("failed to migrate inpod rules to %s, try restarting the pod: %v", s.trafficManager.Backend(), err)
+					status.Backend = s.previousTrafficManager.Backend()
+					status.MigrationError = err.Error()
+					result = "failure"
+				} else {
+					migrated.Insert(pod.UID)
+				}
+				trafficBackendMigrations.With(
+					fromBackendTag.Value(string(s.previousTrafficManager.Backend())),
+					toBackendTag.Value(string(s.trafficManager.Backend())),
+					repairResultTag.Value(result),
+				).Increment()
+			} else if err != nil {
+				log.Warnf("failed to detect %s inpod rules: %v", s.previousTrafficManager.Backend(), err)
+			}
+		}
+		s.backends.Set(pod, status)
+	}
+	return migrated
+}
+
+func (s *NetServer) migrateExistingPod(log *istiolog.Scope, pod *corev1.Pod) (bool, error) {
+	openNetns, err := s.getNetns(pod)
+	if err != nil {
+		return false, err
+	}
+
+	podCfg := getPodLevelTrafficOverrides(pod)
+
+	var found bool
+	err = s.netnsRunner(openNetns, func() error {
+		var err error
+		found, err = trafficmanager.MigrateInpodRules(log, s.previousTrafficManager, s.trafficManager, podCfg)
+		return err
+	})
+	return found, err
+}
+
 // reconcileExistingPod is intended to run on node agent startup, for each pod that was already enrolled prior to startup.
 // Will reconcile any in-pod iptables rules the pod may already have against this node agent's expected/required in-pod iptables rules.
 //
@@ -432,6 +432,142 @@ func TestConstructInitialSnapDoesNotReconcilePodIfIptablesReconciliationDisabled
 	}
 }
 
+func TestConstructInitialSnapMigratesPodsFromPreviousBackend(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setupLogging()
+
+	podCfg := config.AmbientConfig{
+		Reconcile: true,
+	}
+	fakeDeps := &dependencies.DependenciesStub{}
+	fixture := getTestFixureWithIptablesConfig(ctx, fakeDeps, &podCfg, &podCfg)
+	netServer := fixture.netServer
+	previous := &fakeTrafficManager{backend: config.NftablesBackend, hasRules: true}
+	netServer.previousTrafficManager = previous
+
+	migratedPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "migrated",
+		Namespace: "bar",
+		UID:       types.UID("863b91d4-4b68-4efa-917f-4b560e3e86aa"),
+	}}
+	err := netServer.ConstructInitialSnapshot([]*corev1.Pod{migratedPod})
+	assert.NoError(t, err)
+
+	// iptables rules are created exactly once, before the nftables rules are removed
+	assert.Equal(t, previous.deletedTrafficCnt.Load(), 1)
+	assert.Equal(t, previous.deletedCnt.Load(), 0)
+	assert.Equal(t, fixture.nlDeps.AddLoopbackRoutesCnt.Load(), 1)
+	assert.Equal(t, netServer.backends.Report(), &TrafficBackendReport{
+		ConfiguredBackend: config.IptablesBackend,
+		Pods: []PodTrafficBackendStatus{{
+			UID:          "863b91d4-4b68-4efa-917f-4b560e3e86aa",
+			Namespace:    "bar",
+			Name:         "migrated",
+			Backend:      config.IptablesBackend,
+			MigratedFrom: config.NftablesBackend,
+		}},
+	})
+
+	// pods without rules from the previous backend are left alone
+	previous.hasRules = false
+	fakeDeps.ExecutedAll = nil
+	podCfg.Reconcile = false
+	err = netServer.ConstructInitialSnapshot([]*corev1.Pod{migratedPod})
+	assert.NoError(t, err)
+	assert.Equal(t, previous.deletedTrafficCnt.Load(), 1)
+	assert.Equal(t, len(fakeDeps.ExecutedAll), 0)
+}
+
+func TestFailedMigrationKeepsPreviousBackend(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setupLogging()
+	fixture := getTestFixure(ctx)
+	netServer := fixture.netServer
+	previous := &fakeTrafficManager{backend: config.NftablesBackend, hasRules: true}
+	current := &fakeTrafficManager{backend: config.IptablesBackend, createErr: errors.New("iptables-restore failed")}
+	netServer.trafficManager = current
+	netServer.previousTrafficManager = previous
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "foo",
+		Namespace: "bar",
+		UID:       types.UID("863b91d4-4b68-4efa-917f-4b560e3e86aa"),
+	}}
+	err := netServer.ConstructInitialSnapshot([]*corev1.Pod{pod})
+	assert.NoError(t, err)
+
+	report := netServer.backends.Report()
+	assert.Equal(t, len(report.Pods), 1)
+	assert.Equal(t, report.Pods[0].Backend, config.NftablesBackend)
+	assert.Equal(t, report.Pods[0].MigrationError, "failed to create iptables rules: iptables-restore failed")
+	// the partial iptables rules are removed, and the nftables rules are never touched
+	assert.Equal(t, current.deletedTrafficCnt.Load(), 1)
+	assert.Equal(t, current.hasRules, false)
+	assert.Equal(t, previous.deletedTrafficCnt.Load(), 0)
+	assert.Equal(t, previous.createdCnt.Load(), 0)
+	assert.Equal(t, previous.hasRules, true)
+}
+
+func TestRemovePodAfterFailedMigrationDeletesBothBackends(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setupLogging()
+	fixture := getTestFixure(ctx)
+	netServer := fixture.netServer
+	previous := &fakeTrafficManager{
+		backend:          config.NftablesBackend,
+		hasRules:         true,
+		deleteTrafficErr: errors.New("device or resource busy"),
+	}
+	netServer.previousTrafficManager = previous
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "foo",
+		Namespace: "bar",
+		UID:       types.UID("863b91d4-4b68-4efa-917f-4b560e3e86aa"),
+	}}
+	err := netServer.ConstructInitialSnapshot([]*corev1.Pod{pod})
+	assert.NoError(t, err)
+
+	report := netServer.backends.Report()
+	assert.Equal(t, len(report.Pods), 1)
+	assert.Equal(t, report.Pods[0].Backend, config.NftablesBackend)
+	assert.Equal(t, report.Pods[0].MigrationError, "failed to delete nftables rules: device or resource busy")
+	// the iptables rules were created first, and are left in place alongside the nftables rules
+	assert.Equal(t, previous.createdCnt.Load(), 0)
+	assert.Equal(t, fixture.nlDeps.AddLoopbackRoutesCnt.Load(), 1)
+
+	previous.deleteTrafficErr = nil
+	err = netServer.RemovePodFromMesh(ctx, pod, false)
+	assert.NoError(t, err)
+	assert.Equal(t, previous.deletedTrafficCnt.Load(), 2)
+	assert.Equal(t, fixture.nlDeps.DelLoopbackRoutesCnt.Load(), 1)
+	assert.Equal(t, len(netServer.backends.Report().Pods), 0)
+}
+
+func TestReenrollPodDeletesRulesBeforeCreatingThem(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setupLogging()
+	fixture := getTestFixure(ctx)
+	netServer := fixture.netServer
+	current := &fakeTrafficManager{backend: config.IptablesBackend, hasRules: true}
+	netServer.trafficManager = current
+
+	pod := buildConvincingPod(false)
+	fixture.podNsMap.UpsertPodCacheWithNetns(string(pod.UID), WorkloadInfo{
+		Workload: podToWorkload(pod),
+		Netns:    newFakeNs(123),
+	})
+	assert.NoError(t, netServer.reenrollPod(ctx, pod))
+	assert.Equal(t, current.deletedCnt.Load(), 1)
+	assert.Equal(t, current.createdCnt.Load(), 1)
+	assert.Equal(t, current.hasRules, true)
+	assert.Equal(t, fixture.ztunnelServer.addedPods.Load(), 1)
+}
+
 func TestReconcilePodReturnsErrorIfNoNetnsFound(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
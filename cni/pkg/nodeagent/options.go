@@ -16,6 +16,7 @@ package nodeagent
 
 import (
 	"net/netip"
+	"time"
 
 	"istio.io/istio/cni/pkg/util"
 	"istio.io/istio/pkg/config/constants"
@@ -31,6 +32,13 @@ var (
 	HostProbeSNATIP                = netip.MustParseAddr(env.RegisterStringVar("HOST_PROBE_SNAT_IP", DefaultHostProbeSNATIP, "").Get())
 	HostProbeSNATIPV6              = netip.MustParseAddr(env.RegisterStringVar("HOST_PROBE_SNAT_IPV6", DefaultHostProbeSNATIPV6, "").Get())
 	UseScopedIptablesLegacyLocking = env.RegisterBoolVar("AMBIENT_USE_SCOPED_XTABLES_LOCKING", true, "").Get()
+	EnrollmentReconcileInterval    = env.Register("AMBIENT_ENROLLMENT_RECONCILE_INTERVAL", time.Minute,
+		"How often to compare expected, cached and ztunnel-acknowledged pod enrollments. Set to 0 to disable.").Get()
+	EnrollmentRepair = env.Register("AMBIENT_ENROLLMENT_REPAIR", false,
+		"If enabled, enrollment discrepancies that persist across two reconciliation passes are repaired.").Get()
+	TrafficBackendMigration = env.Register("AMBIENT_TRAFFIC_BACKEND_MIGRATION", false,
+		"If enabled, rules programmed by the traffic backend which is not configured (iptables or nftables) are "+
+			"migrated to the configured backend on startup, for the host and all already-enrolled pods.").Get()
 )
 
 const (
@@ -53,8 +53,10 @@ type Server struct {
 	ctx        context.Context
 	kubeClient kube.Client
 
-	handlers  K8sHandlers
-	dataplane MeshDataplane
+	handlers   K8sHandlers
+	dataplane  MeshDataplane
+	enrollment *enrollmentReconciler
+	capture    *captureInspector
 
 	isReady *atomic.Value
 
@@ -73,13 +75,17 @@ func NewServer(ctx context.Context, ready *atomic.Value, pluginSocket string, ar
 		isReady:    ready,
 	}
 
-	s.dataplane, err = initMeshDataplane(client, args)
+	dataplane, err := initMeshDataplane(client, args)
 	if err != nil {
 		return nil, fmt.Errorf("error initializing mesh dataplane: %w", err)
 	}
+	s.dataplane = dataplane
 
 	s.NotReady()
 	s.handlers = setupHandlers(s.ctx, s.kubeClient, s.dataplane, args.SystemNamespace, args.EnablementSelector)
+	s.enrollment = dataplane.enrollmentReconciler(s.handlers)
+	s.capture = dataplane.captureInspector(s.handlers)
+	currentCaptureInspector.Store(s.capture)
 
 	cniServer := startCniPluginServer(ctx, pluginSocket, s.handlers, s.dataplane)
 	err = cniServer.Start()
@@ -114,6 +120,10 @@ func (s *Server) Start() {
 	// Start accepting ztunnel connections
 	// (and send current snapshot when we get one)
 	s.dataplane.Start(s.ctx)
+	// Periodically check that what we think is enrolled matches what ztunnel has acknowledged
+	if s.enrollment != nil && EnrollmentReconcileInterval > 0 {
+		go s.enrollment.Run(s.ctx, EnrollmentReconcileInterval)
+	}
 	// Everything (informer handlers, snapshot, zt server) ready to go
 	log.Info("CNI ambient server marking ready")
 	s.Ready()
@@ -58,14 +58,15 @@ func initMeshDataplane(client kube.Client, args AmbientArgs) (*meshDataplane, er
 		return nil, fmt.Errorf("error initializing the ztunnel server: %w", err)
 	}
 
-	hostTrafficManager, podTrafficManager, err := trafficmanager.NewTrafficRuleManager(&trafficmanager.TrafficRuleManagerConfig{
+	trafficManagerCfg := &trafficmanager.TrafficRuleManagerConfig{
 		NativeNftables: args.NativeNftables,
 		HostConfig:     hostCfg,
 		PodConfig:      podCfg,
 		HostDeps:       realDependenciesHost(args.ForceIptablesBinary),
 		PodDeps:        realDependenciesInpod(UseScopedIptablesLegacyLocking, args.ForceIptablesBinary),
 		NlDeps:         iptables.RealNlDeps(),
-	})
+	}
+	hostTrafficManager, podTrafficManager, err := trafficmanager.NewTrafficRuleManager(trafficManagerCfg)
 	if err != nil {
 		return nil, fmt.Errorf("error creating traffic managers: %w", err)
 	}
@@ -81,11 +82,18 @@ func initMeshDataplane(client kube.Client, args AmbientArgs) (*meshDataplane, er
 		return nil, fmt.Errorf("error initializing the host rules for health checks: %w", err)
 	}
 
+	var previousPodTrafficManager trafficmanager.TrafficRuleManager
+	if TrafficBackendMigration {
+		previousPodTrafficManager = migrateHostRules(trafficManagerCfg)
+	}
+
 	podNetns, err := NewPodNetnsProcFinder(os.DirFS(filepath.Join(pconstants.HostMountsPath, "proc")))
 	if err != nil {
 		return nil, err
 	}
 	netServer := newNetServer(ztunnelServer, podNsMap, podTrafficManager, podNetns)
+	netServer.previousTrafficManager = previousPodTrafficManager
+	currentTrafficBackends.Store(netServer.backends)
 
 	return &meshDataplane{
 		kubeClient:         client.Kube(),
@@ -95,6 +103,32 @@ func initMeshDataplane(client kube.Client, args AmbientArgs) (*meshDataplane, er
 	}, nil
 }
 
+// migrateHostRules removes any host rules left behind by the backend which is not configured, and returns
+// a pod traffic manager for that backend, so already-enrolled pods can be migrated as well.
+// This is designed to be called after the configured backend's host rules have been created.
+//
+// Returns nil if the other backend is not usable on this node, in which case there is nothing to migrate.
+func migrateHostRules(cfg *trafficmanager.TrafficRuleManagerConfig) trafficmanager.TrafficRuleManager {
+	previousCfg := *cfg
+	previousCfg.NativeNftables = !cfg.NativeNftables
+	previousHost, previousPod, err := trafficmanager.NewTrafficRuleManager(&previousCfg)
+	if err != nil {
+		log.Infof("not migrating traffic rules, the alternate backend is unavailable: %v", err)
+		return nil
+	}
+
+	found, err := previousHost.HasHostRules()
+	if err != nil {
+		log.Infof("not migrating traffic rules, unable to inspect %s host rules: %v", previousHost.Backend(), err)
+		return nil
+	}
+	if found {
+		log.Infof("removing host rules left behind by the %s backend", previousHost.Backend())
+		previousHost.DeleteHostRules()
+	}
+	return previousPod
+}
+
 // createHostNetworkAddrSetManager creates a host network addressSet manager. This is designed to be called from the host netns.
 // Note that if the set already exists by name, Create will not return an error.
 //
@@ -128,3 +162,28 @@ func realDependenciesInpod(useScopedLocks bool, forceIptablesBinary string) *dep
 		ForceIptablesBinary:     forceIptablesBinary,
 	}
 }
+
+// enrollmentReconciler builds a reconciler comparing the pods the informer considers enrolled
+// against the netns cache and ztunnel's acknowledged snapshot.
+func (s *meshDataplane) enrollmentReconciler(handlers K8sHandlers) *enrollmentReconciler {
+	netServer, ok := s.netServer.(*NetServer)
+	if !ok {
+		return nil
+	}
+	return newEnrollmentReconciler(
+		handlers.GetNodePodSnapshot,
+		netServer.currentPodSnapshot,
+		netServer.ztunnelServer,
+		netServer.reenrollPod,
+		s.RemovePodFromMesh,
+		EnrollmentRepair,
+	)
+}
+
+// captureInspector builds an inspector reporting the traffic capture state of pods on this node.
+func (s *meshDataplane) captureInspector(handlers K8sHandlers) *captureInspector {
+	return &captureInspector{
+		pods:    handlers.GetNodePodSnapshot,
+		inspect: s.inspectCapture,
+	}
+}
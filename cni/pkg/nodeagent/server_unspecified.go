@@ -47,6 +47,14 @@ func (*meshDataplane) RemovePodFromMesh(ctx context.Context, pod *corev1.Pod, is
 	return errNotImplemented
 }
 
+func (*meshDataplane) enrollmentReconciler(handlers K8sHandlers) *enrollmentReconciler {
+	return nil
+}
+
+func (*meshDataplane) captureInspector(handlers K8sHandlers) *captureInspector {
+	return nil
+}
+
 func (*meshDataplane) Stop(skipCleanup bool) {
 	// not supported
 	return
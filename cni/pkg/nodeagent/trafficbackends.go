@@ -0,0 +1,106 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeagent
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"istio.io/istio/cni/pkg/config"
+	"istio.io/istio/pkg/maps"
+	"istio.io/istio/pkg/monitoring"
+)
+
+var (
+	fromBackendTag = monitoring.CreateLabel("from")
+	toBackendTag   = monitoring.CreateLabel("to")
+
+	trafficBackendMigrations = monitoring.NewSum(
+		"nodeagent_traffic_backend_migrations_total",
+		"The total number of pods whose traffic rules were migrated between iptables and nftables.",
+	)
+)
+
+// currentTrafficBackends holds the per-pod backend status of the running node agent, served by the health server.
+var currentTrafficBackends atomic.Pointer[podTrafficBackends]
+
+// TrafficBackendReport lists the traffic backend programming the redirection rules of each enrolled pod.
+type TrafficBackendReport struct {
+	ConfiguredBackend config.TrafficBackend     `json:"configuredBackend"`
+	Pods              []PodTrafficBackendStatus `json:"pods"`
+}
+
+// PodTrafficBackendStatus is the traffic backend of a single enrolled pod.
+type PodTrafficBackendStatus struct {
+	UID       string                `json:"uid"`
+	Namespace string                `json:"namespace"`
+	Name      string                `json:"name"`
+	Backend   config.TrafficBackend `json:"backend"`
+	// MigratedFrom is set if the pod's rules were migrated from another backend by this node agent.
+	MigratedFrom   config.TrafficBackend `json:"migratedFrom,omitempty"`
+	MigrationError string                `json:"migrationError,omitempty"`
+}
+
+// podTrafficBackends tracks which backend programmed the redirection rules of each enrolled pod.
+type podTrafficBackends struct {
+	configured config.TrafficBackend
+
+	mu   sync.RWMutex
+	pods map[string]PodTrafficBackendStatus
+}
+
+func newPodTrafficBackends(configured config.TrafficBackend) *podTrafficBackends {
+	return &podTrafficBackends{
+		configured: configured,
+		pods:       map[string]PodTrafficBackendStatus{},
+	}
+}
+
+// Set records the backend for a pod, replacing any earlier status.
+func (b *podTrafficBackends) Set(pod *corev1.Pod, status PodTrafficBackendStatus) {
+	status.UID, status.Namespace, status.Name = string(pod.UID), pod.Namespace, pod.Name
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pods[status.UID] = status
+}
+
+// Get returns the backend recorded for a pod, if any.
+func (b *podTrafficBackends) Get(uid string) (config.TrafficBackend, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	status, f := b.pods[uid]
+	return status.Backend, f
+}
+
+// Delete forgets a pod.
+func (b *podTrafficBackends) Delete(uid string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.pods, uid)
+}
+
+// Report returns a snapshot of all tracked pods, sorted by UID.
+func (b *podTrafficBackends) Report() *TrafficBackendReport {
+	b.mu.RLock()
+	pods := maps.Values(b.pods)
+	b.mu.RUnlock()
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].UID < pods[j].UID
+	})
+	return &TrafficBackendReport{ConfiguredBackend: b.configured, Pods: pods}
+}
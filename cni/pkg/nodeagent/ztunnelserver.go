@@ -30,6 +30,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 
 	"istio.io/istio/pkg/monitoring"
+	"istio.io/istio/pkg/util/sets"
 	"istio.io/istio/pkg/zdsapi"
 )
 
@@ -42,6 +43,9 @@ type ZtunnelServer interface {
 	Run(ctx context.Context)
 	PodDeleted(ctx context.Context, uid string) error
 	PodAdded(ctx context.Context, pod *v1.Pod, netns Netns) error
+	// AcknowledgedPods returns the UIDs of the pods the latest ztunnel connection has acknowledged
+	// without error. The boolean is false if no ztunnel is currently connected.
+	AcknowledgedPods() (sets.String, bool)
 	Close() error
 }
 
@@ -59,7 +63,9 @@ To clean up stale ztunnels
 
 type connMgr struct {
 	connectionSet []ZtunnelConnection
-	mu            sync.Mutex
+	// acked holds, per connection, the pod UIDs that ztunnel has acknowledged without error.
+	acked map[uuid.UUID]sets.String
+	mu    sync.Mutex
 }
 
 func (c *connMgr) addConn(conn ZtunnelConnection) {
@@ -67,6 +73,10 @@ func (c *connMgr) addConn(conn ZtunnelConnection) {
 	defer c.mu.Unlock()
 	log := log.WithLabels("conn_uuid", conn.UUID())
 	c.connectionSet = append(c.connectionSet, conn)
+	if c.acked == nil {
+		c.acked = map[uuid.UUID]sets.String{}
+	}
+	c.acked[conn.UUID()] = sets.New[string]()
 	log.Infof("new ztunnel connected, total connected: %v", len(c.connectionSet))
 	ztunnelConnected.RecordInt(int64(len(c.connectionSet)))
 }
@@ -98,10 +108,38 @@ func (c *connMgr) deleteConn(conn ZtunnelConnection) {
 		}
 	}
 	c.connectionSet = retainedConns
+	delete(c.acked, conn.UUID())
 	log.Infof("ztunnel disconnected, total connected %s", len(c.connectionSet))
 	ztunnelConnected.RecordInt(int64(len(c.connectionSet)))
 }
 
+// markAcked records that the given connection acknowledged the pod.
+func (c *connMgr) markAcked(conn ZtunnelConnection, uid string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if acked, f := c.acked[conn.UUID()]; f {
+		acked.Insert(uid)
+	}
+}
+
+// unmarkAckedUnderLock forgets the pod for all connections. c.mu must be held.
+func (c *connMgr) unmarkAckedUnderLock(uid string) {
+	for _, acked := range c.acked {
+		acked.Delete(uid)
+	}
+}
+
+// latestAcked returns a copy of the pods acknowledged by the latest connection.
+func (c *connMgr) latestAcked() (sets.String, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.connectionSet) == 0 {
+		return nil, false
+	}
+	lConn := c.connectionSet[len(c.connectionSet)-1]
+	return c.acked[lConn.UUID()].Copy(), true
+}
+
 // this is used in tests
 // nolint: unused
 func (c *connMgr) len() int {
@@ -299,7 +337,9 @@ func (z *ztunnelServer) sendSnapshot(_ context.Context, conn ZtunnelConnection)
 		}
 		if resp.GetAck().GetError() != "" {
 			log.Errorf("add-workload: got ack error: %s", resp.GetAck().GetError())
+			continue
 		}
+		z.conns.markAcked(conn, uid)
 	}
 	resp, err := conn.SendMsgAndWaitForAck(&zdsapi.WorkloadRequest{
 		Payload: &zdsapi.WorkloadRequest_SnapshotSent{
@@ -360,6 +400,7 @@ func (z *ztunnelServer) PodDeleted(ctx context.Context, uid string) error {
 
 	z.conns.mu.Lock()
 	defer z.conns.mu.Unlock()
+	z.conns.unmarkAckedUnderLock(uid)
 	for _, conn := range z.conns.connectionSet {
 		log := log.WithLabels("conn_uuid", conn.UUID())
 		log.Debug("sending msg to connected ztunnel")
@@ -370,3 +411,7 @@ func (z *ztunnelServer) PodDeleted(ctx context.Context, uid string) error {
 	}
 	return errors.Join(delErr...)
 }
+
+func (z *ztunnelServer) AcknowledgedPods() (sets.String, bool) {
+	return z.conns.latestAcked()
+}
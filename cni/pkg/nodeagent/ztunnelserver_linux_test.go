@@ -550,6 +550,9 @@ func TestZtunnelRemovePod(t *testing.T) {
 	// now remove the pod
 	ztunnelServer := fixture.ztunServer
 	defer ztunnelServer.Close()
+	acked, connected := ztunnelServer.AcknowledgedPods()
+	assert.Equal(t, connected, true)
+	assert.Equal(t, acked.Contains(uid), true)
 	errChan := make(chan error)
 	go func() {
 		errChan <- ztunnelServer.PodDeleted(ctx, uid)
@@ -561,6 +564,8 @@ func TestZtunnelRemovePod(t *testing.T) {
 	sendAck(ztunClient)
 
 	assert.NoError(t, <-errChan)
+	acked, _ = ztunnelServer.AcknowledgedPods()
+	assert.Equal(t, acked.Contains(uid), false)
 
 	ztunClient.Close()
 	// this will retry for a bit, so shouldn't flake
@@ -604,6 +609,9 @@ func TestZtunnelPodAdded(t *testing.T) {
 	sendAck(ztunClient)
 
 	assert.NoError(t, <-errChan)
+	acked, connected := ztunnelServer.AcknowledgedPods()
+	assert.Equal(t, connected, true)
+	assert.Equal(t, acked.Contains(string(pod2.UID)), true)
 
 	ztunClient.Close()
 	// this will retry for a bit, so shouldn't flake
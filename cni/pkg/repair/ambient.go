@@ -0,0 +1,289 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repair
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	"istio.io/istio/cni/pkg/capture"
+	"istio.io/istio/cni/pkg/util"
+	"istio.io/istio/pkg/slices"
+)
+
+// AmbientPodInspector inspects the traffic capture state of ambient pods on this node.
+// It is implemented by the ambient node agent.
+type AmbientPodInspector interface {
+	InspectCapture(pod *corev1.Pod) *capture.Status
+}
+
+// AmbientFailure is the failure signature of a broken ambient pod.
+type AmbientFailure string
+
+const (
+	// AmbientNotEnrolled means the pod enrollment never completed, or ztunnel has not acknowledged the pod.
+	AmbientNotEnrolled AmbientFailure = "NotEnrolled"
+	// AmbientMissingRules means the pod network namespace or the host probe set lacks expected redirection rules.
+	AmbientMissingRules AmbientFailure = "MissingRedirectionRules"
+)
+
+// Actions the controller can take when an ambient pod is detected as broken.
+const (
+	// AmbientActionReenroll removes the enrollment annotation, so the node agent enrolls the pod again.
+	AmbientActionReenroll = "reenroll"
+	// AmbientActionRestart deletes the pod, so it is recreated by its controller.
+	AmbientActionRestart = "restart"
+	// AmbientActionTaint taints the node, so no new pods are scheduled to it.
+	AmbientActionTaint = "taint"
+)
+
+const (
+	ReasonReenrollBrokenPod = "ReenrollBrokenPod"
+	ReasonRestartBrokenPod  = "RestartBrokenPod"
+	ReasonTaintNode         = "TaintNodeForBrokenPod"
+	ReasonRepairRateLimited = "RepairRateLimited"
+)
+
+// brokenAmbientPod tracks an ambient pod detected as broken. Pods are only repaired once they have
+// stayed broken for the grace period, and at most once per grace period after that.
+type brokenAmbientPod struct {
+	uid     types.UID
+	failure AmbientFailure
+	since   time.Time
+}
+
+func validateAmbientAction(action string) error {
+	switch action {
+	case AmbientActionReenroll, AmbientActionRestart, AmbientActionTaint:
+		return nil
+	}
+	return fmt.Errorf("unknown ambient repair action %q", action)
+}
+
+func newAmbientLimiter(perMinute int) *rate.Limiter {
+	if perMinute <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return rate.NewLimiter(rate.Every(time.Minute/time.Duration(perMinute)), perMinute)
+}
+
+func (c *Controller) ambientEnabled() bool {
+	return c.ambient != nil && c.cfg.RepairAmbient
+}
+
+// isAmbientPod returns true if the node agent has started or completed enrolling the pod.
+func isAmbientPod(pod *corev1.Pod) bool {
+	return util.PodFullyEnrolled(pod) || util.PodPartiallyEnrolled(pod)
+}
+
+// resyncAmbientPods periodically queues all ambient pods, as their traffic capture can break
+// without any change to the pod itself.
+func (c *Controller) resyncAmbientPods(stop <-chan struct{}) {
+	t := time.NewTicker(c.cfg.AmbientCheckInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+			for _, pod := range c.pods.List(metav1.NamespaceAll, klabels.Everything()) {
+				if isAmbientPod(pod) {
+					c.queue.AddObject(pod)
+				}
+			}
+		}
+	}
+}
+
+// detectAmbientFailure returns the failure signature of an ambient pod, and a human readable description of it.
+// An empty signature is returned for healthy pods, or if the state of the pod cannot be determined.
+func (c *Controller) detectAmbientFailure(pod *corev1.Pod) (AmbientFailure, string) {
+	if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+		return "", ""
+	}
+	if util.PodPartiallyEnrolled(pod) {
+		return AmbientNotEnrolled, "pod enrollment is pending"
+	}
+	status := c.ambient.InspectCapture(pod)
+	if status == nil {
+		return "", ""
+	}
+	switch {
+	case !status.Enrolled:
+		return AmbientNotEnrolled, "pod network namespace is not tracked by the node agent"
+	case status.ZtunnelConnected && !status.ZtunnelAcknowledged:
+		return AmbientNotEnrolled, "pod has not been acknowledged by ztunnel"
+	case status.Rules != nil && len(status.Rules.Missing) > 0:
+		return AmbientMissingRules, fmt.Sprintf("%d expected %s rules are missing", len(status.Rules.Missing), status.Rules.Backend)
+	case status.HostProbeSet != nil && len(status.HostProbeSet.Missing) > 0:
+		return AmbientMissingRules, fmt.Sprintf("pod IPs %v are missing from host probe set %s",
+			status.HostProbeSet.Missing, status.HostProbeSet.Name)
+	}
+	return "", ""
+}
+
+func (c *Controller) reconcileAmbientPod(pod *corev1.Pod) error {
+	log := repairLog.WithLabels("pod", pod.Namespace+"/"+pod.Name)
+	key := types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}
+
+	failure, detail := c.detectAmbientFailure(pod)
+	if failure == "" {
+		delete(c.brokenAmbientPods, key)
+		return c.untaintRepairedNode()
+	}
+	broken, f := c.brokenAmbientPods[key]
+	if !f || broken.uid != pod.UID || broken.failure != failure {
+		log.Infof("ambient pod detected as broken (%s): %s", failure, detail)
+		ambientFailuresDetected.With(failureLabel.Value(string(failure))).Increment()
+		broken = brokenAmbientPod{uid: pod.UID, failure: failure, since: time.Now()}
+		c.brokenAmbientPods[key] = broken
+	}
+	// Enrollment may still be in flight, or the node agent may fix the pod on its own. We will check again
+	// on the next pod event or resync.
+	if time.Since(broken.since) < c.cfg.AmbientGracePeriod {
+		return nil
+	}
+
+	action := c.cfg.AmbientAction
+	if !c.ambientLimiter.Allow() {
+		log.Warnf("ambient pod detected as broken (%s), but %s is rate limited", failure, action)
+		c.events.Write(pod, corev1.EventTypeWarning, ReasonRepairRateLimited,
+			"pod detected as broken (%s: %s), but %s was rate limited", failure, detail, action)
+		podsRepaired.With(typeLabel.Value(action), resultLabel.Value(resultRateLimited)).Increment()
+		return nil
+	}
+	// Give the repair time to take effect before acting on this pod again.
+	broken.since = time.Now()
+	c.brokenAmbientPods[key] = broken
+
+	switch action {
+	case AmbientActionReenroll:
+		return c.reenrollBrokenPod(pod, failure, detail)
+	case AmbientActionRestart:
+		return c.restartBrokenPod(pod, failure, detail)
+	case AmbientActionTaint:
+		return c.taintNodeOfBrokenPod(pod, failure, detail)
+	}
+	return validateAmbientAction(action)
+}
+
+// reenrollBrokenPod removes the ambient enrollment annotation from a pod. The node agent sees the pod as
+// selected for ambient but not enrolled, and enrolls it again, programming its rules and sending it to ztunnel.
+func (c *Controller) reenrollBrokenPod(pod *corev1.Pod, failure AmbientFailure, detail string) error {
+	m := podsRepaired.With(typeLabel.Value(reenrollType))
+	repairLog.Infof("Ambient pod detected as broken, re-enrolling: %s/%s", pod.Namespace, pod.Name)
+
+	if err := util.AnnotateUnenrollPod(c.client.Kube(), &pod.ObjectMeta); err != nil {
+		c.events.Write(pod, corev1.EventTypeWarning, ReasonReenrollBrokenPod,
+			"pod detected as broken (%s: %s), but failed to re-enroll: %v", failure, detail, err)
+		m.With(resultLabel.Value(resultFail)).Increment()
+		return err
+	}
+	c.events.Write(pod, corev1.EventTypeWarning, ReasonReenrollBrokenPod, "pod detected as broken (%s: %s), re-enrolling", failure, detail)
+	m.With(resultLabel.Value(resultSuccess)).Increment()
+	return nil
+}
+
+func (c *Controller) restartBrokenPod(pod *corev1.Pod, failure AmbientFailure, detail string) error {
+	m := podsRepaired.With(typeLabel.Value(restartType))
+	repairLog.Infof("Ambient pod detected as broken, restarting: %s/%s", pod.Namespace, pod.Name)
+
+	if err := c.deletePod(pod); err != nil {
+		c.events.Write(pod, corev1.EventTypeWarning, ReasonRestartBrokenPod,
+			"pod detected as broken (%s: %s), but failed to restart: %v", failure, detail, err)
+		m.With(resultLabel.Value(resultFail)).Increment()
+		return err
+	}
+	c.events.Write(pod, corev1.EventTypeWarning, ReasonRestartBrokenPod, "pod detected as broken (%s: %s), restarted", failure, detail)
+	m.With(resultLabel.Value(resultSuccess)).Increment()
+	return nil
+}
+
+// taintNodeOfBrokenPod adds a NoSchedule taint to the node of a broken pod, so no further pods are scheduled
+// to a node whose traffic capture is not working. The taint is removed by untaintRepairedNode once no broken
+// ambient pods remain on the node.
+func (c *Controller) taintNodeOfBrokenPod(pod *corev1.Pod, failure AmbientFailure, detail string) error {
+	m := podsRepaired.With(typeLabel.Value(taintType))
+
+	node, err := c.client.Kube().CoreV1().Nodes().Get(context.Background(), pod.Spec.NodeName, metav1.GetOptions{})
+	if err == nil {
+		if slices.FindFunc(node.Spec.Taints, func(t corev1.Taint) bool { return t.Key == c.cfg.AmbientTaintKey }) != nil {
+			c.ambientNodeTainted = true
+			m.With(resultLabel.Value(resultSkip)).Increment()
+			repairLog.Debugf("Node %s already has taint %s, skipping", node.Name, c.cfg.AmbientTaintKey)
+			return nil
+		}
+		repairLog.Infof("Ambient pod %s/%s detected as broken, tainting node %s with %s",
+			pod.Namespace, pod.Name, node.Name, c.cfg.AmbientTaintKey)
+		err = c.patchNodeTaints(node, append(slices.Clone(node.Spec.Taints), corev1.Taint{
+			Key:    c.cfg.AmbientTaintKey,
+			Value:  "true",
+			Effect: corev1.TaintEffectNoSchedule,
+		}))
+	}
+	if err != nil {
+		c.events.Write(pod, corev1.EventTypeWarning, ReasonTaintNode,
+			"pod detected as broken (%s: %s), but failed to taint node %s: %v", failure, detail, pod.Spec.NodeName, err)
+		m.With(resultLabel.Value(resultFail)).Increment()
+		return err
+	}
+	c.ambientNodeTainted = true
+	c.events.Write(pod, corev1.EventTypeWarning, ReasonTaintNode,
+		"pod detected as broken (%s: %s), tainted node %s with %s", failure, detail, pod.Spec.NodeName, c.cfg.AmbientTaintKey)
+	m.With(resultLabel.Value(resultSuccess)).Increment()
+	return nil
+}
+
+// untaintRepairedNode removes the taint added by the taint action once no broken ambient pods remain on this node.
+func (c *Controller) untaintRepairedNode() error {
+	if !c.ambientEnabled() || c.cfg.AmbientAction != AmbientActionTaint || !c.ambientNodeTainted || len(c.brokenAmbientPods) > 0 {
+		return nil
+	}
+	node, err := c.client.Kube().CoreV1().Nodes().Get(context.Background(), c.cfg.NodeName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	taints := slices.FilterInPlace(slices.Clone(node.Spec.Taints), func(t corev1.Taint) bool { return t.Key != c.cfg.AmbientTaintKey })
+	if len(taints) != len(node.Spec.Taints) {
+		repairLog.Infof("No broken ambient pods remain, removing taint %s from node %s", c.cfg.AmbientTaintKey, node.Name)
+		if err := c.patchNodeTaints(node, taints); err != nil {
+			return fmt.Errorf("failed to remove taint %s from node %s: %v", c.cfg.AmbientTaintKey, node.Name, err)
+		}
+	}
+	c.ambientNodeTainted = false
+	return nil
+}
+
+// patchNodeTaints replaces the taints of a node. The patch is conditional on the resource version of the node,
+// so taints changed by others since the node was read are not lost. Patching only requires the nodes patch permission.
+func (c *Controller) patchNodeTaints(node *corev1.Node, taints []corev1.Taint) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{"resourceVersion": node.ResourceVersion},
+		"spec":     map[string]any{"taints": taints},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.client.Kube().CoreV1().Nodes().Patch(context.Background(), node.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
@@ -24,13 +24,26 @@ var (
 	repairType = "repair"
 	labelType  = "label"
 
+	reenrollType = "reenroll"
+	restartType  = "restart"
+	taintType    = "taint"
+
 	resultLabel   = monitoring.CreateLabel("result")
 	resultSuccess = "success"
 	resultSkip    = "skip"
 	resultFail    = "fail"
 
+	resultRateLimited = "rate_limited"
+
+	failureLabel = monitoring.CreateLabel("failure")
+
 	podsRepaired = monitoring.NewSum(
 		"istio_cni_repair_pods_repaired_total",
 		"Total number of pods repaired by repair controller",
 	)
+
+	ambientFailuresDetected = monitoring.NewSum(
+		"istio_cni_repair_ambient_failures_total",
+		"Total number of broken ambient pods detected by repair controller",
+	)
 )
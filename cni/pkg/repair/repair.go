@@ -24,19 +24,27 @@ import (
 
 var repairLog = scopes.CNIAgent
 
-func StartRepair(ctx context.Context, cfg config.RepairConfig) {
+// StartRepair starts the repair controller. Ambient pods are only repaired if an ambient inspector is provided.
+func StartRepair(ctx context.Context, cfg config.RepairConfig, ambient AmbientPodInspector) {
 	if !cfg.Enabled {
 		repairLog.Info("CNI repair controller is disabled")
 		return
 	}
 	repairLog.Info("starting CNI sidecar repair controller")
+	if cfg.RepairAmbient {
+		if ambient == nil {
+			repairLog.Warn("ambient repair is enabled, but ambient mode is not; ambient pods will not be repaired")
+		} else {
+			repairLog.Infof("ambient pod repair is enabled with action %q", cfg.AmbientAction)
+		}
+	}
 
 	client, err := clientSetup()
 	if err != nil {
 		repairLog.Fatalf("CNI repair could not construct clientSet: %s", err)
 	}
 
-	rc, err := NewRepairController(client, cfg)
+	rc, err := NewRepairController(client, cfg, ambient)
 	if err != nil {
 		repairLog.Fatalf("Fatal error constructing repair controller: %+v", err)
 	}
@@ -15,17 +15,23 @@
 package repair
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	klabels "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 
+	"istio.io/api/annotation"
+	"istio.io/istio/cni/pkg/capture"
 	"istio.io/istio/cni/pkg/config"
+	pconstants "istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/kube"
 	"istio.io/istio/pkg/monitoring/monitortest"
 	"istio.io/istio/pkg/slices"
@@ -287,7 +293,7 @@ func TestLabelPods(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mt := monitortest.New(t)
 			tt.config.LabelPods = true
-			c, err := NewRepairController(tt.client, tt.config)
+			c, err := NewRepairController(tt.client, tt.config, nil)
 			assert.NoError(t, err)
 			t.Cleanup(func() {
 				assert.NoError(t, c.queue.WaitForClose(time.Second))
@@ -351,7 +357,7 @@ func TestDeletePods(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mt := monitortest.New(t)
 			tt.config.DeletePods = true
-			c, err := NewRepairController(tt.client, tt.config)
+			c, err := NewRepairController(tt.client, tt.config, nil)
 			assert.NoError(t, err)
 			t.Cleanup(func() {
 				assert.NoError(t, c.queue.WaitForClose(time.Second))
@@ -374,3 +380,165 @@ func TestDeletePods(t *testing.T) {
 		})
 	}
 }
+
+type fakeAmbientInspector map[string]*capture.Status
+
+func (f fakeAmbientInspector) InspectCapture(pod *corev1.Pod) *capture.Status {
+	return f[pod.Name]
+}
+
+func makeAmbientPod(name, redirection string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			UID:         types.UID(name + "-uid"),
+			Annotations: map[string]string{annotation.AmbientRedirection.Name: redirection},
+		},
+		Spec:   corev1.PodSpec{NodeName: "node1"},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestAmbientRepair(t *testing.T) {
+	healthy := &capture.Status{Enrolled: true, ZtunnelConnected: true, ZtunnelAcknowledged: true, Rules: &capture.RuleInspection{}}
+	missingRules := &capture.Status{
+		Enrolled:            true,
+		ZtunnelConnected:    true,
+		ZtunnelAcknowledged: true,
+		Rules:               &capture.RuleInspection{Backend: string(config.IptablesBackend), Missing: []string{"ipv4 nat: -A ISTIO_OUTPUT -j ACCEPT"}},
+	}
+	unacknowledged := &capture.Status{Enrolled: true, ZtunnelConnected: true}
+
+	baseConfig := config.RepairConfig{
+		NodeName:        "node1",
+		RepairAmbient:   true,
+		AmbientTaintKey: "cni.istio.io/capture-broken",
+	}
+	newController := func(t *testing.T, cfg config.RepairConfig, inspector fakeAmbientInspector, pods ...*corev1.Pod) *Controller {
+		client := fakeClient(pods...)
+		_, err := client.Kube().CoreV1().Nodes().Create(context.Background(),
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}, metav1.CreateOptions{})
+		assert.NoError(t, err)
+		c, err := NewRepairController(client, cfg, inspector)
+		assert.NoError(t, err)
+		return c
+	}
+	getPod := func(t *testing.T, c *Controller, name string) *corev1.Pod {
+		pod, err := c.client.Kube().CoreV1().Pods("default").Get(context.Background(), name, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			return nil
+		}
+		assert.NoError(t, err)
+		return pod
+	}
+
+	t.Run("reenroll pending pod", func(t *testing.T) {
+		mt := monitortest.New(t)
+		cfg := baseConfig
+		cfg.AmbientAction = AmbientActionReenroll
+		pod := makeAmbientPod("pending", pconstants.AmbientRedirectionPending)
+		c := newController(t, cfg, fakeAmbientInspector{}, pod)
+
+		assert.NoError(t, c.ReconcilePod(pod))
+		_, annotated := getPod(t, c, pod.Name).Annotations[annotation.AmbientRedirection.Name]
+		assert.Equal(t, annotated, false)
+		mt.Assert(podsRepaired.Name(), map[string]string{"result": resultSuccess, "type": reenrollType}, monitortest.Exactly(1))
+		mt.Assert(ambientFailuresDetected.Name(), map[string]string{"failure": string(AmbientNotEnrolled)}, monitortest.Exactly(1))
+	})
+
+	t.Run("restart pod with missing rules", func(t *testing.T) {
+		cfg := baseConfig
+		cfg.AmbientAction = AmbientActionRestart
+		broken := makeAmbientPod("broken", pconstants.AmbientRedirectionEnabled)
+		working := makeAmbientPod("working", pconstants.AmbientRedirectionEnabled)
+		c := newController(t, cfg, fakeAmbientInspector{"broken": missingRules, "working": healthy}, broken, working)
+
+		assert.NoError(t, c.ReconcilePod(broken))
+		assert.NoError(t, c.ReconcilePod(working))
+		assert.Equal(t, getPod(t, c, broken.Name), nil)
+		assert.Equal(t, getPod(t, c, working.Name) != nil, true)
+	})
+
+	t.Run("taint node once for unacknowledged pod", func(t *testing.T) {
+		mt := monitortest.New(t)
+		cfg := baseConfig
+		cfg.AmbientAction = AmbientActionTaint
+		pod := makeAmbientPod("unacked", pconstants.AmbientRedirectionEnabled)
+		c := newController(t, cfg, fakeAmbientInspector{"unacked": unacknowledged}, pod)
+
+		assert.NoError(t, c.ReconcilePod(pod))
+		assert.NoError(t, c.ReconcilePod(pod))
+		node, err := c.client.Kube().CoreV1().Nodes().Get(context.Background(), "node1", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, node.Spec.Taints, []corev1.Taint{{Key: "cni.istio.io/capture-broken", Value: "true", Effect: corev1.TaintEffectNoSchedule}})
+		mt.Assert(podsRepaired.Name(), map[string]string{"result": resultSuccess, "type": taintType}, monitortest.Exactly(1))
+		mt.Assert(podsRepaired.Name(), map[string]string{"result": resultSkip, "type": taintType}, monitortest.Exactly(1))
+
+		// once the pod is healthy again the taint is removed
+		c.ambient = fakeAmbientInspector{"unacked": healthy}
+		assert.NoError(t, c.ReconcilePod(pod))
+		node, err = c.client.Kube().CoreV1().Nodes().Get(context.Background(), "node1", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, len(node.Spec.Taints), 0)
+	})
+
+	t.Run("remove taint left by a previous run", func(t *testing.T) {
+		cfg := baseConfig
+		cfg.AmbientAction = AmbientActionTaint
+		pod := makeAmbientPod("working", pconstants.AmbientRedirectionEnabled)
+		c := newController(t, cfg, fakeAmbientInspector{"working": healthy}, pod)
+		other := corev1.Taint{Key: "example.com/other", Effect: corev1.TaintEffectNoSchedule}
+		node, err := c.client.Kube().CoreV1().Nodes().Get(context.Background(), "node1", metav1.GetOptions{})
+		assert.NoError(t, err)
+		node.Spec.Taints = []corev1.Taint{{Key: "cni.istio.io/capture-broken", Value: "true", Effect: corev1.TaintEffectNoSchedule}, other}
+		_, err = c.client.Kube().CoreV1().Nodes().Update(context.Background(), node, metav1.UpdateOptions{})
+		assert.NoError(t, err)
+
+		assert.NoError(t, c.ReconcilePod(pod))
+		node, err = c.client.Kube().CoreV1().Nodes().Get(context.Background(), "node1", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, node.Spec.Taints, []corev1.Taint{other})
+		assert.Equal(t, c.ambientNodeTainted, false)
+	})
+
+	t.Run("grace period", func(t *testing.T) {
+		cfg := baseConfig
+		cfg.AmbientAction = AmbientActionRestart
+		cfg.AmbientGracePeriod = time.Hour
+		pod := makeAmbientPod("broken", pconstants.AmbientRedirectionEnabled)
+		c := newController(t, cfg, fakeAmbientInspector{"broken": missingRules}, pod)
+
+		assert.NoError(t, c.ReconcilePod(pod))
+		assert.Equal(t, getPod(t, c, pod.Name) != nil, true)
+		assert.Equal(t, c.brokenAmbientPods[types.NamespacedName{Namespace: "default", Name: "broken"}].failure, AmbientMissingRules)
+
+		// once the pod is healthy again it is no longer tracked
+		c.ambient = fakeAmbientInspector{"broken": healthy}
+		assert.NoError(t, c.ReconcilePod(pod))
+		assert.Equal(t, len(c.brokenAmbientPods), 0)
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		mt := monitortest.New(t)
+		cfg := baseConfig
+		cfg.AmbientAction = AmbientActionRestart
+		cfg.AmbientRepairsPerMinute = 1
+		first := makeAmbientPod("first", pconstants.AmbientRedirectionEnabled)
+		second := makeAmbientPod("second", pconstants.AmbientRedirectionEnabled)
+		c := newController(t, cfg, fakeAmbientInspector{"first": missingRules, "second": missingRules}, first, second)
+
+		assert.NoError(t, c.ReconcilePod(first))
+		assert.NoError(t, c.ReconcilePod(second))
+		assert.Equal(t, getPod(t, c, first.Name), nil)
+		assert.Equal(t, getPod(t, c, second.Name) != nil, true)
+		mt.Assert(podsRepaired.Name(), map[string]string{"result": resultRateLimited, "type": AmbientActionRestart}, monitortest.Exactly(1))
+	})
+
+	t.Run("invalid action", func(t *testing.T) {
+		cfg := baseConfig
+		cfg.AmbientAction = "reboot"
+		_, err := NewRepairController(fakeClient(), cfg, fakeAmbientInspector{})
+		assert.Error(t, err)
+	})
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"strings"
 
+	"golang.org/x/time/rate"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -37,14 +38,31 @@ type Controller struct {
 	cfg          config.RepairConfig
 	events       kclient.EventRecorder
 	repairedPods map[types.NamespacedName]types.UID
+
+	// ambient inspects the capture state of ambient pods. If nil, ambient pods are not repaired.
+	ambient           AmbientPodInspector
+	ambientLimiter    *rate.Limiter
+	brokenAmbientPods map[types.NamespacedName]brokenAmbientPod
+	// ambientNodeTainted is set if this node may have the taint added by the taint action.
+	ambientNodeTainted bool
 }
 
-func NewRepairController(client kube.Client, cfg config.RepairConfig) (*Controller, error) {
+func NewRepairController(client kube.Client, cfg config.RepairConfig, ambient AmbientPodInspector) (*Controller, error) {
 	c := &Controller{
-		cfg:          cfg,
-		client:       client,
-		events:       kclient.NewEventRecorder(client, "cni-repair"),
-		repairedPods: map[types.NamespacedName]types.UID{},
+		cfg:               cfg,
+		client:            client,
+		events:            kclient.NewEventRecorder(client, "cni-repair"),
+		repairedPods:      map[types.NamespacedName]types.UID{},
+		ambient:           ambient,
+		ambientLimiter:    newAmbientLimiter(cfg.AmbientRepairsPerMinute),
+		brokenAmbientPods: map[types.NamespacedName]brokenAmbientPod{},
+		// The taint may remain from before the node agent restarted.
+		ambientNodeTainted: cfg.AmbientAction == AmbientActionTaint,
+	}
+	if c.ambientEnabled() {
+		if err := validateAmbientAction(cfg.AmbientAction); err != nil {
+			return nil, err
+		}
 	}
 	fieldSelectors := []string{}
 	if cfg.FieldSelectors != "" {
@@ -66,6 +84,9 @@ func NewRepairController(client kube.Client, cfg config.RepairConfig) (*Controll
 
 func (c *Controller) Run(stop <-chan struct{}) {
 	kube.WaitForCacheSync("repair controller", stop, c.pods.HasSynced)
+	if c.ambientEnabled() && c.cfg.AmbientCheckInterval > 0 {
+		go c.resyncAmbientPods(stop)
+	}
 	c.queue.Run(stop)
 	c.pods.ShutdownHandlers()
 }
@@ -74,13 +95,17 @@ func (c *Controller) Reconcile(key types.NamespacedName) error {
 	pod := c.pods.Get(key.Name, key.Namespace)
 	if pod == nil {
 		delete(c.repairedPods, key) // Ensure we do not leak
-		// Pod deleted, nothing to do
-		return nil
+		delete(c.brokenAmbientPods, key)
+		// Pod deleted, its node may no longer have any broken ambient pods
+		return c.untaintRepairedNode()
 	}
 	return c.ReconcilePod(pod)
 }
 
 func (c *Controller) ReconcilePod(pod *corev1.Pod) (err error) {
+	if c.ambientEnabled() && isAmbientPod(pod) {
+		return c.reconcileAmbientPod(pod)
+	}
 	if !c.matchesFilter(pod) {
 		return err // Skip, pod doesn't need repair
 	}
@@ -134,15 +159,7 @@ func (c *Controller) deleteBrokenPod(pod *corev1.Pod) error {
 	m := podsRepaired.With(typeLabel.Value(deleteType))
 	repairLog.Infof("Pod detected as broken, deleting: %s/%s", pod.Namespace, pod.Name)
 
-	// Make sure we are deleting what we think we are...
-	preconditions := &metav1.Preconditions{
-		UID:             &pod.UID,
-		ResourceVersion: &pod.ResourceVersion,
-	}
-	err := c.client.Kube().CoreV1().Pods(pod.Namespace).Delete(context.Background(), pod.Name, metav1.DeleteOptions{
-		Preconditions: preconditions,
-	})
-	if err != nil {
+	if err := c.deletePod(pod); err != nil {
 		c.events.Write(pod, corev1.EventTypeWarning, ReasonDeleteBrokenPod, "pod detected as broken, but failed to delete: %v", err)
 		m.With(resultLabel.Value(resultFail)).Increment()
 		return err
@@ -152,6 +169,17 @@ func (c *Controller) deleteBrokenPod(pod *corev1.Pod) error {
 	return nil
 }
 
+func (c *Controller) deletePod(pod *corev1.Pod) error {
+	// Make sure we are deleting what we think we are...
+	preconditions := &metav1.Preconditions{
+		UID:             &pod.UID,
+		ResourceVersion: &pod.ResourceVersion,
+	}
+	return c.client.Kube().CoreV1().Pods(pod.Namespace).Delete(context.Background(), pod.Name, metav1.DeleteOptions{
+		Preconditions: preconditions,
+	})
+}
+
 func (c *Controller) labelBrokenPod(pod *corev1.Pod) error {
 	// Added for safety, to make sure no healthy pods get labeled.
 	m := podsRepaired.With(typeLabel.Value(labelType))
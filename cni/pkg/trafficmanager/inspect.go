@@ -0,0 +1,54 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trafficmanager
+
+import (
+	"istio.io/istio/cni/pkg/capture"
+	"istio.io/istio/cni/pkg/config"
+	istiolog "istio.io/istio/pkg/log"
+)
+
+// InspectInpodRules compares the rules installed in a pod against the rules the manager would generate for it.
+// NOTE that this expects to be run from within the pod network namespace!
+func InspectInpodRules(log *istiolog.Scope, m TrafficRuleManager, podOverrides config.PodLevelOverrides) (*capture.RuleInspection, error) {
+	expected, installed, err := m.InpodRuleState(log, podOverrides)
+	if err != nil {
+		return nil, err
+	}
+	return &capture.RuleInspection{
+		Backend:    string(m.Backend()),
+		Expected:   expected,
+		Installed:  installed,
+		Missing:    subtract(expected, installed),
+		Unexpected: subtract(installed, expected),
+	}, nil
+}
+
+// subtract returns the entries of a not in b, respecting duplicates.
+func subtract(a, b []string) []string {
+	remaining := map[string]int{}
+	for _, s := range b {
+		remaining[s]++
+	}
+	var out []string
+	for _, s := range a {
+		if remaining[s] > 0 {
+			remaining[s]--
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
@@ -29,6 +29,22 @@ type TrafficRuleManager interface {
 	CreateHostRulesForHealthChecks() error
 	DeleteHostRules()
 	ReconcileModeEnabled() bool
+
+	// Backend returns the packet filtering backend this manager programs.
+	Backend() config.TrafficBackend
+	// HasInpodRules reports whether this backend's redirection rules are present.
+	// NOTE that this expects to be run from within the pod network namespace!
+	HasInpodRules(log *istiolog.Scope) (bool, error)
+	// DeleteInpodTrafficRules removes this backend's redirection rules from a pod, leaving the
+	// routes and ip rules shared between backends in place. This is used when migrating a
+	// running pod to another backend, before the new backend's rules are created.
+	DeleteInpodTrafficRules(log *istiolog.Scope) error
+	// HasHostRules reports whether this backend's host-level rules are present.
+	HasHostRules() (bool, error)
+	// InpodRuleState returns the rules this backend would program for a pod with the given overrides,
+	// and the rules currently programmed, in a backend-specific but comparable form.
+	// NOTE that this expects to be run from within the pod network namespace!
+	InpodRuleState(log *istiolog.Scope, podOverrides config.PodLevelOverrides) (expected []string, installed []string, err error)
 }
 
 type TrafficRuleManagerConfig struct {
@@ -112,3 +112,40 @@ func (m *IptablesTrafficManager) ReconcileModeEnabled() bool {
 	}
 	return m.podIptables.ReconcileModeEnabled()
 }
+
+// Backend returns the iptables backend
+func (m *IptablesTrafficManager) Backend() config.TrafficBackend {
+	return config.IptablesBackend
+}
+
+// HasInpodRules reports whether iptables rules are present in a pod's network namespace
+func (m *IptablesTrafficManager) HasInpodRules(log *istiolog.Scope) (bool, error) {
+	if m.podIptables == nil {
+		return false, fmt.Errorf("pod iptables configurator not available (this is likely a host-only traffic manager)")
+	}
+	return m.podIptables.HasInpodRules(log)
+}
+
+// DeleteInpodTrafficRules removes iptables redirection rules from a pod's network namespace, keeping shared routes
+func (m *IptablesTrafficManager) DeleteInpodTrafficRules(log *istiolog.Scope) error {
+	if m.podIptables == nil {
+		return fmt.Errorf("pod iptables configurator not available (this is likely a host-only traffic manager)")
+	}
+	return m.podIptables.DeleteInpodTrafficRules(log)
+}
+
+// HasHostRules reports whether host-level iptables rules are present
+func (m *IptablesTrafficManager) HasHostRules() (bool, error) {
+	if m.hostIptables == nil {
+		return false, fmt.Errorf("host iptables configurator not available (this is likely a pod-only traffic manager)")
+	}
+	return m.hostIptables.HasHostRules()
+}
+
+// InpodRuleState returns the expected and installed iptables rules in a pod's network namespace
+func (m *IptablesTrafficManager) InpodRuleState(log *istiolog.Scope, podOverrides config.PodLevelOverrides) ([]string, []string, error) {
+	if m.podIptables == nil {
+		return nil, nil, fmt.Errorf("pod iptables configurator not available (this is likely a host-only traffic manager)")
+	}
+	return m.podIptables.InpodRuleState(log, podOverrides)
+}
@@ -0,0 +1,55 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trafficmanager
+
+import (
+	"errors"
+	"fmt"
+
+	"istio.io/istio/cni/pkg/config"
+	istiolog "istio.io/istio/pkg/log"
+)
+
+// MigrateInpodRules moves a pod's redirection rules from one backend to another, if the pod has any rules
+// programmed by the `from` backend. The `to` backend's rules are created before the `from` backend's rules are
+// removed, so the pod's traffic stays captured throughout the migration. If the `to` backend's rules cannot be
+// created, any partial rules are removed and the `from` backend's rules are left in place. If the `from` backend's
+// rules cannot be removed, the pod is left with the rules of both backends, and the error is returned so the
+// caller can remove both when the pod leaves the mesh.
+//
+// Returns true if the pod had rules from the `from` backend.
+// NOTE that this expects to be run from within the pod network namespace!
+func MigrateInpodRules(log *istiolog.Scope, from, to TrafficRuleManager, podOverrides config.PodLevelOverrides) (bool, error) {
+	found, err := from.HasInpodRules(log)
+	if err != nil {
+		return false, fmt.Errorf("failed to detect %s rules: %w", from.Backend(), err)
+	}
+	if !found {
+		return false, nil
+	}
+
+	log.Infof("migrating inpod rules from %s to %s", from.Backend(), to.Backend())
+	if err := to.CreateInpodRules(log, podOverrides); err != nil {
+		errs := []error{fmt.Errorf("failed to create %s rules: %w", to.Backend(), err)}
+		if err := to.DeleteInpodTrafficRules(log); err != nil {
+			errs = append(errs, fmt.Errorf("failed to roll back %s rules: %w", to.Backend(), err))
+		}
+		return true, errors.Join(errs...)
+	}
+	if err := from.DeleteInpodTrafficRules(log); err != nil {
+		return true, fmt.Errorf("failed to delete %s rules: %w", from.Backend(), err)
+	}
+	return true, nil
+}
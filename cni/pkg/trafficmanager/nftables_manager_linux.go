@@ -95,3 +95,40 @@ func (m *NftablesTrafficManager) ReconcileModeEnabled() bool {
 	}
 	return m.podNftables.ReconcileModeEnabled()
 }
+
+// Backend returns the nftables backend
+func (m *NftablesTrafficManager) Backend() config.TrafficBackend {
+	return config.NftablesBackend
+}
+
+// HasInpodRules reports whether nftables rules are present in a pod's network namespace
+func (m *NftablesTrafficManager) HasInpodRules(log *istiolog.Scope) (bool, error) {
+	if m.podNftables == nil {
+		return false, fmt.Errorf("pod nftables configurator not available (this is likely a host-only traffic manager)")
+	}
+	return m.podNftables.HasInpodRules(log)
+}
+
+// DeleteInpodTrafficRules removes nftables redirection rules from a pod's network namespace, keeping shared routes
+func (m *NftablesTrafficManager) DeleteInpodTrafficRules(log *istiolog.Scope) error {
+	if m.podNftables == nil {
+		return fmt.Errorf("pod nftables configurator not available (this is likely a host-only traffic manager)")
+	}
+	return m.podNftables.DeleteInpodTrafficRules(log)
+}
+
+// HasHostRules reports whether host-level nftables rules are present
+func (m *NftablesTrafficManager) HasHostRules() (bool, error) {
+	if m.hostNftables == nil {
+		return false, fmt.Errorf("host nftables configurator not available (this is likely a pod-only traffic manager)")
+	}
+	return m.hostNftables.HasHostRules()
+}
+
+// InpodRuleState returns the expected and installed nftables rules in a pod's network namespace
+func (m *NftablesTrafficManager) InpodRuleState(log *istiolog.Scope, podOverrides config.PodLevelOverrides) ([]string, []string, error) {
+	if m.podNftables == nil {
+		return nil, nil, fmt.Errorf("pod nftables configurator not available (this is likely a host-only traffic manager)")
+	}
+	return m.podNftables.InpodRuleState(log, podOverrides)
+}
@@ -26,6 +26,9 @@ import (
 	"istio.io/istio/istioctl/pkg/admin"
 	"istio.io/istio/istioctl/pkg/analyze"
 	"istio.io/istio/istioctl/pkg/authz"
+	"istio.io/istio/istioctl/pkg/ca"
+	"istio.io/istio/istioctl/pkg/capturestatus"
+	"istio.io/istio/istioctl/pkg/certificates"
 	"istio.io/istio/istioctl/pkg/checkinject"
 	"istio.io/istio/istioctl/pkg/cli"
 	"istio.io/istio/istioctl/pkg/completion"
@@ -193,12 +196,16 @@ debug and diagnose their Istio mesh.
 	rootCmd.AddCommand(seeExperimentalCmd("authz"))
 	experimentalCmd.AddCommand(metrics.Cmd(ctx))
 	experimentalCmd.AddCommand(describe.Cmd(ctx))
-	experimentalCmd.AddCommand(config.Cmd())
+	experimentalCmd.AddCommand(config.Cmd(ctx))
 	experimentalCmd.AddCommand(workload.Cmd(ctx))
 	experimentalCmd.AddCommand(internaldebug.DebugCommand(ctx))
 	experimentalCmd.AddCommand(precheck.Cmd(ctx))
 	experimentalCmd.AddCommand(proxyconfig.StatsConfigCmd(ctx))
 	experimentalCmd.AddCommand(checkinject.Cmd(ctx))
+	experimentalCmd.AddCommand(kubeinject.Cmd(ctx))
+	experimentalCmd.AddCommand(capturestatus.Cmd(ctx))
+	experimentalCmd.AddCommand(ca.Cmd(ctx))
+	experimentalCmd.AddCommand(certificates.Cmd(ctx))
 	rootCmd.AddCommand(waypoint.Cmd(ctx))
 	rootCmd.AddCommand(ztunnelconfig.ZtunnelConfig(ctx))
 
@@ -36,6 +36,7 @@ import (
 	"istio.io/istio/pkg/cluster"
 	"istio.io/istio/pkg/config/analysis"
 	"istio.io/istio/pkg/config/analysis/analyzers"
+	"istio.io/istio/pkg/config/analysis/analyzers/custom"
 	"istio.io/istio/pkg/config/analysis/diag"
 	"istio.io/istio/pkg/config/analysis/local"
 	"istio.io/istio/pkg/config/analysis/msg"
@@ -82,6 +83,7 @@ var (
 	revisionSpecified string
 	remoteContexts    []string
 	selectedAnalyzers []string
+	customAnalyzers   []string
 
 	fileExtensions = []string{".json", ".yaml", ".yml"}
 )
@@ -117,7 +119,16 @@ func Analyze(ctx cli.Context) *cobra.Command {
   istioctl analyze -L
   
   # Run specific analyzer
-  istioctl analyze --analyzer "gateway.ConflictingGatewayAnalyzer"`,
+  istioctl analyze --analyzer "gateway.ConflictingGatewayAnalyzer"
+
+  # Run the custom analyzers of a directory in addition to the built-in analyzers
+  istioctl analyze --custom-analyzers my-org-analyzers/
+
+  # Analyze yaml files and report the messages as SARIF, to be uploaded as code scanning results
+  istioctl analyze --use-kube=false -o sarif my-app-config/ > istio.sarif
+
+  # Analyze yaml files and report each analyzer as a JUnit test case
+  istioctl analyze --use-kube=false -o junit my-app-config/ > istio-analyze.xml`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			msgOutputFormat = strings.ToLower(msgOutputFormat)
 			_, ok := formatting.MsgOutputFormats[msgOutputFormat]
@@ -127,8 +138,17 @@ func Analyze(ctx cli.Context) *cobra.Command {
 				}
 			}
 
+			var customs []analysis.Analyzer
+			if len(customAnalyzers) > 0 {
+				var err error
+				if customs, err = custom.Load(customAnalyzers...); err != nil {
+					return err
+				}
+			}
+			allAnalyzers := append(analyzers.All(), customs...)
+
 			if listAnalyzers {
-				fmt.Print(AnalyzersAsString(analyzers.All()))
+				fmt.Print(AnalyzersAsString(allAnalyzers))
 				return nil
 			}
 
@@ -172,9 +192,9 @@ func Analyze(ctx cli.Context) *cobra.Command {
 				selectedNamespace = metav1.NamespaceDefault
 			}
 
-			combinedAnalyzers := analyzers.AllCombined()
+			combinedAnalyzers := analysis.Combine("all", allAnalyzers...)
 			if len(selectedAnalyzers) != 0 {
-				combinedAnalyzers = analyzers.NamedCombined(selectedAnalyzers...)
+				combinedAnalyzers = analyzers.NamedCombinedFrom(allAnalyzers, selectedAnalyzers...)
 			}
 
 			sa := local.NewIstiodAnalyzer(combinedAnalyzers,
@@ -191,7 +211,7 @@ func Analyze(ctx cli.Context) *cobra.Command {
 				// Check to see if the supplied code is valid. If not, emit a
 				// warning but continue.
 				codeIsValid := false
-				for _, at := range msg.All() {
+				for _, at := range append(msg.All(), custom.MessageTypes(customs)...) {
 					if at.Code() == parts[0] {
 						codeIsValid = true
 						break
@@ -274,19 +294,17 @@ func Analyze(ctx cli.Context) *cobra.Command {
 			}
 
 			// Get messages for output
-			outputMessages := result.Messages.SetDocRef("istioctl-analyze").FilterOutLowerThan(outputThreshold.Level)
-
-			if shouldPrintCluster {
-				for i := range outputMessages {
-					m := &outputMessages[i]
-					if m.Resource != nil && m.Resource.Origin.ClusterName().String() != "" {
-						m.PrintCluster = true
-					}
-				}
+			outputMessages := filterOutputMessages(result.Messages, shouldPrintCluster)
+			report := formatting.Report{Tool: "istioctl analyze", Messages: outputMessages, Colorize: colorize}
+			for _, a := range result.ExecutedAnalyzers {
+				report.Checks = append(report.Checks, formatting.Check{
+					Name:     a,
+					Messages: filterOutputMessages(result.MappedMessages[a], shouldPrintCluster),
+				})
 			}
 
 			// Print all the messages to stdout in the specified format
-			output, err := formatting.Print(outputMessages, msgOutputFormat, colorize)
+			output, err := formatting.PrintReport(report, msgOutputFormat)
 			if err != nil {
 				return err
 			}
@@ -371,6 +389,9 @@ func Analyze(ctx cli.Context) *cobra.Command {
 	analysisCmd.PersistentFlags().StringArrayVarP(&selectedAnalyzers, "analyzer", "", []string{},
 		"Select specific analyzers to run. Can be repeated. If not specified, all analyzers are run. "+
 			"(e.g. istioctl analyze --analyzer \"gateway.ConflictingGatewayAnalyzer\")")
+	analysisCmd.PersistentFlags().StringArrayVar(&customAnalyzers, "custom-analyzers", []string{},
+		"Files or directories of custom analyzers, declared with CEL expressions, to run in addition to the built-in "+
+			"analyzers. Can be repeated.")
 	return analysisCmd
 }
 
@@ -381,7 +402,7 @@ func gatherFiles(cmd *cobra.Command, args []string) ([]local.ReaderSource, error
 
 		// Handle "-" as stdin as a special case.
 		if f == "-" {
-			if isatty.IsTerminal(os.Stdin.Fd()) && !isJSONorYAMLOutputFormat() {
+			if isatty.IsTerminal(os.Stdin.Fd()) && !isStructuredOutputFormat() {
 				fmt.Fprint(cmd.OutOrStdout(), "Reading from stdin:\n")
 			}
 			r = os.Stdin
@@ -507,6 +528,20 @@ func AnalyzersAsString(analyzers []analysis.Analyzer) string {
 	return b.String()
 }
 
+// filterOutputMessages returns the messages to output, at or above the output threshold.
+func filterOutputMessages(ms diag.Messages, printCluster bool) diag.Messages {
+	outputMessages := ms.SetDocRef("istioctl-analyze").FilterOutLowerThan(outputThreshold.Level)
+	if printCluster {
+		for i := range outputMessages {
+			m := &outputMessages[i]
+			if m.Resource != nil && m.Resource.Origin.ClusterName().String() != "" {
+				m.PrintCluster = true
+			}
+		}
+	}
+	return outputMessages
+}
+
 func analyzeTargetAsString() string {
 	if allNamespaces {
 		return "all namespaces"
@@ -515,8 +550,8 @@ func analyzeTargetAsString() string {
 }
 
 // TODO: Refactor output writer so that it is smart enough to know when to output what.
-func isJSONorYAMLOutputFormat() bool {
-	return msgOutputFormat == formatting.JSONFormat || msgOutputFormat == formatting.YAMLFormat
+func isStructuredOutputFormat() bool {
+	return msgOutputFormat != formatting.LogFormat
 }
 
 type Client struct {
@@ -15,6 +15,7 @@
 package analyze
 
 import (
+	"regexp"
 	"strings"
 	"testing"
 
@@ -23,6 +24,7 @@ import (
 	"istio.io/istio/istioctl/pkg/cli"
 	"istio.io/istio/istioctl/pkg/util/testutil"
 	"istio.io/istio/pkg/config/analysis/diag"
+	"istio.io/istio/pkg/url"
 )
 
 func TestErrorOnIssuesFound(t *testing.T) {
@@ -123,3 +125,70 @@ func TestRunSpecificAnalyzer(t *testing.T) {
 		})
 	}
 }
+
+func TestRunCustomAnalyzer(t *testing.T) {
+	ctx := cli.NewFakeContext(&cli.NewFakeContextOption{
+		IstioNamespace: "istio-system",
+	})
+	analyze := Analyze(ctx)
+	testutil.VerifyOutput(t, analyze, testutil.TestCase{
+		// The codes of custom analyzers are known to suppressions.
+		Args: []string{
+			"--use-kube=false", "--custom-analyzers", "testdata/analyze-file/custom-analyzers.yaml",
+			"--analyzer", "org.VirtualServiceTimeoutAnalyzer", "-S", "ORG0001=VirtualService ratings.default",
+			"testdata/analyze-file/virtualservice-timeout.yaml",
+		},
+		ExpectedOutput: "Error [ORG0001] (VirtualService default/reviews testdata/analyze-file/virtualservice-timeout.yaml:1) " +
+			"VirtualService reviews has HTTP routes without a timeout\n" +
+			"Error: Analyzers found issues when analyzing namespace: default.\n" +
+			"See " + url.ConfigAnalysis + " for more information about causes and resolutions.\n",
+		WantException: true,
+	})
+}
+
+func TestAnalyzeReportFormats(t *testing.T) {
+	ctx := cli.NewFakeContext(&cli.NewFakeContextOption{
+		IstioNamespace: "istio-system",
+	})
+	args := []string{
+		"--use-kube=false", "--custom-analyzers", "testdata/analyze-file/custom-analyzers.yaml",
+		"--analyzer", "org.VirtualServiceTimeoutAnalyzer", "testdata/analyze-file/virtualservice-timeout.yaml",
+	}
+
+	cases := []struct {
+		caseName string
+		testutil.TestCase
+	}{
+		{
+			caseName: "junit",
+			TestCase: testutil.TestCase{
+				Args: append([]string{"-o", "junit"}, args...),
+				ExpectedOutput: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="istioctl analyze" tests="1" failures="1">
+  <testsuite name="istioctl analyze" tests="1" failures="1" errors="0">
+    <testcase name="org.VirtualServiceTimeoutAnalyzer" classname="istioctl analyze">
+      <failure message="1 issue(s) found" type="Error">Error [ORG0001] (VirtualService default/reviews ` +
+					`testdata/analyze-file/virtualservice-timeout.yaml:1) VirtualService reviews has HTTP routes without a timeout</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`,
+			},
+		},
+		{
+			caseName: "sarif",
+			TestCase: testutil.TestCase{
+				Args: append([]string{"-o", "sarif"}, args...),
+				ExpectedRegexp: regexp.MustCompile(`(?s)"ruleId": "ORG0001",.*` +
+					`"uri": "testdata/analyze-file/virtualservice-timeout.yaml"\s*},\s*"region": {\s*"startLine": 1\s*}`),
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			analyze := Analyze(ctx)
+			testutil.VerifyOutput(t, analyze, tc.TestCase)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ca
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/istioctl/pkg/clioptions"
+	"istio.io/istio/pilot/pkg/carotation"
+	"istio.io/istio/security/pkg/pki/ca"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+// Cmd returns the command to manage the istiod CA.
+func Cmd(ctx cli.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ca",
+		Short: "Manage the Istio certificate authority",
+	}
+	cmd.AddCommand(rotateCmd(ctx))
+	cmd.AddCommand(revokeCmd(ctx))
+	return cmd
+}
+
+func rotateCmd(ctx cli.Context) *cobra.Command {
+	var (
+		opts    clioptions.ControlPlaneOptions
+		certDir string
+		overlap time.Duration
+		status  bool
+		abort   bool
+	)
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Rotate the root certificate of the istiod CA without downtime",
+		Long: `
+Rotates istiod to a new CA certificate, key and root. istiod first distributes the new root to all
+proxies as an additional trust anchor, then switches signing to the new CA once every connected proxy
+has acknowledged it, and finally removes the old root once certificates signed by the old CA have expired.
+
+The CA material is read from a directory with the same layout as the cacerts secret (ca-cert.pem,
+ca-key.pem, cert-chain.pem and root-cert.pem). Rotation requires ISTIO_MULTIROOT_MESH to be enabled
+in istiod, and istiod to be scaled to a single replica for the duration of the rotation. Once the
+rotation completed, update the cacerts secret with the new CA material.`,
+		Example: `  # Start rotating to the CA material in ./new-ca
+  istioctl x ca rotate --cert-dir ./new-ca
+
+  # Keep trusting the old root for 48 hours after istiod switched signing
+  istioctl x ca rotate --cert-dir ./new-ca --overlap 48h
+
+  # Show the rotation state of each istiod instance
+  istioctl x ca rotate --status
+
+  # Abort a rotation before istiod switched signing
+  istioctl x ca rotate --abort`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("rotate takes no arguments")
+			}
+			set := 0
+			for _, b := range []bool{certDir != "", status, abort} {
+				if b {
+					set++
+				}
+			}
+			if set != 1 {
+				return fmt.Errorf("exactly one of --cert-dir, --status or --abort must be set")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeClient, err := ctx.CLIClientWithRevision(ctx.RevisionOrDefault(opts.Revision))
+			if err != nil {
+				return err
+			}
+			secrets := kubeClient.Kube().CoreV1().Secrets(ctx.IstioNamespace())
+			switch {
+			case status:
+				res, err := kubeClient.AllDiscoveryDo(context.Background(), ctx.IstioNamespace(), "debug/ca_rotation")
+				if err != nil {
+					return err
+				}
+				return writeStatus(cmd.OutOrStdout(), res)
+			case abort:
+				err := secrets.Delete(context.Background(), carotation.SecretName, metav1.DeleteOptions{})
+				if kerrors.IsNotFound(err) {
+					return fmt.Errorf("no CA rotation in progress")
+				}
+				if err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "CA rotation aborted. A rotation that already switched signing will still complete.")
+				return nil
+			}
+
+			secret, err := rotationSecret(certDir, ctx.IstioNamespace(), overlap)
+			if err != nil {
+				return err
+			}
+			if _, err := secrets.Create(context.Background(), secret, metav1.CreateOptions{}); err != nil {
+				if !kerrors.IsAlreadyExists(err) {
+					return err
+				}
+				if _, err := secrets.Update(context.Background(), secret, metav1.UpdateOptions{}); err != nil {
+					return err
+				}
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "CA rotation requested. Run \"istioctl x ca rotate --status -i %s\" to follow its progress.\n",
+				ctx.IstioNamespace())
+			return nil
+		},
+	}
+	opts.AttachControlPlaneFlags(cmd)
+	cmd.Flags().StringVar(&certDir, "cert-dir", "", "Directory holding the new CA material, with the layout of the cacerts secret")
+	cmd.Flags().DurationVar(&overlap, "overlap", 0,
+		"How long the old root is trusted after istiod switched signing. Defaults to the maximum workload certificate TTL")
+	cmd.Flags().BoolVar(&status, "status", false, "Show the rotation state of each istiod instance")
+	cmd.Flags().BoolVar(&abort, "abort", false, "Abort the rotation, if istiod has not switched signing yet")
+	return cmd
+}
+
+// rotationSecret builds the rotation request secret from a directory with the cacerts layout.
+func rotationSecret(dir, namespace string, overlap time.Duration) (*corev1.Secret, error) {
+	data := map[string][]byte{}
+	for _, f := range []string{ca.CACertFile, ca.CAPrivateKeyFile, ca.CertChainFile, ca.RootCertFile} {
+		b, err := os.ReadFile(filepath.Join(dir, f))
+		if err != nil {
+			if os.IsNotExist(err) && f == ca.CertChainFile {
+				continue
+			}
+			return nil, err
+		}
+		data[f] = b
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      carotation.SecretName,
+			Namespace: namespace,
+		},
+		Data: data,
+	}
+	if overlap > 0 {
+		secret.Annotations = map[string]string{carotation.OverlapAnnotation: overlap.String()}
+	}
+	req, err := carotation.RequestFromSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := util.Verify(req.CertPem, req.KeyPem, req.CertChainPem, req.RootCertPem, nil); err != nil {
+		return nil, fmt.Errorf("invalid CA material in %s: %v", dir, err)
+	}
+	return secret, nil
+}
+
+func writeStatus(out io.Writer, input map[string][]byte) error {
+	istiods := make([]string, 0, len(input))
+	statuses := make(map[string]carotation.Status, len(input))
+	for istiod, b := range input {
+		var st carotation.Status
+		if err := json.Unmarshal(b, &st); err != nil {
+			return fmt.Errorf("failed to parse CA rotation status from %s: %v", istiod, err)
+		}
+		istiods = append(istiods, istiod)
+		statuses[istiod] = st
+	}
+	sort.Strings(istiods)
+
+	w := new(tabwriter.Writer).Init(out, 0, 8, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ISTIOD\tPHASE\tNEW ROOT\tACKED\tPENDING\tOVERLAP ENDS\tMESSAGE")
+	for _, istiod := range istiods {
+		st := statuses[istiod]
+		root, overlapEnds := "-", "-"
+		if st.NewRoot != nil {
+			root = st.NewRoot.Subject
+		}
+		if st.Phase == carotation.PhaseOverlap {
+			overlapEnds = st.OverlapEndTime.UTC().Format(time.RFC3339)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s\n",
+			istiod, st.Phase, root, st.AckedProxies, len(st.PendingProxies), overlapEnds, st.Message)
+	}
+	return w.Flush()
+}
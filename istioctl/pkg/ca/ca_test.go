@@ -0,0 +1,132 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ca
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/pilot/pkg/carotation"
+	"istio.io/istio/pkg/test/util/assert"
+	"istio.io/istio/security/pkg/pki/ca"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+func writeCA(t *testing.T, mismatchedKey bool) string {
+	t.Helper()
+	gen := func() ([]byte, []byte) {
+		cert, key, err := util.GenCertKeyFromOptions(util.CertOptions{
+			TTL:          time.Hour,
+			Org:          "new",
+			IsCA:         true,
+			IsSelfSigned: true,
+			RSAKeySize:   2048,
+		})
+		assert.NoError(t, err)
+		return cert, key
+	}
+	cert, key := gen()
+	if mismatchedKey {
+		_, key = gen()
+	}
+	dir := t.TempDir()
+	for f, b := range map[string][]byte{ca.CACertFile: cert, ca.CAPrivateKeyFile: key, ca.RootCertFile: cert} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, f), b, 0o600))
+	}
+	return dir
+}
+
+func runRotate(t *testing.T, ctx cli.Context, args ...string) (string, error) {
+	t.Helper()
+	cmd := Cmd(ctx)
+	var out bytes.Buffer
+	cmd.SetArgs(append([]string{"rotate"}, args...))
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SilenceUsage = true
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestRotate(t *testing.T) {
+	ctx := cli.NewFakeContext(&cli.NewFakeContextOption{IstioNamespace: "istio-system"})
+	dir := writeCA(t, false)
+
+	_, err := runRotate(t, ctx, "--cert-dir", dir, "--overlap", "48h")
+	assert.NoError(t, err)
+	client, err := ctx.CLIClientWithRevision(ctx.RevisionOrDefault(""))
+	assert.NoError(t, err)
+	secret, err := client.Kube().CoreV1().Secrets("istio-system").Get(context.Background(), carotation.SecretName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	req, err := carotation.RequestFromSecret(secret)
+	assert.NoError(t, err)
+	assert.Equal(t, req.Overlap, 48*time.Hour)
+
+	// Requesting the rotation again updates the existing secret.
+	_, err = runRotate(t, ctx, "--cert-dir", dir)
+	assert.NoError(t, err)
+
+	_, err = runRotate(t, ctx, "--abort")
+	assert.NoError(t, err)
+	_, err = client.Kube().CoreV1().Secrets("istio-system").Get(context.Background(), carotation.SecretName, metav1.GetOptions{})
+	assert.Error(t, err)
+
+	_, err = runRotate(t, ctx, "--abort")
+	assert.Error(t, err)
+}
+
+func TestRotateInvalid(t *testing.T) {
+	ctx := cli.NewFakeContext(&cli.NewFakeContextOption{IstioNamespace: "istio-system"})
+	_, err := runRotate(t, ctx, "--cert-dir", writeCA(t, true))
+	if err == nil || !strings.Contains(err.Error(), "invalid CA material") {
+		t.Fatalf("expected invalid CA material error, got %v", err)
+	}
+	_, err = runRotate(t, ctx, "--status", "--abort")
+	assert.Error(t, err)
+	_, err = runRotate(t, ctx)
+	assert.Error(t, err)
+
+	client, err := ctx.CLIClientWithRevision(ctx.RevisionOrDefault(""))
+	assert.NoError(t, err)
+	secrets, err := client.Kube().CoreV1().Secrets("istio-system").List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, len(secrets.Items), 0)
+}
+
+func TestRotateStatus(t *testing.T) {
+	ctx := cli.NewFakeContext(&cli.NewFakeContextOption{
+		IstioNamespace: "istio-system",
+		Results: map[string][]byte{
+			"istiod-1.istio-system": []byte(`{"phase":"Overlap","newRoot":{"subject":"O=new"},"ackedProxies":3,` +
+				`"overlapEndTime":"2026-10-19T10:00:00Z","message":"signing with the new CA"}`),
+			"istiod-0.istio-system": []byte(`{"phase":"DistributingTrustAnchor","newRoot":{"subject":"O=new"},"ackedProxies":2,` +
+				`"pendingProxies":["a","b"],"message":"waiting"}`),
+		},
+	})
+	out, err := runRotate(t, ctx, "--status")
+	assert.NoError(t, err)
+	assert.Equal(t, out, `ISTIOD                  PHASE                     NEW ROOT   ACKED   PENDING   OVERLAP ENDS           MESSAGE
+istiod-0.istio-system   DistributingTrustAnchor   O=new      2       2         -                      waiting
+istiod-1.istio-system   Overlap                   O=new      3       0         2026-10-19T10:00:00Z   signing with the new CA
+`)
+}
@@ -0,0 +1,304 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ca
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/istioctl/pkg/clioptions"
+	"istio.io/istio/pilot/pkg/revocation"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/maps"
+	"istio.io/istio/pkg/slices"
+	"istio.io/istio/pkg/spiffe"
+	caserver "istio.io/istio/security/pkg/server/ca"
+)
+
+func revokeCmd(ctx cli.Context) *cobra.Command {
+	var (
+		opts     clioptions.ControlPlaneOptions
+		serial   string
+		spiffeID string
+		reason   string
+		remove   bool
+		list     bool
+		status   bool
+	)
+	cmd := &cobra.Command{
+		Use:   "revoke",
+		Short: "Revoke certificates issued by the istiod CA",
+		Long: `
+Revokes a certificate by serial number, or all certificates of a SPIFFE identity. Revocations are stored in the
+istio-revoked-certificates ConfigMap in the istiod namespace. istiod signs a certificate revocation list (CRL) with
+the revoked serial numbers and pushes it to proxies, which reject revoked peer certificates within seconds.
+Revoked identities are also denied new certificates by the istiod CA.
+
+Revoking an identity also revokes the unexpired certificates istiod issued to it. Removing the revocation of an
+identity does not remove the revocations of these certificates.
+
+Revocation requires ENABLE_CA_REVOCATION to be enabled in istiod, and CRL_XDS_AGENT in the proxies.`,
+		Example: `  # Revoke a certificate by serial number
+  istioctl x ca revoke --serial 7d3f0c1e5a --reason keyCompromise
+
+  # Revoke a workload identity and the certificates issued to it
+  istioctl x ca revoke --spiffe-id spiffe://cluster.local/ns/foo/sa/bar
+
+  # Remove a revocation
+  istioctl x ca revoke --serial 7d3f0c1e5a --remove
+
+  # List the revocations
+  istioctl x ca revoke --list
+
+  # Show the revocation state of each istiod instance
+  istioctl x ca revoke --status`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("revoke takes no arguments")
+			}
+			set := 0
+			for _, b := range []bool{serial != "", spiffeID != "", list, status} {
+				if b {
+					set++
+				}
+			}
+			if set != 1 {
+				return fmt.Errorf("exactly one of --serial, --spiffe-id, --list or --status must be set")
+			}
+			if remove && (list || status) {
+				return fmt.Errorf("--remove requires --serial or --spiffe-id")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeClient, err := ctx.CLIClientWithRevision(ctx.RevisionOrDefault(opts.Revision))
+			if err != nil {
+				return err
+			}
+			configMaps := kubeClient.Kube().CoreV1().ConfigMaps(ctx.IstioNamespace())
+			cm, err := configMaps.Get(context.Background(), revocation.ConfigMapName, metav1.GetOptions{})
+			if kerrors.IsNotFound(err) {
+				cm, err = nil, nil
+			}
+			if err != nil {
+				return err
+			}
+			var entries []revocation.Entry
+			if cm != nil {
+				if entries, err = revocation.EntriesFromConfigMap(cm); err != nil {
+					return err
+				}
+			}
+			switch {
+			case list:
+				return writeRevocations(cmd.OutOrStdout(), entries)
+			case status:
+				res, err := kubeClient.AllDiscoveryDo(context.Background(), ctx.IstioNamespace(), "debug/revocationz")
+				if err != nil {
+					return err
+				}
+				return writeRevocationStatus(cmd.OutOrStdout(), res)
+			}
+
+			req := revocation.Entry{
+				SerialNumber: serial,
+				SpiffeID:     spiffeID,
+				Reason:       reason,
+				RevokedAt:    time.Now().UTC().Truncate(time.Second),
+			}
+			var issued []caserver.IssuedCertificate
+			if spiffeID != "" && !remove {
+				if issued, err = issuedCertificates(kubeClient, ctx.IstioNamespace(), spiffeID); err != nil {
+					// The identity is still denied new certificates.
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: failed to list the certificates issued to %s, "+
+						"only the identity is revoked: %v\n", spiffeID, err)
+				}
+			}
+			updated, err := applyRevocation(entries, req, issued, remove, time.Now())
+			if err != nil {
+				return err
+			}
+			data, err := revocation.ConfigMapData(updated)
+			if err != nil {
+				return err
+			}
+			if cm == nil {
+				cm = &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      revocation.ConfigMapName,
+						Namespace: ctx.IstioNamespace(),
+					},
+					Data: data,
+				}
+				_, err = configMaps.Create(context.Background(), cm, metav1.CreateOptions{})
+			} else {
+				cm.Data = data
+				_, err = configMaps.Update(context.Background(), cm, metav1.UpdateOptions{})
+			}
+			if err != nil {
+				return err
+			}
+			if remove {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Revocation removed.")
+				return nil
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Added %d revocations. "+
+				"Run \"istioctl x ca revoke --status -i %s\" to check istiod distributed them.\n",
+				len(updated)-len(entries), ctx.IstioNamespace())
+			return nil
+		},
+	}
+	opts.AttachControlPlaneFlags(cmd)
+	cmd.Flags().StringVar(&serial, "serial", "", "Hex encoded serial number of the certificate to revoke")
+	cmd.Flags().StringVar(&spiffeID, "spiffe-id", "", "SPIFFE identity to revoke, such as spiffe://cluster.local/ns/foo/sa/bar")
+	cmd.Flags().StringVar(&reason, "reason", "", fmt.Sprintf("Reason of the revocation: one of %s",
+		strings.Join(slices.Sort(maps.Keys(revocation.Reasons)), "|")))
+	cmd.Flags().BoolVar(&remove, "remove", false, "Remove the revocation of the serial number or identity")
+	cmd.Flags().BoolVar(&list, "list", false, "List the revocations")
+	cmd.Flags().BoolVar(&status, "status", false, "Show the revocation state of each istiod instance")
+	return cmd
+}
+
+// issuedCertificates returns the certificates the istiod instances issued to a SPIFFE identity.
+func issuedCertificates(kubeClient kube.CLIClient, istioNamespace, spiffeID string) ([]caserver.IssuedCertificate, error) {
+	id, err := spiffe.ParseIdentity(spiffeID)
+	if err != nil {
+		return nil, err
+	}
+	res, err := kubeClient.AllDiscoveryDo(context.Background(), istioNamespace,
+		"debug/certz?"+url.Values{"namespace": []string{id.Namespace}}.Encode())
+	if err != nil {
+		return nil, err
+	}
+	var out []caserver.IssuedCertificate
+	for istiod, b := range res {
+		var inv caserver.CertInventoryStatus
+		if err := json.Unmarshal(b, &inv); err != nil {
+			return nil, fmt.Errorf("failed to parse certificate inventory from %s: %v", istiod, err)
+		}
+		out = append(out, inv.Certificates...)
+	}
+	return out, nil
+}
+
+// applyRevocation adds or removes a revocation. Revoking an identity also revokes the unexpired certificates
+// issued to it, so proxies reject them before they expire.
+func applyRevocation(entries []revocation.Entry, req revocation.Entry, issued []caserver.IssuedCertificate,
+	remove bool, now time.Time,
+) ([]revocation.Entry, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if remove {
+		out := slices.FilterInPlace(slices.Clone(entries), func(e revocation.Entry) bool {
+			return !sameRevocation(e, req)
+		})
+		if len(out) == len(entries) {
+			return nil, fmt.Errorf("%s is not revoked", req.SerialNumber+req.SpiffeID)
+		}
+		return out, nil
+	}
+	out := slices.Clone(entries)
+	add := func(e revocation.Entry) {
+		if slices.FindFunc(out, func(o revocation.Entry) bool { return sameRevocation(o, e) }) == nil {
+			out = append(out, e)
+		}
+	}
+	add(req)
+	if req.SpiffeID != "" {
+		certs := slices.Filter(issued, func(c caserver.IssuedCertificate) bool {
+			return c.NotAfter.After(now) && slices.Contains(c.SANs, req.SpiffeID)
+		})
+		slices.SortBy(certs, func(c caserver.IssuedCertificate) string { return c.SerialNumber })
+		for _, c := range certs {
+			add(revocation.Entry{SerialNumber: c.SerialNumber, Reason: req.Reason, RevokedAt: req.RevokedAt})
+		}
+	}
+	return out, nil
+}
+
+// sameRevocation returns true if both entries revoke the same serial number or identity.
+func sameRevocation(a, b revocation.Entry) bool {
+	if a.SpiffeID != "" || b.SpiffeID != "" {
+		return a.SpiffeID == b.SpiffeID
+	}
+	x, _ := new(big.Int).SetString(a.SerialNumber, 16)
+	y, _ := new(big.Int).SetString(b.SerialNumber, 16)
+	return x != nil && y != nil && x.Cmp(y) == 0
+}
+
+func writeRevocations(out io.Writer, entries []revocation.Entry) error {
+	w := new(tabwriter.Writer).Init(out, 0, 8, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SERIAL NUMBER\tSPIFFE ID\tREASON\tREVOKED AT")
+	for _, e := range entries {
+		serial, id, reason := "-", "-", "unspecified"
+		if e.SerialNumber != "" {
+			serial = e.SerialNumber
+		}
+		if e.SpiffeID != "" {
+			id = e.SpiffeID
+		}
+		if e.Reason != "" {
+			reason = e.Reason
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", serial, id, reason, e.RevokedAt.UTC().Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+func writeRevocationStatus(out io.Writer, input map[string][]byte) error {
+	istiods := make([]string, 0, len(input))
+	statuses := make(map[string]revocation.Status, len(input))
+	for istiod, b := range input {
+		var st revocation.Status
+		if err := json.Unmarshal(b, &st); err != nil {
+			return fmt.Errorf("failed to parse revocation status from %s: %v", istiod, err)
+		}
+		istiods = append(istiods, istiod)
+		statuses[istiod] = st
+	}
+	sort.Strings(istiods)
+
+	w := new(tabwriter.Writer).Init(out, 0, 8, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ISTIOD\tCERTIFICATES\tIDENTITIES\tCRL NUMBER\tNEXT UPDATE\tERROR")
+	for _, istiod := range istiods {
+		st := statuses[istiod]
+		number, next, errMsg := "-", "-", "-"
+		if st.CRLNumber != "" {
+			number = st.CRLNumber
+			next = st.NextUpdate.UTC().Format(time.RFC3339)
+		}
+		if st.Error != "" {
+			errMsg = st.Error
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\n",
+			istiod, len(st.RevokedSerialNumbers), len(st.RevokedIdentities), number, next, errMsg)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,157 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ca
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/pilot/pkg/revocation"
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/pkg/test/util/assert"
+	caserver "istio.io/istio/security/pkg/server/ca"
+)
+
+func runRevoke(t *testing.T, ctx cli.Context, args ...string) (string, error) {
+	t.Helper()
+	cmd := Cmd(ctx)
+	var out bytes.Buffer
+	cmd.SetArgs(append([]string{"revoke"}, args...))
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SilenceUsage = true
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func revocations(t *testing.T, ctx cli.Context) []revocation.Entry {
+	t.Helper()
+	client, err := ctx.CLIClientWithRevision(ctx.RevisionOrDefault(""))
+	assert.NoError(t, err)
+	cm, err := client.Kube().CoreV1().ConfigMaps("istio-system").Get(context.Background(), revocation.ConfigMapName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	entries, err := revocation.EntriesFromConfigMap(cm)
+	assert.NoError(t, err)
+	return entries
+}
+
+func TestRevoke(t *testing.T) {
+	ctx := cli.NewFakeContext(&cli.NewFakeContextOption{
+		IstioNamespace: "istio-system",
+		Results: map[string][]byte{
+			"istiod-0.istio-system": []byte(`{"certificates":[` +
+				`{"serialNumber":"b","sans":["spiffe://cluster.local/ns/foo/sa/bar"],"notAfter":"2999-01-01T00:00:00Z"},` +
+				`{"serialNumber":"c","sans":["spiffe://cluster.local/ns/foo/sa/other"],"notAfter":"2999-01-01T00:00:00Z"}]}`),
+		},
+	})
+
+	_, err := runRevoke(t, ctx, "--serial", "0A", "--reason", "keyCompromise")
+	assert.NoError(t, err)
+	_, err = runRevoke(t, ctx, "--spiffe-id", "spiffe://cluster.local/ns/foo/sa/bar")
+	assert.NoError(t, err)
+	entries := revocations(t, ctx)
+	assert.Equal(t, len(entries), 3)
+	assert.Equal(t, entries[0].SerialNumber, "0A")
+	assert.Equal(t, entries[0].Reason, "keyCompromise")
+	assert.Equal(t, entries[1].SpiffeID, "spiffe://cluster.local/ns/foo/sa/bar")
+	// The certificate issued to the revoked identity is revoked as well.
+	assert.Equal(t, entries[2].SerialNumber, "b")
+
+	out, err := runRevoke(t, ctx, "--list")
+	assert.NoError(t, err)
+	if !strings.Contains(out, "spiffe://cluster.local/ns/foo/sa/bar") || !strings.Contains(out, "keyCompromise") {
+		t.Fatalf("unexpected list output:\n%s", out)
+	}
+
+	// Serial numbers are compared by value.
+	_, err = runRevoke(t, ctx, "--serial", "a", "--remove")
+	assert.NoError(t, err)
+	assert.Equal(t, len(revocations(t, ctx)), 2)
+	_, err = runRevoke(t, ctx, "--serial", "a", "--remove")
+	assert.Error(t, err)
+}
+
+func TestRevokeInvalid(t *testing.T) {
+	ctx := cli.NewFakeContext(&cli.NewFakeContextOption{IstioNamespace: "istio-system"})
+	for _, args := range [][]string{
+		{},
+		{"--serial", "1", "--spiffe-id", "spiffe://cluster.local/ns/foo/sa/bar"},
+		{"--list", "--remove"},
+		{"--serial", "xyz"},
+		{"--serial", "1", "--reason", "lost"},
+		{"--spiffe-id", "spiffe://cluster.local/foo"},
+	} {
+		if _, err := runRevoke(t, ctx, args...); err == nil {
+			t.Fatalf("expected %v to fail", args)
+		}
+	}
+}
+
+func TestApplyRevocation(t *testing.T) {
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	id := "spiffe://cluster.local/ns/foo/sa/bar"
+	cert := func(serial string, notAfter time.Time, sans ...string) caserver.IssuedCertificate {
+		return caserver.IssuedCertificate{CertificateInfo: security.CertificateInfo{SerialNumber: serial, SANs: sans, NotAfter: notAfter}}
+	}
+	issued := []caserver.IssuedCertificate{
+		cert("2", now.Add(time.Hour), id),
+		cert("1", now.Add(time.Hour), id),
+		cert("3", now.Add(-time.Hour), id),
+		cert("4", now.Add(time.Hour), "spiffe://cluster.local/ns/foo/sa/other"),
+	}
+	existing := []revocation.Entry{{SerialNumber: "01"}}
+
+	got, err := applyRevocation(existing, revocation.Entry{SpiffeID: id, Reason: "keyCompromise", RevokedAt: now}, issued, false, now)
+	assert.NoError(t, err)
+	// Expired certificates and the certificates of other identities are left alone, and "01" is already revoked.
+	assert.Equal(t, got, []revocation.Entry{
+		{SerialNumber: "01"},
+		{SpiffeID: id, Reason: "keyCompromise", RevokedAt: now},
+		{SerialNumber: "2", Reason: "keyCompromise", RevokedAt: now},
+	})
+
+	got, err = applyRevocation(got, revocation.Entry{SpiffeID: id}, nil, true, now)
+	assert.NoError(t, err)
+	assert.Equal(t, got, []revocation.Entry{
+		{SerialNumber: "01"},
+		{SerialNumber: "2", Reason: "keyCompromise", RevokedAt: now},
+	})
+	// The existing entries are not modified.
+	assert.Equal(t, existing, []revocation.Entry{{SerialNumber: "01"}})
+}
+
+func TestRevokeStatus(t *testing.T) {
+	ctx := cli.NewFakeContext(&cli.NewFakeContextOption{
+		IstioNamespace: "istio-system",
+		Results: map[string][]byte{
+			"istiod-1.istio-system": []byte(`{"revokedSerialNumbers":["a"],"revokedIdentities":[],` +
+				`"error":"CA certificate does not allow signing CRLs"}`),
+			"istiod-0.istio-system": []byte(`{"revokedSerialNumbers":["a"],"revokedIdentities":["spiffe://cluster.local/ns/foo/sa/bar"],` +
+				`"crlNumber":"42","nextUpdate":"2026-10-20T10:00:00Z"}`),
+		},
+	})
+	out, err := runRevoke(t, ctx, "--status")
+	assert.NoError(t, err)
+	assert.Equal(t, out, `ISTIOD                  CERTIFICATES   IDENTITIES   CRL NUMBER   NEXT UPDATE            ERROR
+istiod-0.istio-system   1              1            42           2026-10-20T10:00:00Z   -
+istiod-1.istio-system   1              0            -            -                      CA certificate does not allow signing CRLs
+`)
+}
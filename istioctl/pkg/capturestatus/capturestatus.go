@@ -0,0 +1,160 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capturestatus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/cni/pkg/capture"
+	cniconstants "istio.io/istio/cni/pkg/constants"
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/istioctl/pkg/completion"
+	"istio.io/istio/istioctl/pkg/ztunnelconfig"
+)
+
+const (
+	jsonOutput    = "json"
+	summaryOutput = "short"
+
+	// nodeAgentDaemonSet is the name of the Istio CNI node agent DaemonSet
+	nodeAgentDaemonSet = "istio-cni-node"
+)
+
+func Cmd(ctx cli.Context) *cobra.Command {
+	var outputFormat string
+	cmd := &cobra.Command{
+		Use:   "capture-status <pod-name>[.<namespace>]",
+		Short: "Inspect the ambient traffic capture rules installed in a pod",
+		Long: `
+Asks the Istio CNI node agent on the pod's node to enter the pod network namespace and
+compare the installed iptables/nftables rules and host probe set entries against the rules
+the node agent would generate for the pod. Any drift between the two is reported.`,
+		Example: `  # Check the traffic capture state of a pod
+  istioctl x capture-status productpage-v1-7d4c8b5c9d-x2x6q.default
+
+  # Retrieve the full installed and expected rule sets in JSON
+  istioctl x capture-status productpage-v1-7d4c8b5c9d-x2x6q -n default -o json
+`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				cmd.Println(cmd.UsageString())
+				return fmt.Errorf("capture-status requires a pod name")
+			}
+			if outputFormat != jsonOutput && outputFormat != summaryOutput {
+				return fmt.Errorf("output format %q not supported", outputFormat)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeClient, err := ctx.CLIClient()
+			if err != nil {
+				return err
+			}
+			podName, podNs, err := ctx.InferPodInfoFromTypedResource(args[0], ctx.NamespaceOrDefault(ctx.Namespace()))
+			if err != nil {
+				return err
+			}
+			pod, err := kubeClient.Kube().CoreV1().Pods(podNs).Get(context.TODO(), podName, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			if pod.Spec.NodeName == "" {
+				return fmt.Errorf("pod %s.%s is not scheduled to a node", podName, podNs)
+			}
+			agent, err := ztunnelconfig.PodOnNodeFromDaemonset(pod.Spec.NodeName, nodeAgentDaemonSet, ctx.IstioNamespace(), kubeClient)
+			if err != nil {
+				return fmt.Errorf("failed to find the Istio CNI node agent on node %s: %v", pod.Spec.NodeName, err)
+			}
+			path := fmt.Sprintf("%s?namespace=%s&name=%s",
+				strings.TrimPrefix(cniconstants.CaptureEndpoint, "/"), url.QueryEscape(podNs), url.QueryEscape(podName))
+			result, err := kubeClient.EnvoyDoWithPort(context.TODO(), agent.Name, agent.Namespace, "GET", path, cniconstants.DebugPort)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve capture status from %s.%s: %v", agent.Name, agent.Namespace, err)
+			}
+			status := &capture.Status{}
+			if err := json.Unmarshal(result, status); err != nil {
+				return fmt.Errorf("failed to parse capture status from %s.%s: %v", agent.Name, agent.Namespace, err)
+			}
+			if outputFormat == jsonOutput {
+				out, err := json.MarshalIndent(status, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), string(out))
+				return nil
+			}
+			printSummary(cmd.OutOrStdout(), status)
+			return nil
+		},
+		ValidArgsFunction: completion.ValidPodsNameArgs(ctx),
+	}
+	cmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", summaryOutput, "Output format: one of json|short")
+	return cmd
+}
+
+func printSummary(w io.Writer, status *capture.Status) {
+	_, _ = fmt.Fprintf(w, "Pod: %s.%s\n", status.Name, status.Namespace)
+	if status.Node != "" {
+		_, _ = fmt.Fprintf(w, "Node: %s\n", status.Node)
+	}
+	_, _ = fmt.Fprintf(w, "Enrolled: %t\n", status.Enrolled)
+	if status.Enrolled {
+		switch {
+		case !status.ZtunnelConnected:
+			_, _ = fmt.Fprintln(w, "Ztunnel: not connected")
+		case status.ZtunnelAcknowledged:
+			_, _ = fmt.Fprintln(w, "Ztunnel: acknowledged")
+		default:
+			_, _ = fmt.Fprintln(w, "Ztunnel: not acknowledged")
+		}
+	}
+	if status.Rules != nil {
+		_, _ = fmt.Fprintf(w, "Backend: %s\n", status.Rules.Backend)
+		_, _ = fmt.Fprintf(w, "Rules: %d expected, %d installed\n", len(status.Rules.Expected), len(status.Rules.Installed))
+	}
+	if status.HostProbeSet != nil {
+		_, _ = fmt.Fprintf(w, "Host probe set %s: %d expected, %d missing\n",
+			status.HostProbeSet.Name, len(status.HostProbeSet.Expected), len(status.HostProbeSet.Missing))
+	}
+	if status.Drifted() {
+		_, _ = fmt.Fprintln(w, "Drift: detected")
+	} else {
+		_, _ = fmt.Fprintln(w, "Drift: none")
+	}
+	if status.Rules != nil {
+		for _, r := range status.Rules.Missing {
+			_, _ = fmt.Fprintf(w, "  - %s\n", r)
+		}
+		for _, r := range status.Rules.Unexpected {
+			_, _ = fmt.Fprintf(w, "  + %s\n", r)
+		}
+	}
+	if status.HostProbeSet != nil {
+		for _, ip := range status.HostProbeSet.Missing {
+			_, _ = fmt.Fprintf(w, "  - %s %s\n", status.HostProbeSet.Name, ip)
+		}
+	}
+	for _, e := range status.Errors {
+		_, _ = fmt.Fprintf(w, "Error: %s\n", e)
+	}
+}
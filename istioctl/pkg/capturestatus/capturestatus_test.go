@@ -0,0 +1,144 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capturestatus
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+const driftedStatus = `{
+  "namespace": "default",
+  "name": "httpbin",
+  "uid": "1234",
+  "node": "node-1",
+  "enrolled": true,
+  "ztunnelConnected": true,
+  "ztunnelAcknowledged": true,
+  "rules": {
+    "backend": "iptables",
+    "expected": ["ipv4 mangle: -A ISTIO_PRERT -j ACCEPT", "ipv4 nat: -A ISTIO_OUTPUT -j ACCEPT"],
+    "installed": ["ipv4 nat: -A ISTIO_OUTPUT -j ACCEPT", "ipv4 nat: -A ISTIO_OUTPUT -j DROP"],
+    "missing": ["ipv4 mangle: -A ISTIO_PRERT -j ACCEPT"],
+    "unexpected": ["ipv4 nat: -A ISTIO_OUTPUT -j DROP"]
+  },
+  "hostProbeSet": {
+    "name": "istio-inpod-probes",
+    "expected": ["10.0.0.5"],
+    "missing": ["10.0.0.5"]
+  }
+}`
+
+func TestCaptureStatus(t *testing.T) {
+	cases := []struct {
+		name           string
+		args           []string
+		results        map[string][]byte
+		expectedOutput string
+		wantErr        string
+	}{
+		{
+			name:    "drifted pod",
+			args:    []string{"httpbin"},
+			results: map[string][]byte{"istio-cni-node-abcde": []byte(driftedStatus)},
+			expectedOutput: `Pod: httpbin.default
+Node: node-1
+Enrolled: true
+Ztunnel: acknowledged
+Backend: iptables
+Rules: 2 expected, 2 installed
+Host probe set istio-inpod-probes: 1 expected, 1 missing
+Drift: detected
+  - ipv4 mangle: -A ISTIO_PRERT -j ACCEPT
+  + ipv4 nat: -A ISTIO_OUTPUT -j DROP
+  - istio-inpod-probes 10.0.0.5
+`,
+		},
+		{
+			name:    "unenrolled pod",
+			args:    []string{"httpbin.default"},
+			results: map[string][]byte{"istio-cni-node-abcde": []byte(`{"namespace":"default","name":"httpbin","enrolled":false}`)},
+			expectedOutput: `Pod: httpbin.default
+Enrolled: false
+Drift: none
+`,
+		},
+		{
+			name:    "node agent unreachable",
+			args:    []string{"httpbin"},
+			results: map[string][]byte{},
+			wantErr: "failed to retrieve capture status from istio-cni-node-abcde.istio-system",
+		},
+		{
+			name:    "unsupported output",
+			args:    []string{"httpbin", "-o", "yaml"},
+			wantErr: `output format "yaml" not supported`,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := cli.NewFakeContext(&cli.NewFakeContextOption{
+				Namespace:      "default",
+				IstioNamespace: "istio-system",
+				Results:        tt.results,
+				Objects:        testObjects(),
+			})
+			cmd := Cmd(ctx)
+			var out bytes.Buffer
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(&out)
+			cmd.SetErr(&out)
+			cmd.SilenceUsage = true
+			err := cmd.Execute()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, out.String(), tt.expectedOutput)
+		})
+	}
+}
+
+func testObjects() []runtime.Object {
+	labels := map[string]string{"k8s-app": "istio-cni-node"}
+	return []runtime.Object{
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "httpbin", Namespace: "default"},
+			Spec:       corev1.PodSpec{NodeName: "node-1"},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "istio-cni-node", Namespace: "istio-system"},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "istio-cni-node-abcde", Namespace: "istio-system", Labels: labels},
+			Spec:       corev1.PodSpec{NodeName: "node-1"},
+		},
+	}
+}
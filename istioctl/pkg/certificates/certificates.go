@@ -0,0 +1,285 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certificates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/istioctl/pkg/clioptions"
+	"istio.io/istio/istioctl/pkg/completion"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/maps"
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/pkg/slices"
+	"istio.io/istio/pkg/util/sets"
+	caserver "istio.io/istio/security/pkg/server/ca"
+)
+
+const (
+	jsonOutput    = "json"
+	summaryOutput = "short"
+
+	// agentStatusPort is the istio-agent status port, which also serves its debug endpoints
+	agentStatusPort = 15020
+)
+
+// PodCertificates is the certificate state of a pod, compared against the istiod certificate inventory.
+type PodCertificates struct {
+	Pod   string                      `json:"pod"`
+	Agent *security.AgentCertificates `json:"agent"`
+	// IssuedBy are the istiod instances which issued the workload certificate, with their record of it.
+	IssuedBy map[string]caserver.IssuedCertificate `json:"issuedBy,omitempty"`
+	Drift    []string                              `json:"drift,omitempty"`
+}
+
+func Cmd(ctx cli.Context) *cobra.Command {
+	var (
+		opts           clioptions.ControlPlaneOptions
+		outputFormat   string
+		expiringWithin time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "certificates [<pod-name>[.<namespace>]]",
+		Short: "List the workload certificates issued by istiod, or check the certificates of a pod",
+		Long: `
+Without arguments, lists the workload certificates issued by each istiod instance, soonest to expire first.
+Only certificates in the selected namespace are listed, if one is set. istiod only tracks the certificates
+it issues if CA_CERT_INVENTORY_SIZE is set.
+
+With a pod, compares the workload certificate and trust bundle the pod's istio-agent serves to Envoy over SDS
+against the certificates and roots of the istiod instances, and reports any drift.`,
+		Example: `  # List the certificates expiring within the next day
+  istioctl x certificates --expiring-within 24h
+
+  # Check the certificates of a pod
+  istioctl x certificates productpage-v1-7d4c8b5c9d-x2x6q.default`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return fmt.Errorf("at most one pod can be checked")
+			}
+			if outputFormat != jsonOutput && outputFormat != summaryOutput {
+				return fmt.Errorf("output format %q not supported", outputFormat)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeClient, err := ctx.CLIClientWithRevision(ctx.RevisionOrDefault(opts.Revision))
+			if err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				query := url.Values{}
+				if ctx.Namespace() != "" {
+					query.Set("namespace", ctx.Namespace())
+				}
+				if expiringWithin > 0 {
+					query.Set("expiringWithin", expiringWithin.String())
+				}
+				inventories, err := fetchInventories(kubeClient, ctx.IstioNamespace(), query)
+				if err != nil {
+					return err
+				}
+				if outputFormat == jsonOutput {
+					return writeJSON(cmd.OutOrStdout(), inventories)
+				}
+				return printInventories(cmd.OutOrStdout(), inventories)
+			}
+
+			podName, podNs, err := ctx.InferPodInfoFromTypedResource(args[0], ctx.NamespaceOrDefault(ctx.Namespace()))
+			if err != nil {
+				return err
+			}
+			result, err := kubeClient.EnvoyDoWithPort(context.TODO(), podName, podNs, "GET", "debug/certz", agentStatusPort)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve certificates from %s.%s: %v", podName, podNs, err)
+			}
+			agent := &security.AgentCertificates{}
+			if err := json.Unmarshal(result, agent); err != nil {
+				return fmt.Errorf("failed to parse certificates from %s.%s: %v", podName, podNs, err)
+			}
+			query := url.Values{"namespace": []string{podNs}}
+			if agent.Workload != nil {
+				query.Set("serial", agent.Workload.SerialNumber)
+			}
+			inventories, err := fetchInventories(kubeClient, ctx.IstioNamespace(), query)
+			if err != nil {
+				return err
+			}
+			status := comparePod(podName+"."+podNs, agent, inventories, expiringWithin, time.Now())
+			if outputFormat == jsonOutput {
+				return writeJSON(cmd.OutOrStdout(), status)
+			}
+			printPod(cmd.OutOrStdout(), status)
+			return nil
+		},
+		ValidArgsFunction: completion.ValidPodsNameArgs(ctx),
+	}
+	opts.AttachControlPlaneFlags(cmd)
+	cmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", summaryOutput, "Output format: one of json|short")
+	cmd.PersistentFlags().DurationVar(&expiringWithin, "expiring-within", 0,
+		"Only list certificates expiring within this duration. When checking a pod, report its certificate as drifted "+
+			"if it expires within this duration")
+	return cmd
+}
+
+func fetchInventories(kubeClient kube.CLIClient, istioNamespace string, query url.Values) (map[string]caserver.CertInventoryStatus, error) {
+	path := "debug/certz"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	res, err := kubeClient.AllDiscoveryDo(context.Background(), istioNamespace, path)
+	if err != nil {
+		return nil, err
+	}
+	inventories := make(map[string]caserver.CertInventoryStatus, len(res))
+	for istiod, b := range res {
+		var inv caserver.CertInventoryStatus
+		if err := json.Unmarshal(b, &inv); err != nil {
+			return nil, fmt.Errorf("failed to parse certificate inventory from %s: %v", istiod, err)
+		}
+		inventories[istiod] = inv
+	}
+	return inventories, nil
+}
+
+// comparePod compares the certificates served by an agent with the istiod inventories.
+func comparePod(pod string, agent *security.AgentCertificates, inventories map[string]caserver.CertInventoryStatus,
+	expiringWithin time.Duration, now time.Time,
+) *PodCertificates {
+	status := &PodCertificates{Pod: pod, Agent: agent}
+	istiods := slices.Sort(maps.Keys(inventories))
+	w := agent.Workload
+	if w == nil {
+		status.Drift = append(status.Drift, "the agent has no workload certificate cached")
+	} else {
+		for _, istiod := range istiods {
+			for _, c := range inventories[istiod].Certificates {
+				if c.SerialNumber == w.SerialNumber {
+					if status.IssuedBy == nil {
+						status.IssuedBy = map[string]caserver.IssuedCertificate{}
+					}
+					status.IssuedBy[istiod] = c
+				}
+			}
+		}
+		if len(status.IssuedBy) == 0 {
+			status.Drift = append(status.Drift,
+				fmt.Sprintf("certificate %s is not in the inventory of any istiod instance", w.SerialNumber))
+		}
+		switch {
+		case !w.NotAfter.After(now):
+			status.Drift = append(status.Drift, fmt.Sprintf("certificate expired at %s", formatTime(w.NotAfter)))
+		case expiringWithin > 0 && w.NotAfter.Before(now.Add(expiringWithin)):
+			status.Drift = append(status.Drift, fmt.Sprintf("certificate expires at %s, within %v", formatTime(w.NotAfter), expiringWithin))
+		}
+		if w.RootFingerprint == "" {
+			status.Drift = append(status.Drift, "certificate does not chain to any root of the trust bundle")
+		}
+	}
+	trusted := sets.New(agent.RootFingerprints...)
+	for _, istiod := range istiods {
+		for _, root := range inventories[istiod].RootFingerprints {
+			if !trusted.Contains(root) {
+				status.Drift = append(status.Drift, fmt.Sprintf("trust bundle is missing root %s of %s", shortFingerprint(root), istiod))
+			}
+		}
+	}
+	return status
+}
+
+func printPod(w io.Writer, status *PodCertificates) {
+	_, _ = fmt.Fprintf(w, "Pod: %s\n", status.Pod)
+	if c := status.Agent.Workload; c != nil {
+		_, _ = fmt.Fprintf(w, "Serial: %s\n", c.SerialNumber)
+		_, _ = fmt.Fprintf(w, "SANs: %s\n", strings.Join(c.SANs, ", "))
+		_, _ = fmt.Fprintf(w, "Valid: %s to %s\n", formatTime(c.NotBefore), formatTime(c.NotAfter))
+		if c.RootFingerprint != "" {
+			_, _ = fmt.Fprintf(w, "Root: %s\n", shortFingerprint(c.RootFingerprint))
+		}
+	}
+	for _, istiod := range slices.Sort(maps.Keys(status.IssuedBy)) {
+		c := status.IssuedBy[istiod]
+		_, _ = fmt.Fprintf(w, "Issued by: %s at %s to %s\n", istiod, formatTime(c.IssuedAt), c.RequesterAddress)
+	}
+	_, _ = fmt.Fprintf(w, "Trust bundle: %d roots\n", len(status.Agent.RootFingerprints))
+	if len(status.Drift) == 0 {
+		_, _ = fmt.Fprintln(w, "Drift: none")
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Drift: detected")
+	for _, d := range status.Drift {
+		_, _ = fmt.Fprintf(w, "  - %s\n", d)
+	}
+}
+
+func printInventories(out io.Writer, inventories map[string]caserver.CertInventoryStatus) error {
+	type row struct {
+		istiod string
+		cert   caserver.IssuedCertificate
+	}
+	var rows []row
+	for istiod, inv := range inventories {
+		for _, c := range inv.Certificates {
+			rows = append(rows, row{istiod: istiod, cert: c})
+		}
+	}
+	slices.SortFunc(rows, func(a, b row) int {
+		if n := a.cert.NotAfter.Compare(b.cert.NotAfter); n != 0 {
+			return n
+		}
+		return strings.Compare(a.cert.SerialNumber, b.cert.SerialNumber)
+	})
+	w := new(tabwriter.Writer).Init(out, 0, 8, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SERIAL\tNAMESPACE\tSERVICE ACCOUNT\tNOT AFTER\tREQUESTER\tROOT\tISTIOD")
+	for _, r := range rows {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.cert.SerialNumber, r.cert.Namespace, r.cert.ServiceAccount,
+			formatTime(r.cert.NotAfter), r.cert.RequesterAddress, shortFingerprint(r.cert.RootFingerprint), r.istiod)
+	}
+	return w.Flush()
+}
+
+func writeJSON(w io.Writer, v any) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(w, string(out))
+	return nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// shortFingerprint abbreviates a fingerprint for display.
+func shortFingerprint(f string) string {
+	if f == "" {
+		return "-"
+	}
+	if len(f) > 16 {
+		return f[:16]
+	}
+	return f
+}
@@ -0,0 +1,168 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certificates
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/pkg/test/util/assert"
+	caserver "istio.io/istio/security/pkg/server/ca"
+)
+
+const (
+	rootA = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	rootB = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+var discoveryResults = map[string][]byte{
+	"istiod-0.istio-system": []byte(`{"rootFingerprints":["` + rootA + `"],"certificates":[
+  {"serialNumber":"1f","notBefore":"2099-01-01T00:00:00Z","notAfter":"2099-01-02T00:00:00Z","rootFingerprint":"` + rootA + `",
+   "namespace":"default","serviceAccount":"httpbin","requesterAddress":"10.0.0.1","issuedAt":"2099-01-01T00:00:00Z"}]}`),
+	"istiod-1.istio-system": []byte(`{"rootFingerprints":["` + rootA + `","` + rootB + `"],"certificates":[
+  {"serialNumber":"2a","notBefore":"2098-12-31T00:00:00Z","notAfter":"2099-01-01T00:00:00Z","rootFingerprint":"` + rootB + `",
+   "namespace":"default","serviceAccount":"sleep","requesterAddress":"10.0.0.2","issuedAt":"2098-12-31T00:00:00Z"}]}`),
+}
+
+func TestCertificates(t *testing.T) {
+	cases := []struct {
+		name           string
+		args           []string
+		results        map[string][]byte
+		expectedOutput string
+		wantErr        string
+	}{
+		{
+			name: "list",
+			expectedOutput: `SERIAL   NAMESPACE   SERVICE ACCOUNT   NOT AFTER              REQUESTER   ROOT               ISTIOD
+2a       default     sleep             2099-01-01T00:00:00Z   10.0.0.2    bbbbbbbbbbbbbbbb   istiod-1.istio-system
+1f       default     httpbin           2099-01-02T00:00:00Z   10.0.0.1    aaaaaaaaaaaaaaaa   istiod-0.istio-system
+`,
+		},
+		{
+			name: "drifted pod",
+			args: []string{"httpbin"},
+			results: map[string][]byte{"httpbin": []byte(`{"workload":{"serialNumber":"1f","sans":["spiffe://cluster.local/ns/default/sa/httpbin"],` +
+				`"notBefore":"2099-01-01T00:00:00Z","notAfter":"2099-01-02T00:00:00Z","rootFingerprint":"` + rootA + `"},` +
+				`"rootFingerprints":["` + rootA + `"]}`)},
+			expectedOutput: `Pod: httpbin.default
+Serial: 1f
+SANs: spiffe://cluster.local/ns/default/sa/httpbin
+Valid: 2099-01-01T00:00:00Z to 2099-01-02T00:00:00Z
+Root: aaaaaaaaaaaaaaaa
+Issued by: istiod-0.istio-system at 2099-01-01T00:00:00Z to 10.0.0.1
+Trust bundle: 1 roots
+Drift: detected
+  - trust bundle is missing root bbbbbbbbbbbbbbbb of istiod-1.istio-system
+`,
+		},
+		{
+			name:    "agent unreachable",
+			args:    []string{"httpbin"},
+			results: map[string][]byte{},
+			wantErr: "failed to retrieve certificates from httpbin.default",
+		},
+		{
+			name:    "unsupported output",
+			args:    []string{"-o", "yaml"},
+			wantErr: `output format "yaml" not supported`,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := cli.NewFakeContext(&cli.NewFakeContextOption{
+				Namespace:        "default",
+				IstioNamespace:   "istio-system",
+				Results:          tt.results,
+				DiscoveryResults: discoveryResults,
+				Objects: []runtime.Object{&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "httpbin", Namespace: "default"},
+				}},
+			})
+			cmd := Cmd(ctx)
+			var out bytes.Buffer
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(&out)
+			cmd.SetErr(&out)
+			cmd.SilenceUsage = true
+			err := cmd.Execute()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, out.String(), tt.expectedOutput)
+		})
+	}
+}
+
+func TestComparePod(t *testing.T) {
+	now := time.Date(2099, 1, 1, 12, 0, 0, 0, time.UTC)
+	inventories := map[string]caserver.CertInventoryStatus{
+		"istiod-0": {RootFingerprints: []string{rootA}},
+	}
+	cases := []struct {
+		name           string
+		agent          *security.AgentCertificates
+		expiringWithin time.Duration
+		drift          []string
+	}{
+		{
+			name:  "no workload certificate",
+			agent: &security.AgentCertificates{RootFingerprints: []string{rootA}},
+			drift: []string{"the agent has no workload certificate cached"},
+		},
+		{
+			name: "expired and untrusted",
+			agent: &security.AgentCertificates{
+				Workload: &security.CertificateInfo{SerialNumber: "1", NotAfter: now.Add(-time.Minute)},
+			},
+			drift: []string{
+				"certificate 1 is not in the inventory of any istiod instance",
+				"certificate expired at 2099-01-01T11:59:00Z",
+				"certificate does not chain to any root of the trust bundle",
+				"trust bundle is missing root aaaaaaaaaaaaaaaa of istiod-0",
+			},
+		},
+		{
+			name: "expiring soon",
+			agent: &security.AgentCertificates{
+				Workload:         &security.CertificateInfo{SerialNumber: "1", NotAfter: now.Add(time.Hour), RootFingerprint: rootA},
+				RootFingerprints: []string{rootA},
+			},
+			expiringWithin: 2 * time.Hour,
+			drift: []string{
+				"certificate 1 is not in the inventory of any istiod instance",
+				"certificate expires at 2099-01-01T13:00:00Z, within 2h0m0s",
+			},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			got := comparePod("pod.ns", tt.agent, inventories, tt.expiringWithin, now)
+			assert.Equal(t, got.Drift, tt.drift)
+		})
+	}
+}
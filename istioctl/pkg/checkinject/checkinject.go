@@ -34,6 +34,8 @@ import (
 	"istio.io/istio/istioctl/pkg/util"
 	"istio.io/istio/istioctl/pkg/writer/table"
 	analyzer_util "istio.io/istio/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/pkg/kube/inject"
+	"istio.io/istio/pkg/slices"
 )
 
 var labelPairs string
@@ -154,6 +156,14 @@ type webhookAnalysis struct {
 	Reason   string
 }
 
+// AnalyzeWebhooks returns whether the Istio injection webhook configurations match a pod with the given labels, in a
+// namespace with the given labels, and why.
+func AnalyzeWebhooks(whs []admitv1.MutatingWebhookConfiguration, podLabels, nsLabels map[string]string) []inject.WebhookMatch {
+	return slices.Map(analyzeRunningWebhooks(whs, podLabels, nsLabels), func(wa webhookAnalysis) inject.WebhookMatch {
+		return inject.WebhookMatch{Name: wa.Name, Revision: wa.Revision, Matched: wa.Injected, Reason: wa.Reason}
+	})
+}
+
 func analyzeRunningWebhooks(whs []admitv1.MutatingWebhookConfiguration, podLabels, nsLabels map[string]string) []webhookAnalysis {
 	results := make([]webhookAnalysis, 0)
 	for _, mwc := range whs {
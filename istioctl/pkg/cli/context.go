@@ -260,8 +260,10 @@ type fakeInstance struct {
 	clients   map[string]kube.CLIClient
 	rootFlags *RootFlags
 	results   map[string][]byte
-	objects   []runtime.Object
-	version   string
+	// discoveryResults are the results of AllDiscoveryDo, if different from results
+	discoveryResults map[string][]byte
+	objects          []runtime.Object
+	version          string
 }
 
 func (f *fakeInstance) CLIClientWithRevision(rev string) (kube.CLIClient, error) {
@@ -276,8 +278,9 @@ func (f *fakeInstance) CLIClientWithRevision(rev string) (kube.CLIClient, error)
 			kube.SetRevisionForTest(cliclient, rev)
 		}
 		c := MockClient{
-			CLIClient: cliclient,
-			Results:   f.results,
+			CLIClient:        cliclient,
+			Results:          f.results,
+			DiscoveryResults: f.discoveryResults,
 		}
 		f.clients[rev] = c
 	}
@@ -336,6 +339,8 @@ type NewFakeContextOption struct {
 	Namespace      string
 	IstioNamespace string
 	Results        map[string][]byte
+	// DiscoveryResults, if set, are returned by AllDiscoveryDo instead of Results
+	DiscoveryResults map[string][]byte
 	// Objects are the objects to be applied to the fake client
 	Objects []runtime.Object
 	// Version is the version of the fake client
@@ -360,8 +365,9 @@ func NewFakeContext(opts *NewFakeContextOption) Context {
 			impersonateGroup: nil,
 			defaultNamespace: "",
 		},
-		results: opts.Results,
-		objects: opts.Objects,
-		version: opts.Version,
+		results:          opts.Results,
+		discoveryResults: opts.DiscoveryResults,
+		objects:          opts.Objects,
+		version:          opts.Version,
 	}
 }
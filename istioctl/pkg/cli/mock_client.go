@@ -48,6 +48,8 @@ var _ kube.PortForwarder = MockPortForwarder{}
 type MockClient struct {
 	// Results is a map of podName to the results of the expected test on the pod
 	Results map[string][]byte
+	// DiscoveryResults, if set, are returned by AllDiscoveryDo instead of Results
+	DiscoveryResults map[string][]byte
 	kube.CLIClient
 }
 
@@ -56,6 +58,9 @@ func (c MockClient) NewPortForwarder(_, _, _ string, _, _ int) (kube.PortForward
 }
 
 func (c MockClient) AllDiscoveryDo(_ context.Context, _, _ string) (map[string][]byte, error) {
+	if c.DiscoveryResults != nil {
+		return c.DiscoveryResults, nil
+	}
 	return c.Results, nil
 }
 
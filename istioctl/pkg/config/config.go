@@ -23,6 +23,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"istio.io/istio/istioctl/pkg/cli"
 	"istio.io/istio/istioctl/pkg/root"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/env"
@@ -41,15 +42,27 @@ var settableFlags = map[string]env.VariableInfo{
 }
 
 // Cmd represents the config subcommand command
-func Cmd() *cobra.Command {
+func Cmd(ctx cli.Context) *cobra.Command {
 	configCmd := &cobra.Command{
 		Use:   "config SUBCOMMAND",
-		Short: "Configure istioctl defaults",
+		Short: "Configure istioctl defaults, and inspect the Istio configuration",
 		Args:  cobra.NoArgs,
 		Example: `  # list configuration parameters
-  istioctl experimental config list`,
+  istioctl experimental config list
+
+  # compare the configuration of a directory to the cluster
+  istioctl experimental config diff manifests/
+
+  # show the configuration graph of a proxy
+  istioctl experimental config graph --proxy productpage-v1-7d4c8b5c9d-x2x6q.default
+
+  # show the proxies a configuration change would push
+  istioctl experimental config impact -f reviews-destination-rule.yaml`,
 	}
 	configCmd.AddCommand(listCommand())
+	configCmd.AddCommand(diffCommand(ctx))
+	configCmd.AddCommand(graphCommand(ctx))
+	configCmd.AddCommand(impactCommand(ctx))
 	return configCmd
 }
 
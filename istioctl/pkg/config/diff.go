@@ -0,0 +1,516 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/pilot/pkg/config/file"
+	istioconfig "istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/analysis/legacy/source/kube"
+	"istio.io/istio/pkg/config/resource"
+	"istio.io/istio/pkg/config/schema/collection"
+	"istio.io/istio/pkg/config/schema/collections"
+	sresource "istio.io/istio/pkg/config/schema/resource"
+	kubelib "istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/kube/fielddiff"
+	"istio.io/istio/pkg/util/sets"
+)
+
+const (
+	textOutput = "text"
+	jsonOutput = "json"
+	yamlOutput = "yaml"
+)
+
+// Drift states of an object, relative to the cluster.
+const (
+	driftAdded    = "added"
+	driftRemoved  = "removed"
+	driftModified = "modified"
+)
+
+// ignoredAnnotations are annotations which are set by tools or by the file source, rather than by the configuration.
+var ignoredAnnotations = sets.New(
+	"kubectl.kubernetes.io/last-applied-configuration",
+	file.FieldMapKey,
+	file.ReferenceKey,
+)
+
+// objectKey identifies an object of the configuration.
+type objectKey struct {
+	gvk       istioconfig.GroupVersionKind
+	namespace string
+	name      string
+}
+
+// object is an object of the configuration, normalized for comparison.
+type object struct {
+	key objectKey
+	// source is the file and line the object is declared at, for desired objects.
+	source string
+	// content holds the labels and annotations, and the spec of the object.
+	content map[string]any
+}
+
+// ObjectDrift is the drift of an object of a directory from the cluster.
+type ObjectDrift struct {
+	Kind      string       `json:"kind"`
+	Namespace string       `json:"namespace,omitempty"`
+	Name      string       `json:"name"`
+	Drift     string       `json:"drift"`
+	Source    string       `json:"source,omitempty"`
+	Fields    []FieldDrift `json:"fields,omitempty"`
+}
+
+// FieldDrift is a field of an object whose value in the cluster differs from its value in the directory.
+type FieldDrift struct {
+	Path    string `json:"path"`
+	Live    any    `json:"live,omitempty"`
+	Desired any    `json:"desired,omitempty"`
+}
+
+func diffCommand(ctx cli.Context) *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "diff <file|directory>...",
+		Short: "Compare the Istio configuration of files to the cluster",
+		Long: `Compare the Istio and Gateway API resources of files or directories, such as the desired state kept in Git, to
+the resources of the cluster, and report the drift between them.
+
+Resources are matched by kind, namespace and name. Resources of the files missing from the cluster are reported as
+added, and resources of the cluster missing from the files are reported as removed, in the namespaces of the files.
+Resources present in both are reported as modified if their labels, annotations or spec differ, with the path of each
+differing field. Defaults of the CRDs of the cluster are applied to the resources of the files, and fields set to
+their zero value are ignored, so that only meaningful differences are reported.
+
+The command exits with a non-zero status if any drift is found, for use in CI.`,
+		Example: `  # Compare the configuration of a directory to the cluster
+  istioctl x config diff manifests/istio/
+
+  # Report the drift as JSON
+  istioctl x config diff manifests/istio/ -o json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != textOutput && output != jsonOutput && output != yamlOutput {
+				return fmt.Errorf("unknown output format %q, expected one of text|json|yaml", output)
+			}
+			client, err := ctx.CLIClient()
+			if err != nil {
+				return err
+			}
+			schemas := collections.PilotGatewayAPI()
+			desired, err := readDesired(schemas, args, ctx.NamespaceOrDefault(ctx.Namespace()))
+			if err != nil {
+				return err
+			}
+			defaults := crdDefaults(client, schemas)
+			desiredObjects, err := normalizeAll(schemas, desired, defaults)
+			if err != nil {
+				return err
+			}
+			live, err := listLive(client, schemas, desired)
+			if err != nil {
+				return err
+			}
+			liveObjects, err := normalizeAll(schemas, live, defaults)
+			if err != nil {
+				return err
+			}
+			drifts := diffObjects(desiredObjects, liveObjects)
+			if err := printDrifts(cmd.OutOrStdout(), drifts, output); err != nil {
+				return err
+			}
+			if len(drifts) > 0 {
+				return fmt.Errorf("%d resources drifted from the cluster", len(drifts))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", textOutput, "Output format: one of text|json|yaml")
+	return cmd
+}
+
+// readDesired reads the configuration of files and directories, with the namespace of resources defaulting to
+// defaultNamespace.
+func readDesired(schemas collection.Schemas, paths []string, defaultNamespace string) ([]istioconfig.Config, error) {
+	src := file.NewKubeSource(schemas)
+	src.SetDefaultNamespace(resource.Namespace(defaultNamespace))
+	for _, path := range paths {
+		err := filepath.Walk(path, func(name string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			if ext := filepath.Ext(name); name != path && ext != ".yaml" && ext != ".yml" && ext != ".json" {
+				return nil
+			}
+			b, err := os.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			return src.ApplyContent(name, string(b))
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	var out []istioconfig.Config
+	for _, s := range schemas.All() {
+		out = append(out, src.List(s.GroupVersionKind(), "")...)
+	}
+	return out, nil
+}
+
+// listLive lists the resources of the cluster which may correspond to the desired resources: the namespaced
+// resources of their namespaces, and the cluster scoped resources of their kinds.
+func listLive(client kubelib.CLIClient, schemas collection.Schemas, desired []istioconfig.Config) ([]istioconfig.Config, error) {
+	namespaces := sets.New[string]()
+	kinds := sets.New[istioconfig.GroupVersionKind]()
+	for _, c := range desired {
+		if c.Namespace != "" {
+			namespaces.Insert(c.Namespace)
+		}
+		kinds.Insert(c.GroupVersionKind)
+	}
+	var out []istioconfig.Config
+	for _, s := range schemas.All() {
+		listNamespaces := sets.SortedList(namespaces)
+		if s.IsClusterScoped() {
+			if !kinds.Contains(s.GroupVersionKind()) {
+				continue
+			}
+			listNamespaces = []string{metav1.NamespaceAll}
+		}
+		for _, ns := range listNamespaces {
+			list, err := client.Dynamic().Resource(s.GroupVersionResource()).Namespace(ns).List(context.TODO(), metav1.ListOptions{})
+			if kerrors.IsNotFound(err) {
+				// The CRD of the kind is not installed.
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to list %s: %v", s.Kind(), err)
+			}
+			for i := range list.Items {
+				c, err := fromUnstructured(s, &list.Items[i])
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, c)
+			}
+		}
+	}
+	return out, nil
+}
+
+func fromUnstructured(s sresource.Schema, u *unstructured.Unstructured) (istioconfig.Config, error) {
+	spec, err := s.NewInstance()
+	if err != nil {
+		return istioconfig.Config{}, err
+	}
+	if v, f := u.Object["spec"]; f {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return istioconfig.Config{}, err
+		}
+		if err := istioconfig.ApplyJSON(spec, string(b)); err != nil {
+			return istioconfig.Config{}, fmt.Errorf("failed to parse %s %s/%s: %v", s.Kind(), u.GetNamespace(), u.GetName(), err)
+		}
+	}
+	return istioconfig.Config{
+		Meta: istioconfig.Meta{
+			GroupVersionKind: s.GroupVersionKind(),
+			Name:             u.GetName(),
+			Namespace:        u.GetNamespace(),
+			Labels:           u.GetLabels(),
+			Annotations:      u.GetAnnotations(),
+		},
+		Spec: spec,
+	}, nil
+}
+
+// crdDefaults returns the OpenAPI schema of the spec of each kind, as defined by the CRDs of the cluster. Kinds
+// whose CRD cannot be read have no defaults.
+func crdDefaults(client kubelib.CLIClient, schemas collection.Schemas) map[istioconfig.GroupVersionKind]*apiextv1.JSONSchemaProps {
+	out := map[istioconfig.GroupVersionKind]*apiextv1.JSONSchemaProps{}
+	for _, s := range schemas.All() {
+		crd, err := client.Ext().ApiextensionsV1().CustomResourceDefinitions().Get(context.TODO(), s.Plural()+"."+s.Group(), metav1.GetOptions{})
+		if err != nil {
+			continue
+		}
+		for _, v := range crd.Spec.Versions {
+			if v.Name == s.Version() && v.Schema != nil && v.Schema.OpenAPIV3Schema != nil {
+				if spec, f := v.Schema.OpenAPIV3Schema.Properties["spec"]; f {
+					out[s.GroupVersionKind()] = &spec
+				}
+			}
+		}
+	}
+	return out
+}
+
+// applyDefaults sets the default values of a schema on the unset fields of a value, as the API server does.
+func applyDefaults(value any, s *apiextv1.JSONSchemaProps) {
+	if s == nil {
+		return
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for name, prop := range s.Properties {
+			if _, f := v[name]; !f && prop.Default != nil {
+				var d any
+				if err := json.Unmarshal(prop.Default.Raw, &d); err == nil {
+					v[name] = d
+				}
+			}
+			if field, f := v[name]; f {
+				applyDefaults(field, &prop)
+			}
+		}
+		if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+			for name, field := range v {
+				if _, f := s.Properties[name]; !f {
+					applyDefaults(field, s.AdditionalProperties.Schema)
+				}
+			}
+		}
+	case []any:
+		if s.Items != nil && s.Items.Schema != nil {
+			for _, item := range v {
+				applyDefaults(item, s.Items.Schema)
+			}
+		}
+	}
+}
+
+func normalizeAll(schemas collection.Schemas, configs []istioconfig.Config,
+	defaults map[istioconfig.GroupVersionKind]*apiextv1.JSONSchemaProps,
+) (map[objectKey]object, error) {
+	out := make(map[objectKey]object, len(configs))
+	for _, c := range configs {
+		s, f := schemas.FindByGroupVersionKind(c.GroupVersionKind)
+		if !f {
+			continue
+		}
+		o, err := normalize(s, c, defaults[c.GroupVersionKind])
+		if err != nil {
+			return nil, fmt.Errorf("failed to normalize %s %s/%s: %v", c.GroupVersionKind.Kind, c.Namespace, c.Name, err)
+		}
+		out[o.key] = o
+	}
+	return out, nil
+}
+
+// normalize converts an object to the content it is compared on. The spec is defaulted, and round tripped through
+// its type, so that fields set to their zero value and unset fields are equal.
+func normalize(s sresource.Schema, c istioconfig.Config, defaults *apiextv1.JSONSchemaProps) (object, error) {
+	o := object{
+		key:     objectKey{gvk: c.GroupVersionKind, namespace: c.Namespace, name: c.Name},
+		content: map[string]any{},
+	}
+	if ref := c.Annotations[file.ReferenceKey]; ref != "" {
+		pos := &kube.Position{}
+		if err := json.Unmarshal([]byte(ref), pos); err == nil {
+			o.source = pos.String()
+		}
+	}
+	metadata := map[string]any{}
+	if len(c.Labels) > 0 {
+		labels := map[string]any{}
+		for k, v := range c.Labels {
+			labels[k] = v
+		}
+		metadata["labels"] = labels
+	}
+	annotations := map[string]any{}
+	for k, v := range c.Annotations {
+		if !ignoredAnnotations.Contains(k) {
+			annotations[k] = v
+		}
+	}
+	if len(annotations) > 0 {
+		metadata["annotations"] = annotations
+	}
+	if len(metadata) > 0 {
+		o.content["metadata"] = metadata
+	}
+
+	spec, err := toMap(c.Spec)
+	if err != nil {
+		return o, err
+	}
+	if defaults != nil {
+		applyDefaults(spec, defaults)
+		b, err := json.Marshal(spec)
+		if err != nil {
+			return o, err
+		}
+		typed, err := s.NewInstance()
+		if err != nil {
+			return o, err
+		}
+		if err := istioconfig.ApplyJSON(typed, string(b)); err != nil {
+			return o, err
+		}
+		if spec, err = toMap(typed); err != nil {
+			return o, err
+		}
+	}
+	if len(spec) > 0 {
+		o.content["spec"] = spec
+	}
+	return o, nil
+}
+
+func toMap(spec istioconfig.Spec) (map[string]any, error) {
+	b, err := istioconfig.ToJSON(spec)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// diffObjects compares the desired objects to the live objects, sorted by kind, namespace and name.
+func diffObjects(desired, live map[objectKey]object) []ObjectDrift {
+	var out []ObjectDrift
+	for key, d := range desired {
+		l, f := live[key]
+		if !f {
+			out = append(out, newDrift(key, driftAdded, d.source, nil))
+			continue
+		}
+		if fields := diffFields(l.content, d.content); len(fields) > 0 {
+			out = append(out, newDrift(key, driftModified, d.source, fields))
+		}
+	}
+	for key := range live {
+		if _, f := desired[key]; !f {
+			out = append(out, newDrift(key, driftRemoved, "", nil))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		if out[i].Namespace != out[j].Namespace {
+			return out[i].Namespace < out[j].Namespace
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+func newDrift(key objectKey, drift, source string, fields []FieldDrift) ObjectDrift {
+	return ObjectDrift{
+		Kind:      key.gvk.Kind,
+		Namespace: key.namespace,
+		Name:      key.name,
+		Drift:     drift,
+		Source:    source,
+		Fields:    fields,
+	}
+}
+
+// diffFields returns the fields whose live and desired values differ.
+func diffFields(live, desired map[string]any) []FieldDrift {
+	var out []FieldDrift
+	for _, c := range fielddiff.Diff(live, desired) {
+		out = append(out, FieldDrift{Path: c.Path, Live: c.Live, Desired: c.Desired})
+	}
+	return out
+}
+
+func printDrifts(w io.Writer, drifts []ObjectDrift, output string) error {
+	switch output {
+	case jsonOutput:
+		b, err := json.MarshalIndent(drifts, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case yamlOutput:
+		b, err := yaml.Marshal(drifts)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}
+	if len(drifts) == 0 {
+		_, err := fmt.Fprintln(w, "No drift found.")
+		return err
+	}
+	counts := map[string]int{}
+	for _, d := range drifts {
+		counts[d.Drift]++
+		name := d.Name
+		if d.Namespace != "" {
+			name = d.Namespace + "/" + d.Name
+		}
+		source := ""
+		if d.Source != "" {
+			source = fmt.Sprintf(" (%s)", d.Source)
+		}
+		fmt.Fprintf(w, "%s %s %s%s\n", driftSymbol(d.Drift), d.Kind, name, source)
+		for _, f := range d.Fields {
+			fmt.Fprintf(w, "    %s: %s -> %s\n", f.Path, valueString(f.Live), valueString(f.Desired))
+		}
+	}
+	_, err := fmt.Fprintf(w, "\n%d added, %d removed, %d modified\n", counts[driftAdded], counts[driftRemoved], counts[driftModified])
+	return err
+}
+
+func driftSymbol(drift string) string {
+	switch drift {
+	case driftAdded:
+		return "+"
+	case driftRemoved:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+func valueString(v any) string {
+	if v == nil {
+		return "<unset>"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
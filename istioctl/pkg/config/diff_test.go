@@ -0,0 +1,166 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	networking "istio.io/api/networking/v1alpha3"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	istioconfig "istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/collections"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+const desiredYAML = `apiVersion: networking.istio.io/v1
+kind: DestinationRule
+metadata:
+  name: reviews
+spec:
+  host: reviews
+  trafficPolicy:
+    tls:
+      mode: ISTIO_MUTUAL
+---
+apiVersion: networking.istio.io/v1
+kind: ServiceEntry
+metadata:
+  name: external
+  namespace: other
+  labels:
+    app: external
+spec:
+  hosts:
+  - example.com
+  ports:
+  - number: 443
+    name: https
+    protocol: TLS
+`
+
+func TestReadDesired(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(desiredYAML), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not configuration"), 0o644))
+
+	configs, err := readDesired(collections.PilotGatewayAPI(), []string{dir}, "default")
+	assert.NoError(t, err)
+	objects, err := normalizeAll(collections.PilotGatewayAPI(), configs, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, len(objects), 2)
+
+	dr, f := objects[objectKey{gvk: gvk.DestinationRule, namespace: "default", name: "reviews"}]
+	assert.Equal(t, f, true)
+	assert.Equal(t, dr.source, filepath.Join(dir, "config.yaml")+":1")
+	assert.Equal(t, dr.content, map[string]any{
+		"spec": map[string]any{
+			"host":          "reviews",
+			"trafficPolicy": map[string]any{"tls": map[string]any{"mode": "ISTIO_MUTUAL"}},
+		},
+	})
+	se, f := objects[objectKey{gvk: gvk.ServiceEntry, namespace: "other", name: "external"}]
+	assert.Equal(t, f, true)
+	assert.Equal(t, se.content["metadata"], any(map[string]any{"labels": map[string]any{"app": "external"}}))
+}
+
+func TestNormalizeDefaults(t *testing.T) {
+	s := collections.DestinationRule
+	defaults := &apiextv1.JSONSchemaProps{
+		Properties: map[string]apiextv1.JSONSchemaProps{
+			"host": {Type: "string"},
+			"trafficPolicy": {
+				Type: "object",
+				Properties: map[string]apiextv1.JSONSchemaProps{
+					"tls": {
+						Type: "object",
+						Properties: map[string]apiextv1.JSONSchemaProps{
+							"mode": {Type: "string", Default: &apiextv1.JSON{Raw: []byte(`"ISTIO_MUTUAL"`)}},
+						},
+					},
+				},
+			},
+		},
+	}
+	withDefault := istioconfig.Config{
+		Meta: istioconfig.Meta{GroupVersionKind: gvk.DestinationRule, Name: "reviews", Namespace: "default"},
+		Spec: &networking.DestinationRule{
+			Host:          "reviews",
+			TrafficPolicy: &networking.TrafficPolicy{Tls: &networking.ClientTLSSettings{}},
+		},
+	}
+	explicit := istioconfig.Config{
+		Meta: istioconfig.Meta{GroupVersionKind: gvk.DestinationRule, Name: "reviews", Namespace: "default"},
+		Spec: &networking.DestinationRule{
+			Host:          "reviews",
+			TrafficPolicy: &networking.TrafficPolicy{Tls: &networking.ClientTLSSettings{Mode: networking.ClientTLSSettings_ISTIO_MUTUAL}},
+		},
+	}
+	a, err := normalize(s, withDefault, defaults)
+	assert.NoError(t, err)
+	b, err := normalize(s, explicit, defaults)
+	assert.NoError(t, err)
+	assert.Equal(t, a.content, b.content)
+}
+
+func TestDiffObjects(t *testing.T) {
+	key := func(name string) objectKey {
+		return objectKey{gvk: gvk.VirtualService, namespace: "default", name: name}
+	}
+	desired := map[objectKey]object{
+		key("same"): {key: key("same"), content: map[string]any{"spec": map[string]any{"hosts": []any{"a"}}}},
+		key("new"):  {key: key("new"), source: "vs.yaml:1", content: map[string]any{}},
+		key("changed"): {key: key("changed"), source: "vs.yaml:10", content: map[string]any{
+			"metadata": map[string]any{"labels": map[string]any{"app.kubernetes.io/name": "reviews"}},
+			"spec":     map[string]any{"hosts": []any{"a", "b"}, "gateways": []any{"mesh"}},
+		}},
+	}
+	live := map[objectKey]object{
+		key("same"): {key: key("same"), content: map[string]any{"spec": map[string]any{"hosts": []any{"a"}}}},
+		key("old"):  {key: key("old"), content: map[string]any{}},
+		key("changed"): {key: key("changed"), content: map[string]any{
+			"spec": map[string]any{"hosts": []any{"a", "c"}},
+		}},
+	}
+	drifts := diffObjects(desired, live)
+	assert.Equal(t, drifts, []ObjectDrift{
+		{
+			Kind: "VirtualService", Namespace: "default", Name: "changed", Drift: driftModified, Source: "vs.yaml:10",
+			Fields: []FieldDrift{
+				{Path: `metadata.labels["app.kubernetes.io/name"]`, Live: nil, Desired: "reviews"},
+				{Path: "spec.gateways", Live: nil, Desired: []any{"mesh"}},
+				{Path: "spec.hosts[1]", Live: "c", Desired: "b"},
+			},
+		},
+		{Kind: "VirtualService", Namespace: "default", Name: "new", Drift: driftAdded, Source: "vs.yaml:1"},
+		{Kind: "VirtualService", Namespace: "default", Name: "old", Drift: driftRemoved},
+	})
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, printDrifts(out, drifts, textOutput))
+	assert.Equal(t, out.String(), `~ VirtualService default/changed (vs.yaml:10)
+    metadata.labels["app.kubernetes.io/name"]: <unset> -> "reviews"
+    spec.gateways: <unset> -> ["mesh"]
+    spec.hosts[1]: "c" -> "b"
++ VirtualService default/new (vs.yaml:1)
+- VirtualService default/old
+
+1 added, 1 removed, 1 modified
+`)
+}
@@ -0,0 +1,129 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"github.com/spf13/cobra"
+
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/istioctl/pkg/clioptions"
+	"istio.io/istio/istioctl/pkg/completion"
+	"istio.io/istio/istioctl/pkg/multixds"
+	"istio.io/istio/pilot/pkg/model"
+	v3 "istio.io/istio/pilot/pkg/xds/v3"
+)
+
+const dotOutput = "dot"
+
+func graphCommand(ctx cli.Context) *cobra.Command {
+	var (
+		opts         clioptions.ControlPlaneOptions
+		centralOpts  clioptions.CentralControlPlaneOptions
+		proxy        string
+		outputFormat string
+	)
+	cmd := &cobra.Command{
+		Use:   "graph --proxy <pod-name>[.<namespace>]",
+		Short: "Show the graph of the Istio configuration which applies to a proxy",
+		Long: `Show the graph of the configuration resources which apply to a proxy, and of their relationships, as computed
+by the istiod instance the proxy is connected to: the Sidecar scoping the proxy, the services and routes it imports,
+the services the routes send traffic to, the DestinationRules configuring the services, the gateways and the routes
+attached to them, and the policies selecting the proxy. Resources generated from Gateway API resources are shown as
+the resources they are generated from.`,
+		Example: `  # Show the configuration graph of a pod as JSON
+  istioctl x config graph --proxy productpage-v1-7d4c8b5c9d-x2x6q.default
+
+  # Render the configuration graph of a pod with Graphviz
+  istioctl x config graph --proxy productpage-v1-7d4c8b5c9d-x2x6q.default -o dot | dot -Tsvg > graph.svg`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if proxy == "" {
+				return fmt.Errorf("--proxy is required")
+			}
+			if outputFormat != jsonOutput && outputFormat != dotOutput {
+				return fmt.Errorf("unknown output format %q, expected one of json|dot", outputFormat)
+			}
+			return cobra.NoArgs(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeClient, err := ctx.CLIClientWithRevision(ctx.RevisionOrDefault(opts.Revision))
+			if err != nil {
+				return err
+			}
+			podName, podNamespace, err := ctx.InferPodInfoFromTypedResource(proxy, ctx.NamespaceOrDefault(ctx.Namespace()))
+			if err != nil {
+				return err
+			}
+			query := url.Values{"proxyID": []string{podName + "." + podNamespace}}
+			xdsRequest := discovery.DiscoveryRequest{
+				ResourceNames: []string{"configgraph?" + query.Encode()},
+				Node: &core.Node{
+					Id: "debug~0.0.0.0~istioctl~cluster.local",
+				},
+				TypeUrl: v3.DebugType,
+			}
+			xdsResponses, err := multixds.FirstRequestAndProcessXds(&xdsRequest, centralOpts, ctx.IstioNamespace(), "", "", kubeClient,
+				multixds.Options{MessageWriter: cmd.OutOrStdout()})
+			if err != nil {
+				return err
+			}
+			graph, err := parseConfigGraph(xdsResponses)
+			if err != nil {
+				return err
+			}
+			return printConfigGraph(cmd.OutOrStdout(), graph, outputFormat)
+		},
+	}
+	opts.AttachControlPlaneFlags(cmd)
+	centralOpts.AttachControlPlaneFlags(cmd)
+	cmd.Flags().StringVar(&proxy, "proxy", "", "The pod of the proxy, as <pod-name>[.<namespace>]")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", jsonOutput, "Output format: one of json|dot")
+	_ = cmd.RegisterFlagCompletionFunc("proxy", completion.ValidPodsNameArgs(ctx))
+	return cmd
+}
+
+// parseConfigGraph returns the configuration graph of the first response of istiod.
+func parseConfigGraph(responses map[string]*discovery.DiscoveryResponse) (*model.ConfigGraph, error) {
+	for istiod, response := range responses {
+		for _, resource := range response.Resources {
+			graph := &model.ConfigGraph{}
+			if err := json.Unmarshal(resource.Value, graph); err != nil {
+				return nil, fmt.Errorf("failed to retrieve the configuration graph from %s: %s", istiod, strings.TrimSpace(string(resource.Value)))
+			}
+			return graph, nil
+		}
+	}
+	return nil, fmt.Errorf("no istiod instance returned a configuration graph")
+}
+
+func printConfigGraph(w io.Writer, graph *model.ConfigGraph, outputFormat string) error {
+	if outputFormat == dotOutput {
+		_, err := fmt.Fprint(w, graph.DOT())
+		return err
+	}
+	b, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
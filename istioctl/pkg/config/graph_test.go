@@ -0,0 +1,78 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/pilot/pkg/model"
+	v3 "istio.io/istio/pilot/pkg/xds/v3"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+const graphJSON = `{"proxy":"productpage.default",
+"nodes":[{"id":"Proxy/productpage.default","kind":"Proxy","name":"productpage.default"},
+  {"id":"Sidecar/default/default","kind":"Sidecar","name":"default","namespace":"default"}],
+"edges":[{"from":"Sidecar/default/default","to":"Proxy/productpage.default","relation":"scopes"}]}`
+
+func TestParseConfigGraph(t *testing.T) {
+	_, err := parseConfigGraph(map[string]*discovery.DiscoveryResponse{
+		"istiod-0": {Resources: []*anypb.Any{{TypeUrl: v3.DebugType, Value: []byte("You must provide a proxyID in the query string\n")}}},
+	})
+	assert.Equal(t, err.Error(), "failed to retrieve the configuration graph from istiod-0: You must provide a proxyID in the query string")
+
+	_, err = parseConfigGraph(map[string]*discovery.DiscoveryResponse{"istiod-0": {}})
+	assert.Error(t, err)
+}
+
+func TestPrintConfigGraph(t *testing.T) {
+	graph, err := parseConfigGraph(map[string]*discovery.DiscoveryResponse{
+		"istiod-0": {Resources: []*anypb.Any{{TypeUrl: v3.DebugType, Value: []byte(graphJSON)}}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, graph.Edges, []model.ConfigGraphEdge{
+		{From: "Sidecar/default/default", To: "Proxy/productpage.default", Relation: model.ConfigGraphScopes},
+	})
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, printConfigGraph(out, graph, dotOutput))
+	assert.Equal(t, out.String(), `digraph "productpage.default" {
+  rankdir=LR;
+  node [shape=box];
+  "Proxy/productpage.default" [label="Proxy\nproductpage.default"];
+  "Sidecar/default/default" [label="Sidecar\ndefault/default"];
+  "Sidecar/default/default" -> "Proxy/productpage.default" [label="scopes"];
+}
+`)
+}
+
+func TestGraphCommandArgs(t *testing.T) {
+	cmd := graphCommand(cli.NewFakeContext(nil))
+	cmd.SetArgs([]string{})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	assert.Error(t, cmd.Execute())
+
+	cmd = graphCommand(cli.NewFakeContext(nil))
+	cmd.SetArgs([]string{"--proxy", "productpage.default", "-o", "yaml"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	assert.Error(t, cmd.Execute())
+}
@@ -0,0 +1,197 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/istioctl/pkg/clioptions"
+	pilotxds "istio.io/istio/pilot/pkg/xds"
+	"istio.io/istio/pkg/kube"
+)
+
+func impactCommand(ctx cli.Context) *cobra.Command {
+	var (
+		opts         clioptions.ControlPlaneOptions
+		filename     string
+		outputFormat string
+	)
+	cmd := &cobra.Command{
+		Use:   "impact -f <file>",
+		Short: "Show the proxies a configuration change would push",
+		Long: `Show the proxies which an update of the configuration resources in a file would push, and the xDS types which
+would be pushed to them, without applying the resources. Each istiod instance checks the proxies connected to it,
+using the same checks it makes before a push. Resources without a namespace are assumed to be in the namespace of
+the command.`,
+		Example: `  # Show the proxies an update of a DestinationRule would push
+  istioctl x config impact -f reviews-destination-rule.yaml
+
+  # Show the proxies an update of an AuthorizationPolicy would push, as JSON
+  kubectl get authorizationpolicy deny-all -n foo -o yaml | istioctl x config impact -f - -o json`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if filename == "" {
+				return fmt.Errorf("--filename is required")
+			}
+			if outputFormat != textOutput && outputFormat != jsonOutput {
+				return fmt.Errorf("unknown output format %q, expected one of text|json", outputFormat)
+			}
+			return cobra.NoArgs(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				body []byte
+				err  error
+			)
+			if filename == "-" {
+				body, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				body, err = os.ReadFile(filename)
+			}
+			if err != nil {
+				return err
+			}
+			kubeClient, err := ctx.CLIClientWithRevision(ctx.RevisionOrDefault(opts.Revision))
+			if err != nil {
+				return err
+			}
+			impacts, err := queryPushImpact(context.Background(), kubeClient, ctx.IstioNamespace(), ctx.NamespaceOrDefault(ctx.Namespace()), body)
+			if err != nil {
+				return err
+			}
+			return printPushImpact(cmd.OutOrStdout(), impacts, outputFormat)
+		},
+	}
+	opts.AttachControlPlaneFlags(cmd)
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "The file with the configuration resources, or - to read them from stdin")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", textOutput, "Output format: one of text|json")
+	return cmd
+}
+
+// queryPushImpact sends the configuration resources to every istiod instance, and returns the impact reported by each
+// of them.
+func queryPushImpact(ctx context.Context, kubeClient kube.CLIClient, istioNamespace, namespace string,
+	body []byte,
+) (map[string]pilotxds.PushImpact, error) {
+	istiods, err := kubeClient.GetIstioPods(ctx, istioNamespace, metav1.ListOptions{
+		LabelSelector: "app=istiod",
+		FieldSelector: kube.RunningStatus,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(istiods) == 0 {
+		return nil, fmt.Errorf("unable to find any Istiod instances")
+	}
+	path := "debug/push_impact?" + url.Values{"namespace": []string{namespace}}.Encode()
+	out := map[string]pilotxds.PushImpact{}
+	for i := range istiods {
+		istiod := &istiods[i]
+		res, err := postToIstiod(ctx, kubeClient, istiod.Name, istiod.Namespace, kube.FindIstiodMonitoringPort(istiod), path, body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute the impact on %s: %v", istiod.Name, err)
+		}
+		impact := pilotxds.PushImpact{}
+		if err := json.Unmarshal(res, &impact); err != nil {
+			return nil, fmt.Errorf("failed to parse the impact reported by %s: %v", istiod.Name, err)
+		}
+		out[istiod.Name] = impact
+	}
+	return out, nil
+}
+
+func postToIstiod(ctx context.Context, kubeClient kube.CLIClient, podName, podNamespace string, port int, path string, body []byte) ([]byte, error) {
+	fw, err := kubeClient.NewPortForwarder(podName, podNamespace, "", 0, port)
+	if err != nil {
+		return nil, err
+	}
+	if err := fw.Start(); err != nil {
+		return nil, fmt.Errorf("failure running port forward process: %v", err)
+	}
+	defer fw.Close()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("http://%s/%s", fw.Address(), path), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
+
+func printPushImpact(w io.Writer, impacts map[string]pilotxds.PushImpact, outputFormat string) error {
+	if outputFormat == jsonOutput {
+		b, err := json.MarshalIndent(impacts, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	}
+	istiods := make([]string, 0, len(impacts))
+	for istiod := range impacts {
+		istiods = append(istiods, istiod)
+	}
+	sort.Strings(istiods)
+	pushed, connected := 0, 0
+	var configs, unrecognized []string
+	for _, istiod := range istiods {
+		pushed += len(impacts[istiod].Proxies)
+		connected += impacts[istiod].ConnectedProxies
+		if configs == nil {
+			configs = impacts[istiod].ConfigsUpdated
+			unrecognized = impacts[istiod].UnrecognizedKinds
+		}
+	}
+	_, _ = fmt.Fprintf(w, "Configs updated: %s\n", strings.Join(configs, ", "))
+	if len(unrecognized) > 0 {
+		_, _ = fmt.Fprintf(w, "Unrecognized kinds, ignored: %s\n", strings.Join(unrecognized, ", "))
+	}
+	_, _ = fmt.Fprintf(w, "Proxies pushed: %d of %d connected\n", pushed, connected)
+	if pushed == 0 {
+		return nil
+	}
+	_, _ = fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 8, 3, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "PROXY\tTYPES\tISTIOD")
+	for _, istiod := range istiods {
+		for _, p := range impacts[istiod].Proxies {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", p.Proxy, strings.Join(p.Types, ","), istiod)
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,73 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"istio.io/istio/istioctl/pkg/cli"
+	pilotxds "istio.io/istio/pilot/pkg/xds"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestPrintPushImpact(t *testing.T) {
+	impacts := map[string]pilotxds.PushImpact{
+		"istiod-1": {
+			ConfigsUpdated:   []string{"DestinationRule/default/reviews"},
+			ConnectedProxies: 2,
+			Proxies:          []pilotxds.ProxyPushImpact{{Proxy: "reviews-v1.default", Types: []string{"CDS", "LDS"}}},
+		},
+		"istiod-0": {
+			ConfigsUpdated:   []string{"DestinationRule/default/reviews"},
+			ConnectedProxies: 3,
+			Proxies:          []pilotxds.ProxyPushImpact{{Proxy: "productpage.default", Types: []string{"CDS"}}},
+		},
+	}
+	out := &bytes.Buffer{}
+	assert.NoError(t, printPushImpact(out, impacts, textOutput))
+	assert.Equal(t, out.String(), `Configs updated: DestinationRule/default/reviews
+Proxies pushed: 2 of 5 connected
+
+PROXY                 TYPES     ISTIOD
+productpage.default   CDS       istiod-0
+reviews-v1.default    CDS,LDS   istiod-1
+`)
+
+	out.Reset()
+	assert.NoError(t, printPushImpact(out, map[string]pilotxds.PushImpact{
+		"istiod-0": {
+			ConfigsUpdated:    []string{"Sidecar/foo/default"},
+			ConnectedProxies:  3,
+			Proxies:           []pilotxds.ProxyPushImpact{},
+			UnrecognizedKinds: []string{"v1/ConfigMap"},
+		},
+	}, textOutput))
+	assert.Equal(t, out.String(), "Configs updated: Sidecar/foo/default\nUnrecognized kinds, ignored: v1/ConfigMap\nProxies pushed: 0 of 3 connected\n")
+}
+
+func TestImpactCommandArgs(t *testing.T) {
+	cmd := impactCommand(cli.NewFakeContext(nil))
+	cmd.SetArgs([]string{})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	assert.Error(t, cmd.Execute())
+
+	cmd = impactCommand(cli.NewFakeContext(nil))
+	cmd.SetArgs([]string{"-f", "dr.yaml", "-o", "dot"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	assert.Error(t, cmd.Execute())
+}
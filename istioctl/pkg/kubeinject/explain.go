@@ -0,0 +1,266 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubeinject
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/istio/istioctl/pkg/checkinject"
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/pkg/kube/inject"
+)
+
+const (
+	textOutput = "text"
+	jsonOutput = "json"
+	yamlOutput = "yaml"
+)
+
+// Cmd returns the experimental inject command.
+func Cmd(ctx cli.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "inject",
+		Short: "Commands to troubleshoot the sidecar injection",
+	}
+	cmd.AddCommand(explainCmd(ctx))
+	cmd.AddCommand(templateTestCmd())
+	return cmd
+}
+
+func explainCmd(ctx cli.Context) *cobra.Command {
+	var filename, revision, output string
+	cmd := &cobra.Command{
+		Use:   "explain",
+		Short: "Explain how the sidecar injector injects a pod",
+		Long: `Explain how the sidecar injector injects a pod, without creating it.
+
+The pod is sent to the explain endpoint of the injection webhook, which reports the decisions taken from the
+annotations and labels of the pod, the mesh config and the injector configuration, the templates it selects, and
+the resulting patch, with the step of the injection each operation originates from: the templates, the overrides
+of the pod containers, the rewrites applied after templating, or the injection metadata.
+
+The resource may be a pod, or any resource with a pod template, such as a deployment or a cron job.`,
+		Example: `  # Explain the injection of a deployment
+  istioctl x inject explain -f deployment.yaml
+
+  # Explain the injection of a pod by the canary revision, in namespace test
+  kubectl get pod productpage -n test -o yaml | istioctl x inject explain -f - --revision canary -n test`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if filename == "" {
+				return errors.New("filename not specified (see --filename or -f)")
+			}
+			var in []byte
+			var err error
+			if filename == "-" {
+				in, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				in, err = os.ReadFile(filename)
+			}
+			if err != nil {
+				return err
+			}
+			pod, err := podFromResource(in)
+			if err != nil {
+				return err
+			}
+			if pod.Namespace == "" {
+				pod.Namespace = ctx.NamespaceOrDefault(ctx.Namespace())
+			}
+			explanation, err := explain(ctx, pod, revision)
+			if err != nil {
+				return err
+			}
+			return printExplanation(cmd.OutOrStdout(), explanation, output)
+		},
+	}
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "Input Kubernetes resource filename, or - to read from stdin")
+	cmd.Flags().StringVarP(&revision, "revision", "r", "",
+		"Control plane revision explaining the injection. Defaults to the revision of the webhook matching the pod")
+	cmd.Flags().StringVarP(&output, "output", "o", textOutput, "Output format: one of text|json|yaml")
+	return cmd
+}
+
+// explain asks the injector of the revision to explain the injection of a pod, along with the webhook configurations
+// of the cluster matching it.
+func explain(ctx cli.Context, pod *corev1.Pod, revision string) (*inject.Explanation, error) {
+	client, err := ctx.CLIClient()
+	if err != nil {
+		return nil, err
+	}
+	ns, err := client.Kube().CoreV1().Namespaces().Get(context.TODO(), pod.Namespace, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	whs, err := client.Kube().AdmissionregistrationV1().MutatingWebhookConfigurations().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	matches := checkinject.AnalyzeWebhooks(whs.Items, pod.Labels, ns.Labels)
+	matched := false
+	for _, m := range matches {
+		if m.Matched {
+			if revision == "" {
+				revision = m.Revision
+			}
+			matched = true
+		}
+	}
+	injector, err := setUpExternalInjector(ctx, revision, "")
+	if err != nil {
+		return nil, err
+	}
+	explanation, err := injector.Explain(pod, pod.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	explanation.Webhooks = matches
+	if !matched && explanation.Injected {
+		explanation.Injected = false
+		explanation.Decisions = append(explanation.Decisions, inject.Decision{
+			Name:   "webhook",
+			Value:  "false",
+			Reason: "no injection MutatingWebhookConfiguration matches the pod, so the injector is never called",
+		})
+	}
+	return explanation, nil
+}
+
+// podFromResource returns the pod of a resource: the resource itself if it is a pod, or its pod template.
+func podFromResource(in []byte) (*corev1.Pod, error) {
+	obj := map[string]any{}
+	if err := yaml.Unmarshal(in, &obj); err != nil {
+		return nil, fmt.Errorf("could not decode resource: %v", err)
+	}
+	pod := &corev1.Pod{}
+	if obj["kind"] == "Pod" {
+		if err := yaml.Unmarshal(in, pod); err != nil {
+			return nil, fmt.Errorf("could not decode pod: %v", err)
+		}
+		return pod, nil
+	}
+	template := nestedMap(obj, "spec", "template")
+	if template == nil {
+		// A CronJob templates a job, which templates the pod.
+		template = nestedMap(obj, "spec", "jobTemplate", "spec", "template")
+	}
+	if template == nil {
+		return nil, fmt.Errorf("%v is neither a pod nor a resource with a pod template", obj["kind"])
+	}
+	b, err := json.Marshal(template)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, pod); err != nil {
+		return nil, fmt.Errorf("could not decode pod template: %v", err)
+	}
+	meta := nestedMap(obj, "metadata")
+	if pod.Namespace == "" {
+		pod.Namespace, _ = meta["namespace"].(string)
+	}
+	if pod.Name == "" && pod.GenerateName == "" {
+		if name, _ := meta["name"].(string); name != "" {
+			pod.GenerateName = name + "-"
+		}
+	}
+	return pod, nil
+}
+
+func nestedMap(obj map[string]any, fields ...string) map[string]any {
+	for _, f := range fields {
+		next, ok := obj[f].(map[string]any)
+		if !ok {
+			return nil
+		}
+		obj = next
+	}
+	return obj
+}
+
+func printExplanation(writer io.Writer, e *inject.Explanation, output string) error {
+	switch output {
+	case jsonOutput:
+		b, err := json.MarshalIndent(e, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(writer, string(b))
+		return err
+	case yamlOutput:
+		b, err := yaml.Marshal(e)
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(b)
+		return err
+	case textOutput:
+	default:
+		return fmt.Errorf("unknown output format %q, expected one of text|json|yaml", output)
+	}
+
+	verdict := "will not be injected"
+	if e.Injected {
+		verdict = "will be injected"
+	}
+	fmt.Fprintf(writer, "Pod %s %s by revision %s.\n", e.Pod, verdict, e.Revision)
+	if e.Error != "" {
+		fmt.Fprintf(writer, "Injection failed: %s\n", e.Error)
+	}
+	w := new(tabwriter.Writer).Init(writer, 0, 8, 3, ' ', 0)
+	if len(e.Webhooks) > 0 {
+		fmt.Fprintln(w, "\nWEBHOOK\tREVISION\tMATCHED\tREASON")
+		for _, m := range e.Webhooks {
+			fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", m.Name, m.Revision, m.Matched, m.Reason)
+		}
+	}
+	if len(e.Decisions) > 0 {
+		fmt.Fprintln(w, "\nDECISION\tVALUE\tREASON")
+		for _, d := range e.Decisions {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", d.Name, d.Value, d.Reason)
+		}
+	}
+	if len(e.Templates) > 0 {
+		fmt.Fprintln(w, "\nTEMPLATE\tALIAS\tSOURCE")
+		for _, t := range e.Templates {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", t.Name, valueOrNone(t.Alias), t.Source)
+		}
+	}
+	if len(e.Patch) > 0 {
+		fmt.Fprintln(w, "\nORIGIN\tOP\tPATH")
+		for _, op := range e.Patch {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", strings.Join(op.Origins, ","), op.Op, op.Path)
+		}
+	}
+	return w.Flush()
+}
+
+func valueOrNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
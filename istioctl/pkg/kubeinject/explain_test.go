@@ -0,0 +1,158 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubeinject
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"istio.io/istio/pkg/kube/inject"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestPodFromResource(t *testing.T) {
+	cases := []struct {
+		name         string
+		in           string
+		namespace    string
+		podName      string
+		generateName string
+		labels       map[string]string
+		err          string
+	}{
+		{
+			name: "pod",
+			in: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: productpage
+  namespace: test
+  labels:
+    app: productpage
+spec:
+  containers:
+  - name: app
+`,
+			namespace: "test",
+			podName:   "productpage",
+			labels:    map[string]string{"app": "productpage"},
+		},
+		{
+			name: "deployment",
+			in: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: productpage
+  namespace: test
+spec:
+  template:
+    metadata:
+      labels:
+        app: productpage
+    spec:
+      containers:
+      - name: app
+`,
+			namespace:    "test",
+			generateName: "productpage-",
+			labels:       map[string]string{"app": "productpage"},
+		},
+		{
+			name: "cron job",
+			in: `
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: report
+spec:
+  jobTemplate:
+    spec:
+      template:
+        metadata:
+          labels:
+            app: report
+        spec:
+          containers:
+          - name: app
+`,
+			generateName: "report-",
+			labels:       map[string]string{"app": "report"},
+		},
+		{
+			name: "no pod template",
+			in: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: productpage
+`,
+			err: "Service is neither a pod nor a resource with a pod template",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			pod, err := podFromResource([]byte(tt.in))
+			if tt.err != "" {
+				assert.Error(t, err)
+				assert.Equal(t, err.Error(), tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, pod.Namespace, tt.namespace)
+			assert.Equal(t, pod.Name, tt.podName)
+			assert.Equal(t, pod.GenerateName, tt.generateName)
+			assert.Equal(t, pod.Labels, tt.labels)
+			assert.Equal(t, len(pod.Spec.Containers), 1)
+		})
+	}
+}
+
+func TestPrintExplanation(t *testing.T) {
+	e := &inject.Explanation{
+		Pod:      "test/productpage-",
+		Revision: "canary",
+		Injected: true,
+		Webhooks: []inject.WebhookMatch{{Name: "istio-sidecar-injector-canary", Revision: "canary", Matched: true, Reason: "Pod label matches"}},
+		Decisions: []inject.Decision{
+			{Name: "injection", Value: "true", Reason: "the injection policy of the injector configuration is enabled"},
+		},
+		Templates: []inject.TemplateSelection{{Name: "sidecar", Source: "default templates of the injector configuration"}},
+		Patch: []inject.PatchOperation{
+			{Op: "add", Path: "/spec/containers/1", Origins: []string{inject.PatchOriginTemplate, inject.PatchOriginRewrite}},
+		},
+	}
+	out := &bytes.Buffer{}
+	assert.NoError(t, printExplanation(out, e, textOutput))
+	for _, want := range []string{
+		"Pod test/productpage- will be injected by revision canary.",
+		"istio-sidecar-injector-canary   canary     true      Pod label matches",
+		"injection   true    the injection policy of the injector configuration is enabled",
+		"sidecar    -       default templates of the injector configuration",
+		"template,rewrite   add   /spec/containers/1",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	assert.NoError(t, printExplanation(out, e, jsonOutput))
+	assert.Equal(t, strings.Contains(out.String(), `"origins": [`), true)
+
+	assert.Error(t, printExplanation(out, e, "table"))
+}
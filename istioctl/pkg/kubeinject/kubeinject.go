@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"sort"
 	"strings"
@@ -77,6 +78,81 @@ func (e ExternalInjector) Inject(pod *corev1.Pod, deploymentNS string) ([]byte,
 	if cc == nil {
 		return nil, nil
 	}
+	podBytes, err := json.Marshal(pod)
+	if pod.Namespace != "" {
+		deploymentNS = pod.Namespace
+	}
+	if err != nil {
+		return nil, err
+	}
+	rev := &admission.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: admission.SchemeGroupVersion.String(),
+			Kind:       "AdmissionReview",
+		},
+		Request: &admission.AdmissionRequest{
+			Object: runtime.RawExtension{Raw: podBytes},
+			Kind: metav1.GroupVersionKind{
+				Group:   admission.GroupName,
+				Version: admission.SchemeGroupVersion.Version,
+				Kind:    "AdmissionRequest",
+			},
+			Resource:           metav1.GroupVersionResource{},
+			SubResource:        "",
+			RequestKind:        nil,
+			RequestResource:    nil,
+			RequestSubResource: "",
+			Name:               pod.Name,
+			Namespace:          deploymentNS,
+		},
+		Response: nil,
+	}
+	revBytes, err := json.Marshal(rev)
+	if err != nil {
+		return nil, err
+	}
+	body, err := e.do(revBytes, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	var obj runtime.Object
+	var ar *kube.AdmissionReview
+	out, _, err := deserializer.Decode(body, nil, obj)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode body: %v", err)
+	}
+	ar, err = kube.AdmissionReviewKubeToAdapter(out)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode object: %v", err)
+	}
+
+	return ar.Response.Patch, nil
+}
+
+// Explain asks the injection webhook to explain the injection of a pod, without admitting it.
+func (e ExternalInjector) Explain(pod *corev1.Pod, namespace string) (*inject.Explanation, error) {
+	if e.clientConfig == nil {
+		return nil, errors.New("no injection webhook configured")
+	}
+	podBytes, err := json.Marshal(pod)
+	if err != nil {
+		return nil, err
+	}
+	body, err := e.do(podBytes, inject.ExplainPath, url.Values{"namespace": []string{namespace}})
+	if err != nil {
+		return nil, err
+	}
+	explanation := &inject.Explanation{}
+	if err := json.Unmarshal(body, explanation); err != nil {
+		return nil, fmt.Errorf("could not decode explanation: %v", err)
+	}
+	return explanation, nil
+}
+
+// do posts a request to the injection webhook. If endpoint is set, the request is sent to this path of the webhook
+// instead of the injection path, which is passed as the path query parameter along with query.
+func (e ExternalInjector) do(body []byte, endpoint string, query url.Values) ([]byte, error) {
+	cc := e.clientConfig
 	var address string
 	if cc.URL != nil {
 		address = *cc.URL
@@ -144,60 +220,32 @@ func (e ExternalInjector) Inject(pod *corev1.Pod, deploymentNS string) ([]byte,
 			return nil, err
 		}
 	}
-	podBytes, err := json.Marshal(pod)
-	if pod.Namespace != "" {
-		deploymentNS = pod.Namespace
-	}
-	if err != nil {
-		return nil, err
-	}
-	rev := &admission.AdmissionReview{
-		TypeMeta: metav1.TypeMeta{
-			APIVersion: admission.SchemeGroupVersion.String(),
-			Kind:       "AdmissionReview",
-		},
-		Request: &admission.AdmissionRequest{
-			Object: runtime.RawExtension{Raw: podBytes},
-			Kind: metav1.GroupVersionKind{
-				Group:   admission.GroupName,
-				Version: admission.SchemeGroupVersion.Version,
-				Kind:    "AdmissionRequest",
-			},
-			Resource:           metav1.GroupVersionResource{},
-			SubResource:        "",
-			RequestKind:        nil,
-			RequestResource:    nil,
-			RequestSubResource: "",
-			Name:               pod.Name,
-			Namespace:          deploymentNS,
-		},
-		Response: nil,
-	}
-	revBytes, err := json.Marshal(rev)
-	if err != nil {
-		return nil, err
+	if endpoint != "" {
+		u, err := url.Parse(address)
+		if err != nil {
+			return nil, err
+		}
+		if query == nil {
+			query = url.Values{}
+		}
+		query.Set("path", u.Path)
+		u.Path = endpoint
+		u.RawQuery = query.Encode()
+		address = u.String()
 	}
-	resp, err := client.Post(address, "application/json", bytes.NewBuffer(revBytes))
+	resp, err := client.Post(address, "application/json", bytes.NewBuffer(body))
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	out, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
-	var obj runtime.Object
-	var ar *kube.AdmissionReview
-	out, _, err := deserializer.Decode(body, nil, obj)
-	if err != nil {
-		return nil, fmt.Errorf("could not decode body: %v", err)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("injection webhook returned %s: %s", resp.Status, strings.TrimSpace(string(out)))
 	}
-	ar, err = kube.AdmissionReviewKubeToAdapter(out)
-	if err != nil {
-		return nil, fmt.Errorf("could not decode object: %v", err)
-	}
-
-	return ar.Response.Patch, nil
+	return out, nil
 }
 
 var (
@@ -0,0 +1,228 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubeinject
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/kube/inject"
+)
+
+// goldenSuffix is the suffix of the expected output of an input manifest, as in the injection tests.
+const goldenSuffix = ".injected"
+
+type templateTestOptions struct {
+	injectConfigFile string
+	valuesFile       string
+	meshConfigFile   string
+	revision         string
+	path             string
+	update           bool
+}
+
+func templateTestCmd() *cobra.Command {
+	opts := templateTestOptions{}
+	cmd := &cobra.Command{
+		Use:   "test <directory>",
+		Short: "Test injection templates against golden files",
+		Long: `Test injection templates against golden files, without a cluster.
+
+Each manifest <name>.yaml of the directory is injected with the templates, and the output is compared to the
+golden file <name>.yaml.injected, as in the injection tests of Istio. The manifests may be pods, or any resource
+with a pod template. The injection follows the webhook: the injection policy, default templates, aliases and
+injected annotations of the injector configuration are honored, and pods which would not be injected are left
+unchanged.
+
+The injector configuration may be the injector ConfigMap, its config key, or a single sidecar template. Use
+--update to write the golden files from the current output.`,
+		Example: `  # Test the templates of the injector ConfigMap
+  kubectl get configmap istio-sidecar-injector -n istio-system -o yaml > injector.yaml
+  istioctl x inject test --injectConfigFile injector.yaml testdata/
+
+  # Write the golden files of new test cases
+  istioctl x inject test --injectConfigFile config.yaml --valuesFile values.yaml --update testdata/`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.injectConfigFile == "" {
+				return errors.New("injection configuration not specified (see --injectConfigFile)")
+			}
+			injector, meshConfig, err := opts.injector()
+			if err != nil {
+				return err
+			}
+			return runTemplateTests(cmd.OutOrStdout(), injector, meshConfig, opts.revision, args[0], opts.update)
+		},
+	}
+	cmd.Flags().StringVar(&opts.injectConfigFile, "injectConfigFile", "",
+		"Injector ConfigMap, injection configuration or sidecar template file")
+	cmd.Flags().StringVar(&opts.valuesFile, "valuesFile", "",
+		"Injection values file. Defaults to the values of the injector ConfigMap, if any")
+	cmd.Flags().StringVar(&opts.meshConfigFile, "meshConfigFile", "", "Mesh configuration file. Defaults to the default mesh configuration")
+	cmd.Flags().StringVarP(&opts.revision, "revision", "r", "", "Control plane revision of the injector")
+	cmd.Flags().StringVar(&opts.path, "path", "/inject",
+		"Injection path of the webhook configuration, which may set the cluster and network, e.g. /inject/cluster/c1/net/n1")
+	cmd.Flags().BoolVar(&opts.update, "update", false, "Write the golden files instead of comparing them")
+	return cmd
+}
+
+func (o templateTestOptions) injector() (*inject.LocalInjector, *meshconfig.MeshConfig, error) {
+	in, err := os.ReadFile(o.injectConfigFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	config, values, err := readInjectorConfig(in)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading --injectConfigFile: %v", err)
+	}
+	if o.valuesFile != "" {
+		b, err := os.ReadFile(o.valuesFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		values = string(b)
+	}
+	if values == "" {
+		values = "{}"
+	}
+	valuesConfig, err := inject.NewValuesConfig(values)
+	if err != nil {
+		return nil, nil, err
+	}
+	var meshConfig *meshconfig.MeshConfig
+	if o.meshConfigFile != "" {
+		if meshConfig, err = mesh.ReadMeshConfig(o.meshConfigFile); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		meshConfig = mesh.DefaultMeshConfig()
+	}
+	return inject.NewLocalInjector(config, valuesConfig, meshConfig, o.revision, o.path), meshConfig, nil
+}
+
+// readInjectorConfig reads an injector configuration, along with the values of the injector ConfigMap if the
+// configuration is a ConfigMap. As kube-inject, it accepts a single sidecar template as well.
+func readInjectorConfig(in []byte) (*inject.Config, string, error) {
+	var values string
+	cm := &corev1.ConfigMap{}
+	if err := yaml.Unmarshal(in, cm); err == nil && cm.Kind == "ConfigMap" {
+		config, f := cm.Data[injectConfigMapKey]
+		if !f {
+			return nil, "", fmt.Errorf("missing configuration map key %q in %q", injectConfigMapKey, cm.Name)
+		}
+		in = []byte(config)
+		values = cm.Data[valuesConfigMapKey]
+	}
+	config := inject.Config{}
+	if err := yaml.Unmarshal(in, &config); err != nil || len(config.RawTemplates) == 0 {
+		// This must be a direct template, instead of an inject.Config. We support both formats
+		config = inject.Config{
+			RawTemplates:     inject.RawTemplates{inject.SidecarTemplateName: string(in)},
+			DefaultTemplates: []string{inject.SidecarTemplateName},
+		}
+		var err error
+		if config.Templates, err = inject.ParseTemplates(config.RawTemplates); err != nil {
+			return nil, "", err
+		}
+	} else if config, err = inject.UnmarshalConfig(in); err != nil {
+		return nil, "", err
+	}
+	if config.Policy == "" {
+		config.Policy = inject.InjectionPolicyEnabled
+	}
+	return &config, values, nil
+}
+
+// runTemplateTests injects the manifests of a directory, and compares the output to their golden files. It returns
+// an error if any test fails.
+func runTemplateTests(w io.Writer, injector inject.Injector, meshConfig *meshconfig.MeshConfig, revision, dir string, update bool) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var inputs []string
+	for _, e := range entries {
+		if !e.IsDir() && (strings.HasSuffix(e.Name(), ".yaml") || strings.HasSuffix(e.Name(), ".yml")) {
+			inputs = append(inputs, e.Name())
+		}
+	}
+	if len(inputs) == 0 {
+		return fmt.Errorf("no manifests found in %s", dir)
+	}
+	sort.Strings(inputs)
+
+	failed := 0
+	for _, name := range inputs {
+		in, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		got := &bytes.Buffer{}
+		err = inject.IntoResourceFile(injector, nil, inject.ValuesConfig{}, revision, meshConfig, in, got, func(string) {})
+		_ = in.Close()
+		if err != nil {
+			failed++
+			fmt.Fprintf(w, "FAIL %s: %v\n", name, err)
+			continue
+		}
+		goldenFile := filepath.Join(dir, name+goldenSuffix)
+		if update {
+			if err := os.WriteFile(goldenFile, got.Bytes(), 0o644); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "UPDATED %s\n", name+goldenSuffix)
+			continue
+		}
+		want, err := os.ReadFile(goldenFile)
+		if err != nil {
+			failed++
+			fmt.Fprintf(w, "FAIL %s: missing golden file %s, use --update to write it\n", name, name+goldenSuffix)
+			continue
+		}
+		if bytes.Equal(want, got.Bytes()) {
+			fmt.Fprintf(w, "PASS %s\n", name)
+			continue
+		}
+		failed++
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(want)),
+			B:        difflib.SplitLines(got.String()),
+			FromFile: name + goldenSuffix,
+			ToFile:   "injected " + name,
+			Context:  3,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "FAIL %s\n%s\n", name, diff)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d injection tests failed", failed, len(inputs))
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubeinject
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"istio.io/istio/pkg/kube/inject"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func copyTestDir(t *testing.T, dir string) string {
+	out := t.TempDir()
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	for _, e := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(filepath.Join(out, e.Name()), b, 0o644))
+	}
+	return out
+}
+
+func TestTemplateTests(t *testing.T) {
+	opts := templateTestOptions{
+		injectConfigFile: "testdata/templatetest-config.yaml",
+		valuesFile:       "testdata/templatetest-values.yaml",
+	}
+	injector, meshConfig, err := opts.injector()
+	assert.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, runTemplateTests(out, injector, meshConfig, "", "testdata/templatetest", false))
+	assert.Equal(t, out.String(), "PASS debug.yaml\nPASS deployment.yaml\nPASS disabled.yaml\n")
+
+	// A template change is reported as a diff against the golden file.
+	dir := copyTestDir(t, "testdata/templatetest")
+	golden := filepath.Join(dir, "debug.yaml.injected")
+	b, err := os.ReadFile(golden)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(golden, bytes.ReplaceAll(b, []byte("--proxyLogLevel=debug"), []byte("--proxyLogLevel=info")), 0o644))
+	assert.NoError(t, os.Remove(filepath.Join(dir, "disabled.yaml.injected")))
+	out.Reset()
+	err = runTemplateTests(out, injector, meshConfig, "", dir, false)
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), "2 of 3 injection tests failed")
+	for _, want := range []string{
+		"FAIL debug.yaml\n",
+		"-    - --proxyLogLevel=info\n+    - --proxyLogLevel=debug\n",
+		"PASS deployment.yaml\n",
+		"FAIL disabled.yaml: missing golden file disabled.yaml.injected, use --update to write it\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+
+	// Updating writes the golden files from the current output.
+	out.Reset()
+	assert.NoError(t, runTemplateTests(out, injector, meshConfig, "", dir, true))
+	out.Reset()
+	assert.NoError(t, runTemplateTests(out, injector, meshConfig, "", dir, false))
+}
+
+func TestReadInjectorConfig(t *testing.T) {
+	config, values, err := readInjectorConfig([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: istio-sidecar-injector
+data:
+  values: |
+    global:
+      hub: example.com
+  config: |
+    policy: disabled
+    alwaysInjectSelector: []
+    templates:
+      sidecar: |
+        spec: {}
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, config.Policy, inject.InjectionPolicyDisabled)
+	assert.Equal(t, config.DefaultTemplates, []string{inject.SidecarTemplateName})
+	assert.Equal(t, len(config.Templates), 1)
+	assert.Equal(t, values, "global:\n  hub: example.com\n")
+
+	// A single template is the sidecar template, and the policy defaults to enabled.
+	config, values, err = readInjectorConfig([]byte("spec: {}\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, config.Policy, inject.InjectionPolicyEnabled)
+	assert.Equal(t, config.RawTemplates, inject.RawTemplates{inject.SidecarTemplateName: "spec: {}\n"})
+	assert.Equal(t, values, "")
+
+	_, _, err = readInjectorConfig([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: istio-sidecar-injector
+data: {}
+`))
+	assert.Error(t, err)
+}
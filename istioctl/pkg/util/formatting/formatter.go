@@ -30,15 +30,25 @@ import (
 
 // Formatting options for Messages
 const (
-	LogFormat  = "log"
-	JSONFormat = "json"
-	YAMLFormat = "yaml"
+	LogFormat   = "log"
+	JSONFormat  = "json"
+	YAMLFormat  = "yaml"
+	SARIFFormat = "sarif"
+	JUnitFormat = "junit"
 )
 
 var (
-	MsgOutputFormatKeys = []string{LogFormat, JSONFormat, YAMLFormat}
+	MsgOutputFormatKeys = []string{LogFormat, JSONFormat, YAMLFormat, SARIFFormat, JUnitFormat}
 	MsgOutputFormats    = make(map[string]bool)
 	termEnvVar          = env.Register("TERM", "", "Specifies terminal type.  Use 'dumb' to suppress color output")
+
+	formatters = map[string]Formatter{
+		LogFormat:   FormatterFunc(func(r Report) (string, error) { return printLog(r.Messages, r.Colorize), nil }),
+		JSONFormat:  FormatterFunc(func(r Report) (string, error) { return printJSON(r.Messages) }),
+		YAMLFormat:  FormatterFunc(func(r Report) (string, error) { return printYAML(r.Messages) }),
+		SARIFFormat: FormatterFunc(printSARIF),
+		JUnitFormat: FormatterFunc(printJUnit),
+	}
 )
 
 func init() {
@@ -47,18 +57,53 @@ func init() {
 	}
 }
 
+// Report holds the findings of a command, such as istioctl analyze, to be output in one of the supported formats.
+type Report struct {
+	// Tool is the name of the command producing the report, for instance "istioctl analyze".
+	Tool string
+	// Messages are the messages to output.
+	Messages diag.Messages
+	// Checks are the checks which ran, with the messages each of them produced. They are used by the formats listing
+	// checks, such as JUnit, which fall back to grouping the messages by code if no check is set.
+	Checks []Check
+	// Colorize enables colored output, where supported.
+	Colorize bool
+}
+
+// Check is a check of a report, such as an analyzer or the validation of a resource.
+type Check struct {
+	Name     string
+	Messages diag.Messages
+}
+
+// Formatter renders a report in an output format.
+type Formatter interface {
+	Format(r Report) (string, error)
+}
+
+// FormatterFunc is a function implementing Formatter.
+type FormatterFunc func(r Report) (string, error)
+
+// Format implements Formatter
+func (f FormatterFunc) Format(r Report) (string, error) {
+	return f(r)
+}
+
 // Print output messages in the specified format with color options
 func Print(ms diag.Messages, format string, colorize bool) (string, error) {
-	switch format {
-	case LogFormat:
-		return printLog(ms, colorize), nil
-	case JSONFormat:
-		return printJSON(ms)
-	case YAMLFormat:
-		return printYAML(ms)
-	default:
+	return PrintReport(Report{Messages: ms, Colorize: colorize}, format)
+}
+
+// PrintReport outputs a report in the specified format.
+func PrintReport(r Report, format string) (string, error) {
+	f, ok := formatters[format]
+	if !ok {
 		return "", fmt.Errorf("invalid format, expected one of %v but got %q", MsgOutputFormatKeys, format)
 	}
+	if r.Tool == "" {
+		r.Tool = "istioctl"
+	}
+	return f.Format(r)
 }
 
 func printLog(ms diag.Messages, colorize bool) string {
@@ -15,11 +15,15 @@
 package formatting
 
 import (
+	"encoding/json"
 	"testing"
 
 	. "github.com/onsi/gomega"
 
 	"istio.io/istio/pkg/config/analysis/diag"
+	"istio.io/istio/pkg/config/analysis/legacy/source/kube"
+	"istio.io/istio/pkg/config/resource"
+	"istio.io/istio/pkg/config/schema/gvk"
 	"istio.io/istio/pkg/url"
 )
 
@@ -177,3 +181,126 @@ func TestFormatter_PintLogForMultiCluster(t *testing.T) {
 			"\033[33mWarning\033[0m [C1] [cluster-another] (GrandCastle) Collapse danger: the castle is too old",
 	))
 }
+
+func fileResource(name, filename string, line int) *resource.Instance {
+	return &resource.Instance{
+		Metadata: resource.Metadata{FullName: resource.NewFullName("default", resource.LocalName(name))},
+		Origin: &kube.Origin{
+			Type:     gvk.VirtualService,
+			FullName: resource.NewFullName("default", resource.LocalName(name)),
+			Ref:      &kube.Position{Filename: filename, Line: line},
+		},
+	}
+}
+
+func TestFormatter_PrintSARIF(t *testing.T) {
+	g := NewWithT(t)
+
+	firstMsg := diag.NewMessage(
+		diag.NewMessageType(diag.Error, "IST0101", "Referenced %s not found: %q"),
+		fileResource("reviews", "config/reviews.yaml", 10),
+		"host", "reviews",
+	)
+	// The line of the field the message refers to takes precedence over the line of the resource.
+	firstMsg.Line = 14
+	secondMsg := diag.NewMessage(
+		diag.NewMessageType(diag.Info, "ORG0001", "Collapse danger: %v"),
+		diag.MockResource("GrandCastle"),
+		"the castle is too old",
+	)
+	thirdMsg := diag.NewMessage(
+		diag.NewMessageType(diag.Error, "IST0101", "Referenced %s not found: %q"),
+		fileResource("ratings", "config/ratings.yaml", 1),
+		"host", "ratings",
+	)
+
+	output, err := PrintReport(Report{Tool: "istioctl analyze", Messages: diag.Messages{firstMsg, secondMsg, thirdMsg}}, SARIFFormat)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var log sarifLog
+	g.Expect(json.Unmarshal([]byte(output), &log)).To(Succeed())
+	g.Expect(log.Version).To(Equal("2.1.0"))
+	g.Expect(log.Runs).To(HaveLen(1))
+	run := log.Runs[0]
+	g.Expect(run.Tool.Driver.Name).To(Equal("istioctl analyze"))
+	g.Expect(run.Tool.Driver.Rules).To(Equal([]sarifRule{
+		{ID: "IST0101", HelpURI: url.ConfigAnalysis + "/ist0101/", DefaultConfiguration: sarifConfiguration{Level: "error"}},
+		{ID: "ORG0001", DefaultConfiguration: sarifConfiguration{Level: "note"}},
+	}))
+	g.Expect(run.Results).To(HaveLen(3))
+	g.Expect(run.Results[0]).To(Equal(sarifResult{
+		RuleID:  "IST0101",
+		Level:   "error",
+		Message: sarifMessage{Text: `Referenced host not found: "reviews"`},
+		Locations: []sarifLocation{{
+			PhysicalLocation: &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: "config/reviews.yaml"},
+				Region:           &sarifRegion{StartLine: 14},
+			},
+			LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: "VirtualService default/reviews", Kind: "resource"}},
+		}},
+	}))
+	g.Expect(run.Results[1].RuleIndex).To(Equal(1))
+	g.Expect(run.Results[1].Locations[0].PhysicalLocation).To(BeNil())
+	g.Expect(run.Results[2].RuleIndex).To(Equal(0))
+	g.Expect(run.Results[2].Locations[0].PhysicalLocation.Region.StartLine).To(Equal(1))
+
+	output, err = PrintReport(Report{}, SARIFFormat)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(json.Unmarshal([]byte(output), &log)).To(Succeed())
+	g.Expect(log.Runs[0].Tool.Driver.Name).To(Equal("istioctl"))
+	g.Expect(log.Runs[0].Results).To(BeEmpty())
+}
+
+func TestFormatter_PrintJUnit(t *testing.T) {
+	g := NewWithT(t)
+
+	firstMsg := diag.NewMessage(
+		diag.NewMessageType(diag.Error, "B1", "Explosion accident: %v"),
+		diag.MockResource("SoapBubble"),
+		"the bubble is too big",
+	)
+	secondMsg := diag.NewMessage(
+		diag.NewMessageType(diag.Info, "C1", "Collapse danger: %v"),
+		diag.MockResource("GrandCastle"),
+		"the castle is too old",
+	)
+
+	report := Report{
+		Tool:     "istioctl analyze",
+		Messages: diag.Messages{firstMsg, secondMsg},
+		Checks: []Check{
+			{Name: "bubble.Analyzer", Messages: diag.Messages{firstMsg}},
+			{Name: "castle.Analyzer", Messages: diag.Messages{secondMsg}},
+			{Name: "empty.Analyzer"},
+		},
+	}
+	output, err := PrintReport(report, JUnitFormat)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(output).To(Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="istioctl analyze" tests="3" failures="1">
+  <testsuite name="istioctl analyze" tests="3" failures="1" errors="0">
+    <testcase name="bubble.Analyzer" classname="istioctl analyze">
+      <failure message="1 issue(s) found" type="Error">Error [B1] (SoapBubble) Explosion accident: the bubble is too big</failure>
+    </testcase>
+    <testcase name="castle.Analyzer" classname="istioctl analyze">
+      <system-out>Info [C1] (GrandCastle) Collapse danger: the castle is too old</system-out>
+    </testcase>
+    <testcase name="empty.Analyzer" classname="istioctl analyze"></testcase>
+  </testsuite>
+</testsuites>`))
+
+	// Without checks, messages are grouped by code.
+	report.Checks = nil
+	output, err = PrintReport(report, JUnitFormat)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(output).To(ContainSubstring(`<testcase name="B1" classname="istioctl analyze">`))
+	g.Expect(output).To(ContainSubstring(`<testcase name="C1" classname="istioctl analyze">`))
+}
+
+func TestFormatter_PrintInvalidFormat(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := Print(diag.Messages{}, "xml", false)
+	g.Expect(err).To(HaveOccurred())
+}
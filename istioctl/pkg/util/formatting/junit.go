@@ -0,0 +1,118 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatting
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"istio.io/istio/pkg/config/analysis/diag"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// printJUnit outputs a report as a JUnit XML test suite, with a test case per check. A check fails if it produced
+// warnings or errors; informational messages are only reported as the output of the test case.
+func printJUnit(r Report) (string, error) {
+	checks := r.Checks
+	if checks == nil {
+		checks = checksByCode(r.Messages)
+	}
+	suite := junitTestSuite{Name: r.Tool, Tests: len(checks), Cases: []junitTestCase{}}
+	for _, c := range checks {
+		tc := junitTestCase{Name: c.Name, Classname: r.Tool}
+		var failures, infos diag.Messages
+		for _, m := range c.Messages {
+			if m.Type.Level().IsWorseThanOrEqualTo(diag.Warning) {
+				failures = append(failures, m)
+			} else {
+				infos = append(infos, m)
+			}
+		}
+		if len(failures) > 0 {
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d issue(s) found", len(failures)),
+				Type:    worstLevel(failures).String(),
+				Text:    printLog(failures, false),
+			}
+			suite.Failures++
+		}
+		tc.SystemOut = printLog(infos, false)
+		suite.Cases = append(suite.Cases, tc)
+	}
+	out, err := xml.MarshalIndent(junitTestSuites{
+		Name:     r.Tool,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(out), nil
+}
+
+// checksByCode groups messages by code, for reports which do not list their checks.
+func checksByCode(ms diag.Messages) []Check {
+	var checks []Check
+	index := map[string]int{}
+	for _, m := range ms {
+		i, ok := index[m.Type.Code()]
+		if !ok {
+			i = len(checks)
+			index[m.Type.Code()] = i
+			checks = append(checks, Check{Name: m.Type.Code()})
+		}
+		checks[i].Messages = append(checks[i].Messages, m)
+	}
+	return checks
+}
+
+func worstLevel(ms diag.Messages) diag.Level {
+	worst := diag.Info
+	for _, m := range ms {
+		if m.Type.Level().IsWorseThanOrEqualTo(worst) {
+			worst = m.Type.Level()
+		}
+	}
+	return worst
+}
@@ -0,0 +1,181 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package formatting
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"istio.io/istio/pkg/config/analysis/diag"
+	"istio.io/istio/pkg/config/analysis/legacy/source/kube"
+	"istio.io/istio/pkg/url"
+	"istio.io/istio/pkg/version"
+)
+
+// The subset of the SARIF 2.1.0 format (https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html) used to
+// report messages, as consumed by code scanning tools.
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	HelpURI              string             `json:"helpUri,omitempty"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+var sarifLevels = map[diag.Level]string{
+	diag.Info:    "note",
+	diag.Warning: "warning",
+	diag.Error:   "error",
+}
+
+// printSARIF outputs the messages of a report as the results of a SARIF run, with a rule per message code. Messages
+// on resources read from files are located at the line of the resource, or of the field they refer to.
+func printSARIF(r Report) (string, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           r.Tool,
+			Version:        version.Info.Version,
+			InformationURI: url.ConfigAnalysis,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	rules := map[string]int{}
+	for _, m := range r.Messages {
+		code := m.Type.Code()
+		index, ok := rules[code]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			rules[code] = index
+			rule := sarifRule{ID: code, DefaultConfiguration: sarifConfiguration{Level: sarifLevels[m.Type.Level()]}}
+			// Only the messages of Istio are documented; custom analyzers use other codes.
+			if strings.HasPrefix(code, "IST") {
+				rule.HelpURI = fmt.Sprintf("%s/%s/", url.ConfigAnalysis, strings.ToLower(code))
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+		result := sarifResult{
+			RuleID:    code,
+			RuleIndex: index,
+			Level:     sarifLevels[m.Type.Level()],
+			Message:   sarifMessage{Text: fmt.Sprintf(m.Type.Template(), m.Parameters...)},
+		}
+		if m.Resource != nil {
+			loc := sarifLocation{
+				LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: m.Resource.Origin.FriendlyName(), Kind: "resource"}},
+			}
+			if file, line := fileLocation(m); file != "" {
+				loc.PhysicalLocation = &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: fileURI(file)}}
+				if line > 0 {
+					loc.PhysicalLocation.Region = &sarifRegion{StartLine: line}
+				}
+			}
+			result.Locations = []sarifLocation{loc}
+		}
+		run.Results = append(run.Results, result)
+	}
+	out, err := json.MarshalIndent(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}, "", "  ")
+	return string(out), err
+}
+
+// fileLocation returns the file and line a message refers to, if its resource was read from a file.
+func fileLocation(m diag.Message) (string, int) {
+	if m.Resource == nil || m.Resource.Origin == nil {
+		return "", 0
+	}
+	pos, ok := m.Resource.Origin.Reference().(*kube.Position)
+	if !ok || pos == nil || pos.Filename == "" {
+		return "", 0
+	}
+	if m.Line != 0 {
+		return pos.Filename, m.Line
+	}
+	return pos.Filename, pos.Line
+}
+
+// fileURI returns the URI of a file. Relative paths are kept relative, so that code scanning tools resolve them
+// against the root of the repository.
+func fileURI(file string) string {
+	if filepath.IsAbs(file) {
+		return "file://" + filepath.ToSlash(file)
+	}
+	return filepath.ToSlash(file)
+}
@@ -29,19 +29,27 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	kubeyaml "k8s.io/apimachinery/pkg/util/yaml"
 	"sigs.k8s.io/yaml"
 
 	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/istioctl/pkg/util/formatting"
 	operator "istio.io/istio/operator/pkg/apis"
 	operatorvalidate "istio.io/istio/operator/pkg/apis/validation"
+	"istio.io/istio/pilot/pkg/config/file/util/kubeyaml"
+	"istio.io/istio/pilot/pkg/config/kube/crd"
+	"istio.io/istio/pilot/pkg/networking/core"
 	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/analysis/diag"
+	"istio.io/istio/pkg/config/analysis/legacy/source/kube"
+	"istio.io/istio/pkg/config/analysis/msg"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/protocol"
+	"istio.io/istio/pkg/config/resource"
 	"istio.io/istio/pkg/config/schema/collections"
 	"istio.io/istio/pkg/config/schema/gvk"
-	"istio.io/istio/pkg/config/schema/resource"
+	sresource "istio.io/istio/pkg/config/schema/resource"
 	"istio.io/istio/pkg/config/validation"
+	"istio.io/istio/pkg/config/validation/policy"
 	"istio.io/istio/pkg/kube/labels"
 	"istio.io/istio/pkg/log"
 	"istio.io/istio/pkg/slices"
@@ -67,7 +75,14 @@ Example resource specifications include:
 	fileExtensions = []string{".json", ".yaml", ".yml"}
 )
 
-type validator struct{}
+type validator struct {
+	// policy holds the organization policy rules the resources are checked against, if any.
+	policy *policy.Policy
+	// envoyFilterReference is the configuration the patches of EnvoyFilters are verified to match against, if set.
+	envoyFilterReference []config.Config
+	// checks records the validation of each resource, and the failures to read files, to be output as a report.
+	checks []formatting.Check
+}
 
 func checkFields(un *unstructured.Unstructured) error {
 	var errs error
@@ -106,6 +121,18 @@ func (v *validator) validateResource(istioNamespace, defaultNamespace string, un
 		}
 
 		warnings, err := schema.ValidateConfig(*obj)
+		if err != nil {
+			return warnings, err
+		}
+		policyWarnings, err := v.policy.Evaluate(*obj)
+		if policyWarnings != nil {
+			warnings = multierror.Append(warnings, policyWarnings)
+		}
+		if err == nil && v.envoyFilterReference != nil && obj.GroupVersionKind == gvk.EnvoyFilter {
+			if matchWarnings := v.verifyEnvoyFilterMatch(*obj); matchWarnings != nil {
+				warnings = multierror.Append(warnings, matchWarnings)
+			}
+		}
 		return warnings, err
 	}
 
@@ -164,6 +191,58 @@ func (v *validator) validateResource(istioNamespace, defaultNamespace string, un
 	return nil, nil
 }
 
+// verifyEnvoyFilterMatch reports the patches of an EnvoyFilter which select nothing in the reference configuration.
+func (v *validator) verifyEnvoyFilterMatch(ef config.Config) validation.Warning {
+	unmatched, err := core.UnmatchedEnvoyFilterPatches(ef, v.envoyFilterReference)
+	if err != nil {
+		return fmt.Errorf("cannot verify the matches of the EnvoyFilter patches: %v", err)
+	}
+	var warnings error
+	for _, i := range unmatched {
+		warnings = multierror.Append(warnings, fmt.Errorf("Envoy filter: configPatches[%d] matches nothing in the reference configuration", i)) // nolint: stylecheck
+	}
+	return warnings
+}
+
+// readReferenceConfig reads the Istio configuration of files or directories, the reference EnvoyFilter patches are
+// verified against.
+func readReferenceConfig(paths []string) ([]config.Config, error) {
+	var inputs []string
+	for _, path := range paths {
+		err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || (file != path && !isFileFormatValid(file)) {
+				return nil
+			}
+			b, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			inputs = append(inputs, string(b))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	configs, _, err := crd.ParseInputs(strings.Join(inputs, "\n---\n"))
+	if err != nil {
+		return nil, err
+	}
+	for i := range configs {
+		if configs[i].Namespace == "" {
+			configs[i].Namespace = metav1.NamespaceDefault
+		}
+	}
+	// An empty reference configuration still verifies the patches, against the configuration of an empty mesh.
+	if configs == nil {
+		configs = []config.Config{}
+	}
+	return configs, nil
+}
+
 func (v *validator) validateServicePortPrefix(istioNamespace string, un *unstructured.Unstructured) error {
 	var errs error
 	if un.GetNamespace() == handleNamespace(istioNamespace) {
@@ -237,12 +316,13 @@ func (v *validator) validateFile(path string, istioNamespace *string, defaultNam
 	var errs error
 	var warnings validation.Warning
 	for {
-		doc, err := yamlReader.Read()
+		doc, line, err := yamlReader.Read()
 		if err == io.EOF {
 			return warnings, errs
 		}
 		if err != nil {
 			errs = multierror.Append(errs, multierror.Prefix(err, fmt.Sprintf("failed to decode file %s: ", path)))
+			v.recordFileError(path, err)
 			return warnings, errs
 		}
 		if len(doc) == 0 {
@@ -251,10 +331,12 @@ func (v *validator) validateFile(path string, istioNamespace *string, defaultNam
 		out := map[string]any{}
 		if err := yaml.UnmarshalStrict(doc, &out); err != nil {
 			errs = multierror.Append(errs, multierror.Prefix(err, fmt.Sprintf("failed to decode file %s: ", path)))
+			v.recordFileError(path, err)
 			return warnings, errs
 		}
 		un := unstructured.Unstructured{Object: out}
 		warning, err := v.validateResource(*istioNamespace, defaultNamespace, &un, writer)
+		v.recordResource(path, line, &un, warning, err)
 		if err != nil {
 			errs = multierror.Append(errs, multierror.Prefix(err, fmt.Sprintf("%s/%s/%s:",
 				un.GetKind(), un.GetNamespace(), un.GetName())))
@@ -266,18 +348,70 @@ func (v *validator) validateFile(path string, istioNamespace *string, defaultNam
 	}
 }
 
+// recordResource records the validation of a resource read from a file, starting at the given line.
+func (v *validator) recordResource(path string, line int, un *unstructured.Unstructured, warning validation.Warning, err error) {
+	name := resource.NewFullName(resource.Namespace(un.GetNamespace()), resource.LocalName(un.GetName()))
+	r := &resource.Instance{
+		Metadata: resource.Metadata{FullName: name},
+		Origin: &kube.Origin{
+			Type: config.GroupVersionKind{
+				Group:   un.GroupVersionKind().Group,
+				Version: un.GroupVersionKind().Version,
+				Kind:    un.GroupVersionKind().Kind,
+			},
+			FullName: name,
+			Ref:      &kube.Position{Filename: path, Line: line},
+		},
+	}
+	check := formatting.Check{Name: fmt.Sprintf("%s (%s)", r.Origin.FriendlyName(), r.Origin.Reference())}
+	for _, e := range errorList(err) {
+		check.Messages = append(check.Messages, msg.NewSchemaValidationError(r, e))
+	}
+	for _, w := range errorList(warning) {
+		check.Messages = append(check.Messages, msg.NewSchemaWarning(r, w))
+	}
+	v.checks = append(v.checks, check)
+}
+
+// recordFileError records the failure to read a file.
+func (v *validator) recordFileError(path string, err error) {
+	v.checks = append(v.checks, formatting.Check{Name: path, Messages: diag.Messages{msg.NewSchemaValidationError(nil, err)}})
+}
+
+// report returns the report of the validated resources.
+func (v *validator) report() formatting.Report {
+	r := formatting.Report{Tool: "istioctl validate", Checks: v.checks}
+	for _, c := range v.checks {
+		r.Messages = append(r.Messages, c.Messages...)
+	}
+	return r
+}
+
+// errorList returns the errors wrapped by err.
+func errorList(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if me, ok := err.(*multierror.Error); ok {
+		return me.WrappedErrors()
+	}
+	return []error{err}
+}
+
 func isFileFormatValid(file string) bool {
 	ext := filepath.Ext(file)
 	return slices.Contains(fileExtensions, ext)
 }
 
-func validateFiles(istioNamespace *string, defaultNamespace string, filenames []string, writer io.Writer) error {
+// validateFiles validates the resources of files. If an output format is set, the report of the validation is written
+// to out, and writer only receives the hints about non-Istio resources.
+func validateFiles(istioNamespace *string, defaultNamespace string, filenames []string, v *validator, format string,
+	out, writer io.Writer,
+) error {
 	if len(filenames) == 0 {
 		return errMissingFilename
 	}
 
-	v := &validator{}
-
 	var errs error
 	var reader io.ReadCloser
 	warningsByFilename := map[string]validation.Warning{}
@@ -290,6 +424,7 @@ func validateFiles(istioNamespace *string, defaultNamespace string, filenames []
 			reader, err = os.Open(path)
 			if err != nil {
 				errs = multierror.Append(errs, fmt.Errorf("cannot read file %q: %v", path, err))
+				v.recordFileError(path, err)
 				return
 			}
 		}
@@ -329,6 +464,7 @@ func validateFiles(istioNamespace *string, defaultNamespace string, filenames []
 			fi, err := os.Stat(filename)
 			if err != nil {
 				errs = multierror.Append(errs, fmt.Errorf("cannot stat file %q: %v", filename, err))
+				v.recordFileError(filename, err)
 				continue
 			}
 			isDir = fi.IsDir()
@@ -344,8 +480,19 @@ func validateFiles(istioNamespace *string, defaultNamespace string, filenames []
 			processedFiles[path] = true
 		}); err != nil {
 			errs = multierror.Append(errs, err)
+			v.recordFileError(filename, err)
 		}
 	}
+
+	if format != "" {
+		output, err := formatting.PrintReport(v.report(), format)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(out, output)
+		return errs
+	}
+
 	filenames = []string{}
 	for p := range processedFiles {
 		filenames = append(filenames, p)
@@ -387,7 +534,11 @@ func validateFiles(istioNamespace *string, defaultNamespace string, filenames []
 // NewValidateCommand creates a new command for validating Istio k8s resources.
 func NewValidateCommand(ctx cli.Context) *cobra.Command {
 	var filenames []string
+	var policyFiles []string
+	var referenceFiles []string
+	var verifyEnvoyFilterMatch bool
 	var referential bool
+	var outputFormat string
 
 	c := &cobra.Command{
 		Use:     "validate -f FILENAME [options]",
@@ -408,14 +559,43 @@ func NewValidateCommand(ctx cli.Context) *cobra.Command {
   # Validate current services under 'default' namespace within the cluster
   kubectl get services -o yaml | istioctl validate -f -
 
+  # Validate all yaml files under a directory and report the result of each resource as a JUnit test case
+  istioctl validate -f my-app-config/ -o junit > istio-validate.xml
+
+  # Validate all yaml files under a directory against the organization policy rules enforced by istiod
+  istioctl validate -f my-app-config/ --policy policies.yaml
+
+  # Validate EnvoyFilters, and verify that their patches match the configuration of the gateways
+  istioctl validate -f envoyfilters/ --verify-envoyfilter-match --reference-config gateways/
+
   # Also see the related command 'istioctl analyze'
   istioctl analyze samples/bookinfo/networking/bookinfo-gateway.yaml
 `,
 		Args: cobra.NoArgs,
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			outputFormat = strings.ToLower(outputFormat)
+			if outputFormat != "" && !formatting.MsgOutputFormats[outputFormat] {
+				return fmt.Errorf("%s not a valid option for format. See istioctl validate --help", outputFormat)
+			}
+			return nil
+		},
 		RunE: func(c *cobra.Command, _ []string) error {
 			istioNamespace := ctx.IstioNamespace()
 			defaultNamespace := ctx.NamespaceOrDefault("")
-			return validateFiles(&istioNamespace, defaultNamespace, filenames, c.OutOrStderr())
+			v := &validator{}
+			if len(policyFiles) > 0 {
+				var err error
+				if v.policy, err = policy.Load(policyFiles...); err != nil {
+					return err
+				}
+			}
+			if verifyEnvoyFilterMatch {
+				var err error
+				if v.envoyFilterReference, err = readReferenceConfig(referenceFiles); err != nil {
+					return fmt.Errorf("loading --reference-config: %v", err)
+				}
+			}
+			return validateFiles(&istioNamespace, defaultNamespace, filenames, v, outputFormat, c.OutOrStdout(), c.OutOrStderr())
 		},
 	}
 
@@ -423,6 +603,17 @@ func NewValidateCommand(ctx cli.Context) *cobra.Command {
 	flags.StringSliceVarP(&filenames, "filename", "f", nil, "Inputs of files to validate")
 	flags.BoolVarP(&referential, "referential", "x", true, "Enable structural validation for policy and telemetry")
 	_ = flags.MarkHidden("referential")
+	flags.StringSliceVar(&policyFiles, "policy", nil,
+		"Files or directories of organization policy rules, declared with CEL expressions, to check the resources against. "+
+			"Violations of Deny rules are errors, and violations of Warn rules are warnings")
+	flags.BoolVar(&verifyEnvoyFilterMatch, "verify-envoyfilter-match", false,
+		"Verify that the patches of EnvoyFilters match the configuration generated for the proxies they select, "+
+			"built from the --reference-config. Patches which match nothing are reported as warnings")
+	flags.StringSliceVar(&referenceFiles, "reference-config", nil,
+		"Files or directories of Istio configuration, such as ServiceEntries and Gateways, the EnvoyFilter patches are verified against. "+
+			"Defaults to an empty mesh")
+	flags.StringVarP(&outputFormat, "output", "o", "",
+		fmt.Sprintf("Output format: one of %v. By default, the result of each file is printed", formatting.MsgOutputFormatKeys))
 	return c
 }
 
@@ -460,7 +651,7 @@ func handleNamespace(istioNamespace string) string {
 }
 
 // TODO(nmittler): Remove this once Pilot migrates to galley schema.
-func convertObjectFromUnstructured(schema resource.Schema, un *unstructured.Unstructured, domain string) (*config.Config, error) {
+func convertObjectFromUnstructured(schema sresource.Schema, un *unstructured.Unstructured, domain string) (*config.Config, error) {
 	data, err := fromSchemaAndJSONMap(schema, un.Object["spec"])
 	if err != nil {
 		return nil, err
@@ -482,7 +673,7 @@ func convertObjectFromUnstructured(schema resource.Schema, un *unstructured.Unst
 }
 
 // TODO(nmittler): Remove this once Pilot migrates to galley schema.
-func fromSchemaAndJSONMap(schema resource.Schema, data any) (config.Spec, error) {
+func fromSchemaAndJSONMap(schema sresource.Schema, data any) (config.Spec, error) {
 	// Marshal to json bytes
 	str, err := json.Marshal(data)
 	if err != nil {
@@ -28,6 +28,8 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/pilot/pkg/config/kube/crd"
+	"istio.io/istio/pkg/config"
 	"istio.io/istio/pkg/test/util/assert"
 )
 
@@ -756,6 +758,27 @@ func TestValidateCommand(t *testing.T) {
 	invalidPortNamingSvcFile, closeInvalidPortNamingSvcFile := createTestFile(t, invalidPortNamingSvc)
 	defer closeInvalidPortNamingSvcFile.Close()
 
+	denyPolicyFilename, closeDenyPolicyFile := createTestFile(t, `
+rules:
+- name: reserved-host
+  kinds: [VirtualService]
+  condition: '!("d" in resource.spec.hosts)'
+  message: routes to d are managed by the platform team
+`)
+	defer closeDenyPolicyFile.Close()
+
+	warnPolicyFilename, closeWarnPolicyFile := createTestFile(t, `
+rules:
+- name: team-label
+  condition: '"team" in resource.metadata.labels'
+  action: Warn
+  message: resources should have a team label
+`)
+	defer closeWarnPolicyFile.Close()
+
+	envoyFilterFilename, closeEnvoyFilterFile := createTestFile(t, envoyFilterYAML)
+	defer closeEnvoyFilterFile.Close()
+
 	tempDirYAML := createTestDirectory(t, map[string]string{
 		"valid.yaml":       validYAML,
 		"invalid.yaml":     invalidYAML,
@@ -928,6 +951,54 @@ Error: 1 error occurred:
 			args:      []string{"--filename", tempDirYAML, "--filename", tempDirJSON},
 			wantError: true, // Since the directory has invalid files
 		},
+		{
+			name: "junit report",
+			args: []string{"--filename", warningFilename, "-o", "junit"},
+			expectedRegexp: regexp.MustCompile(`(?s)<testsuites name="istioctl validate" tests="3" failures="2">.*` +
+				`<testcase name="VirtualService invalid-virtual-service \(.*:1\)" classname="istioctl validate">\s*` +
+				`<failure message="1 issue\(s\) found" type="Error">Error \[IST0106\].*weight -15 &lt; 0</failure>.*` +
+				`<testcase name="VirtualService valid-virtual-service1 \(.*:\d+\)" classname="istioctl validate"></testcase>.*` +
+				`<testcase name="DestinationRule reviews-cb-policy \(.*:\d+\)" classname="istioctl validate">\s*` +
+				`<failure message="1 issue\(s\) found" type="Warning">Warning \[IST0133\]`),
+			wantError: true,
+		},
+		{
+			name:           "sarif report",
+			args:           []string{"--filename", validFilenameYAML, "-o", "sarif"},
+			expectedRegexp: regexp.MustCompile(`(?s)"name": "istioctl validate",.*"results": \[\]`),
+		},
+		{
+			name: "policy denial",
+			args: []string{"--filename", validFilenameYAML, "--policy", denyPolicyFilename},
+			expectedRegexp: regexp.MustCompile(
+				`VirtualService//valid-virtual-service1: policy rule reserved-host: routes to d are managed by the platform team`),
+			wantError: true,
+		},
+		{
+			name:           "policy warning",
+			args:           []string{"--filename", validFilenameYAML, "--policy", warnPolicyFilename},
+			expectedRegexp: regexp.MustCompile(`(?m)".*" has warnings: \n\t\* VirtualService//valid-virtual-service: policy rule team-label`),
+		},
+		{
+			name:      "invalid policy",
+			args:      []string{"--filename", validFilenameYAML, "--policy", validFilenameYAML},
+			wantError: true,
+		},
+		{
+			name:           "envoyfilter match",
+			args:           []string{"--filename", envoyFilterFilename, "--verify-envoyfilter-match"},
+			expectedRegexp: regexp.MustCompile(`Envoy filter: configPatches\[0\] matches nothing in the reference configuration`),
+		},
+		{
+			name:      "invalid reference config",
+			args:      []string{"--filename", envoyFilterFilename, "--verify-envoyfilter-match", "--reference-config", "missing.yaml"},
+			wantError: true,
+		},
+		{
+			name:      "invalid output format",
+			args:      []string{"--filename", validFilenameYAML, "-o", "xml"},
+			wantError: true,
+		},
 	}
 	for i, c := range cases {
 		t.Run(fmt.Sprintf("[%v] %v", i, c.name), func(t *testing.T) {
@@ -969,3 +1040,52 @@ func TestGetTemplateLabels(t *testing.T) {
 		"version": "v1",
 	})
 }
+
+const envoyFilterYAML = `
+apiVersion: networking.istio.io/v1alpha3
+kind: EnvoyFilter
+metadata:
+  name: httpbin-timeout
+  namespace: default
+spec:
+  configPatches:
+  - applyTo: CLUSTER
+    match:
+      context: SIDECAR_OUTBOUND
+      cluster:
+        service: httpbin.example.com
+    patch:
+      operation: MERGE
+      value:
+        connect_timeout: 1s
+`
+
+func TestVerifyEnvoyFilterMatch(t *testing.T) {
+	envoyFilter, _, err := crd.ParseInputs(envoyFilterYAML)
+	assert.NoError(t, err)
+	ef := envoyFilter[0]
+
+	// The patched cluster does not exist in an empty mesh.
+	v := &validator{envoyFilterReference: []config.Config{}}
+	assert.Equal(t, v.verifyEnvoyFilterMatch(ef).Error(),
+		"1 error occurred:\n\t* Envoy filter: configPatches[0] matches nothing in the reference configuration\n\n")
+
+	referenceDir := createTestDirectory(t, map[string]string{"httpbin.yaml": `
+apiVersion: networking.istio.io/v1
+kind: ServiceEntry
+metadata:
+  name: httpbin
+spec:
+  hosts: [httpbin.example.com]
+  ports:
+  - number: 80
+    name: http
+    protocol: HTTP
+  resolution: DNS
+`})
+	t.Cleanup(func() { os.RemoveAll(referenceDir) })
+	v.envoyFilterReference, err = readReferenceConfig([]string{referenceDir})
+	assert.NoError(t, err)
+	assert.Equal(t, len(v.envoyFilterReference), 1)
+	assert.NoError(t, v.verifyEnvoyFilterMatch(ef))
+}
@@ -24,6 +24,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	authenticationv1 "k8s.io/api/authentication/v1"
@@ -52,6 +53,7 @@ import (
 	netutil "istio.io/istio/pkg/util/net"
 	"istio.io/istio/pkg/util/protomarshal"
 	"istio.io/istio/pkg/util/shellescape"
+	"istio.io/istio/security/pkg/server/ca/authenticate/attestation"
 )
 
 var (
@@ -68,6 +70,7 @@ var (
 	ingressSvc     string
 	autoRegister   bool
 	dnsCapture     bool
+	joinToken      bool
 	ports          []string
 	resourceLabels []string
 	annotations    []string
@@ -80,6 +83,8 @@ var (
 const (
 	istioEastWestGatewayServiceName = "istio-eastwestgateway"
 	filePerms                       = os.FileMode(0o744)
+	// vmCertDir is where the agent on the VM keeps its certificates, when enrolled with a join token.
+	vmCertDir = "/etc/certs"
 )
 
 func Cmd(ctx cli.Context) *cobra.Command {
@@ -211,7 +216,10 @@ Configure requires either the WorkloadGroup artifact path or its location on the
   istioctl x workload entry configure -f workloadgroup.yaml -o config
 
   # configure example using the API server
-  istioctl x workload entry configure --name foo --namespace bar -o config`,
+  istioctl x workload entry configure --name foo --namespace bar -o config
+
+  # configure example with a one-time join token, for VMs without access to Kubernetes tokens
+  istioctl x workload entry configure --name foo --namespace bar --join-token -o config`,
 		Args: func(cmd *cobra.Command, args []string) error {
 			if filename == "" && (name == "" || namespace == "") {
 				return fmt.Errorf("expecting a WorkloadGroup artifact file or the name and namespace of an existing WorkloadGroup")
@@ -279,6 +287,8 @@ Configure requires either the WorkloadGroup artifact path or its location on the
 	configureCmd.PersistentFlags().BoolVar(&dnsCapture, "capture-dns", true, "Enables the capture of outgoing DNS packets on port 53, redirecting to istio-agent")
 	configureCmd.PersistentFlags().StringVar(&internalIP, "internalIP", "", "Internal IP address of the workload")
 	configureCmd.PersistentFlags().StringVar(&externalIP, "externalIP", "", "External IP address of the workload")
+	configureCmd.PersistentFlags().BoolVar(&joinToken, "join-token", false, "Mints a one-time join token, valid for --tokenDuration, "+
+		"instead of a Kubernetes token. Requires ENABLE_CA_JOIN_TOKENS on istiod.")
 	opts.AttachControlPlaneFlags(configureCmd)
 	return configureCmd
 }
@@ -325,7 +335,7 @@ func createConfig(kubeClient kube.CLIClient, wg *clientnetworking.WorkloadGroup,
 	if err := createClusterEnv(wg, proxyConfig, istioNamespace, revision, internalIP, externalIP, outputDir); err != nil {
 		return err
 	}
-	if err := createCertsTokens(kubeClient, wg, outputDir, out); err != nil {
+	if err := createCertsTokens(kubeClient, wg, istioNamespace, outputDir, out); err != nil {
 		return err
 	}
 	if err := createHosts(kubeClient, istioNamespace, ingressIP, outputDir, revision); err != nil {
@@ -373,6 +383,12 @@ func createClusterEnv(wg *clientnetworking.WorkloadGroup, config *meshconfig.Pro
 	if isRevisioned(revision) {
 		overrides["CA_ADDR"] = IstiodAddr(istioNamespace, revision)
 	}
+	if joinToken {
+		// A join token only authenticates the first certificate request. The certificate is written to, and
+		// provisioned from, the same directory so XDS and certificate renewals authenticate with it.
+		overrides["PROV_CERT"] = vmCertDir
+		overrides["OUTPUT_CERTS"] = vmCertDir
+	}
 	if len(internalIP) > 0 {
 		overrides["ISTIO_SVC_IP"] = internalIP
 	} else if len(externalIP) > 0 {
@@ -393,9 +409,10 @@ func createClusterEnv(wg *clientnetworking.WorkloadGroup, config *meshconfig.Pro
 }
 
 // Get and store the needed certificate and token. The certificate comes from the CA root cert, and
-// the token is generated by kubectl under the workload group's namespace and service account
+// the token is generated by kubectl under the workload group's namespace and service account, or is a
+// one-time join token if requested.
 // TODO: Make the following accurate when using the Kubernetes certificate signer
-func createCertsTokens(kubeClient kube.CLIClient, wg *clientnetworking.WorkloadGroup, dir string, out io.Writer) error {
+func createCertsTokens(kubeClient kube.CLIClient, wg *clientnetworking.WorkloadGroup, istioNamespace, dir string, out io.Writer) error {
 	rootCert, err := kubeClient.Kube().CoreV1().ConfigMaps(wg.Namespace).Get(context.Background(), controller.CACertNamespaceConfigMap, metav1.GetOptions{})
 	// errors if the requested configmap does not exist in the given namespace
 	if err != nil {
@@ -407,6 +424,9 @@ func createCertsTokens(kubeClient kube.CLIClient, wg *clientnetworking.WorkloadG
 
 	serviceAccount := wg.Spec.Template.ServiceAccount
 	tokenPath := filepath.Join(dir, "istio-token")
+	if joinToken {
+		return createJoinToken(kubeClient, wg, istioNamespace, tokenPath, out)
+	}
 	token := &authenticationv1.TokenRequest{
 		// ObjectMeta isn't required in real k8s, but needed for tests
 		ObjectMeta: metav1.ObjectMeta{
@@ -431,6 +451,28 @@ func createCertsTokens(kubeClient kube.CLIClient, wg *clientnetworking.WorkloadG
 	return nil
 }
 
+// createJoinToken mints a one-time join token for the workload group's namespace and service account,
+// and stores it at tokenPath. The token is enabled by a Secret in the istiod namespace, deleted once used.
+func createJoinToken(kubeClient kube.CLIClient, wg *clientnetworking.WorkloadGroup, istioNamespace, tokenPath string, out io.Writer) error {
+	serviceAccount := wg.Spec.Template.ServiceAccount
+	expiration := time.Now().Add(time.Duration(tokenDuration) * time.Second)
+	token, secret, err := attestation.NewJoinToken(istioNamespace,
+		attestation.Identity{Namespace: wg.Namespace, ServiceAccount: serviceAccount}, expiration)
+	if err != nil {
+		return err
+	}
+	if _, err := kubeClient.Kube().CoreV1().Secrets(istioNamespace).Create(context.Background(), secret, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("could not create join token secret in namespace %s: %v", istioNamespace, err)
+	}
+	if err := os.WriteFile(tokenPath, []byte(token), filePerms); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Warning: a one-time join token for namespace %q and service account %q, valid until %s, has been generated and "+
+		"stored at %q. Once enrolled, the workload authenticates with its certificate in %s\n",
+		wg.Namespace, serviceAccount, expiration.Format(time.RFC3339), tokenPath, vmCertDir)
+	return nil
+}
+
 func createMeshConfig(kubeClient kube.CLIClient, wg *clientnetworking.WorkloadGroup, istioNamespace, clusterID, dir,
 	revision string,
 ) (*meshconfig.ProxyConfig, error) {
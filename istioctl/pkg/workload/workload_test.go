@@ -33,6 +33,7 @@ import (
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/kube"
 	"istio.io/istio/pkg/test/util/assert"
+	"istio.io/istio/security/pkg/server/ca/authenticate/attestation"
 )
 
 var fakeCACert = []byte("fake-CA-cert")
@@ -296,6 +297,62 @@ func TestWorkloadEntryConfigure(t *testing.T) {
 	}
 }
 
+func TestWorkloadEntryConfigureJoinToken(t *testing.T) {
+	testdir := t.TempDir()
+	var client kube.CLIClient
+	createClientFunc := func(c kube.CLIClient) {
+		client = c
+		c.Kube().CoreV1().ConfigMaps("bar").Create(context.Background(), &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "bar", Name: "istio-ca-root-cert"},
+			Data:       map[string]string{"root-cert.pem": string(fakeCACert)},
+		}, metav1.CreateOptions{})
+		c.Kube().CoreV1().ConfigMaps("istio-system").Create(context.Background(), &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "istio-system", Name: "istio-rev-1"},
+			Data: map[string]string{
+				"mesh": string(util.ReadFile(t, "testdata/vmconfig/ipv4/meshconfig.yaml")),
+			},
+		}, metav1.CreateOptions{})
+	}
+	args := []string{
+		"entry", "configure",
+		"-f", "testdata/vmconfig/ipv4/workloadgroup.yaml",
+		"--internalIP", "10.10.10.10",
+		"--ingressIP", "10.10.10.11",
+		"--clusterID", constants.DefaultClusterName,
+		"--revision", "rev-1",
+		"--join-token",
+		"-o", testdir,
+	}
+	if output, err := runTestCmd(t, createClientFunc, "rev-1", args); err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+
+	// After enrollment, the agent authenticates with its certificate.
+	clusterEnv := string(util.ReadFile(t, path.Join(testdir, "cluster.env")))
+	for _, want := range []string{"PROV_CERT='/etc/certs'", "OUTPUT_CERTS='/etc/certs'"} {
+		if !strings.Contains(clusterEnv, want) {
+			t.Fatalf("expected %s in cluster.env:\n%s", want, clusterEnv)
+		}
+	}
+
+	// The token is accepted by istiod until it is consumed.
+	token := strings.TrimPrefix(string(util.ReadFile(t, path.Join(testdir, "istio-token"))), attestation.JoinTokenType+":")
+	plugin := attestation.NewJoinTokens(client.Kube(), "istio-system")
+	id, err := plugin.Attest(context.Background(), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Namespace != "bar" || id.ServiceAccount != "vm-serviceaccount" {
+		t.Fatalf("unexpected identity %+v", id)
+	}
+	if err := id.Consume(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plugin.Attest(context.Background(), token); err == nil {
+		t.Fatalf("expected the join token to be single use")
+	}
+}
+
 func TestWorkloadEntryToPodPortsMeta(t *testing.T) {
 	cases := []struct {
 		description string
@@ -30,6 +30,7 @@ import (
 	"istio.io/istio/istioctl/pkg/install/k8sversion"
 	"istio.io/istio/istioctl/pkg/util"
 	"istio.io/istio/operator/pkg/install"
+	"istio.io/istio/operator/pkg/manifest"
 	"istio.io/istio/operator/pkg/render"
 	"istio.io/istio/operator/pkg/util/clog"
 	"istio.io/istio/operator/pkg/util/progress"
@@ -61,6 +62,18 @@ type InstallArgs struct {
 	ManifestsPath string
 	// Revision is the Istio control plane revision the command targets.
 	Revision string
+	// PostRenderer is the path to an executable modifying the rendered manifests.
+	PostRenderer string
+	// PostRendererArgs are the arguments of the post renderer.
+	PostRendererArgs []string
+	// ImageRewrites are the "prefix=replacement" rewrites of the image references.
+	ImageRewrites []string
+	// ImageLockFile is the path to a file pinning images to their digest.
+	ImageLockFile string
+	// ImageOCILayout is the path to an OCI image layout pinning images to their digest.
+	ImageOCILayout string
+	// RecordState records the applied manifests in the cluster, for later dry runs to detect drift.
+	RecordState bool
 }
 
 func (a *InstallArgs) String() string {
@@ -73,6 +86,8 @@ func (a *InstallArgs) String() string {
 	b.WriteString("Set:              " + fmt.Sprint(a.Set) + "\n")
 	b.WriteString("ManifestsPath:    " + a.ManifestsPath + "\n")
 	b.WriteString("Revision:         " + a.Revision + "\n")
+	b.WriteString("PostRenderer:     " + a.PostRenderer + "\n")
+	b.WriteString("ImageRewrites:    " + fmt.Sprint(a.ImageRewrites) + "\n")
 	return b.String()
 }
 
@@ -87,6 +102,12 @@ func addInstallFlags(cmd *cobra.Command, args *InstallArgs) {
 	cmd.PersistentFlags().StringVarP(&args.ManifestsPath, "charts", "", "", ChartsDeprecatedStr)
 	cmd.PersistentFlags().StringVarP(&args.ManifestsPath, "manifests", "d", "", ManifestsFlagHelpStr)
 	cmd.PersistentFlags().StringVarP(&args.Revision, "revision", "r", "", revisionFlagHelpStr)
+	cmd.PersistentFlags().StringVar(&args.PostRenderer, "post-renderer", "", postRendererFlagHelpStr)
+	cmd.PersistentFlags().StringArrayVar(&args.PostRendererArgs, "post-renderer-args", nil, postRendererArgsFlagHelpStr)
+	cmd.PersistentFlags().StringArrayVar(&args.ImageRewrites, "image-rewrite", nil, imageRewriteFlagHelpStr)
+	cmd.PersistentFlags().StringVar(&args.ImageLockFile, "image-lock-file", "", imageLockFileFlagHelpStr)
+	cmd.PersistentFlags().StringVar(&args.ImageOCILayout, "image-oci-layout", "", imageOCILayoutFlagHelpStr)
+	cmd.PersistentFlags().BoolVar(&args.RecordState, "record-state", false, recordStateFlagHelpStr)
 }
 
 // InstallCmdWithArgs generates an Istio install manifest and applies it to a cluster
@@ -157,8 +178,20 @@ func Install(kubeClient kube.CLIClient, rootArgs *RootArgs, iArgs *InstallArgs,
 	}
 
 	setFlags := applyFlagAliases(iArgs.Set, iArgs.ManifestsPath, iArgs.Revision)
+	images, err := imageOptions(iArgs.ImageRewrites, iArgs.ImageLockFile, iArgs.ImageOCILayout)
+	if err != nil {
+		return err
+	}
 
-	manifests, vals, err := render.GenerateManifest(iArgs.InFilenames, setFlags, iArgs.Force, kubeClient, l)
+	manifests, vals, err := render.Render(render.Options{
+		Files:         iArgs.InFilenames,
+		SetFlags:      setFlags,
+		Force:         iArgs.Force,
+		Client:        kubeClient,
+		Logger:        l,
+		PostRenderers: postRenderers(iArgs.PostRenderer, iArgs.PostRendererArgs),
+		Images:        images,
+	})
 	if err != nil {
 		return fmt.Errorf("generate config: %v", err)
 	}
@@ -170,15 +203,6 @@ func Install(kubeClient kube.CLIClient, rootArgs *RootArgs, iArgs *InstallArgs,
 	// Print information about version changing
 	detectIstioVersionDiff(p, tag, namespace, kubeClient, revision)
 
-	// Install is mutating state in the cluster; give users a confirmation to ensure they want this.
-	if !rootArgs.DryRun && !iArgs.SkipConfirmation {
-		prompt := fmt.Sprintf("This will install the Istio %s profile %q into the cluster. Proceed? (y/N)", tag, profile)
-		if !Confirm(prompt, stdOut) {
-			p.Println("Cancelled.")
-			os.Exit(1)
-		}
-	}
-
 	i := install.Installer{
 		Force:          iArgs.Force,
 		DryRun:         rootArgs.DryRun,
@@ -188,7 +212,21 @@ func Install(kubeClient kube.CLIClient, rootArgs *RootArgs, iArgs *InstallArgs,
 		Logger:         l,
 		Values:         vals,
 		ProgressLogger: progress.NewLog(),
+		RecordState:    iArgs.RecordState,
+	}
+	if rootArgs.DryRunMode != "" {
+		return printInstallPlan(i, manifests, rootArgs.DryRunMode == DryRunVerify, stdOut)
+	}
+
+	// Install is mutating state in the cluster; give users a confirmation to ensure they want this.
+	if !rootArgs.DryRun && !iArgs.SkipConfirmation {
+		prompt := fmt.Sprintf("This will install the Istio %s profile %q into the cluster. Proceed? (y/N)", tag, profile)
+		if !Confirm(prompt, stdOut) {
+			p.Println("Cancelled.")
+			os.Exit(1)
+		}
 	}
+
 	if err := i.InstallManifests(manifests); err != nil {
 		return fmt.Errorf("failed to install manifests: %v", err)
 	}
@@ -200,6 +238,20 @@ func Install(kubeClient kube.CLIClient, rootArgs *RootArgs, iArgs *InstallArgs,
 	return nil
 }
 
+// printInstallPlan prints the changes installing the manifests makes to the cluster. If verify is set, it fails if
+// there are any.
+func printInstallPlan(i install.Installer, manifests []manifest.ManifestSet, verify bool, stdOut io.Writer) error {
+	plan, err := i.Plan(manifests)
+	if err != nil {
+		return fmt.Errorf("failed to compute the install plan: %v", err)
+	}
+	plan.Write(stdOut)
+	if verify && !plan.Empty() {
+		return fmt.Errorf("the cluster differs from the rendered manifests: %s", plan.Summary())
+	}
+	return nil
+}
+
 // detectIstioVersionDiff will show warning if istioctl version and control plane version are different
 // nolint: interfacer
 func detectIstioVersionDiff(p Printer, tag string, ns string, kubeClient kube.CLIClient, revision string) {
@@ -0,0 +1,258 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mesh
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/spf13/cobra"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/istio/operator/pkg/component"
+	"istio.io/istio/operator/pkg/install"
+	"istio.io/istio/operator/pkg/manifest"
+	"istio.io/istio/operator/pkg/render"
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/operator/pkg/util/progress"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/slices"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestInstallPlan(t *testing.T) {
+	c := setupInstallPlanClient()
+	installer := func(setFlags ...string) (install.Installer, []manifest.ManifestSet) {
+		t.Helper()
+		manifests, values, err := render.GenerateManifest([]string{inFileAbsolutePath("minimal")},
+			append([]string{"installPackagePath=" + string(liveCharts)}, setFlags...), false, c, nil)
+		assert.NoError(t, err)
+		return install.Installer{
+			SkipWait:       true,
+			Kube:           c,
+			Logger:         clog.NewDefaultLogger(),
+			Values:         values,
+			ProgressLogger: progress.NewLog(),
+			RecordState:    true,
+		}, manifests
+	}
+	plan := func(setFlags ...string) *install.Plan {
+		t.Helper()
+		i, manifests := installer(setFlags...)
+		p, err := i.Plan(manifests)
+		assert.NoError(t, err)
+		return p
+	}
+
+	// Nothing is installed yet.
+	p := plan()
+	assert.Equal(t, p.HasState, false)
+	if len(p.Changes) == 0 || len(p.Drifted()) != 0 {
+		t.Fatalf("expected only creations, got %v", p.Summary())
+	}
+	for _, ch := range p.Changes {
+		assert.Equal(t, ch.Action, install.ActionCreate)
+	}
+
+	// The state is only recorded when asked.
+	i, manifests := installer()
+	i.RecordState = false
+	assert.NoError(t, i.InstallManifests(manifests))
+	p = plan()
+	assert.Equal(t, p.HasState, false)
+	assert.Equal(t, p.Empty(), true)
+
+	i, manifests = installer()
+	assert.NoError(t, i.InstallManifests(manifests))
+	p = plan()
+	assert.Equal(t, p.HasState, true)
+	assert.Equal(t, p.Empty(), true)
+
+	// Changes made outside of the installer are reported as drift.
+	istiod := getIstiod(t, c)
+	assert.NoError(t, unstructured.SetNestedField(istiod.Object, "drifted", "spec", "template", "spec", "serviceAccountName"))
+	dc, err := c.DynamicClientFor(gvk.Deployment.Kubernetes(), istiod, "")
+	assert.NoError(t, err)
+	_, err = dc.Update(context.Background(), istiod, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	p = plan()
+	assert.Equal(t, p.Summary(), "0 to create, 1 to update, 0 to prune, 1 changed outside of the installer")
+	assert.Equal(t, p.Changes[0].Fields, []install.FieldChange{{
+		Path:    "spec.template.spec.serviceAccountName",
+		Live:    "drifted",
+		Desired: "istiod",
+		Drifted: true,
+	}})
+
+	// Changes of the rendered manifests are not drift.
+	p = plan("values.pilot.traceSampling=5")
+	var out bytes.Buffer
+	p.Write(&out)
+	deployment := slices.FindFunc(p.Changes, func(c install.ObjectChange) bool {
+		return c.Kind == gvk.Deployment.Kind
+	})
+	assert.Equal(t, deployment != nil, true)
+	fields := deployment.Fields
+	assert.Equal(t, len(fields), 2)
+	assert.Equal(t, fields[0].Desired, "5")
+	assert.Equal(t, fields[0].Drifted, false)
+	assert.Equal(t, fields[1].Drifted, true)
+	if !strings.Contains(out.String(), `spec.template.spec.serviceAccountName: "drifted" -> "istiod" (changed outside of the installer)`) {
+		t.Fatalf("unexpected plan:\n%s", out.String())
+	}
+
+	// Disabled components are pruned.
+	p = plan("components.pilot.enabled=false")
+	for _, ch := range p.Changes {
+		assert.Equal(t, ch.Action, install.ActionPrune)
+		assert.Equal(t, ch.Component, component.PilotComponentName)
+	}
+	if len(p.Changes) == 0 {
+		t.Fatal("expected istiod to be pruned")
+	}
+}
+
+// installPlanClient is a fake client whose dry run patches return the patched object without persisting it, like the
+// API server does, which the shared fake client ignores.
+type installPlanClient struct {
+	kube.CLIClient
+}
+
+func (c installPlanClient) DynamicClientFor(g schema.GroupVersionKind, obj *unstructured.Unstructured, namespace string) (dynamic.ResourceInterface, error) {
+	dc, err := c.CLIClient.DynamicClientFor(g, obj, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return dryRunResource{dc}, nil
+}
+
+type dryRunResource struct {
+	dynamic.ResourceInterface
+}
+
+// Patch restores the object as it was before the patch when the patch is a dry run.
+func (r dryRunResource) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions,
+	subresources ...string,
+) (*unstructured.Unstructured, error) {
+	if len(opts.DryRun) == 0 {
+		return r.ResourceInterface.Patch(ctx, name, pt, data, opts, subresources...)
+	}
+	old, err := r.Get(ctx, name, metav1.GetOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return nil, err
+	}
+	existed := err == nil
+	res, err := r.ResourceInterface.Patch(ctx, name, pt, data, opts, subresources...)
+	if !existed {
+		_ = r.Delete(ctx, name, metav1.DeleteOptions{})
+	} else {
+		_, _ = r.Update(ctx, old, metav1.UpdateOptions{})
+	}
+	return res, err
+}
+
+// setupInstallPlanClient returns the shared fake client, with dry run patches, and with apply patches of existing
+// objects approximated by merge patches, as the fake client cannot apply to unstructured objects.
+func setupInstallPlanClient() kube.CLIClient {
+	c := SetupFakeClient()
+	df := c.Dynamic().(*dynamicfake.FakeDynamicClient)
+	df.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patch := action.(clienttesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		existing, err := df.Tracker().Get(patch.GetResource(), patch.GetNamespace(), patch.GetName())
+		if kerrors.IsNotFound(err) {
+			// Created by the reactor of the shared fake client.
+			return false, nil, nil
+		}
+		if err != nil {
+			return true, nil, err
+		}
+		current, err := json.Marshal(existing)
+		if err != nil {
+			return true, nil, err
+		}
+		applied, err := yaml.YAMLToJSON(patch.GetPatch())
+		if err != nil {
+			return true, nil, err
+		}
+		merged, err := jsonpatch.MergePatch(current, applied)
+		if err != nil {
+			return true, nil, err
+		}
+		us := &unstructured.Unstructured{}
+		if err := us.UnmarshalJSON(merged); err != nil {
+			return true, nil, err
+		}
+		if err := df.Tracker().Update(patch.GetResource(), us, patch.GetNamespace()); err != nil {
+			return true, nil, err
+		}
+		return true, us, nil
+	})
+	return installPlanClient{c}
+}
+
+func getIstiod(t *testing.T, c kube.CLIClient) *unstructured.Unstructured {
+	t.Helper()
+	dc, err := c.DynamicClientFor(gvk.Deployment.Kubernetes(), nil, "istio-system")
+	assert.NoError(t, err)
+	istiod, err := dc.Get(context.Background(), "istiod", metav1.GetOptions{})
+	assert.NoError(t, err)
+	return istiod
+}
+
+func TestDryRunFlag(t *testing.T) {
+	cases := []struct {
+		args []string
+		want RootArgs
+		err  bool
+	}{
+		{args: nil, want: RootArgs{}},
+		{args: []string{"--dry-run"}, want: RootArgs{DryRun: true}},
+		{args: []string{"--dry-run=false"}, want: RootArgs{}},
+		{args: []string{"--dry-run=diff"}, want: RootArgs{DryRun: true, DryRunMode: DryRunDiff}},
+		{args: []string{"--dry-run=verify"}, want: RootArgs{DryRun: true, DryRunMode: DryRunVerify}},
+		{args: []string{"--dry-run=everything"}, err: true},
+	}
+	for _, tt := range cases {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			args := &RootArgs{}
+			cmd := &cobra.Command{}
+			addFlags(cmd, args)
+			err := cmd.ParseFlags(tt.args)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, *args, tt.want)
+		})
+	}
+}
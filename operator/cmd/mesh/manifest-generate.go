@@ -49,6 +49,16 @@ type ManifestGenerateArgs struct {
 	Revision string
 	// Filter is the list of components to render
 	Filter []string
+	// PostRenderer is the path to an executable modifying the rendered manifests.
+	PostRenderer string
+	// PostRendererArgs are the arguments of the post renderer.
+	PostRendererArgs []string
+	// ImageRewrites are the "prefix=replacement" rewrites of the image references.
+	ImageRewrites []string
+	// ImageLockFile is the path to a file pinning images to their digest.
+	ImageLockFile string
+	// ImageOCILayout is the path to an OCI image layout pinning images to their digest.
+	ImageOCILayout string
 }
 
 var kubeClientFunc func() (kube.CLIClient, error)
@@ -60,6 +70,8 @@ func (a *ManifestGenerateArgs) String() string {
 	b.WriteString("Force:         " + fmt.Sprint(a.Force) + "\n")
 	b.WriteString("ManifestsPath: " + a.ManifestsPath + "\n")
 	b.WriteString("Revision:      " + a.Revision + "\n")
+	b.WriteString("PostRenderer:  " + a.PostRenderer + "\n")
+	b.WriteString("ImageRewrites: " + fmt.Sprint(a.ImageRewrites) + "\n")
 	return b.String()
 }
 
@@ -72,6 +84,11 @@ func addManifestGenerateFlags(cmd *cobra.Command, args *ManifestGenerateArgs) {
 	cmd.PersistentFlags().StringVarP(&args.Revision, "revision", "r", "", revisionFlagHelpStr)
 	cmd.PersistentFlags().StringSliceVar(&args.Filter, "filter", nil, "")
 	_ = cmd.PersistentFlags().MarkHidden("filter")
+	cmd.PersistentFlags().StringVar(&args.PostRenderer, "post-renderer", "", postRendererFlagHelpStr)
+	cmd.PersistentFlags().StringArrayVar(&args.PostRendererArgs, "post-renderer-args", nil, postRendererArgsFlagHelpStr)
+	cmd.PersistentFlags().StringArrayVar(&args.ImageRewrites, "image-rewrite", nil, imageRewriteFlagHelpStr)
+	cmd.PersistentFlags().StringVar(&args.ImageLockFile, "image-lock-file", "", imageLockFileFlagHelpStr)
+	cmd.PersistentFlags().StringVar(&args.ImageOCILayout, "image-oci-layout", "", imageOCILayoutFlagHelpStr)
 
 	cmd.PersistentFlags().BoolVar(&args.EnableClusterSpecific, "cluster-specific", false,
 		"If enabled, the current cluster will be checked for cluster-specific setting detection.")
@@ -126,7 +143,18 @@ const (
 
 func ManifestGenerate(kubeClient kube.CLIClient, mgArgs *ManifestGenerateArgs, l clog.Logger) error {
 	setFlags := applyFlagAliases(mgArgs.Set, mgArgs.ManifestsPath, mgArgs.Revision)
-	manifests, _, err := render.GenerateManifest(mgArgs.InFilenames, setFlags, mgArgs.Force, kubeClient, nil)
+	images, err := imageOptions(mgArgs.ImageRewrites, mgArgs.ImageLockFile, mgArgs.ImageOCILayout)
+	if err != nil {
+		return err
+	}
+	manifests, _, err := render.Render(render.Options{
+		Files:         mgArgs.InFilenames,
+		SetFlags:      setFlags,
+		Force:         mgArgs.Force,
+		Client:        kubeClient,
+		PostRenderers: postRenderers(mgArgs.PostRenderer, mgArgs.PostRendererArgs),
+		Images:        images,
+	})
 	if err != nil {
 		return err
 	}
@@ -535,6 +535,35 @@ func TestPrune(t *testing.T) {
 	}
 }
 
+func TestManifestGeneratePostRenderer(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "post-render.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nsed \"s/^  name: istiod$/  name: istiod-$1-$ISTIO_COMPONENT/\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	m := generateManifest(t, "minimal", "--post-renderer "+script+" --post-renderer-args org", liveCharts, nil)
+	if parseObjectSetFromManifest(t, m).kind(gvk.Deployment.Kind).nameEquals("istiod-org-Pilot") == nil {
+		t.Fatalf("expected the post renderer to rename istiod, got:\n%s", m)
+	}
+}
+
+func TestManifestGenerateImages(t *testing.T) {
+	g := NewWithT(t)
+	lock := filepath.Join(t.TempDir(), "images.lock")
+	digest := "sha256:" + strings.Repeat("a", 64)
+	if err := os.WriteFile(lock, []byte("images:\n  registry.example.com/istio/pilot:bar: "+digest+
+		"\n  registry.example.com/istio/proxyv2:bar: "+digest+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := generateManifest(t, "minimal", "-s hub=docker.io/istio -s tag=bar --image-rewrite docker.io/istio=registry.example.com/istio "+
+		"--image-lock-file "+lock, liveCharts, []string{"templates/deployment.yaml"})
+	dobj := mustGetDeployment(g, parseObjectSetFromManifest(t, m), "istiod")
+	c := getContainer(dobj, "discovery")
+	g.Expect(c).Should(HavePathValueEqual(PathValue{"image", "registry.example.com/istio/pilot:bar@" + digest}))
+
+	_, err := runManifestGenerate([]string{inFileAbsolutePath("minimal")}, "--image-rewrite docker.io/istio", liveCharts, nil)
+	g.Expect(err).Should(HaveOccurred())
+}
+
 func TestManifestGenerateAllOff(t *testing.T) {
 	g := NewWithT(t)
 	m := generateManifest(t, "all_off", "", liveCharts, nil)
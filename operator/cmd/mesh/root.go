@@ -15,6 +15,9 @@
 package mesh
 
 import (
+	"fmt"
+	"strconv"
+
 	"github.com/spf13/cobra"
 
 	binversion "istio.io/istio/operator/version"
@@ -41,14 +44,69 @@ If set to true, the user is not prompted and a Yes response is assumed in all ca
 This flag can be specified multiple times to overlay multiple files. Multiple files are overlaid in left to right order.`
 	ForceFlagHelpStr       = `Proceed even with validation errors.`
 	VerifyCRInstallHelpStr = "Verify the Istio control plane after installation/in-place upgrade"
+
+	postRendererFlagHelpStr = `Path to an executable modifying the rendered manifests. It is run once per component, with the
+manifests of the component as multi-document YAML on its standard input and the ISTIO_COMPONENT environment variable
+set, and must write the modified manifests to its standard output.`
+	postRendererArgsFlagHelpStr = `An argument to the post renderer. This flag can be specified multiple times.`
+
+	imageRewriteFlagHelpStr = `Rewrite the images starting with a prefix, in the form prefix=replacement, e.g.
+docker.io/istio=registry.example.com/istio. It applies to all components and injected proxies. This flag can be
+specified multiple times; the longest matching prefix applies.`
+	imageLockFileFlagHelpStr = `Path to an image lock file mapping image references to their digest, e.g.
+"images: {docker.io/istio/proxyv2:1.28.0: sha256:...}". Images are pinned to their digest, and images missing from
+the file are an error.`
+	imageOCILayoutFlagHelpStr = `Path to an OCI image layout directory holding the images of the install. Images are
+pinned to the digest of the image with the same reference in the layout, and images missing from it are an error.`
+	recordStateFlagHelpStr = `Record the applied manifests in a ConfigMap of the Istio namespace, so that later runs with --dry-run=diff
+tell the changes made to the installed resources outside of istioctl apart from the changes of the manifests.`
+)
+
+const (
+	// DryRunDiff prints the changes the command makes to the cluster.
+	DryRunDiff = "diff"
+	// DryRunVerify prints the changes the command makes to the cluster, and fails if there are any.
+	DryRunVerify = "verify"
 )
 
 type RootArgs struct {
 	// DryRun performs all steps except actually applying the manifests or creating output dirs/files.
 	DryRun bool
+	// DryRunMode is DryRunDiff or DryRunVerify if the dry run prints the changes to the cluster, empty otherwise.
+	DryRunMode string
+}
+
+// dryRunFlag is the value of --dry-run, which is either a boolean or a dry run mode.
+type dryRunFlag struct {
+	args *RootArgs
+}
+
+func (f dryRunFlag) String() string {
+	if f.args.DryRunMode != "" {
+		return f.args.DryRunMode
+	}
+	return strconv.FormatBool(f.args.DryRun)
+}
+
+func (f dryRunFlag) Set(s string) error {
+	if s == DryRunDiff || s == DryRunVerify {
+		f.args.DryRun, f.args.DryRunMode = true, s
+		return nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return fmt.Errorf("must be true, false, %s or %s", DryRunDiff, DryRunVerify)
+	}
+	f.args.DryRun, f.args.DryRunMode = b, ""
+	return nil
+}
+
+func (f dryRunFlag) Type() string {
+	return "string"
 }
 
 func addFlags(cmd *cobra.Command, rootArgs *RootArgs) {
-	cmd.PersistentFlags().BoolVarP(&rootArgs.DryRun, "dry-run", "",
-		false, "Console/log output only, make no changes.")
+	cmd.PersistentFlags().VarPF(dryRunFlag{rootArgs}, "dry-run", "",
+		"Console/log output only, make no changes. Set to diff to print the changes install would make to the cluster, "+
+			"grouped by component, or to verify to also fail if there are any.").NoOptDefVal = "true"
 }
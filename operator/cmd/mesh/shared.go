@@ -20,6 +20,7 @@ import (
 	"io"
 	"strings"
 
+	"istio.io/istio/operator/pkg/render"
 	"istio.io/istio/pkg/log"
 )
 
@@ -76,3 +77,37 @@ func applyFlagAliases(flags []string, manifestsPath, revision string) []string {
 	}
 	return flags
 }
+
+// postRenderers returns the post renderers selected with --post-renderer.
+func postRenderers(path string, args []string) []render.PostRenderer {
+	if path == "" {
+		return nil
+	}
+	return []render.PostRenderer{render.ExecPostRenderer(path, args...)}
+}
+
+// imageOptions returns the image rewrites and digests selected with --image-rewrite, --image-lock-file and
+// --image-oci-layout.
+func imageOptions(rewrites []string, lockFile, ociLayout string) (render.Images, error) {
+	images := render.Images{}
+	for _, rw := range rewrites {
+		from, to, ok := strings.Cut(rw, "=")
+		if !ok || from == "" || to == "" {
+			return images, fmt.Errorf("invalid image rewrite %q, expected prefix=replacement", rw)
+		}
+		if images.Rewrites == nil {
+			images.Rewrites = map[string]string{}
+		}
+		images.Rewrites[from] = to
+	}
+	if lockFile != "" && ociLayout != "" {
+		return images, fmt.Errorf("only one of --image-lock-file and --image-oci-layout can be set")
+	}
+	var err error
+	if lockFile != "" {
+		images.Digests, err = render.ReadImageLock(lockFile)
+	} else if ociLayout != "" {
+		images.Digests, err = render.ReadOCILayout(ociLayout)
+	}
+	return images, err
+}
@@ -112,6 +112,9 @@ func UninstallCmd(ctx cli.Context) *cobra.Command {
 // runUninstall uninstalls control plane by either pruning by target revision or deleting specified manifests.
 func runUninstall(cmd *cobra.Command, ctx cli.Context, rootArgs *RootArgs, uiArgs *uninstallArgs) error {
 	l := clog.NewConsoleLogger(cmd.OutOrStdout(), cmd.ErrOrStderr(), installerScope)
+	if rootArgs.DryRunMode != "" {
+		return fmt.Errorf("--dry-run=%s is not supported by uninstall", rootArgs.DryRunMode)
+	}
 
 	var kubeClient kube.CLIClient
 	var err error
@@ -22,6 +22,7 @@ import (
 
 	"github.com/hashicorp/go-multierror"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	klabels "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
@@ -53,6 +54,9 @@ type Installer struct {
 	WaitTimeout    time.Duration
 	Logger         clog.Logger
 	ProgressLogger *progress.Log
+	// RecordState records the applied manifests in the Istio namespace, so that later dry runs detect the changes
+	// made outside of the installer.
+	RecordState bool
 }
 
 // InstallManifests applies a set of rendered manifests to the cluster.
@@ -90,6 +94,11 @@ func (i Installer) InstallManifests(manifests []manifest.ManifestSet) error {
 		}
 	}
 
+	if i.RecordState && !i.DryRun {
+		if err := i.writeState(manifests); err != nil {
+			i.Logger.LogAndErrorf("failed to record the install state, the next dry run cannot detect changes made outside of the installer: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -192,8 +201,10 @@ func (i Installer) applyManifestSet(manifestSet manifest.ManifestSet) error {
 }
 
 // serverSideApply creates or updates an object in the API server depending on whether it already exists.
+// fieldOwnerOperator is the field manager of the server-side applies of the installer.
+const fieldOwnerOperator = "istio-operator"
+
 func (i Installer) serverSideApply(obj manifest.Manifest) error {
-	const fieldOwnerOperator = "istio-operator"
 	dc, err := i.Kube.DynamicClientFor(obj.GroupVersionKind(), obj.Unstructured, "")
 	if err != nil {
 		return err
@@ -232,6 +243,27 @@ func (i Installer) prune(manifests []manifest.ManifestSet) error {
 	}
 	i.ProgressLogger.SetState(progress.StatePruning)
 
+	pruned, err := i.prunedObjects(manifests)
+	if err != nil {
+		return err
+	}
+	var errs util.Errors
+	for _, p := range pruned {
+		if err := uninstall.DeleteResource(i.Kube, i.DryRun, i.Logger, p.object); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs.ToError()
+}
+
+// prunedObject is an object in the cluster that is not a part of the installed set of objects.
+type prunedObject struct {
+	component component.Name
+	object    *unstructured.Unstructured
+}
+
+// prunedObjects returns the objects prune removes, in the order they should be removed.
+func (i Installer) prunedObjects(manifests []manifest.ManifestSet) ([]prunedObject, error) {
 	// Build up a map of component->resources, so we know what to keep around
 	excluded := map[component.Name]sets.String{}
 	// Include all components in case we disabled some.
@@ -248,21 +280,21 @@ func (i Installer) prune(manifests []manifest.ManifestSet) error {
 	selector := klabels.Set(coreLabels).AsSelectorPreValidated()
 	componentRequirement, err := klabels.NewRequirement(manifest.IstioComponentLabel, selection.Exists, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	selector = selector.Add(*componentRequirement)
 
-	var errs util.Errors
+	var out []prunedObject
 	resources := uninstall.PrunedResourcesSchemas()
 	for _, gvk := range resources {
 		dc, err := i.Kube.DynamicClientFor(gvk, nil, "")
 		if err != nil {
-			return err
+			return nil, err
 		}
 		objs, err := dc.List(context.Background(), metav1.ListOptions{LabelSelector: selector.String()})
 		if err := controllers.IgnoreNotFound(err); err != nil {
 			// Cluster may not even have these resources; ignore these errors
-			return err
+			return nil, err
 		}
 		if objs == nil {
 			continue
@@ -281,14 +313,11 @@ func (i Installer) prune(manifests []manifest.ManifestSet) error {
 				if !componentLabels.Matches(klabels.Set(obj.GetLabels())) {
 					continue
 				}
-
-				if err := uninstall.DeleteResource(i.Kube, i.DryRun, i.Logger, &obj); err != nil {
-					errs = append(errs, err)
-				}
+				out = append(out, prunedObject{component: component, object: &obj})
 			}
 		}
 	}
-	return errs.ToError()
+	return out, nil
 }
 
 var componentDependencies = map[component.Name][]component.Name{
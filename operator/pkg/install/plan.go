@@ -0,0 +1,312 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package install
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"istio.io/istio/operator/pkg/component"
+	"istio.io/istio/operator/pkg/manifest"
+	"istio.io/istio/pkg/kube/fielddiff"
+	"istio.io/istio/pkg/maps"
+	"istio.io/istio/pkg/ptr"
+	"istio.io/istio/pkg/slices"
+	"istio.io/istio/pkg/util/sets"
+)
+
+// Action is the change an install makes to an object.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionPrune  Action = "prune"
+)
+
+// FieldChange is a field an install changes.
+type FieldChange struct {
+	// Path of the field, such as spec.template.spec.containers[0].image.
+	Path string
+	// Live is the value in the cluster, or nil if it is not set.
+	Live any
+	// Desired is the value in the rendered manifests.
+	Desired any
+	// Drifted is true if the field was changed outside of the installer since the last install, and the install
+	// reverts it.
+	Drifted bool
+}
+
+// ObjectChange is a change an install makes to an object.
+type ObjectChange struct {
+	Component component.Name
+	Action    Action
+	Kind      string
+	Namespace string
+	Name      string
+	// Fields are the changed fields of an update.
+	Fields []FieldChange
+	// Drifted is true if the object was changed or deleted outside of the installer since the last install.
+	Drifted bool
+}
+
+func (c ObjectChange) String() string {
+	if c.Namespace == "" {
+		return c.Kind + " " + c.Name
+	}
+	return c.Kind + " " + c.Namespace + "/" + c.Name
+}
+
+// Plan is the set of changes an install makes to the cluster. It results from a three-way comparison of the rendered
+// manifests, the manifests applied by the last install and the live objects.
+type Plan struct {
+	Changes []ObjectChange
+	// HasState is false if the manifests applied by the last install are unknown, in which case changes made outside
+	// of the installer are not detected.
+	HasState bool
+}
+
+// Empty returns true if the cluster matches the rendered manifests.
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// Drifted returns the changes to objects that were modified outside of the installer.
+func (p *Plan) Drifted() []ObjectChange {
+	return slices.Filter(p.Changes, func(c ObjectChange) bool {
+		return c.Drifted
+	})
+}
+
+// Summary returns a one line summary of the plan.
+func (p *Plan) Summary() string {
+	count := func(a Action) int {
+		return len(slices.Filter(p.Changes, func(c ObjectChange) bool {
+			return c.Action == a
+		}))
+	}
+	s := fmt.Sprintf("%d to create, %d to update, %d to prune", count(ActionCreate), count(ActionUpdate), count(ActionPrune))
+	if d := len(p.Drifted()); d > 0 {
+		s += fmt.Sprintf(", %d changed outside of the installer", d)
+	}
+	return s
+}
+
+// Write prints the plan, grouped by component.
+func (p *Plan) Write(w io.Writer) {
+	if p.Empty() {
+		_, _ = fmt.Fprintln(w, "No changes. The cluster matches the rendered manifests.")
+		return
+	}
+	symbols := map[Action]string{ActionCreate: "+", ActionUpdate: "~", ActionPrune: "-"}
+	for _, c := range component.AllComponents {
+		changes := slices.Filter(p.Changes, func(oc ObjectChange) bool {
+			return oc.Component == c.UserFacingName
+		})
+		if len(changes) == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "%s:\n", c.UserFacingName)
+		for _, oc := range changes {
+			drift := ""
+			if oc.Drifted && oc.Action == ActionCreate {
+				drift = " (deleted outside of the installer)"
+			}
+			_, _ = fmt.Fprintf(w, "  %s %s %s%s\n", symbols[oc.Action], oc.Action, oc, drift)
+			for _, f := range oc.Fields {
+				drift := ""
+				if f.Drifted {
+					drift = " (changed outside of the installer)"
+				}
+				_, _ = fmt.Fprintf(w, "      %s: %s -> %s%s\n", f.Path, formatValue(f.Live), formatValue(f.Desired), drift)
+			}
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+	_, _ = fmt.Fprintf(w, "Plan: %s.\n", p.Summary())
+	if !p.HasState {
+		_, _ = fmt.Fprintln(w, "The state of the last install was not found; changes made outside of the installer are not detected.")
+	}
+}
+
+// Plan computes the changes installing the manifests makes to the cluster, without changing it. Webhooks deployed
+// out-of-band from the manifests, such as revision tags, are not a part of the plan.
+func (i Installer) Plan(manifests []manifest.ManifestSet) (*Plan, error) {
+	state, err := i.readState()
+	if err != nil {
+		return nil, err
+	}
+	plan := &Plan{HasState: state != nil}
+	for _, mfs := range manifests {
+		for _, m := range mfs.Manifests {
+			m, err := i.applyLabelsAndAnnotations(m, string(mfs.Component))
+			if err != nil {
+				return nil, err
+			}
+			live, err := i.liveObject(m)
+			if err != nil {
+				return nil, err
+			}
+			last, hasLast := state[mfs.Component][m.Hash()]
+			oc := ObjectChange{
+				Component: mfs.Component,
+				Kind:      m.GetKind(),
+				Namespace: m.GetNamespace(),
+				Name:      m.GetName(),
+			}
+			if live == nil {
+				oc.Action = ActionCreate
+				oc.Drifted = hasLast
+				plan.Changes = append(plan.Changes, oc)
+				continue
+			}
+			var lastObj *unstructured.Unstructured
+			if hasLast {
+				lastObj = last.Unstructured
+			}
+			applied, err := i.dryRunApply(m)
+			if err != nil {
+				return nil, err
+			}
+			oc.Fields = diffObject(applied, live, lastObj)
+			if len(oc.Fields) == 0 {
+				continue
+			}
+			oc.Action = ActionUpdate
+			oc.Drifted = slices.FindFunc(oc.Fields, func(f FieldChange) bool {
+				return f.Drifted
+			}) != nil
+			plan.Changes = append(plan.Changes, oc)
+		}
+	}
+
+	pruned, err := i.prunedObjects(manifests)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pruned {
+		plan.Changes = append(plan.Changes, ObjectChange{
+			Component: p.component,
+			Action:    ActionPrune,
+			Kind:      p.object.GetKind(),
+			Namespace: p.object.GetNamespace(),
+			Name:      p.object.GetName(),
+		})
+	}
+	return plan, nil
+}
+
+// liveObject returns the object in the cluster, or nil if it does not exist.
+func (i Installer) liveObject(m manifest.Manifest) (*unstructured.Unstructured, error) {
+	dc, err := i.Kube.DynamicClientFor(m.GroupVersionKind(), m.Unstructured, "")
+	if err != nil {
+		return nil, err
+	}
+	live, err := dc.Get(context.Background(), m.GetName(), metav1.GetOptions{})
+	// The kind does not exist if its CRD is a part of the install.
+	if kerrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil, nil
+	}
+	return live, err
+}
+
+// dryRunApply returns the object as a server-side apply of the manifest would leave it in the cluster, with the
+// defaults of the server applied and its values normalized.
+func (i Installer) dryRunApply(m manifest.Manifest) (*unstructured.Unstructured, error) {
+	dc, err := i.Kube.DynamicClientFor(m.GroupVersionKind(), m.Unstructured, "")
+	if err != nil {
+		return nil, err
+	}
+	applied, err := dc.Patch(context.Background(), m.GetName(), types.ApplyPatchType, []byte(m.Content), metav1.PatchOptions{
+		DryRun:       []string{metav1.DryRunAll},
+		Force:        ptr.Of(true),
+		FieldManager: fieldOwnerOperator,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to dry run the server-side apply of %s/%s/%s: %v", m.GetKind(), m.GetNamespace(), m.GetName(), err)
+	}
+	return applied, nil
+}
+
+// ignoredFields are fields istiod patches at runtime, which would otherwise always show up as changed.
+var ignoredFields = map[string]sets.String{
+	"ValidatingWebhookConfiguration": sets.New("webhooks[*].clientConfig.caBundle", "webhooks[*].failurePolicy"),
+	"MutatingWebhookConfiguration":   sets.New("webhooks[*].clientConfig.caBundle"),
+}
+
+var listIndex = regexp.MustCompile(`\[\d+\]`)
+
+// diffObject returns the fields of live an install changes. applied is the object as the install leaves it, so that
+// the defaults of the server and the fields set by controllers are not reported. last is the object applied by the
+// last install, or nil if it is unknown.
+func diffObject(applied, live, last *unstructured.Unstructured) []FieldChange {
+	var changes []fielddiff.Change
+	if last != nil {
+		changes = fielddiff.ThreeWay(comparedFields(live.Object), comparedFields(applied.Object), comparedFields(last.Object))
+	} else {
+		changes = fielddiff.Diff(comparedFields(live.Object), comparedFields(applied.Object))
+	}
+	ignored := ignoredFields[applied.GetKind()]
+	var out []FieldChange
+	for _, c := range changes {
+		if ignored.Contains(listIndex.ReplaceAllString(c.Path, "[*]")) {
+			continue
+		}
+		out = append(out, FieldChange(c))
+	}
+	return out
+}
+
+// comparedFields returns the fields of an object an install manages: all but the status and the metadata other than
+// labels and annotations.
+func comparedFields(obj map[string]any) map[string]any {
+	out := maps.Clone(obj)
+	delete(out, "status")
+	if md, ok := obj["metadata"].(map[string]any); ok {
+		kept := map[string]any{}
+		for _, k := range []string{"labels", "annotations"} {
+			if v, ok := md[k]; ok {
+				kept[k] = v
+			}
+		}
+		out["metadata"] = kept
+	}
+	return out
+}
+
+const maxValueLength = 80
+
+func formatValue(v any) string {
+	if v == nil {
+		return "<unset>"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	if len(b) > maxValueLength {
+		return string(b[:maxValueLength]) + "..."
+	}
+	return string(b)
+}
@@ -0,0 +1,87 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package install
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func object(t *testing.T, y string) *unstructured.Unstructured {
+	t.Helper()
+	us := &unstructured.Unstructured{}
+	assert.NoError(t, yaml.Unmarshal([]byte(y), us))
+	return us
+}
+
+func TestDiffObject(t *testing.T) {
+	// The object as the install leaves it keeps the fields only set in the cluster.
+	applied := object(t, `
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: istiod
+  uid: "1234"
+  resourceVersion: "2"
+  labels: {app: istiod, extra: label}
+webhooks:
+- name: a
+  failurePolicy: Ignore
+  clientConfig: {caBundle: ""}
+  rules: [{operations: [CREATE]}]
+  sideEffects: None
+  timeoutSeconds: 10
+  matchPolicy: Equivalent
+status: {}
+`)
+	live := object(t, `
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: istiod
+  uid: "1234"
+  resourceVersion: "1"
+  labels: {app: istiod, extra: label}
+webhooks:
+- name: a
+  failurePolicy: Fail
+  clientConfig: {caBundle: Zm9v}
+  rules: [{operations: [CREATE, UPDATE]}]
+  sideEffects: None
+  timeoutSeconds: 10.0
+  matchPolicy: Equivalent
+status: {}
+`)
+	last := object(t, `
+kind: ValidatingWebhookConfiguration
+webhooks:
+- name: a
+  rules: [{operations: [CREATE]}]
+`)
+	// Fields patched by istiod and the metadata set by the server are ignored.
+	want := []FieldChange{{
+		Path:    "webhooks[0].rules[0].operations",
+		Live:    []any{"CREATE", "UPDATE"},
+		Desired: []any{"CREATE"},
+		Drifted: true,
+	}}
+	assert.Equal(t, diffObject(applied, live, last), want)
+
+	// Without the last install, the change cannot be attributed.
+	want[0].Drifted = false
+	assert.Equal(t, diffObject(applied, live, nil), want)
+}
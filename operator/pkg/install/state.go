@@ -0,0 +1,137 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package install
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/operator/pkg/component"
+	"istio.io/istio/operator/pkg/manifest"
+)
+
+// stateConfigMapName is the name of the ConfigMap recording the manifests applied by the last install, suffixed with
+// the name of the IstioOperator and the revision, if set. It lets a dry run tell changes made to the installed objects
+// outside of the installer apart from the changes of the new manifests.
+const stateConfigMapName = "istio-install-state"
+
+// installState holds the manifests applied by the last install, keyed by component and object hash.
+type installState map[component.Name]map[string]manifest.Manifest
+
+func (i Installer) stateName() string {
+	name := stateConfigMapName
+	if n := i.Values.GetPathString("metadata.name"); n != "" {
+		name += "-" + n
+	}
+	if r := i.Values.GetPathString("spec.values.revision"); r != "" && r != "default" {
+		name += "-" + r
+	}
+	return name
+}
+
+// stateNamespace is the Istio namespace, which holds the state whatever the namespace of the IstioOperator.
+func (i Installer) stateNamespace() string {
+	return i.Values.GetPathStringOr("spec.values.global.istioNamespace", "istio-system")
+}
+
+// readState returns the manifests applied by the last install, or nil if they are unknown.
+func (i Installer) readState() (installState, error) {
+	cm, err := i.Kube.Kube().CoreV1().ConfigMaps(i.stateNamespace()).Get(context.Background(), i.stateName(), metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := installState{}
+	for c, b := range cm.BinaryData {
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, fmt.Errorf("invalid install state for %s: %v", c, err)
+		}
+		content, err := io.ReadAll(zr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid install state for %s: %v", c, err)
+		}
+		manifests, err := manifest.ParseMultiple(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("invalid install state for %s: %v", c, err)
+		}
+		objects := map[string]manifest.Manifest{}
+		for _, m := range manifests {
+			objects[m.Hash()] = m
+		}
+		state[component.Name(c)] = objects
+	}
+	return state, nil
+}
+
+// writeState records the applied manifests. They are compressed, as the CRDs alone exceed the size limit of a ConfigMap.
+func (i Installer) writeState(manifests []manifest.ManifestSet) error {
+	data := map[string][]byte{}
+	for _, mfs := range manifests {
+		if len(mfs.Manifests) == 0 {
+			continue
+		}
+		contents := make([]string, 0, len(mfs.Manifests))
+		for _, m := range mfs.Manifests {
+			m, err := i.applyLabelsAndAnnotations(m, string(mfs.Component))
+			if err != nil {
+				return err
+			}
+			contents = append(contents, m.Content)
+		}
+		var b bytes.Buffer
+		zw := gzip.NewWriter(&b)
+		if _, err := zw.Write([]byte(strings.Join(contents, "\n---\n"))); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		data[string(mfs.Component)] = b.Bytes()
+	}
+
+	// The state is labeled as part of the control plane, so uninstalling the revision removes it, but is never pruned.
+	labels := getOwnerLabels(i.Values, string(component.PilotComponentName))
+	labels[manifest.OwningResourceNotPruned] = "true"
+	ns := i.stateNamespace()
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      i.stateName(),
+			Namespace: ns,
+			Labels:    labels,
+		},
+		BinaryData: data,
+	}
+	configMaps := i.Kube.Kube().CoreV1().ConfigMaps(ns)
+	if _, err := configMaps.Create(context.Background(), cm, metav1.CreateOptions{}); err != nil {
+		if !kerrors.IsAlreadyExists(err) {
+			return err
+		}
+		if _, err := configMaps.Update(context.Background(), cm, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
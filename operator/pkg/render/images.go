@@ -0,0 +1,261 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"istio.io/istio/operator/pkg/component"
+	"istio.io/istio/operator/pkg/manifest"
+	"istio.io/istio/operator/pkg/values"
+	"istio.io/istio/pkg/kube/inject/proxyimage"
+)
+
+// Images configures the image references of the rendered manifests, for instance to pull from the mirror of an
+// air-gapped cluster. It applies to the containers of all components and to the proxies injected by istiod.
+type Images struct {
+	// Rewrites maps image prefixes, such as docker.io/istio, to the prefix replacing them, such as
+	// registry.example.com/istio. Prefixes match whole path components, and the longest matching prefix applies.
+	Rewrites map[string]string
+	// Digests maps image references to their digest. If set, all images are pinned to their digest, and rendering
+	// fails for images without one. References are looked up after rewriting, then as rendered.
+	Digests map[string]string
+}
+
+// Empty returns true if the images are rendered unchanged.
+func (im Images) Empty() bool {
+	return len(im.Rewrites) == 0 && im.Digests == nil
+}
+
+// sidecarInjectorConfigMap is the prefix of the name of the ConfigMaps holding the injection configuration.
+const sidecarInjectorConfigMap = "istio-sidecar-injector"
+
+// postRenderer returns a post renderer rewriting the images of the manifests. Injected proxies take their image from
+// the values of the injector ConfigMap, which are set to the rewritten and pinned proxy image. As a full reference,
+// it overrides the image type selected with the sidecar.istio.io/proxyImageType annotation.
+func (im Images) postRenderer() PostRenderer {
+	return func(comp component.Name, manifests []manifest.Manifest) ([]manifest.Manifest, error) {
+		for _, m := range manifests {
+			if err := im.rewriteContainers(m.Object); err != nil {
+				return nil, fmt.Errorf("%s: %v", m.Hash(), err)
+			}
+			if m.GetKind() == "ConfigMap" && strings.HasPrefix(m.GetName(), sidecarInjectorConfigMap) {
+				if err := im.rewriteInjectorValues(m.Object); err != nil {
+					return nil, fmt.Errorf("%s: %v", m.Hash(), err)
+				}
+			}
+		}
+		return manifests, nil
+	}
+}
+
+// rewriteContainers rewrites the images of the containers found in obj, such as the containers of a pod template.
+func (im Images) rewriteContainers(obj map[string]any) error {
+	for k, v := range obj {
+		switch v := v.(type) {
+		case map[string]any:
+			if err := im.rewriteContainers(v); err != nil {
+				return err
+			}
+		case []any:
+			isContainers := k == "containers" || k == "initContainers" || k == "ephemeralContainers"
+			for _, item := range v {
+				item, ok := item.(map[string]any)
+				if !ok {
+					continue
+				}
+				if image, ok := item["image"].(string); ok && isContainers {
+					resolved, err := im.Resolve(image)
+					if err != nil {
+						return err
+					}
+					item["image"] = resolved
+				}
+				if err := im.rewriteContainers(item); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// rewriteInjectorValues sets the proxy images of the values of the injector ConfigMap to their resolved reference.
+func (im Images) rewriteInjectorValues(cm map[string]any) error {
+	raw, ok := values.GetPathAs[string](values.Map(cm), "data.values")
+	if !ok {
+		return nil
+	}
+	vals, err := values.MapFromYaml([]byte(raw))
+	if err != nil {
+		return fmt.Errorf("could not parse injector values: %v", err)
+	}
+	// The injection templates use the proxy image as is if it is a full reference.
+	proxy := vals.GetPathString("global.proxy.image")
+	if !strings.Contains(proxy, "/") {
+		proxy = proxyImage(vals)
+	}
+	resolved, err := im.Resolve(proxy)
+	if err != nil {
+		return err
+	}
+	if resolved == proxy {
+		return nil
+	}
+	if err := vals.SetPath("global.proxy.image", resolved); err != nil {
+		return err
+	}
+	if err := vals.SetPath("global.proxy_init.image", resolved); err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(vals, "", "  ")
+	if err != nil {
+		return err
+	}
+	return values.Map(cm).SetPath("data.values", string(out))
+}
+
+// proxyImage returns the proxy image built by the injector from the injector values, as inject.ProxyImage does.
+func proxyImage(vals values.Map) string {
+	tag := ""
+	if t, f := vals.GetPath("global.tag"); f && t != nil {
+		tag = fmt.Sprint(t)
+	}
+	return proxyimage.URL(vals.GetPathString("global.hub"), vals.GetPathStringOr("global.proxy.image", "proxyv2"), tag,
+		vals.GetPathString("global.variant"))
+}
+
+// Resolve returns the reference an image is rendered with: rewritten, then pinned to its digest if digests are set.
+// The images of gateways set to "auto" are left alone, as they are injected.
+func (im Images) Resolve(image string) (string, error) {
+	if image == "" || image == "auto" {
+		return image, nil
+	}
+	ref := im.rewrite(image)
+	if im.Digests == nil || strings.Contains(ref, "@") {
+		return ref, nil
+	}
+	digest, f := im.Digests[ref]
+	if !f {
+		digest, f = im.Digests[image]
+	}
+	if !f {
+		return "", fmt.Errorf("no digest found for image %s", ref)
+	}
+	return ref + "@" + digest, nil
+}
+
+func (im Images) rewrite(image string) string {
+	best, replacement := "", ""
+	for prefix, to := range im.Rewrites {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if len(prefix) > len(best) && hasImagePrefix(image, prefix) {
+			best, replacement = prefix, strings.TrimSuffix(to, "/")
+		}
+	}
+	if best == "" {
+		return image
+	}
+	return replacement + image[len(best):]
+}
+
+// hasImagePrefix returns true if prefix is made of whole components of the image reference.
+func hasImagePrefix(image, prefix string) bool {
+	if prefix == "" || !strings.HasPrefix(image, prefix) {
+		return false
+	}
+	rest := image[len(prefix):]
+	return rest == "" || strings.ContainsAny(rest[:1], "/:@")
+}
+
+var digestRegexp = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-zA-Z0-9=_-]+$`)
+
+// ImageLock is the format of an image lock file, mapping image references to their digest:
+//
+//	images:
+//	  docker.io/istio/proxyv2:1.28.0: sha256:...
+type ImageLock struct {
+	Images map[string]string `json:"images"`
+}
+
+// ReadImageLock reads the digests of an image lock file.
+func ReadImageLock(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lock := ImageLock{}
+	if err := yaml.UnmarshalStrict(b, &lock); err != nil {
+		return nil, fmt.Errorf("invalid image lock file %s: %v", path, err)
+	}
+	for ref, digest := range lock.Images {
+		if !digestRegexp.MatchString(digest) {
+			return nil, fmt.Errorf("invalid image lock file %s: invalid digest %q for %s", path, digest, ref)
+		}
+	}
+	if lock.Images == nil {
+		lock.Images = map[string]string{}
+	}
+	return lock.Images, nil
+}
+
+const (
+	// ociRefNameAnnotation is the annotation of the OCI image layout holding the reference of an image.
+	ociRefNameAnnotation = "org.opencontainers.image.ref.name"
+	// containerdImageNameAnnotation holds the full reference of an image in the layouts exported by containerd and
+	// docker, where the OCI annotation only holds the tag.
+	containerdImageNameAnnotation = "io.containerd.image.name"
+)
+
+// ReadOCILayout reads the digests of the images of an OCI image layout directory, such as the output of
+// "docker save" or "oras copy --to-oci-layout". Images are identified by their full reference; manifests annotated
+// with a bare tag are ignored.
+func ReadOCILayout(dir string) (map[string]string, error) {
+	b, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if err != nil {
+		return nil, err
+	}
+	index := struct {
+		Manifests []struct {
+			Digest      string            `json:"digest"`
+			Annotations map[string]string `json:"annotations"`
+		} `json:"manifests"`
+	}{}
+	if err := json.Unmarshal(b, &index); err != nil {
+		return nil, fmt.Errorf("invalid OCI layout %s: %v", dir, err)
+	}
+	digests := map[string]string{}
+	for _, m := range index.Manifests {
+		if !digestRegexp.MatchString(m.Digest) {
+			return nil, fmt.Errorf("invalid OCI layout %s: invalid digest %q", dir, m.Digest)
+		}
+		ref := m.Annotations[containerdImageNameAnnotation]
+		if ref == "" {
+			ref = m.Annotations[ociRefNameAnnotation]
+		}
+		if !strings.Contains(ref, "/") {
+			continue
+		}
+		digests[ref] = m.Digest
+	}
+	return digests, nil
+}
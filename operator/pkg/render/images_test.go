@@ -0,0 +1,157 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"istio.io/istio/operator/pkg/component"
+	"istio.io/istio/operator/pkg/manifest"
+	"istio.io/istio/operator/pkg/values"
+	"istio.io/istio/pkg/kube/inject"
+	"istio.io/istio/pkg/maps"
+	"istio.io/istio/pkg/slices"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func digest(c byte) string {
+	return "sha256:" + strings.Repeat(string(c), 64)
+}
+
+func podImages(sets []manifest.ManifestSet) map[string]string {
+	images := map[string]string{}
+	for _, set := range sets {
+		for _, m := range set.Manifests {
+			containers, _, _ := unstructured.NestedSlice(m.Object, "spec", "template", "spec", "containers")
+			for _, c := range containers {
+				images[m.GetKind()+"/"+m.GetName()] = c.(map[string]any)["image"].(string)
+			}
+		}
+	}
+	return images
+}
+
+func TestRenderImages(t *testing.T) {
+	setFlags := []string{"profile=ambient", "values.global.hub=docker.io/istio", "values.global.tag=1.28.0"}
+	images := Images{
+		Rewrites: map[string]string{
+			"docker.io":       "registry.example.com/docker",
+			"docker.io/istio": "registry.example.com/istio",
+		},
+		Digests: map[string]string{
+			"registry.example.com/istio/pilot:1.28.0-distroless":       digest('a'),
+			"registry.example.com/istio/install-cni:1.28.0-distroless": digest('b'),
+			"registry.example.com/istio/ztunnel:1.28.0-distroless":     digest('c'),
+			// References can be looked up as rendered.
+			"docker.io/istio/proxyv2:1.28.0-distroless": digest('d'),
+		},
+	}
+	sets, _, err := Render(Options{SetFlags: setFlags, Images: images})
+	assert.NoError(t, err)
+	assert.Equal(t, podImages(sets), map[string]string{
+		"Deployment/istiod":        "registry.example.com/istio/pilot:1.28.0-distroless@" + digest('a'),
+		"DaemonSet/istio-cni-node": "registry.example.com/istio/install-cni:1.28.0-distroless@" + digest('b'),
+		"DaemonSet/ztunnel":        "registry.example.com/istio/ztunnel:1.28.0-distroless@" + digest('c'),
+	})
+
+	// Injected proxies use the pinned proxy image.
+	injector := findManifest(t, sets, component.PilotComponentName, "ConfigMap", "istio-sidecar-injector")
+	assert.Equal(t, injector != nil, true)
+	raw, _ := values.GetPathAs[string](values.Map(injector.Object), "data.values")
+	vc, err := inject.NewValuesConfig(raw)
+	assert.NoError(t, err)
+	proxy := "registry.example.com/istio/proxyv2:1.28.0-distroless@" + digest('d')
+	assert.Equal(t, vc.Struct().GetGlobal().GetProxy().GetImage(), proxy)
+	assert.Equal(t, vc.Struct().GetGlobal().GetProxyInit().GetImage(), proxy)
+
+	// Images without a digest fail the rendering.
+	delete(images.Digests, "registry.example.com/istio/ztunnel:1.28.0-distroless")
+	_, _, err = Render(Options{SetFlags: setFlags, Images: images})
+	assert.Error(t, err)
+	if !strings.Contains(err.Error(), "no digest found for image registry.example.com/istio/ztunnel:1.28.0-distroless") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Rewrites alone leave the injector values unchanged if the proxy image is not rewritten.
+	sets, _, err = Render(Options{
+		SetFlags: []string{"profile=minimal", "values.global.hub=docker.io/istio", "values.global.tag=1.28.0"},
+		Images:   Images{Rewrites: map[string]string{"gcr.io/istio-release": "registry.example.com/istio"}},
+	})
+	assert.NoError(t, err)
+	injector = findManifest(t, sets, component.PilotComponentName, "ConfigMap", "istio-sidecar-injector")
+	raw, _ = values.GetPathAs[string](values.Map(injector.Object), "data.values")
+	vc, err = inject.NewValuesConfig(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, vc.Struct().GetGlobal().GetProxy().GetImage(), "proxyv2")
+}
+
+func TestResolveImage(t *testing.T) {
+	images := Images{Rewrites: map[string]string{
+		"docker.io/istio": "mirror.example.com/istio",
+		"quay.io/":        "mirror.example.com/quay/",
+	}}
+	cases := map[string]string{
+		"docker.io/istio/proxyv2:1.28.0":    "mirror.example.com/istio/proxyv2:1.28.0",
+		"docker.io/istiofoo/proxyv2:1.28.0": "docker.io/istiofoo/proxyv2:1.28.0",
+		"quay.io/org/app@" + digest('a'):    "mirror.example.com/quay/org/app@" + digest('a'),
+		"auto":                              "auto",
+	}
+	for image, want := range cases {
+		got, err := images.Resolve(image)
+		assert.NoError(t, err)
+		assert.Equal(t, got, want)
+	}
+
+	// Images already pinned to a digest are not looked up.
+	images.Digests = map[string]string{}
+	got, err := images.Resolve("quay.io/org/app@" + digest('a'))
+	assert.NoError(t, err)
+	assert.Equal(t, got, "mirror.example.com/quay/org/app@"+digest('a'))
+	_, err = images.Resolve("quay.io/org/app:v1")
+	assert.Error(t, err)
+}
+
+func TestReadImageDigests(t *testing.T) {
+	dir := t.TempDir()
+	lock := filepath.Join(dir, "images.lock")
+	assert.NoError(t, os.WriteFile(lock, []byte("images:\n  docker.io/istio/pilot:1.28.0: "+digest('a')+"\n"), 0o644))
+	digests, err := ReadImageLock(lock)
+	assert.NoError(t, err)
+	assert.Equal(t, digests, map[string]string{"docker.io/istio/pilot:1.28.0": digest('a')})
+
+	assert.NoError(t, os.WriteFile(lock, []byte("images:\n  docker.io/istio/pilot:1.28.0: latest\n"), 0o644))
+	_, err = ReadImageLock(lock)
+	assert.Error(t, err)
+
+	layout := filepath.Join(dir, "layout")
+	assert.NoError(t, os.Mkdir(layout, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(layout, "index.json"), []byte(`{"schemaVersion":2,"manifests":[
+{"digest":"`+digest('a')+`","annotations":{"org.opencontainers.image.ref.name":"registry.example.com/istio/pilot:1.28.0"}},
+{"digest":"`+digest('b')+`","annotations":{"io.containerd.image.name":"registry.example.com/istio/ztunnel:1.28.0",
+  "org.opencontainers.image.ref.name":"1.28.0"}},
+{"digest":"`+digest('c')+`","annotations":{"org.opencontainers.image.ref.name":"1.28.0"}}]}`), 0o644))
+	digests, err = ReadOCILayout(layout)
+	assert.NoError(t, err)
+	assert.Equal(t, slices.Sort(maps.Keys(digests)), []string{
+		"registry.example.com/istio/pilot:1.28.0",
+		"registry.example.com/istio/ztunnel:1.28.0",
+	})
+	assert.Equal(t, digests["registry.example.com/istio/ztunnel:1.28.0"], digest('b'))
+}
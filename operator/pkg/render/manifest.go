@@ -37,11 +37,42 @@ import (
 	pkgversion "istio.io/istio/pkg/version"
 )
 
+// Options configure Render.
+type Options struct {
+	// Files are the paths to IstioOperator files, overlaid in order.
+	Files []string
+	// SetFlags are "path=value" IstioOperator overrides, applied in order after the files.
+	SetFlags []string
+	// Force logs validation errors as warnings, instead of failing.
+	Force bool
+	// Client is optional; if it is provided, cluster-specific settings are auto-detected.
+	Client kube.Client
+	// Logger is optional; if it is provided, warning messages are logged.
+	Logger clog.Logger
+	// PostRenderers are applied to the manifests of each component, after the registered post renderers.
+	PostRenderers []PostRenderer
+	// Images rewrites and pins the images of all components, after the post renderers.
+	Images Images
+}
+
 // GenerateManifest produces fully rendered Kubernetes objects from rendering Helm charts.
 // Inputs can be files and --set strings.
 // Client is option; if it is provided, cluster-specific settings can be auto-detected.
 // Logger is also option; if it is provided warning messages may be logged.
 func GenerateManifest(files []string, setFlags []string, force bool, client kube.Client, logger clog.Logger) ([]manifest.ManifestSet, values.Map, error) {
+	return Render(Options{
+		Files:    files,
+		SetFlags: setFlags,
+		Force:    force,
+		Client:   client,
+		Logger:   logger,
+	})
+}
+
+// Render renders an IstioOperator into Kubernetes objects, grouped by component, and returns them with the merged
+// IstioOperator. Use TypedObjects to convert the manifests to typed objects.
+func Render(opts Options) ([]manifest.ManifestSet, values.Map, error) {
+	files, setFlags, force, client, logger := opts.Files, opts.SetFlags, opts.Force, opts.Client, opts.Logger
 	// First, compute our final configuration input. This will be in the form of an IstioOperator, but as an unstructured values.Map.
 	// This allows safe access to get/fetch values dynamically, and avoids issues are typing and whether we should emit empty fields.
 	merged, err := MergeInputs(files, setFlags, client)
@@ -100,6 +131,14 @@ func GenerateManifest(files []string, setFlags []string, force bool, client kube
 		}
 	}
 
+	renderers := append(registeredPostRenderers(), opts.PostRenderers...)
+	if !opts.Images.Empty() {
+		renderers = append(renderers, opts.Images.postRenderer())
+	}
+	if err := postRender(allManifests, renderers); err != nil {
+		return nil, nil, fmt.Errorf("post rendering: %v", err)
+	}
+
 	// Log any warnings we got from the charts
 	if logger != nil {
 		for _, w := range chartWarnings {
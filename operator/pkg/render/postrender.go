@@ -0,0 +1,127 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"istio.io/istio/operator/pkg/component"
+	"istio.io/istio/operator/pkg/manifest"
+	"istio.io/istio/pkg/kube"
+)
+
+// PostRenderer modifies the manifests of a component once they are rendered and post processed, for instance to apply
+// organization policies such as labels, image mirrors or security contexts without forking the charts. It may add,
+// remove or change objects.
+type PostRenderer func(comp component.Name, manifests []manifest.Manifest) ([]manifest.Manifest, error)
+
+var (
+	postRenderersMu sync.RWMutex
+	postRenderers   []PostRenderer
+)
+
+// RegisterPostRenderer registers a post renderer applied to all rendered manifests, including the manifests of
+// istioctl install and manifest generate. Post renderers run in registration order, before the post renderers
+// passed in Options. It is meant to be called from the init functions of binaries embedding istioctl.
+func RegisterPostRenderer(r PostRenderer) {
+	postRenderersMu.Lock()
+	defer postRenderersMu.Unlock()
+	postRenderers = append(postRenderers, r)
+}
+
+func registeredPostRenderers() []PostRenderer {
+	postRenderersMu.RLock()
+	defer postRenderersMu.RUnlock()
+	return append([]PostRenderer{}, postRenderers...)
+}
+
+// ExecPostRenderer returns a post renderer running an executable once per component. As with Helm post renderers,
+// the manifests are written to its standard input as multi-document YAML, and the modified manifests are read from
+// its standard output. The ISTIO_COMPONENT environment variable holds the name of the component.
+func ExecPostRenderer(path string, args ...string) PostRenderer {
+	return func(comp component.Name, manifests []manifest.Manifest) ([]manifest.Manifest, error) {
+		var stdin, stdout, stderr bytes.Buffer
+		for _, m := range manifests {
+			stdin.WriteString(m.Content)
+			stdin.WriteString("\n---\n")
+		}
+		cmd := exec.Command(path, args...)
+		cmd.Env = append(os.Environ(), "ISTIO_COMPONENT="+string(comp))
+		cmd.Stdin = &stdin
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("post renderer %s failed for %s: %v: %s", path, comp, err, strings.TrimSpace(stderr.String()))
+		}
+		out, err := manifest.ParseMultiple(stdout.String())
+		if err != nil {
+			return nil, fmt.Errorf("post renderer %s returned invalid manifests for %s: %v", path, comp, err)
+		}
+		return out, nil
+	}
+}
+
+// postRender applies the post renderers to the manifests of each component, in the component order.
+func postRender(all map[component.Name]manifest.ManifestSet, renderers []PostRenderer) error {
+	if len(renderers) == 0 {
+		return nil
+	}
+	for _, comp := range component.AllComponents {
+		set, f := all[comp.UserFacingName]
+		if !f {
+			continue
+		}
+		for _, r := range renderers {
+			out, err := r(set.Component, set.Manifests)
+			if err != nil {
+				return err
+			}
+			// Post renderers may modify the objects in place, so rebuild their content.
+			for i, m := range out {
+				if out[i], err = manifest.FromObject(m.Unstructured); err != nil {
+					return err
+				}
+			}
+			set.Manifests = out
+		}
+		all[comp.UserFacingName] = set
+	}
+	return nil
+}
+
+// TypedObjects converts manifests to the typed objects of the Kubernetes and Istio APIs. Objects of kinds unknown to
+// the Istio scheme are returned as unstructured objects.
+func TypedObjects(manifests []manifest.Manifest) ([]runtime.Object, error) {
+	out := make([]runtime.Object, 0, len(manifests))
+	for _, m := range manifests {
+		obj, err := kube.IstioScheme.New(m.GroupVersionKind())
+		if err != nil {
+			out = append(out, m.Unstructured)
+			continue
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(m.Object, obj); err != nil {
+			return nil, fmt.Errorf("convert %s: %v", m.Hash(), err)
+		}
+		out = append(out, obj)
+	}
+	return out, nil
+}
@@ -0,0 +1,101 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	"istio.io/istio/operator/pkg/component"
+	"istio.io/istio/operator/pkg/manifest"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func findManifest(t *testing.T, sets []manifest.ManifestSet, c component.Name, kind, name string) *manifest.Manifest {
+	t.Helper()
+	for _, m := range manifest.ExtractComponent(sets, c) {
+		if m.GetKind() == kind && m.GetName() == name {
+			return &m
+		}
+	}
+	return nil
+}
+
+func TestRenderPostRenderers(t *testing.T) {
+	var order []string
+	label := func(name string) PostRenderer {
+		return func(comp component.Name, manifests []manifest.Manifest) ([]manifest.Manifest, error) {
+			order = append(order, fmt.Sprintf("%s/%s", name, comp))
+			for _, m := range manifests {
+				labels := m.GetLabels()
+				if labels == nil {
+					labels = map[string]string{}
+				}
+				labels["example.com/"+name] = "true"
+				m.SetLabels(labels)
+			}
+			return manifests, nil
+		}
+	}
+	t.Cleanup(func() {
+		postRenderers = nil
+	})
+	RegisterPostRenderer(label("registered"))
+
+	script := filepath.Join(t.TempDir(), "post-render.sh")
+	assert.NoError(t, os.WriteFile(script, []byte(`#!/bin/sh
+cat
+echo "---"
+echo "apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: policy-$ISTIO_COMPONENT-$1"
+`), 0o755))
+
+	sets, _, err := Render(Options{
+		SetFlags:      []string{"profile=minimal"},
+		PostRenderers: []PostRenderer{label("option"), ExecPostRenderer(script, "v1")},
+	})
+	assert.NoError(t, err)
+	// Post renderers run per component, in the component order, the registered ones first.
+	assert.Equal(t, order, []string{"registered/Base", "option/Base", "registered/Pilot", "option/Pilot"})
+
+	istiod := findManifest(t, sets, component.PilotComponentName, "Deployment", "istiod")
+	assert.Equal(t, istiod != nil, true)
+	assert.Equal(t, istiod.GetLabels()["example.com/registered"], "true")
+	assert.Equal(t, istiod.GetLabels()["example.com/option"], "true")
+	assert.Equal(t, findManifest(t, sets, component.PilotComponentName, "ConfigMap", "policy-Pilot-v1") != nil, true)
+
+	objs, err := TypedObjects([]manifest.Manifest{*istiod})
+	assert.NoError(t, err)
+	d, ok := objs[0].(*appsv1.Deployment)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, d.Labels["example.com/option"], "true")
+}
+
+func TestExecPostRendererFailure(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "post-render.sh")
+	assert.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\necho denied >&2\nexit 1\n"), 0o755))
+	_, _, err := Render(Options{
+		SetFlags:      []string{"profile=minimal"},
+		PostRenderers: []PostRenderer{ExecPostRenderer(script)},
+	})
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), fmt.Sprintf("post rendering: post renderer %s failed for Base: exit status 1: denied", script))
+}
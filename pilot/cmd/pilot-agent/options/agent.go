@@ -43,6 +43,7 @@ func NewAgentOptions(proxy *ProxyArgs, cfg *meshconfig.ProxyConfig, sds istioage
 		IsIPv6:                   proxy.IsIPv6(),
 		ProxyType:                proxy.Type,
 		EnableDynamicProxyConfig: enableProxyConfigXdsEnv,
+		EnableCRL:                enableCRLXdsEnv,
 		WASMOptions: wasm.Options{
 			InsecureRegistries:    sets.New(insecureRegistries...),
 			ModuleExpiry:          wasmModuleExpiry,
@@ -68,6 +69,7 @@ func NewAgentOptions(proxy *ProxyArgs, cfg *meshconfig.ProxyConfig, sds istioage
 		IstiodSAN:                   istiodSAN.Get(),
 		SDSFactory:                  sds,
 		WorkloadIdentitySocketFile:  workloadIdentitySocketFile,
+		SpiffeWorkloadAPISocketPath: spiffeWorkloadAPISocketPath,
 		EnvoySkipDeprecatedLogs:     envoySkipDeprecatedLogsEnv,
 	}
 	if enableWDSEnvWasSet {
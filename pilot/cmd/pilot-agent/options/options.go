@@ -25,6 +25,7 @@ import (
 	"istio.io/istio/pkg/jwt"
 	"istio.io/istio/pkg/security"
 	"istio.io/istio/pkg/wasm"
+	"istio.io/istio/security/pkg/keyprovider"
 )
 
 var (
@@ -58,6 +59,24 @@ var (
 	workloadIdentitySocketFile = env.Register("WORKLOAD_IDENTITY_SOCKET_FILE", security.DefaultWorkloadIdentitySocketFile,
 		fmt.Sprintf("SPIRE workload identity SDS socket filename. If set, an SDS socket with this name must exist at %s", security.WorkloadIdentityPath)).Get()
 
+	spiffeWorkloadAPISocketPath = env.Register("SPIFFE_WORKLOAD_API_SOCKET", "",
+		"If set, the agent serves the SPIFFE Workload API on a Unix domain socket at this path, so applications "+
+			"can fetch their own X.509-SVIDs and trust bundle. JWT-SVIDs require ENABLE_CA_JWT_SVID on istiod.").Get()
+
+	workloadKeyProvider = env.Register("WORKLOAD_KEY_PROVIDER", security.InMemoryKeyProvider,
+		"The provider of workload private keys: memory, keystore (encrypted files in WORKLOAD_KEYSTORE_DIR) or "+
+			"external (a signer process listening on WORKLOAD_KEY_SIGNER_SOCKET). Keystore and external keys are never "+
+			"handed to Envoy, which signs with them through the private key provider named by "+
+			"WORKLOAD_KEY_SIGNER_ENVOY_PROVIDER, and requires an Envoy build with that provider.").Get()
+	workloadKeystoreDir = env.Register("WORKLOAD_KEYSTORE_DIR", "./etc/istio/proxy/keystore",
+		"The directory of the workload keystore, used with the keystore key provider.").Get()
+	workloadKeystoreKeyFile = env.Register("WORKLOAD_KEYSTORE_KEY_FILE", "",
+		"The file holding the 32 byte AES key, raw or base64 encoded, encrypting the workload keystore.").Get()
+	workloadKeySignerSocket = env.Register("WORKLOAD_KEY_SIGNER_SOCKET", "",
+		"The unix domain socket of the signer holding workload private keys, used with the external key provider.").Get()
+	workloadKeySignerEnvoyProvider = env.Register("WORKLOAD_KEY_SIGNER_ENVOY_PROVIDER", keyprovider.ExternalSignerProviderName,
+		"The name of the Envoy private key provider signing with keystore and external workload private keys.").Get()
+
 	// set to "SYSTEM" for ACME/public signed CA servers.
 	caRootCA = env.Register("CA_ROOT_CA", "",
 		"Explicitly set the root CA to expect for the CA connection.").Get()
@@ -114,6 +133,10 @@ var (
 	enableProxyConfigXdsEnv = env.Register("PROXY_CONFIG_XDS_AGENT", false,
 		"If set to true, agent retrieves dynamic proxy-config updates via xds channel").Get()
 
+	enableCRLXdsEnv = env.Register("CRL_XDS_AGENT", false,
+		"If set to true, agent retrieves the certificate revocation lists of the istiod CA via xds channel, "+
+			"and adds them to the root certificate served to Envoy. Requires ENABLE_CA_REVOCATION on istiod.").Get()
+
 	wasmInsecureRegistries = env.Register("WASM_INSECURE_REGISTRIES", "",
 		"allow agent pull wasm plugin from insecure registries or https server, for example: 'localhost:5000,docker-registry:5000'").Get()
 
@@ -26,6 +26,7 @@ import (
 	"istio.io/istio/pkg/log"
 	"istio.io/istio/pkg/security"
 	"istio.io/istio/security/pkg/credentialfetcher"
+	"istio.io/istio/security/pkg/keyprovider"
 	"istio.io/istio/security/pkg/nodeagent/cafile"
 )
 
@@ -70,6 +71,19 @@ func NewSecurityOptions(proxyConfig *meshconfig.ProxyConfig, stsPort int, tokenM
 
 	extractCAHeadersFromEnv(o)
 
+	keyProvider, err := keyprovider.NewKeyProvider(keyprovider.Options{
+		Type:            workloadKeyProvider,
+		KeystoreDir:     workloadKeystoreDir,
+		KeystoreKeyFile: workloadKeystoreKeyFile,
+		SignerSocket:    workloadKeySignerSocket,
+		EnvoyProvider:   workloadKeySignerEnvoyProvider,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key provider: %v", err)
+	}
+	log.Infof("using key provider of %s type", workloadKeyProvider)
+	o.KeyProvider = keyProvider
+
 	return o, err
 }
 
@@ -24,16 +24,17 @@ import (
 
 func NewStatusServerOptions(ipv6 bool, t model.NodeType, proxyConfig *meshconfig.ProxyConfig, agent *istioagent.Agent) *status.Options {
 	return &status.Options{
-		IPv6:           ipv6,
-		PodIP:          InstanceIPVar.Get(),
-		AdminPort:      uint16(proxyConfig.ProxyAdminPort),
-		StatusPort:     uint16(proxyConfig.StatusPort),
-		KubeAppProbers: kubeAppProberNameVar.Get(),
-		NodeType:       t,
-		Probes:         []ready.Prober{agent},
-		NoEnvoy:        agent.EnvoyDisabled(),
-		FetchDNS:       agent.GetDNSTable,
-		GRPCBootstrap:  agent.GRPCBootstrapPath(),
+		IPv6:              ipv6,
+		PodIP:             InstanceIPVar.Get(),
+		AdminPort:         uint16(proxyConfig.ProxyAdminPort),
+		StatusPort:        uint16(proxyConfig.StatusPort),
+		KubeAppProbers:    kubeAppProberNameVar.Get(),
+		NodeType:          t,
+		Probes:            []ready.Prober{agent},
+		NoEnvoy:           agent.EnvoyDisabled(),
+		FetchDNS:          agent.GetDNSTable,
+		FetchCertificates: agent.GetCertificates,
+		GRPCBootstrap:     agent.GRPCBootstrapPath(),
 		TriggerDrain: func() {
 			agent.DrainNow()
 		},
@@ -56,6 +56,7 @@ import (
 	"istio.io/istio/pkg/model"
 	"istio.io/istio/pkg/monitoring"
 	"istio.io/istio/pkg/network"
+	"istio.io/istio/pkg/security"
 	"istio.io/istio/pkg/slices"
 	istioNetUtil "istio.io/istio/pkg/util/net"
 	"istio.io/istio/pkg/util/protomarshal"
@@ -133,6 +134,7 @@ type Options struct {
 	EnvoyPrometheusPort int
 	Context             context.Context
 	FetchDNS            func() *dnsProto.NameTable
+	FetchCertificates   func() *security.AgentCertificates
 	NoEnvoy             bool
 	GRPCBootstrap       string
 	EnableProfiling     bool
@@ -392,6 +394,7 @@ func (s *Server) Run(ctx context.Context) {
 		mux.HandleFunc("/debug/pprof/trace", s.handlePprofTrace)
 	}
 	mux.HandleFunc("/debug/ndsz", s.handleNdsz)
+	mux.HandleFunc("/debug/certz", s.handleCertz)
 
 	l, err := net.Listen("tcp", fmt.Sprintf(":%d", s.statusPort))
 	if err != nil {
@@ -914,6 +917,31 @@ func (s *Server) handleNdsz(w http.ResponseWriter, r *http.Request) {
 	writeJSONProto(w, nametable)
 }
 
+// handleCertz reports the workload certificate and trust bundle the agent serves over SDS.
+func (s *Server) handleCertz(w http.ResponseWriter, r *http.Request) {
+	if !istioNetUtil.IsRequestFromLocalhost(r) {
+		http.Error(w, "Only requests from localhost are allowed", http.StatusForbidden)
+		return
+	}
+	var certs *security.AgentCertificates
+	if s.config.FetchCertificates != nil {
+		certs = s.config.FetchCertificates()
+	}
+	if certs == nil {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{}`))
+		return
+	}
+	b, err := json.Marshal(certs)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
 // writeJSONProto writes a protobuf to a json payload, handling content type, marshaling, and errors
 func writeJSONProto(w http.ResponseWriter, obj proto.Message) {
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,133 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"istio.io/istio/pilot/pkg/carotation"
+	"istio.io/istio/pilot/pkg/features"
+	"istio.io/istio/pkg/kube/controllers"
+	"istio.io/istio/pkg/kube/kclient"
+	"istio.io/istio/pkg/log"
+	"istio.io/istio/pkg/slices"
+)
+
+// initCARotation watches the CA rotation secret in the istiod namespace, and rotates the istiod CA
+// to the CA material it holds. Rotation distributes the new root through the workload trust bundle,
+// so it requires ISTIO_MULTIROOT_MESH. The rotation state is persisted in the CA rotation status secret,
+// so a restarted istiod resumes the rotation. As the acknowledgements of the proxies are only tracked by the
+// istiod they are connected to, rotation is refused while more than one istiod instance runs in the namespace.
+func (s *Server) initCARotation(args *PilotArgs) {
+	if s.CA == nil || s.kubeClient == nil {
+		return
+	}
+	if !features.MultiRootMesh {
+		log.Debugf("CA rotation is disabled, it requires ISTIO_MULTIROOT_MESH")
+		return
+	}
+
+	istiods := kclient.NewFiltered[*corev1.Pod](s.kubeClient, kclient.Filter{
+		Namespace:     args.Namespace,
+		LabelSelector: "app=istiod",
+	})
+	rotator := carotation.NewRotator(s.CA, s.workloadTrustBundle, s.XDSServer, carotation.Options{
+		Overlap:         maxWorkloadCertTTL.Get(),
+		OnSigningChange: s.updateRootCertAndGenKeyCert,
+		Instances: func() int {
+			return len(slices.Filter(istiods.List(args.Namespace, klabels.Everything()), func(p *corev1.Pod) bool {
+				return p.DeletionTimestamp == nil && p.Status.Phase == corev1.PodRunning
+			}))
+		},
+		Store: caRotationStore{client: s.kubeClient.Kube(), namespace: args.Namespace},
+	})
+	// Resume the rotation before the rotation secret is seen, so it is not started again from scratch.
+	if err := rotator.Restore(); err != nil {
+		log.Errorf("failed to restore CA rotation: %v", err)
+	}
+	s.XDSServer.CARotationStatus = rotator.Status
+
+	start := func(secret *corev1.Secret) {
+		req, err := carotation.RequestFromSecret(secret)
+		if err != nil {
+			log.Errorf("invalid CA rotation request: %v", err)
+			return
+		}
+		if err := rotator.Start(req); err != nil {
+			log.Errorf("failed to start CA rotation: %v", err)
+		}
+	}
+	secrets := kclient.NewFiltered[*corev1.Secret](s.kubeClient, kclient.Filter{
+		Namespace:     args.Namespace,
+		FieldSelector: "metadata.name=" + carotation.SecretName,
+	})
+	secrets.AddEventHandler(controllers.EventHandler[*corev1.Secret]{
+		AddFunc: start,
+		UpdateFunc: func(_, secret *corev1.Secret) {
+			start(secret)
+		},
+		DeleteFunc: func(*corev1.Secret) {
+			if err := rotator.Abort(); err != nil {
+				log.Warnf("CA rotation secret was deleted: %v", err)
+			}
+		},
+	})
+
+	s.addStartFunc("ca rotation", func(stop <-chan struct{}) error {
+		go rotator.Run(stop)
+		return nil
+	})
+}
+
+// caRotationStore persists the CA rotation state in the CA rotation status secret. It reads the secret
+// directly, as the state is restored before informers are started.
+type caRotationStore struct {
+	client    kubernetes.Interface
+	namespace string
+}
+
+var _ carotation.Store = caRotationStore{}
+
+func (c caRotationStore) Get() (*corev1.Secret, error) {
+	secret, err := c.client.CoreV1().Secrets(c.namespace).Get(context.TODO(), carotation.StatusSecretName, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return secret, err
+}
+
+func (c caRotationStore) Put(secret *corev1.Secret) error {
+	secret.Namespace = c.namespace
+	secrets := c.client.CoreV1().Secrets(c.namespace)
+	_, err := secrets.Update(context.TODO(), secret, metav1.UpdateOptions{})
+	if kerrors.IsNotFound(err) {
+		_, err = secrets.Create(context.TODO(), secret, metav1.CreateOptions{})
+	}
+	return err
+}
+
+func (c caRotationStore) Delete() error {
+	err := c.client.CoreV1().Secrets(c.namespace).Delete(context.TODO(), carotation.StatusSecretName, metav1.DeleteOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -48,6 +48,7 @@ func InitGenerators(
 	generators[v3.ExtensionConfigurationType] = ecdsGen
 	generators[v3.NameTableType] = &xds.NdsGenerator{ConfigGenerator: cg}
 	generators[v3.ProxyConfigType] = &xds.PcdsGenerator{TrustBundle: env.TrustBundle}
+	generators[v3.CRLType] = &xds.CrlGenerator{Server: s}
 
 	workloadGen := &xds.WorkloadGenerator{Server: s}
 	generators[v3.AddressType] = workloadGen
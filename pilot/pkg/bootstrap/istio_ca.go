@@ -28,6 +28,7 @@ import (
 	"google.golang.org/grpc"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 
 	"istio.io/api/security/v1beta1"
 	"istio.io/istio/pilot/pkg/features"
@@ -145,6 +146,22 @@ func (s *Server) initCAServer(ca caserver.CertificateAuthority, opts *caOptions)
 		log.Fatalf("failed to create istio ca server: %v", startErr)
 	}
 	s.caServer = caServer
+	s.XDSServer.IssuedCertificates = caServer.IssuedCertificates
+	if features.EnableCAJWTSVID {
+		var client kubernetes.Interface
+		if s.kubeClient != nil {
+			client = s.kubeClient.Kube()
+		}
+		caServer.JWTKeys = caserver.NewJWTKeyStore(client, opts.Namespace, features.CAJWTSVIDKeyRotation, features.CAJWTSVIDTTL)
+		s.addStartFunc("jwt-svid signing keys", func(stop <-chan struct{}) error {
+			go caServer.JWTKeys.Run(stop)
+			return nil
+		})
+	}
+	s.addStartFunc("certificate inventory", func(stop <-chan struct{}) error {
+		go caServer.RunInventory(stop)
+		return nil
+	})
 }
 
 // RunCA will start the cert signing GRPC service on an existing server.
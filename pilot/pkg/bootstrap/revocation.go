@@ -0,0 +1,84 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"istio.io/istio/pilot/pkg/features"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/revocation"
+	"istio.io/istio/pkg/config/schema/kind"
+	"istio.io/istio/pkg/kube/controllers"
+	"istio.io/istio/pkg/kube/kclient"
+	"istio.io/istio/pkg/log"
+	"istio.io/istio/pkg/util/sets"
+)
+
+// initCARevocation watches the revoked certificates ConfigMap in the istiod namespace, and distributes the
+// CRL signed by the istiod CA to proxies. It must be called after the CA server is started.
+func (s *Server) initCARevocation(args *PilotArgs) error {
+	if !features.EnableCARevocation || s.CA == nil || s.kubeClient == nil {
+		return nil
+	}
+	if err := revocation.CheckCA(s.CA); err != nil {
+		return fmt.Errorf("%v: reissue the CA certificate with the cRLSign key usage, or disable ENABLE_CA_REVOCATION", err)
+	}
+	key := model.ConfigKey{Kind: kind.ConfigMap, Name: revocation.ConfigMapName, Namespace: args.Namespace}
+	controller := revocation.NewController(s.CA, func() {
+		// Only the CRL generator, and the ztunnel policies for the revoked identities, handle the revocation
+		// ConfigMap, so this does not recompute other resources.
+		s.XDSServer.ConfigUpdate(&model.PushRequest{
+			Full:           false,
+			ConfigsUpdated: sets.New(key),
+			Reason:         model.NewReasonStats(model.SecretTrigger),
+		})
+	})
+	s.XDSServer.Revocations = controller
+	if s.caServer != nil {
+		s.caServer.IsRevoked = controller.IsRevoked
+	}
+
+	update := func(cm *corev1.ConfigMap) {
+		entries, err := revocation.EntriesFromConfigMap(cm)
+		if err != nil {
+			// Keep the last valid revocations, rather than un-revoking everything on a typo.
+			log.Errorf("invalid %s ConfigMap: %v", revocation.ConfigMapName, err)
+			return
+		}
+		controller.Update(entries)
+	}
+	configMaps := kclient.NewFiltered[*corev1.ConfigMap](s.kubeClient, kclient.Filter{
+		Namespace:     args.Namespace,
+		FieldSelector: "metadata.name=" + revocation.ConfigMapName,
+	})
+	configMaps.AddEventHandler(controllers.EventHandler[*corev1.ConfigMap]{
+		AddFunc: update,
+		UpdateFunc: func(_, cm *corev1.ConfigMap) {
+			update(cm)
+		},
+		DeleteFunc: func(*corev1.ConfigMap) {
+			controller.Update(nil)
+		},
+	})
+
+	s.addStartFunc("ca revocation", func(stop <-chan struct{}) error {
+		go controller.Run(stop)
+		return nil
+	})
+	return nil
+}
@@ -78,13 +78,16 @@ import (
 	"istio.io/istio/pkg/monitoring"
 	"istio.io/istio/pkg/network"
 	"istio.io/istio/pkg/security"
+	"istio.io/istio/pkg/slices"
 	"istio.io/istio/pkg/spiffe"
 	"istio.io/istio/pkg/util/sets"
 	xdspkg "istio.io/istio/pkg/xds"
+	"istio.io/istio/security/pkg/k8s/chiron"
 	"istio.io/istio/security/pkg/pki/ca"
 	"istio.io/istio/security/pkg/pki/ra"
 	caserver "istio.io/istio/security/pkg/server/ca"
 	"istio.io/istio/security/pkg/server/ca/authenticate"
+	"istio.io/istio/security/pkg/server/ca/authenticate/attestation"
 	"istio.io/istio/security/pkg/server/ca/authenticate/kubeauth"
 )
 
@@ -347,6 +350,9 @@ func NewServer(args *PilotArgs, initFuncs ...func(*Server)) (*Server, error) {
 		return nil, err
 	}
 
+	// CA rotation regenerates the Istiod certs, so it must be initialized after them.
+	s.initCARotation(args)
+
 	// Secure gRPC Server must be initialized after CA is created as may use a Citadel generated cert.
 	if err := s.initSecureDiscoveryService(args, s.environment.Mesh().GetTrustDomain()); err != nil {
 		return nil, fmt.Errorf("error initializing secure gRPC Listener: %v", err)
@@ -407,9 +413,19 @@ func NewServer(args *PilotArgs, initFuncs ...func(*Server)) (*Server, error) {
 		s.XDSServer.Authenticators = authenticators
 	}
 	caOpts.Authenticators = authenticators
+	if features.EnableCAJoinTokens && s.kubeClient != nil {
+		// Join tokens are single use, so they only authenticate the first certificate request. Enrolled workloads
+		// authenticate XDS and certificate renewals with their certificate.
+		caOpts.Authenticators = append(slices.Clone(authenticators),
+			attestation.NewAuthenticator(s.environment.Watcher, attestation.NewJoinTokens(s.kubeClient.Kube(), args.Namespace)))
+	}
 
 	// Start CA or RA server. This should be called after CA and Istiod certs have been created.
 	s.startCA(caOpts)
+	s.initServiceCertController(args)
+	if err := s.initCARevocation(args); err != nil {
+		return nil, fmt.Errorf("error initializing CA revocation: %v", err)
+	}
 
 	// TODO: don't run this if galley is started, one ctlz is enough
 	if args.CtrlZOptions != nil {
@@ -1196,6 +1212,26 @@ func (s *Server) initIPAutoallocateController(args *PilotArgs) {
 	})
 }
 
+// initServiceCertController provisions certificates from the Istiod CA for annotated Services.
+func (s *Server) initServiceCertController(args *PilotArgs) {
+	if !features.EnableServiceDNSCerts || s.CA == nil || s.kubeClient == nil {
+		return
+	}
+	s.addStartFunc("service cert controller", func(stop <-chan struct{}) error {
+		go leaderelection.
+			NewLeaderElection(args.Namespace, args.PodName, leaderelection.ServiceCertController, args.Revision, s.kubeClient).
+			AddRunFunction(func(leaderStop <-chan struct{}) {
+				chiron.NewServiceCertController(s.kubeClient, s.CA, chiron.ServiceCertOptions{
+					DomainSuffix:     args.RegistryOptions.KubeOptions.DomainSuffix,
+					CertTTL:          features.ServiceDNSCertTTL,
+					GracePeriodRatio: 0.5,
+					ResyncPeriod:     10 * time.Minute,
+				}).Run(leaderStop)
+			}).Run(stop)
+		return nil
+	})
+}
+
 func (s *Server) initMulticluster(args *PilotArgs) {
 	if s.kubeClient == nil {
 		return
@@ -15,8 +15,12 @@
 package bootstrap
 
 import (
+	v1 "k8s.io/api/core/v1"
+
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pkg/config/schema/collections"
+	"istio.io/istio/pkg/config/validation/policy"
+	"istio.io/istio/pkg/kube/watcher/configmapwatcher"
 	"istio.io/istio/pkg/log"
 	"istio.io/istio/pkg/webhooks/validation/controller"
 	"istio.io/istio/pkg/webhooks/validation/server"
@@ -34,11 +38,21 @@ func (s *Server) initConfigValidation(args *PilotArgs) error {
 		DomainSuffix: args.RegistryOptions.KubeOptions.DomainSuffix,
 		Mux:          s.httpsMux,
 	}
-	_, err := server.New(params)
+	wh, err := server.New(params)
 	if err != nil {
 		return err
 	}
 
+	if features.ValidationPolicyConfigMap != "" && s.kubeClient != nil {
+		watcher := configmapwatcher.NewController(s.kubeClient, args.Namespace, features.ValidationPolicyConfigMap, func(cm *v1.ConfigMap) {
+			updateValidationPolicy(wh, cm)
+		})
+		s.addStartFunc("validation policy", func(stop <-chan struct{}) error {
+			go watcher.Run(stop)
+			return nil
+		})
+	}
+
 	s.readinessFlags.configValidationReady.Store(true)
 
 	if features.ValidationWebhookConfigName != "" && s.kubeClient != nil {
@@ -51,3 +65,20 @@ func (s *Server) initConfigValidation(args *PilotArgs) error {
 	}
 	return nil
 }
+
+// updateValidationPolicy sets the policy of the validation webhook from its ConfigMap. An invalid policy is ignored,
+// keeping the previous one.
+func updateValidationPolicy(wh *server.Webhook, cm *v1.ConfigMap) {
+	if cm == nil {
+		log.Warnf("validation policy ConfigMap %s not found, no policy is enforced", features.ValidationPolicyConfigMap)
+		wh.SetPolicy(nil)
+		return
+	}
+	p, err := policy.Parse([]byte(cm.Data[policy.Key]))
+	if err != nil {
+		log.Errorf("invalid validation policy in ConfigMap %s, keeping the previous policy: %v", cm.Name, err)
+		return
+	}
+	log.Infof("enforcing %d validation policy rules from ConfigMap %s", p.Rules(), cm.Name)
+	wh.SetPolicy(p)
+}
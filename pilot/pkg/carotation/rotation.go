@@ -0,0 +1,443 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package carotation implements a zero-downtime rotation of the root certificate of the istiod CA.
+//
+// A rotation goes through the following phases:
+//  1. DistributingTrustAnchor: the new root is added to the mesh trust bundle as an additional trust anchor,
+//     and the rotation waits until every connected proxy has acknowledged the updated trust bundle.
+//  2. Overlap: the CA signs with the new key and certificate, while both roots remain trusted, until all
+//     workload certificates signed by the old CA have expired.
+//  3. Completed: the old root is removed from the trust bundle.
+//
+// A rotation can be aborted until the CA has switched signing.
+//
+// The phase of the rotation and the CA material rotated to are persisted in a Store, so a restarted istiod
+// resumes the rotation. The acknowledgements of the proxies are only tracked by the istiod instance they are
+// connected to, so a rotation is refused while more than one istiod instance shares the CA.
+package carotation
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	tb "istio.io/istio/pilot/pkg/trustbundle"
+	"istio.io/istio/pkg/log"
+	"istio.io/istio/security/pkg/pki/ca"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+var rotationLog = log.RegisterScope("carotation", "CA root rotation")
+
+const (
+	// SecretName is the name of the secret, in the istiod namespace, holding the CA material to rotate to.
+	// It has the same layout as the cacerts secret.
+	SecretName = "istio-ca-rotation"
+	// OverlapAnnotation overrides, on the rotation secret, how long both roots are trusted after the CA
+	// switched signing. It should not be shorter than the lifetime of workload certificates.
+	OverlapAnnotation = "ca.istio.io/rotation-overlap"
+)
+
+// Phase is a step of a root CA rotation.
+type Phase string
+
+const (
+	// PhaseIdle means no rotation was requested.
+	PhaseIdle Phase = "Idle"
+	// PhaseDistributing means the new root is distributed as an additional trust anchor, and the
+	// rotation is waiting for proxies to acknowledge it.
+	PhaseDistributing Phase = "DistributingTrustAnchor"
+	// PhaseOverlap means the CA signs with the new certificate, and both roots are trusted until
+	// certificates signed by the old CA have expired.
+	PhaseOverlap Phase = "Overlap"
+	// PhaseCompleted means the old root has been removed.
+	PhaseCompleted Phase = "Completed"
+	// PhaseFailed means the requested CA material is not usable.
+	PhaseFailed Phase = "Failed"
+)
+
+// Request is the CA material to rotate to.
+type Request struct {
+	CertPem      []byte
+	KeyPem       []byte
+	CertChainPem []byte
+	RootCertPem  []byte
+	// Overlap is how long both roots are trusted after switching signing. Zero uses the rotator default.
+	Overlap time.Duration
+}
+
+func (r Request) sameMaterial(o Request) bool {
+	return bytes.Equal(r.CertPem, o.CertPem) && bytes.Equal(r.KeyPem, o.KeyPem) &&
+		bytes.Equal(r.CertChainPem, o.CertChainPem) && bytes.Equal(r.RootCertPem, o.RootCertPem)
+}
+
+// RequestFromSecret reads a rotation request from a secret with the cacerts layout.
+func RequestFromSecret(secret *corev1.Secret) (Request, error) {
+	req := Request{
+		CertPem:      secret.Data[ca.CACertFile],
+		KeyPem:       secret.Data[ca.CAPrivateKeyFile],
+		CertChainPem: secret.Data[ca.CertChainFile],
+		RootCertPem:  secret.Data[ca.RootCertFile],
+	}
+	for key, v := range map[string][]byte{
+		ca.CACertFile:       req.CertPem,
+		ca.CAPrivateKeyFile: req.KeyPem,
+		ca.RootCertFile:     req.RootCertPem,
+	} {
+		if len(v) == 0 {
+			return req, fmt.Errorf("secret %s/%s is missing %s", secret.Namespace, secret.Name, key)
+		}
+	}
+	if len(req.CertChainPem) == 0 {
+		req.CertChainPem = req.CertPem
+	}
+	if v, f := secret.Annotations[OverlapAnnotation]; f {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return req, fmt.Errorf("invalid %s annotation on secret %s/%s: %v", OverlapAnnotation, secret.Namespace, secret.Name, err)
+		}
+		req.Overlap = d
+	}
+	return req, nil
+}
+
+// CertInfo identifies a root certificate.
+type CertInfo struct {
+	Subject     string    `json:"subject"`
+	Fingerprint string    `json:"fingerprint"`
+	NotAfter    time.Time `json:"notAfter"`
+}
+
+// Status is the state of the rotation, as exposed on the istiod debug endpoint.
+type Status struct {
+	Phase          Phase     `json:"phase"`
+	Message        string    `json:"message,omitempty"`
+	NewRoot        *CertInfo `json:"newRoot,omitempty"`
+	OldRoot        *CertInfo `json:"oldRoot,omitempty"`
+	StartTime      time.Time `json:"startTime"`
+	PhaseStartTime time.Time `json:"phaseStartTime"`
+	OverlapEndTime time.Time `json:"overlapEndTime"`
+	// AckedProxies is the number of proxies connected to this istiod that have acknowledged the new trust anchor.
+	AckedProxies int `json:"ackedProxies"`
+	// PendingProxies are the proxies connected to this istiod that have not acknowledged the new trust anchor yet.
+	PendingProxies []string `json:"pendingProxies,omitempty"`
+}
+
+// SigningCA is the CA whose signing material is rotated.
+type SigningCA interface {
+	GetCAKeyCertBundle() *util.KeyCertBundle
+}
+
+// TrustAnchors is the mesh trust bundle the new root is distributed through.
+type TrustAnchors interface {
+	UpdateTrustAnchor(anchorConfig *tb.TrustAnchorUpdate) error
+}
+
+// AckTracker reports whether connected proxies have acknowledged the mesh trust bundle.
+type AckTracker interface {
+	// TrustBundleAcks returns the number of proxies that acknowledged a trust bundle sent after since,
+	// and the IDs of those that did not.
+	TrustBundleAcks(since time.Time) (acked int, pending []string)
+}
+
+// Options configures a Rotator.
+type Options struct {
+	// Overlap is the default time both roots are trusted after switching signing.
+	Overlap time.Duration
+	// CheckInterval is how often the rotation checks whether it can move to the next phase.
+	CheckInterval time.Duration
+	// OnSigningChange is called after the CA signing material or roots changed, to regenerate
+	// the istiod certificates and publish the CA roots.
+	OnSigningChange func() error
+	// Instances returns the number of istiod instances sharing the CA. If nil, a single instance is assumed.
+	Instances func() int
+	// Store persists the rotation state. If nil, the rotation state is only kept in memory.
+	Store Store
+}
+
+// Rotator drives the rotation of the CA to a new root.
+type Rotator struct {
+	ca      SigningCA
+	anchors TrustAnchors
+	acks    AckTracker
+	opts    Options
+	now     func() time.Time
+
+	mu     sync.Mutex
+	req    *Request
+	old    oldMaterial
+	status Status
+	// dirty is set if the rotation state could not be persisted.
+	dirty bool
+}
+
+// oldMaterial is the CA material in use when the rotation started, restored if switching signing fails.
+type oldMaterial struct {
+	cert, key, chain, root, crl []byte
+}
+
+// NewRotator creates a rotator for the given CA.
+func NewRotator(signingCA SigningCA, anchors TrustAnchors, acks AckTracker, opts Options) *Rotator {
+	if opts.CheckInterval == 0 {
+		opts.CheckInterval = 10 * time.Second
+	}
+	return &Rotator{
+		ca:      signingCA,
+		anchors: anchors,
+		acks:    acks,
+		opts:    opts,
+		now:     time.Now,
+		status:  Status{Phase: PhaseIdle},
+	}
+}
+
+// Status returns the current state of the rotation.
+func (r *Rotator) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.status
+	if s.PendingProxies != nil {
+		s.PendingProxies = append([]string(nil), s.PendingProxies...)
+	}
+	return s
+}
+
+// Start begins a rotation to the requested CA material. Requesting the rotation already in progress is a no-op.
+// A new rotation can only replace one that has not switched signing yet.
+func (r *Rotator) Start(req Request) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.req != nil && r.req.sameMaterial(req) && r.status.Phase != PhaseFailed {
+		r.req.Overlap = req.Overlap
+		return nil
+	}
+	if r.status.Phase == PhaseOverlap {
+		return fmt.Errorf("cannot start a new rotation: the CA already switched signing to %s", r.status.NewRoot.Subject)
+	}
+	if r.status.Phase == PhaseDistributing {
+		rotationLog.Infof("replacing in progress rotation to %s", r.status.NewRoot.Subject)
+	}
+
+	r.req = &req
+	r.status = Status{Phase: PhaseIdle, StartTime: r.now()}
+	if err := util.Verify(req.CertPem, req.KeyPem, req.CertChainPem, req.RootCertPem, nil); err != nil {
+		return r.fail(fmt.Errorf("invalid CA material: %v", err))
+	}
+	newRoot, err := certInfo(req.RootCertPem)
+	if err != nil {
+		return r.fail(fmt.Errorf("invalid root certificate: %v", err))
+	}
+	r.status.NewRoot = newRoot
+	if err := r.checkSingleInstance(); err != nil {
+		return r.fail(err)
+	}
+
+	cert, key, chain, root := r.ca.GetCAKeyCertBundle().GetAllPem()
+	r.old = oldMaterial{cert: cert, key: key, chain: chain, root: root, crl: r.ca.GetCAKeyCertBundle().GetCRLPem()}
+	if oldRoot, err := certInfo(root); err == nil {
+		r.status.OldRoot = oldRoot
+	}
+	if bytes.Equal(cert, req.CertPem) {
+		r.setPhase(PhaseCompleted, "the CA already signs with the requested certificate")
+		return nil
+	}
+
+	// Proxies may acknowledge the new trust anchor as soon as it is pushed, so the phase must start before.
+	r.setPhase(PhaseDistributing, "waiting for proxies to acknowledge the new trust anchor")
+	if err := r.setRotationAnchor(req.RootCertPem); err != nil {
+		return r.fail(fmt.Errorf("failed to distribute the new root: %v", err))
+	}
+	rotationLog.Infof("started CA rotation to root %s (%s)", newRoot.Subject, newRoot.Fingerprint)
+	r.persist()
+	return nil
+}
+
+// Abort cancels a rotation that has not switched signing yet, removing the new root from the trust bundle.
+func (r *Rotator) Abort() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch r.status.Phase {
+	case PhaseOverlap:
+		return fmt.Errorf("cannot abort the rotation: the CA already switched signing to %s", r.status.NewRoot.Subject)
+	case PhaseDistributing, PhaseFailed:
+		if err := r.setRotationAnchor(nil); err != nil {
+			return fmt.Errorf("failed to remove the new root from the trust bundle: %v", err)
+		}
+		rotationLog.Infof("aborted CA rotation")
+		r.status = Status{Phase: PhaseIdle, Message: "rotation aborted"}
+	}
+	r.req = nil
+	r.persist()
+	return nil
+}
+
+// Run periodically moves the rotation to its next phase, until stop is closed.
+func (r *Rotator) Run(stop <-chan struct{}) {
+	t := time.NewTicker(r.opts.CheckInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+			r.Reconcile()
+		}
+	}
+}
+
+// Reconcile moves the rotation to its next phase, if the current one is done.
+func (r *Rotator) Reconcile() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.dirty {
+		r.persist()
+	}
+	switch r.status.Phase {
+	case PhaseDistributing:
+		acked, pending := r.acks.TrustBundleAcks(r.status.PhaseStartTime)
+		r.status.AckedProxies, r.status.PendingProxies = acked, pending
+		if len(pending) > 0 {
+			return
+		}
+		// Proxies connected to other instances are not tracked by this one.
+		if err := r.checkSingleInstance(); err != nil {
+			rotationLog.Warnf("not switching CA signing: %v", err)
+			r.status.Message = err.Error()
+			return
+		}
+		if err := r.switchSigning(); err != nil {
+			rotationLog.Errorf("failed to switch CA signing: %v", err)
+			r.status.Message = fmt.Sprintf("failed to switch signing, will retry: %v", err)
+			return
+		}
+		overlap := r.opts.Overlap
+		if r.req.Overlap > 0 {
+			overlap = r.req.Overlap
+		}
+		r.setPhase(PhaseOverlap, "signing with the new CA, waiting for certificates signed by the old CA to expire")
+		r.status.OverlapEndTime = r.status.PhaseStartTime.Add(overlap)
+		rotationLog.Infof("CA switched signing to %s, old root will be removed at %v", r.status.NewRoot.Subject, r.status.OverlapEndTime)
+		r.persist()
+	case PhaseOverlap:
+		if r.now().Before(r.status.OverlapEndTime) {
+			return
+		}
+		if err := r.removeOldRoot(); err != nil {
+			rotationLog.Errorf("failed to remove the old CA root: %v", err)
+			r.status.Message = fmt.Sprintf("failed to remove the old root, will retry: %v", err)
+			return
+		}
+		r.setPhase(PhaseCompleted, "rotation complete, update the CA secret with the new CA material")
+		rotationLog.Infof("CA rotation to %s completed", r.status.NewRoot.Subject)
+		r.persist()
+	}
+}
+
+// switchSigning makes the CA sign with the new material, while trusting both roots.
+func (r *Rotator) switchSigning() error {
+	roots := util.AppendCertByte(r.req.RootCertPem, r.old.root)
+	if err := r.ca.GetCAKeyCertBundle().VerifyAndSetAll(r.req.CertPem, r.req.KeyPem, r.req.CertChainPem, roots, nil); err != nil {
+		return err
+	}
+	if err := r.notifySigningChange(); err != nil {
+		return err
+	}
+	// Both roots are now published as the CA roots.
+	return r.setRotationAnchor(nil)
+}
+
+// removeOldRoot makes the CA trust only the new root.
+func (r *Rotator) removeOldRoot() error {
+	if err := r.ca.GetCAKeyCertBundle().VerifyAndSetAll(r.req.CertPem, r.req.KeyPem, r.req.CertChainPem, r.req.RootCertPem, nil); err != nil {
+		return err
+	}
+	return r.notifySigningChange()
+}
+
+// notifySigningChange publishes the CA change, restoring the previous CA material if that fails.
+func (r *Rotator) notifySigningChange() error {
+	if r.opts.OnSigningChange == nil {
+		return nil
+	}
+	if err := r.opts.OnSigningChange(); err != nil {
+		o := r.old
+		if r.status.Phase == PhaseOverlap {
+			o = oldMaterial{cert: r.req.CertPem, key: r.req.KeyPem, chain: r.req.CertChainPem, root: util.AppendCertByte(r.req.RootCertPem, r.old.root)}
+		}
+		if rerr := r.ca.GetCAKeyCertBundle().VerifyAndSetAll(o.cert, o.key, o.chain, o.root, o.crl); rerr != nil {
+			rotationLog.Errorf("failed to restore CA material: %v", rerr)
+		}
+		return err
+	}
+	return nil
+}
+
+// checkSingleInstance returns an error if more than one istiod instance shares the CA, as each instance only
+// tracks the proxies connected to it, and would switch signing on its own.
+func (r *Rotator) checkSingleInstance() error {
+	if r.opts.Instances == nil {
+		return nil
+	}
+	if n := r.opts.Instances(); n > 1 {
+		return fmt.Errorf("CA rotation requires a single istiod instance, found %d: scale istiod to one replica during the rotation", n)
+	}
+	return nil
+}
+
+func (r *Rotator) setRotationAnchor(root []byte) error {
+	certs := []string{}
+	if len(root) > 0 {
+		certs = append(certs, string(root))
+	}
+	return r.anchors.UpdateTrustAnchor(&tb.TrustAnchorUpdate{
+		TrustAnchorConfig: tb.TrustAnchorConfig{Certs: certs},
+		Source:            tb.SourceRotation,
+	})
+}
+
+func (r *Rotator) setPhase(p Phase, msg string) {
+	r.status.Phase = p
+	r.status.Message = msg
+	r.status.PhaseStartTime = r.now()
+	r.status.PendingProxies = nil
+}
+
+func (r *Rotator) fail(err error) error {
+	rotationLog.Errorf("CA rotation failed: %v", err)
+	r.setPhase(PhaseFailed, err.Error())
+	r.persist()
+	return err
+}
+
+func certInfo(pemBytes []byte) (*CertInfo, error) {
+	cert, err := util.ParsePemEncodedCertificate(pemBytes)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(cert.Raw)
+	return &CertInfo{
+		Subject:     cert.Subject.String(),
+		Fingerprint: hex.EncodeToString(sum[:]),
+		NotAfter:    cert.NotAfter,
+	}, nil
+}
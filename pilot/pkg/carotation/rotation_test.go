@@ -0,0 +1,376 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package carotation
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tb "istio.io/istio/pilot/pkg/trustbundle"
+	"istio.io/istio/pkg/test/util/assert"
+	"istio.io/istio/security/pkg/pki/ca"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+type fakeCA struct {
+	bundle *util.KeyCertBundle
+}
+
+func (f fakeCA) GetCAKeyCertBundle() *util.KeyCertBundle {
+	return f.bundle
+}
+
+type fakeAcks struct {
+	acked   int
+	pending []string
+	since   time.Time
+}
+
+func (f *fakeAcks) TrustBundleAcks(since time.Time) (int, []string) {
+	f.since = since
+	return f.acked, f.pending
+}
+
+func newCA(t *testing.T, org string) Request {
+	t.Helper()
+	cert, key, err := util.GenCertKeyFromOptions(util.CertOptions{
+		TTL:          time.Hour,
+		Org:          org,
+		IsCA:         true,
+		IsSelfSigned: true,
+		RSAKeySize:   2048,
+	})
+	assert.NoError(t, err)
+	return Request{CertPem: cert, KeyPem: key, CertChainPem: cert, RootCertPem: cert}
+}
+
+type rotationFixture struct {
+	rotator *Rotator
+	bundle  *util.KeyCertBundle
+	trust   *tb.TrustBundle
+	acks    *fakeAcks
+	now     time.Time
+	changes int
+	failure error
+}
+
+func newFixture(t *testing.T) *rotationFixture {
+	return newFixtureFrom(t, newCA(t, "old"), nil)
+}
+
+// newFixtureFrom creates a fixture whose CA signs with old, as an istiod starting with the old CA material would.
+func newFixtureFrom(t *testing.T, old Request, store Store) *rotationFixture {
+	bundle, err := util.NewVerifiedKeyCertBundleFromPem(old.CertPem, old.KeyPem, old.CertChainPem, old.RootCertPem, nil)
+	assert.NoError(t, err)
+	f := &rotationFixture{
+		bundle: bundle,
+		trust:  tb.NewTrustBundle(nil, nil),
+		acks:   &fakeAcks{},
+		now:    time.Now(),
+	}
+	f.rotator = NewRotator(fakeCA{bundle: bundle}, f.trust, f.acks, Options{
+		Overlap: time.Hour,
+		OnSigningChange: func() error {
+			if f.failure != nil {
+				return f.failure
+			}
+			f.changes++
+			return nil
+		},
+		Store: store,
+	})
+	f.rotator.now = func() time.Time { return f.now }
+	return f
+}
+
+func (f *rotationFixture) trusted(req Request) bool {
+	for _, c := range f.trust.GetTrustBundle() {
+		if c == string(req.RootCertPem) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRotation(t *testing.T) {
+	f := newFixture(t)
+	oldRoot := f.bundle.GetRootCertPem()
+	next := newCA(t, "new")
+
+	assert.Equal(t, f.rotator.Status().Phase, PhaseIdle)
+	assert.NoError(t, f.rotator.Start(next))
+	assert.Equal(t, f.rotator.Status().Phase, PhaseDistributing)
+	assert.Equal(t, f.trusted(next), true)
+	assert.Equal(t, f.rotator.Status().NewRoot.Subject, "O=new")
+	assert.Equal(t, f.rotator.Status().OldRoot.Subject, "O=old")
+
+	// Signing must not switch while a proxy has not acknowledged the new root.
+	f.acks.acked, f.acks.pending = 2, []string{"sidecar~10.0.0.1~a.default~default.svc.cluster.local"}
+	f.rotator.Reconcile()
+	assert.Equal(t, f.acks.since, f.now)
+	st := f.rotator.Status()
+	assert.Equal(t, st.Phase, PhaseDistributing)
+	assert.Equal(t, st.AckedProxies, 2)
+	assert.Equal(t, st.PendingProxies, f.acks.pending)
+	assert.Equal(t, f.changes, 0)
+
+	f.acks.acked, f.acks.pending = 3, nil
+	f.now = f.now.Add(time.Minute)
+	f.rotator.Reconcile()
+	st = f.rotator.Status()
+	assert.Equal(t, st.Phase, PhaseOverlap)
+	assert.Equal(t, st.OverlapEndTime, f.now.Add(time.Hour))
+	assert.Equal(t, f.changes, 1)
+	cert, _, _, root := f.bundle.GetAllPem()
+	assert.Equal(t, cert, next.CertPem)
+	assert.Equal(t, bytes.Contains(root, oldRoot), true)
+	assert.Equal(t, bytes.Contains(root, next.RootCertPem), true)
+	// Both roots are now published by the CA itself.
+	assert.Equal(t, f.trusted(next), false)
+
+	assert.Error(t, f.rotator.Abort())
+	assert.Error(t, f.rotator.Start(newCA(t, "other")))
+
+	f.now = f.now.Add(30 * time.Minute)
+	f.rotator.Reconcile()
+	assert.Equal(t, f.rotator.Status().Phase, PhaseOverlap)
+
+	f.now = f.now.Add(30 * time.Minute)
+	f.rotator.Reconcile()
+	assert.Equal(t, f.rotator.Status().Phase, PhaseCompleted)
+	assert.Equal(t, f.changes, 2)
+	assert.Equal(t, f.bundle.GetRootCertPem(), next.RootCertPem)
+
+	// Requesting the same rotation again, e.g. on a resync of the secret, is a no-op.
+	assert.NoError(t, f.rotator.Start(next))
+	assert.Equal(t, f.rotator.Status().Phase, PhaseCompleted)
+}
+
+func TestRotationAbort(t *testing.T) {
+	f := newFixture(t)
+	next := newCA(t, "new")
+	assert.NoError(t, f.rotator.Start(next))
+	assert.Equal(t, f.trusted(next), true)
+
+	assert.NoError(t, f.rotator.Abort())
+	assert.Equal(t, f.rotator.Status().Phase, PhaseIdle)
+	assert.Equal(t, f.trusted(next), false)
+	f.rotator.Reconcile()
+	assert.Equal(t, f.changes, 0)
+}
+
+func TestRotationOverlapOverride(t *testing.T) {
+	f := newFixture(t)
+	req := newCA(t, "new")
+	req.Overlap = 5 * time.Minute
+	assert.NoError(t, f.rotator.Start(req))
+	f.rotator.Reconcile()
+	assert.Equal(t, f.rotator.Status().OverlapEndTime, f.now.Add(5*time.Minute))
+}
+
+func TestRotationSwitchFailure(t *testing.T) {
+	f := newFixture(t)
+	before, _, _, _ := f.bundle.GetAllPem()
+	assert.NoError(t, f.rotator.Start(newCA(t, "new")))
+
+	f.failure = errors.New("boom")
+	f.rotator.Reconcile()
+	st := f.rotator.Status()
+	assert.Equal(t, st.Phase, PhaseDistributing)
+	assert.Equal(t, strings.Contains(st.Message, "boom"), true)
+	after, _, _, _ := f.bundle.GetAllPem()
+	assert.Equal(t, after, before)
+
+	f.failure = nil
+	f.rotator.Reconcile()
+	assert.Equal(t, f.rotator.Status().Phase, PhaseOverlap)
+}
+
+// pushClock advances the clock of the fixture when the trust anchor is pushed, as proxies may acknowledge
+// the push immediately.
+type pushClock struct {
+	TrustAnchors
+	f *rotationFixture
+}
+
+func (p pushClock) UpdateTrustAnchor(anchorConfig *tb.TrustAnchorUpdate) error {
+	p.f.now = p.f.now.Add(time.Second)
+	return p.TrustAnchors.UpdateTrustAnchor(anchorConfig)
+}
+
+func TestRotationPhaseStartsBeforePush(t *testing.T) {
+	f := newFixture(t)
+	f.rotator.anchors = pushClock{TrustAnchors: f.trust, f: f}
+	start := f.now
+	assert.NoError(t, f.rotator.Start(newCA(t, "new")))
+	assert.Equal(t, f.rotator.Status().PhaseStartTime, start)
+	f.rotator.Reconcile()
+	assert.Equal(t, f.acks.since, start)
+}
+
+func TestRotationRequiresSingleInstance(t *testing.T) {
+	f := newFixture(t)
+	instances := 2
+	f.rotator.opts.Instances = func() int { return instances }
+	next := newCA(t, "new")
+	assert.Error(t, f.rotator.Start(next))
+	assert.Equal(t, f.rotator.Status().Phase, PhaseFailed)
+	assert.Equal(t, f.trusted(next), false)
+
+	instances = 1
+	assert.NoError(t, f.rotator.Start(next))
+	assert.Equal(t, f.rotator.Status().Phase, PhaseDistributing)
+
+	// Signing does not switch if another instance started during the rotation.
+	instances = 2
+	f.rotator.Reconcile()
+	st := f.rotator.Status()
+	assert.Equal(t, st.Phase, PhaseDistributing)
+	assert.Equal(t, strings.Contains(st.Message, "single istiod instance"), true)
+	assert.Equal(t, f.changes, 0)
+
+	instances = 1
+	f.rotator.Reconcile()
+	assert.Equal(t, f.rotator.Status().Phase, PhaseOverlap)
+}
+
+func TestRotationInvalidMaterial(t *testing.T) {
+	f := newFixture(t)
+	req := newCA(t, "new")
+	req.KeyPem = newCA(t, "other").KeyPem
+	assert.Error(t, f.rotator.Start(req))
+	assert.Equal(t, f.rotator.Status().Phase, PhaseFailed)
+	assert.Equal(t, f.trusted(req), false)
+}
+
+type memoryStore struct {
+	secret *corev1.Secret
+}
+
+func (m *memoryStore) Get() (*corev1.Secret, error) {
+	return m.secret, nil
+}
+
+func (m *memoryStore) Put(secret *corev1.Secret) error {
+	m.secret = secret
+	return nil
+}
+
+func (m *memoryStore) Delete() error {
+	m.secret = nil
+	return nil
+}
+
+func TestRotationResumesAfterRestart(t *testing.T) {
+	old := newCA(t, "old")
+	next := newCA(t, "new")
+	store := &memoryStore{}
+	restart := func() *rotationFixture {
+		f := newFixtureFrom(t, old, store)
+		assert.NoError(t, f.rotator.Restore())
+		return f
+	}
+
+	f := restart()
+	assert.Equal(t, f.rotator.Status().Phase, PhaseIdle)
+	assert.NoError(t, f.rotator.Start(next))
+
+	// The new root is distributed again.
+	f = restart()
+	assert.Equal(t, f.rotator.Status().Phase, PhaseDistributing)
+	assert.Equal(t, f.trusted(next), true)
+	// The rotation secret is seen again by the restarted istiod.
+	assert.NoError(t, f.rotator.Start(next))
+	assert.Equal(t, f.rotator.Status().Phase, PhaseDistributing)
+	f.rotator.Reconcile()
+	overlapEnd := f.rotator.Status().OverlapEndTime
+	assert.Equal(t, f.rotator.Status().Phase, PhaseOverlap)
+
+	// The CA signs with the new material again, and trusts both roots.
+	f = restart()
+	st := f.rotator.Status()
+	assert.Equal(t, st.Phase, PhaseOverlap)
+	assert.Equal(t, st.OverlapEndTime, overlapEnd)
+	assert.Equal(t, st.NewRoot.Subject, "O=new")
+	assert.Equal(t, f.changes, 1)
+	cert, _, _, root := f.bundle.GetAllPem()
+	assert.Equal(t, cert, next.CertPem)
+	assert.Equal(t, bytes.Contains(root, old.RootCertPem), true)
+	assert.Equal(t, bytes.Contains(root, next.RootCertPem), true)
+	f.now = overlapEnd
+	f.rotator.Reconcile()
+	assert.Equal(t, f.rotator.Status().Phase, PhaseCompleted)
+
+	// The CA keeps signing with the new material until the cacerts secret is updated.
+	f = restart()
+	assert.Equal(t, f.rotator.Status().Phase, PhaseCompleted)
+	cert, _, _, root = f.bundle.GetAllPem()
+	assert.Equal(t, cert, next.CertPem)
+	assert.Equal(t, root, next.RootCertPem)
+
+	// Deleting the rotation secret once the rotation completed discards the state.
+	assert.NoError(t, f.rotator.Abort())
+	assert.Equal(t, store.secret, nil)
+	f = restart()
+	assert.Equal(t, f.rotator.Status().Phase, PhaseIdle)
+	cert, _, _, _ = f.bundle.GetAllPem()
+	assert.Equal(t, cert, old.CertPem)
+}
+
+func TestRestoreDiscardsStateAfterCAChange(t *testing.T) {
+	store := &memoryStore{}
+	f := newFixtureFrom(t, newCA(t, "old"), store)
+	assert.NoError(t, f.rotator.Start(newCA(t, "new")))
+	f.rotator.Reconcile()
+	assert.Equal(t, f.rotator.Status().Phase, PhaseOverlap)
+
+	// The CA material was replaced outside of the rotation while istiod was down.
+	replaced := newCA(t, "replaced")
+	f = newFixtureFrom(t, replaced, store)
+	assert.NoError(t, f.rotator.Restore())
+	assert.Equal(t, f.rotator.Status().Phase, PhaseIdle)
+	assert.Equal(t, store.secret, nil)
+	cert, _, _, _ := f.bundle.GetAllPem()
+	assert.Equal(t, cert, replaced.CertPem)
+}
+
+func TestRequestFromSecret(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        SecretName,
+			Namespace:   "istio-system",
+			Annotations: map[string]string{OverlapAnnotation: "12h"},
+		},
+		Data: map[string][]byte{
+			ca.CACertFile:       []byte("cert"),
+			ca.CAPrivateKeyFile: []byte("key"),
+			ca.RootCertFile:     []byte("root"),
+		},
+	}
+	req, err := RequestFromSecret(secret)
+	assert.NoError(t, err)
+	assert.Equal(t, req.CertChainPem, []byte("cert"))
+	assert.Equal(t, req.Overlap, 12*time.Hour)
+
+	delete(secret.Data, ca.CAPrivateKeyFile)
+	_, err = RequestFromSecret(secret)
+	assert.Error(t, err)
+}
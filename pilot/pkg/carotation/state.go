@@ -0,0 +1,144 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package carotation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/security/pkg/pki/ca"
+)
+
+const (
+	// StatusSecretName is the name of the secret, in the istiod namespace, the rotation state is persisted to.
+	// It holds the CA material rotated to, with the layout of the cacerts secret, and the phase of the rotation.
+	StatusSecretName = "istio-ca-rotation-status"
+
+	// oldCertKey holds the certificate the CA signed with when the rotation started.
+	oldCertKey = "old-ca-cert.pem"
+	// statusKey holds the Status of the rotation, in JSON.
+	statusKey = "status.json"
+)
+
+// Store persists the rotation state, so a rotation resumes where it left off when istiod restarts.
+type Store interface {
+	// Get returns the persisted rotation state, or nil if there is none.
+	Get() (*corev1.Secret, error)
+	// Put persists the rotation state.
+	Put(secret *corev1.Secret) error
+	// Delete removes the persisted rotation state, if any.
+	Delete() error
+}
+
+// Restore resumes the rotation persisted in the store, if any: the new root is distributed again, or the CA
+// signs again with the new material, depending on the persisted phase. It must be called before the rotation
+// is started or run.
+//
+// The persisted state is discarded if the CA no longer signs with the certificate it signed with when the
+// rotation started, as the CA material was then replaced outside of the rotation.
+func (r *Rotator) Restore() error {
+	if r.opts.Store == nil {
+		return nil
+	}
+	secret, err := r.opts.Store.Get()
+	if err != nil || secret == nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	req, err := RequestFromSecret(secret)
+	var status Status
+	if err == nil {
+		err = json.Unmarshal(secret.Data[statusKey], &status)
+	}
+	if err != nil {
+		rotationLog.Warnf("discarding invalid CA rotation state: %v", err)
+		return r.opts.Store.Delete()
+	}
+	bundle := r.ca.GetCAKeyCertBundle()
+	cert, key, chain, root := bundle.GetAllPem()
+	if !bytes.Equal(cert, secret.Data[oldCertKey]) {
+		rotationLog.Infof("discarding CA rotation state: the CA material changed since the rotation started")
+		return r.opts.Store.Delete()
+	}
+
+	r.req = &req
+	r.old = oldMaterial{cert: cert, key: key, chain: chain, root: root, crl: bundle.GetCRLPem()}
+	r.status = status
+	switch status.Phase {
+	case PhaseDistributing:
+		err = r.setRotationAnchor(req.RootCertPem)
+	case PhaseOverlap:
+		err = r.switchSigning()
+	case PhaseCompleted:
+		err = r.removeOldRoot()
+	default:
+		r.req = nil
+		r.status = Status{Phase: PhaseIdle}
+		return r.opts.Store.Delete()
+	}
+	if err != nil {
+		r.status.Message = fmt.Sprintf("failed to restore the rotation, will retry: %v", err)
+		return fmt.Errorf("failed to restore the CA rotation in phase %s: %v", status.Phase, err)
+	}
+	rotationLog.Infof("restored CA rotation to %s in phase %s", status.NewRoot.Subject, status.Phase)
+	return nil
+}
+
+// persist saves the rotation state. A failure is retried on the next reconciliation. Until then, a restarted
+// istiod resumes the rotation from an earlier phase, which is safe as every phase can be entered again.
+func (r *Rotator) persist() {
+	if r.opts.Store == nil {
+		return
+	}
+	var err error
+	if r.req == nil || r.status.Phase == PhaseIdle || r.status.Phase == PhaseFailed {
+		err = r.opts.Store.Delete()
+	} else {
+		err = r.opts.Store.Put(r.stateSecret())
+	}
+	r.dirty = err != nil
+	if err != nil {
+		rotationLog.Warnf("failed to persist the CA rotation state, will retry: %v", err)
+	}
+}
+
+func (r *Rotator) stateSecret() *corev1.Secret {
+	status := r.status
+	status.AckedProxies, status.PendingProxies = 0, nil
+	// Status only holds plain fields, so it always marshals.
+	st, _ := json.Marshal(status)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: StatusSecretName},
+		Data: map[string][]byte{
+			ca.CACertFile:       r.req.CertPem,
+			ca.CAPrivateKeyFile: r.req.KeyPem,
+			ca.CertChainFile:    r.req.CertChainPem,
+			ca.RootCertFile:     r.req.RootCertPem,
+			oldCertKey:          r.old.cert,
+			statusKey:           st,
+		},
+	}
+	if r.req.Overlap > 0 {
+		secret.Annotations = map[string]string{OverlapAnnotation: r.req.Overlap.String()}
+	}
+	return secret
+}
@@ -23,9 +23,11 @@ import (
 
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model/kstatus"
+	"istio.io/istio/pkg/config"
 	"istio.io/istio/pkg/config/schema/gvk"
 	"istio.io/istio/pkg/kube/controllers"
 	"istio.io/istio/pkg/maps"
+	"istio.io/istio/pkg/ptr"
 	"istio.io/istio/pkg/slices"
 	"istio.io/istio/pkg/util/sets"
 )
@@ -409,6 +411,21 @@ func generateSupportedKinds(l k8s.Listener) ([]k8s.RouteGroupKind, bool) {
 	return supported, true
 }
 
+// RouteKindAllowed returns true if a listener accepts the routes of a kind.
+func RouteKindAllowed(l k8s.Listener, kind config.GroupVersionKind) bool {
+	supported, _ := generateSupportedKinds(l)
+	return kindAllowed(supported, kind)
+}
+
+func kindAllowed(allowed []k8s.RouteGroupKind, kind config.GroupVersionKind) bool {
+	for _, ak := range allowed {
+		if string(ak.Kind) == kind.Kind && ptr.OrDefault((*string)(ak.Group), gvk.GatewayClass.Group) == kind.Group {
+			return true
+		}
+	}
+	return false
+}
+
 func FilterInPlaceByIndex[E any](s []E, keep func(int) bool) []E {
 	i := 0
 	for j := 0; j < len(s); j++ {
@@ -18,6 +18,7 @@ import (
 	"cmp"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"net"
 	"net/netip"
@@ -110,28 +111,11 @@ func convertHTTPRoute(ctx RouteContext, r k8s.HTTPRouteRule,
 	}
 
 	for _, match := range r.Matches {
-		uri, err := createURIMatch(match)
+		m, err := convertHTTPRouteMatch(match)
 		if err != nil {
 			return nil, nil, err
 		}
-		headers, err := createHeadersMatch(match)
-		if err != nil {
-			return nil, nil, err
-		}
-		qp, err := createQueryParamsMatch(match)
-		if err != nil {
-			return nil, nil, err
-		}
-		method, err := createMethodMatch(match)
-		if err != nil {
-			return nil, nil, err
-		}
-		vs.Match = append(vs.Match, &istio.HTTPMatchRequest{
-			Uri:         uri,
-			Headers:     headers,
-			QueryParams: qp,
-			Method:      method,
-		})
+		vs.Match = append(vs.Match, m)
 	}
 	var mirrorBackendErr *ConfigError
 	for _, filter := range r.Filters {
@@ -236,6 +220,66 @@ func convertHTTPRoute(ctx RouteContext, r k8s.HTTPRouteRule,
 	return vs, nil, mirrorBackendErr
 }
 
+// ConvertHTTPRouteMatch converts the match of an HTTPRoute rule to the match of a VirtualService route, as done when
+// translating the route.
+func ConvertHTTPRouteMatch(match k8s.HTTPRouteMatch) (*istio.HTTPMatchRequest, error) {
+	m, err := convertHTTPRouteMatch(match)
+	if err != nil {
+		return nil, errors.New(err.Message)
+	}
+	return m, nil
+}
+
+func convertHTTPRouteMatch(match k8s.HTTPRouteMatch) (*istio.HTTPMatchRequest, *ConfigError) {
+	uri, err := createURIMatch(match)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := createHeadersMatch(match)
+	if err != nil {
+		return nil, err
+	}
+	qp, err := createQueryParamsMatch(match)
+	if err != nil {
+		return nil, err
+	}
+	method, err := createMethodMatch(match)
+	if err != nil {
+		return nil, err
+	}
+	return &istio.HTTPMatchRequest{
+		Uri:         uri,
+		Headers:     headers,
+		QueryParams: qp,
+		Method:      method,
+	}, nil
+}
+
+// ConvertGRPCRouteMatch converts the match of a GRPCRoute rule to the match of a VirtualService route, as done when
+// translating the route.
+func ConvertGRPCRouteMatch(match k8s.GRPCRouteMatch) (*istio.HTTPMatchRequest, error) {
+	m, err := convertGRPCRouteMatch(match)
+	if err != nil {
+		return nil, errors.New(err.Message)
+	}
+	return m, nil
+}
+
+func convertGRPCRouteMatch(match k8s.GRPCRouteMatch) (*istio.HTTPMatchRequest, *ConfigError) {
+	uri, err := createGRPCURIMatch(match)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := createGRPCHeadersMatch(match)
+	if err != nil {
+		return nil, err
+	}
+	return &istio.HTTPMatchRequest{
+		Uri:     uri,
+		Headers: headers,
+	}, nil
+}
+
 func joinErrors(a *ConfigError, b *ConfigError) *ConfigError {
 	if b == nil {
 		return a
@@ -260,18 +304,11 @@ func convertGRPCRoute(ctx RouteContext, r k8s.GRPCRouteRule,
 	}
 
 	for _, match := range r.Matches {
-		uri, err := createGRPCURIMatch(match)
-		if err != nil {
-			return nil, err
-		}
-		headers, err := createGRPCHeadersMatch(match)
+		m, err := convertGRPCRouteMatch(match)
 		if err != nil {
 			return nil, err
 		}
-		vs.Match = append(vs.Match, &istio.HTTPMatchRequest{
-			Uri:     uri,
-			Headers: headers,
-		})
+		vs.Match = append(vs.Match, m)
 	}
 	for _, filter := range r.Filters {
 		switch filter.Type {
@@ -411,9 +448,9 @@ func routeMeta(obj controllers.Object) map[string]string {
 	return m
 }
 
-// sortHTTPRoutes sorts generated vs routes to meet gateway-api requirements
+// SortHTTPRoutes sorts generated vs routes to meet gateway-api requirements
 // see https://gateway-api.sigs.k8s.io/v1alpha2/references/spec/#gateway.networking.k8s.io/v1alpha2.HTTPRouteRule
-func sortHTTPRoutes(routes []*istio.HTTPRoute) {
+func SortHTTPRoutes(routes []*istio.HTTPRoute) {
 	sort.SliceStable(routes, func(i, j int) bool {
 		if len(routes[i].Match) == 0 {
 			return false
@@ -496,6 +533,24 @@ func hostnameToStringList(h []k8s.Hostname) []string {
 	})
 }
 
+// RouteHosts returns the hosts a route applies to on a listener: the hostnames of the route matching the hostname of
+// the listener, narrowed down to the hostname of the listener. Routes without hostnames match all hostnames.
+func RouteHosts(hostnames []k8s.Hostname, listenerHostname string) []string {
+	if listenerHostname == "" {
+		listenerHostname = "*"
+	}
+	var hosts []string
+	for _, h := range hostnameToStringList(hostnames) {
+		switch {
+		case host.Name(h).SubsetOf(host.Name(listenerHostname)):
+			hosts = append(hosts, h)
+		case host.Name(listenerHostname).SubsetOf(host.Name(h)):
+			hosts = append(hosts, listenerHostname)
+		}
+	}
+	return hosts
+}
+
 var allowedParentReferences = sets.New(
 	gvk.KubernetesGateway,
 	gvk.Service,
@@ -659,14 +714,7 @@ func referenceAllowed(
 		}
 	}
 	// Also make sure this route kind is allowed
-	matched := false
-	for _, ak := range parent.AllowedKinds {
-		if string(ak.Kind) == routeKind.Kind && ptr.OrDefault((*string)(ak.Group), gvk.GatewayClass.Group) == routeKind.Group {
-			matched = true
-			break
-		}
-	}
-	if !matched {
+	if !kindAllowed(parent.AllowedKinds, routeKind) {
 		return &ParentError{
 			Reason:  ParentErrorNotAllowed,
 			Message: fmt.Sprintf("kind %v is not allowed", routeKind),
@@ -2413,6 +2461,22 @@ func namespacesFromSelector(ctx krt.HandlerContext, localNamespace string, names
 	return namespaces
 }
 
+// RouteNamespaceAllowed returns true if the AllowedRoutes of a listener of a Gateway of gatewayNamespace accept the
+// routes of a namespace, given its labels. It is the check namespacesFromSelector applies to each namespace.
+func RouteNamespaceAllowed(lr *k8s.AllowedRoutes, gatewayNamespace, namespace string, labels map[string]string) bool {
+	if lr == nil || lr.Namespaces == nil || lr.Namespaces.From == nil || *lr.Namespaces.From == k8s.NamespacesFromSame {
+		return namespace == gatewayNamespace
+	}
+	if *lr.Namespaces.From == k8s.NamespacesFromAll || lr.Namespaces.Selector == nil {
+		return true
+	}
+	ls, err := metav1.LabelSelectorAsSelector(lr.Namespaces.Selector)
+	if err != nil {
+		return false
+	}
+	return ls.Matches(toNamespaceSet(namespace, labels))
+}
+
 // namespaceAcceptedByAllowListeners determines a list of allowed namespaces for a given AllowedListener
 func namespaceAcceptedByAllowListeners(localNamespace string, parent *k8sbeta.Gateway, lookupNamespace func(string) *corev1.Namespace) bool {
 	lr := parent.Spec.AllowedListeners
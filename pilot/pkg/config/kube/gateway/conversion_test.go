@@ -56,6 +56,7 @@ import (
 	"istio.io/istio/pkg/ptr"
 	"istio.io/istio/pkg/slices"
 	"istio.io/istio/pkg/test"
+	"istio.io/istio/pkg/test/util/assert"
 	"istio.io/istio/pkg/util/sets"
 )
 
@@ -1290,7 +1291,7 @@ func TestSortHTTPRoutes(t *testing.T) {
 
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
-			sortHTTPRoutes(tt.in)
+			SortHTTPRoutes(tt.in)
 			if !reflect.DeepEqual(tt.in, tt.out) {
 				t.Fatalf("expected %v, got %v", tt.out, tt.in)
 			}
@@ -1789,3 +1790,29 @@ func kubernetesObjectsFromString(s string) ([]runtime.Object, error) {
 func firstValue[T, U any](val T, _ U) T {
 	return val
 }
+
+func TestRouteHosts(t *testing.T) {
+	assert.Equal(t, RouteHosts(nil, ""), []string{"*"})
+	assert.Equal(t, RouteHosts(nil, "*.example.com"), []string{"*.example.com"})
+	assert.Equal(t, RouteHosts([]k8s.Hostname{"a.example.com", "b.other.com", "*"}, "*.example.com"),
+		[]string{"a.example.com", "*.example.com"})
+}
+
+func TestRouteListenerChecks(t *testing.T) {
+	selector := &k8s.AllowedRoutes{Namespaces: &k8s.RouteNamespaces{
+		From:     ptr.Of(k8s.NamespacesFromSelector),
+		Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"gateway": "true"}},
+	}}
+	assert.Equal(t, RouteNamespaceAllowed(nil, "gw", "gw", nil), true)
+	assert.Equal(t, RouteNamespaceAllowed(nil, "gw", "apps", nil), false)
+	assert.Equal(t, RouteNamespaceAllowed(selector, "gw", "apps", map[string]string{"gateway": "true"}), true)
+	assert.Equal(t, RouteNamespaceAllowed(selector, "gw", "other", nil), false)
+
+	http := k8s.Listener{Protocol: k8s.HTTPProtocolType}
+	passthrough := k8s.Listener{Protocol: k8s.TLSProtocolType, TLS: &k8s.ListenerTLSConfig{Mode: ptr.Of(k8s.TLSModePassthrough)}}
+	assert.Equal(t, RouteKindAllowed(http, gvk.HTTPRoute), true)
+	assert.Equal(t, RouteKindAllowed(http, gvk.TLSRoute), false)
+	assert.Equal(t, RouteKindAllowed(passthrough, gvk.TLSRoute), true)
+	http.AllowedRoutes = &k8s.AllowedRoutes{Kinds: []k8s.RouteGroupKind{{Kind: k8s.Kind(gvk.GRPCRoute.Kind)}}}
+	assert.Equal(t, RouteKindAllowed(http, gvk.HTTPRoute), false)
+}
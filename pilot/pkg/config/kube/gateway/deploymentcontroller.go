@@ -722,6 +722,13 @@ func (d *DeploymentController) render(templateName string, mi TemplateInput) ([]
 		}
 		templateOverlays = append(templateOverlays, cm.Data)
 	}
+	scaling, err := extractScalingOverlays(mi.Gateway, templateOverlays)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scaling annotations: %v", err)
+	}
+	if scaling != nil {
+		templateOverlays = append(templateOverlays, scaling)
+	}
 
 	labelToMatch := map[string]string{label.IoK8sNetworkingGatewayGatewayName.Name: mi.Name}
 	proxyConfig := d.env.GetProxyConfigOrDefault(mi.Namespace, labelToMatch, nil, cfg.MeshConfig)
@@ -866,6 +873,176 @@ func fetchParameters(gw *gateway.Gateway) (*types.NamespacedName, error) {
 	return nil, nil
 }
 
+const (
+	// AutoscalingMinReplicasAnnotation sets the minimum replicas of the generated HorizontalPodAutoscaler.
+	AutoscalingMinReplicasAnnotation = "gateway.istio.io/autoscaling-min-replicas"
+	// AutoscalingMaxReplicasAnnotation sets the maximum replicas of the generated HorizontalPodAutoscaler.
+	// Setting this annotation (or the min replicas annotation) opts the Gateway into HorizontalPodAutoscaler generation.
+	AutoscalingMaxReplicasAnnotation = "gateway.istio.io/autoscaling-max-replicas"
+	// AutoscalingTargetCPUAnnotation sets the average CPU utilization percentage targeted by the generated
+	// HorizontalPodAutoscaler.
+	AutoscalingTargetCPUAnnotation = "gateway.istio.io/autoscaling-target-cpu-utilization"
+	// DisruptionBudgetMinAvailableAnnotation sets minAvailable on the generated PodDisruptionBudget.
+	// This may be an integer or a percentage.
+	DisruptionBudgetMinAvailableAnnotation = "gateway.istio.io/disruption-budget-min-available"
+	// DisruptionBudgetMaxUnavailableAnnotation sets maxUnavailable on the generated PodDisruptionBudget.
+	// This may be an integer or a percentage.
+	DisruptionBudgetMaxUnavailableAnnotation = "gateway.istio.io/disruption-budget-max-unavailable"
+)
+
+// extractScalingOverlays builds horizontalPodAutoscaler and podDisruptionBudget overlays from the scaling
+// annotations on the Gateway. These are applied after any class defaults and parametersRef overlays, so an
+// annotation on the Gateway always takes precedence. The class defaults and parametersRef overlays are used to keep
+// the fields the annotations do not set consistent with them.
+// If no scaling annotations are set, nil is returned and no HorizontalPodAutoscaler or PodDisruptionBudget is rendered
+// (unless requested by another overlay).
+func extractScalingOverlays(gw *gateway.Gateway, previous []map[string]string) (map[string]string, error) {
+	overlays := map[string]string{}
+
+	hpa := map[string]any{}
+	var minReplicas, maxReplicas int
+	if v, f := gw.Annotations[AutoscalingMinReplicasAnnotation]; f {
+		n, err := parsePositiveInt(AutoscalingMinReplicasAnnotation, v)
+		if err != nil {
+			return nil, err
+		}
+		minReplicas = n
+		hpa["minReplicas"] = n
+	}
+	if v, f := gw.Annotations[AutoscalingMaxReplicasAnnotation]; f {
+		n, err := parsePositiveInt(AutoscalingMaxReplicasAnnotation, v)
+		if err != nil {
+			return nil, err
+		}
+		maxReplicas = n
+		hpa["maxReplicas"] = n
+	}
+	if minReplicas > 0 && maxReplicas > 0 && minReplicas > maxReplicas {
+		return nil, fmt.Errorf("%s (%d) must not exceed %s (%d)",
+			AutoscalingMinReplicasAnnotation, minReplicas, AutoscalingMaxReplicasAnnotation, maxReplicas)
+	}
+	if minReplicas > 0 && maxReplicas == 0 {
+		// The template defaults maxReplicas to 1, which would be invalid with a larger minimum. A maxReplicas set by
+		// another overlay is kept.
+		previousMax, err := overlayMaxReplicas(previous)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case previousMax == 0:
+			hpa["maxReplicas"] = minReplicas
+		case previousMax < minReplicas:
+			return nil, fmt.Errorf("%s (%d) must not exceed the maxReplicas (%d) of the gateway parameters",
+				AutoscalingMinReplicasAnnotation, minReplicas, previousMax)
+		}
+	}
+	if v, f := gw.Annotations[AutoscalingTargetCPUAnnotation]; f {
+		if len(hpa) == 0 {
+			return nil, fmt.Errorf("%s requires %s or %s to be set",
+				AutoscalingTargetCPUAnnotation, AutoscalingMinReplicasAnnotation, AutoscalingMaxReplicasAnnotation)
+		}
+		n, err := parsePositiveInt(AutoscalingTargetCPUAnnotation, v)
+		if err != nil {
+			return nil, err
+		}
+		hpa["metrics"] = []any{map[string]any{
+			"type": "Resource",
+			"resource": map[string]any{
+				"name": "cpu",
+				"target": map[string]any{
+					"type":               "Utilization",
+					"averageUtilization": n,
+				},
+			},
+		}}
+	}
+	if len(hpa) > 0 {
+		b, err := yaml.Marshal(map[string]any{"spec": hpa})
+		if err != nil {
+			return nil, err
+		}
+		overlays["horizontalPodAutoscaler"] = string(b)
+	}
+
+	pdb := map[string]any{}
+	minAvailable, hasMin := gw.Annotations[DisruptionBudgetMinAvailableAnnotation]
+	maxUnavailable, hasMax := gw.Annotations[DisruptionBudgetMaxUnavailableAnnotation]
+	if hasMin && hasMax {
+		return nil, fmt.Errorf("only one of %s and %s may be set",
+			DisruptionBudgetMinAvailableAnnotation, DisruptionBudgetMaxUnavailableAnnotation)
+	}
+	if hasMin {
+		v, err := parseIntOrPercent(DisruptionBudgetMinAvailableAnnotation, minAvailable)
+		if err != nil {
+			return nil, err
+		}
+		pdb["minAvailable"] = v
+		// A PodDisruptionBudget may only set one of the fields; drop the one set by another overlay.
+		pdb["maxUnavailable"] = nil
+	}
+	if hasMax {
+		v, err := parseIntOrPercent(DisruptionBudgetMaxUnavailableAnnotation, maxUnavailable)
+		if err != nil {
+			return nil, err
+		}
+		pdb["maxUnavailable"] = v
+		pdb["minAvailable"] = nil
+	}
+	if len(pdb) > 0 {
+		b, err := yaml.Marshal(map[string]any{"spec": pdb})
+		if err != nil {
+			return nil, err
+		}
+		overlays["podDisruptionBudget"] = string(b)
+	}
+
+	if len(overlays) == 0 {
+		return nil, nil
+	}
+	return overlays, nil
+}
+
+// overlayMaxReplicas returns the maxReplicas set by the horizontalPodAutoscaler overlays, the last one winning, or 0.
+func overlayMaxReplicas(overlays []map[string]string) (int, error) {
+	maxReplicas := 0
+	for _, overlays := range overlays {
+		overlay, f := overlays["horizontalPodAutoscaler"]
+		if !f {
+			continue
+		}
+		hpa := autoscalingv2.HorizontalPodAutoscaler{}
+		if err := yaml.Unmarshal([]byte(overlay), &hpa); err != nil {
+			return 0, fmt.Errorf("invalid horizontalPodAutoscaler overlay: %v", err)
+		}
+		if hpa.Spec.MaxReplicas > 0 {
+			maxReplicas = int(hpa.Spec.MaxReplicas)
+		}
+	}
+	return maxReplicas, nil
+}
+
+func parsePositiveInt(name, v string) (int, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer, got %q", name, v)
+	}
+	return n, nil
+}
+
+func parseIntOrPercent(name, v string) (any, error) {
+	if pct, ok := strings.CutSuffix(v, "%"); ok {
+		if n, err := strconv.Atoi(pct); err != nil || n < 0 || n > 100 {
+			return nil, fmt.Errorf("%s must be an integer or a percentage, got %q", name, v)
+		}
+		return v, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("%s must be an integer or a percentage, got %q", name, v)
+	}
+	return n, nil
+}
+
 func (d *DeploymentController) setGatewayControllerVersion(gws gateway.Gateway) error {
 	patch := fmt.Sprintf(`{"apiVersion":"gateway.networking.k8s.io/v1beta1","kind":"Gateway","metadata":{"annotations":{"%s":"%d"}}}`,
 		ControllerVersionAnnotation, ControllerVersion)
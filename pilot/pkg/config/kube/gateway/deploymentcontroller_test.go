@@ -24,7 +24,9 @@ import (
 
 	"go.uber.org/atomic"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -480,6 +482,34 @@ spec:
 			}),
 			values: ``,
 		},
+		{
+			name: "scaling-annotations",
+			gw: k8sbeta.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "waypoint",
+					Namespace: "default",
+					Annotations: map[string]string{
+						AutoscalingMinReplicasAnnotation:       "2",
+						AutoscalingMaxReplicasAnnotation:       "5",
+						AutoscalingTargetCPUAnnotation:         "80",
+						DisruptionBudgetMinAvailableAnnotation: "50%",
+					},
+				},
+				Spec: k8s.GatewaySpec{
+					GatewayClassName: constants.WaypointGatewayClassName,
+					Listeners: []k8s.Listener{{
+						Name:     "mesh",
+						Port:     k8s.PortNumber(15008),
+						Protocol: "ALL",
+					}},
+				},
+			},
+			objects: defaultObjects,
+			values: `global:
+  hub: test
+  tag: test
+  network: network-1`,
+		},
 		{
 			name: "illegal_customizations",
 			gw: k8sbeta.Gateway{
@@ -686,6 +716,150 @@ func TestMeshGatewayReconciliation(t *testing.T) {
 	assert.ChannelIsEmpty(t, writes)
 }
 
+func TestExtractScalingOverlays(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		previous    []map[string]string
+		want        map[string]string
+		wantErr     bool
+	}{
+		{
+			name: "none",
+			want: nil,
+		},
+		{
+			name: "min only",
+			annotations: map[string]string{
+				AutoscalingMinReplicasAnnotation: "3",
+			},
+			want: map[string]string{
+				"horizontalPodAutoscaler": "spec:\n  maxReplicas: 3\n  minReplicas: 3\n",
+			},
+		},
+		{
+			name: "pdb percentage",
+			annotations: map[string]string{
+				DisruptionBudgetMaxUnavailableAnnotation: "25%",
+			},
+			want: map[string]string{
+				"podDisruptionBudget": "spec:\n  maxUnavailable: 25%\n  minAvailable: null\n",
+			},
+		},
+		{
+			name: "min only with parameters max",
+			annotations: map[string]string{
+				AutoscalingMinReplicasAnnotation: "3",
+			},
+			previous: []map[string]string{{"horizontalPodAutoscaler": "spec:\n  maxReplicas: 10\n"}},
+			want: map[string]string{
+				"horizontalPodAutoscaler": "spec:\n  minReplicas: 3\n",
+			},
+		},
+		{
+			name: "min exceeds parameters max",
+			annotations: map[string]string{
+				AutoscalingMinReplicasAnnotation: "3",
+			},
+			previous: []map[string]string{{"horizontalPodAutoscaler": "spec:\n  maxReplicas: 2\n"}},
+			wantErr:  true,
+		},
+		{
+			name: "pdb with parameters",
+			annotations: map[string]string{
+				DisruptionBudgetMinAvailableAnnotation: "50%",
+			},
+			previous: []map[string]string{{"podDisruptionBudget": "spec:\n  maxUnavailable: 1\n"}},
+			want: map[string]string{
+				"podDisruptionBudget": "spec:\n  maxUnavailable: null\n  minAvailable: 50%\n",
+			},
+		},
+		{
+			name: "min exceeds max",
+			annotations: map[string]string{
+				AutoscalingMinReplicasAnnotation: "4",
+				AutoscalingMaxReplicasAnnotation: "2",
+			},
+			wantErr: true,
+		},
+		{
+			name: "cpu without replicas",
+			annotations: map[string]string{
+				AutoscalingTargetCPUAnnotation: "80",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid replicas",
+			annotations: map[string]string{
+				AutoscalingMaxReplicasAnnotation: "zero",
+			},
+			wantErr: true,
+		},
+		{
+			name: "both pdb fields",
+			annotations: map[string]string{
+				DisruptionBudgetMinAvailableAnnotation:   "1",
+				DisruptionBudgetMaxUnavailableAnnotation: "1",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			gw := &k8sbeta.Gateway{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			got, err := extractScalingOverlays(gw, tt.previous)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
+
+func TestScalingOverlaysWithParameters(t *testing.T) {
+	parameters := map[string]string{
+		"horizontalPodAutoscaler": "spec:\n  minReplicas: 2\n  maxReplicas: 10\n",
+		"podDisruptionBudget":     "spec:\n  maxUnavailable: 1\n",
+	}
+	gw := &k8sbeta.Gateway{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		AutoscalingMinReplicasAnnotation:       "4",
+		DisruptionBudgetMinAvailableAnnotation: "50%",
+	}}}
+	scaling, err := extractScalingOverlays(gw, []map[string]string{parameters})
+	assert.NoError(t, err)
+	overlays := []map[string]string{parameters, scaling}
+
+	out, err := applyOverlay(`apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: gw
+spec:
+  maxReplicas: 1
+`, overlays)
+	assert.NoError(t, err)
+	hpa := autoscalingv2.HorizontalPodAutoscaler{}
+	assert.NoError(t, yaml.Unmarshal([]byte(out), &hpa))
+	// The maxReplicas of the parameters is kept.
+	assert.Equal(t, *hpa.Spec.MinReplicas, int32(4))
+	assert.Equal(t, hpa.Spec.MaxReplicas, int32(10))
+
+	out, err = applyOverlay(`apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: gw
+spec: {}
+`, overlays)
+	assert.NoError(t, err)
+	pdb := policyv1.PodDisruptionBudget{}
+	assert.NoError(t, yaml.Unmarshal([]byte(out), &pdb))
+	// Only the field set by the annotation is kept.
+	assert.Equal(t, pdb.Spec.MinAvailable.String(), "50%")
+	assert.Equal(t, pdb.Spec.MaxUnavailable == nil, true)
+}
+
 func buildFilter(allowedNamespace string) kubetypes.DynamicObjectFilter {
 	return kubetypes.NewStaticObjectFilter(func(obj any) bool {
 		if ns, ok := obj.(string); ok {
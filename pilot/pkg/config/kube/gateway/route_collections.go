@@ -159,7 +159,7 @@ func HTTPRouteCollection(
 					continue
 				}
 				name := obj.Name + "~" + strconv.Itoa(count) + "~" + constants.KubernetesGatewayName
-				sortHTTPRoutes(routes)
+				SortHTTPRoutes(routes)
 
 				// Populate Extra field for inference pool configs
 				extraData := make(map[string]any)
@@ -352,7 +352,7 @@ func GRPCRouteCollection(
 					continue
 				}
 				name := fmt.Sprintf("%s~%d~%s", obj.Name, count, constants.KubernetesGatewayName)
-				sortHTTPRoutes(routes)
+				SortHTTPRoutes(routes)
 
 				// Populate Extra field for inference pool configs (if GRPCRoute supports them)
 				extraData := make(map[string]any)
@@ -778,7 +778,7 @@ func mergeHTTPRoutes(baseVirtualServices krt.Collection[RouteWithKey], opts ...k
 			base.Annotations[constants.InternalParentNames] = fmt.Sprintf("%s,%s",
 				base.Annotations[constants.InternalParentNames], config.Annotations[constants.InternalParentNames])
 		}
-		sortHTTPRoutes(baseVS.Http)
+		SortHTTPRoutes(baseVS.Http)
 		base.Name = strings.ReplaceAll(object.Key, "/", "~")
 		return ptr.Of(&base)
 	}, opts...)
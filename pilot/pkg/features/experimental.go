@@ -116,6 +116,20 @@ var (
 			"Istio Resources",
 	).Get()
 
+	AnalysisCustomAnalyzers = env.Register(
+		"PILOT_ANALYSIS_CUSTOM_ANALYZERS",
+		"",
+		"If analysis is enabled, a comma separated list of files or directories of custom analyzers, declared with "+
+			"CEL expressions, to run in addition to the built-in analyzers.",
+	).Get()
+
+	AnalysisCustomAnalyzersConfigMap = env.Register(
+		"PILOT_ANALYSIS_CUSTOM_ANALYZERS_CONFIGMAP",
+		"",
+		"If analysis is enabled, the name of a ConfigMap in the istiod namespace declaring custom analyzers under its "+
+			"\"analyzers\" key, to run in addition to the built-in analyzers. The analyzers are rebuilt when it changes.",
+	).Get()
+
 	AnalysisInterval = func() time.Duration {
 		val, _ := env.Register(
 			"PILOT_ANALYSIS_INTERVAL",
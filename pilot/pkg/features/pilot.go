@@ -114,6 +114,10 @@ var (
 		"If not empty, the controller will automatically patch validatingwebhookconfiguration when the CA certificate changes. "+
 			"Only works in kubernetes environment.").Get()
 
+	ValidationPolicyConfigMap = env.Register("PILOT_VALIDATION_POLICY_CONFIGMAP", "",
+		"If not empty, the name of a ConfigMap in the istiod namespace declaring organization policies, as CEL rules "+
+			"under its \"policies\" key, which the validation webhook enforces on Istio resources.").Get()
+
 	RemoteClusterTimeout = env.Register(
 		"PILOT_REMOTE_CLUSTER_TIMEOUT",
 		30*time.Second,
@@ -16,6 +16,7 @@ package features
 
 import (
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/types"
 
@@ -93,4 +94,45 @@ var (
 	UseCacertsForSelfSignedCA = env.Register("USE_CACERTS_FOR_SELF_SIGNED_CA", false,
 		"If enabled, istiod will use a secret named cacerts to store its self-signed istio-"+
 			"generated root certificate.").Get()
+
+	CertInventorySize = env.Register("CA_CERT_INVENTORY_SIZE", 0,
+		"The maximum number of issued workload certificates istiod tracks for the /debug/certz endpoint and "+
+			"the citadel_server_issued_certs metric. The oldest certificates are evicted first. 0, the default, disables tracking.").Get()
+
+	EnableCAJWTSVID = env.Register("ENABLE_CA_JWT_SVID", false,
+		"If enabled, istiod mints JWT-SVIDs for authenticated workloads, signed with dedicated keys stored in the "+
+			"istio-jwt-svid-keys secret. "+
+			"The istio-agent uses this to serve JWT-SVIDs over the SPIFFE Workload API.").Get()
+
+	CAJWTSVIDTTL = env.Register("CA_JWT_SVID_TTL", 5*time.Minute,
+		"The lifetime of the JWT-SVIDs minted by istiod.").Get()
+
+	CAJWTSVIDKeyRotation = env.Register("CA_JWT_SVID_KEY_ROTATION", 24*time.Hour,
+		"How often istiod generates a new key to sign JWT-SVIDs with. Retired keys remain in the JWT bundle "+
+			"until the JWT-SVIDs they signed have expired.").Get()
+
+	EnableServiceDNSCerts = env.Register("ENABLE_SERVICE_DNS_CERTS", false,
+		"If enabled, istiod provisions serving certificates from the mesh CA into Secrets, for the Services annotated "+
+			"with security.istio.io/dns-cert-secret, and renews them. This only applies when istiod is the CA.").Get()
+
+	ServiceDNSCertTTL = env.Register("SERVICE_DNS_CERT_TTL", 30*24*time.Hour,
+		"The lifetime of the Service DNS certificates provisioned by istiod. They are renewed after half of their lifetime.").Get()
+
+	EnableCARevocation = env.Register("ENABLE_CA_REVOCATION", false,
+		"If enabled, istiod watches the istio-revoked-certificates ConfigMap in its namespace, distributes a "+
+			"certificate revocation list of the revoked serial numbers to proxies, and stops issuing certificates "+
+			"for revoked SPIFFE IDs. Proxies reject certificates from CAs without a CRL while any certificate is "+
+			"revoked, so this is only suited to meshes where istiod signs all workload certificates.").Get()
+
+	// CRLOnlyVerifyLeafCert is read by the agent, when serving the CRLs distributed by istiod to Envoy.
+	CRLOnlyVerifyLeafCert, CRLOnlyVerifyLeafCertSet = env.Register("CRL_ONLY_VERIFY_LEAF_CERT", true,
+		"If true, Envoy only checks the leaf certificate of peers against the CRLs distributed by istiod; if false, it "+
+			"checks every certificate of the chain, which requires a CRL for each of them. If unset, only the leaf is checked "+
+			"when istiod distributes a single CRL. Either way Envoy rejects peers whose issuer has no CRL, so clusters with "+
+			"different intermediate CAs need the CRLs of the other intermediates plugged into their ca-crl.pem.").Lookup()
+
+	EnableCAJoinTokens = env.Register("ENABLE_CA_JOIN_TOKENS", false,
+		"If enabled, the CA authenticates certificate signing requests with one-time join tokens, stored as "+
+			"Secrets in the istiod namespace. This allows workloads outside of Kubernetes, such as VMs, to get their "+
+			"first certificate without a Kubernetes token. Join tokens are minted by 'istioctl x workload entry configure --join-token'.").Get()
 )
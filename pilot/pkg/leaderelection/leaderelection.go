@@ -59,6 +59,7 @@ const (
 	InferencePoolController     = "istio-gateway-inferencepool"
 	NodeUntaintController       = "istio-node-untaint"
 	IPAutoallocateController    = "istio-ip-autoallocate"
+	ServiceCertController       = "istio-service-cert"
 )
 
 // Leader election key prefix for remote istiod managed clusters
@@ -0,0 +1,304 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/serviceregistry/provider"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/config/schema/kind"
+	"istio.io/istio/pkg/maps"
+	"istio.io/istio/pkg/ptr"
+	"istio.io/istio/pkg/slices"
+	"istio.io/istio/pkg/util/sets"
+)
+
+// Relations between the nodes of a ConfigGraph.
+const (
+	// ConfigGraphScopes links a Sidecar to the proxies it scopes.
+	ConfigGraphScopes = "scopes"
+	// ConfigGraphImports links a proxy to the services and routes visible to it.
+	ConfigGraphImports = "imports"
+	// ConfigGraphRoutesTo links a route to the services it sends traffic to.
+	ConfigGraphRoutesTo = "routes to"
+	// ConfigGraphDelegatesTo links a VirtualService to its delegates.
+	ConfigGraphDelegatesTo = "delegates to"
+	// ConfigGraphConfigures links a DestinationRule to the services it configures.
+	ConfigGraphConfigures = "configures"
+	// ConfigGraphAttachesTo links a route to the gateways it is bound to.
+	ConfigGraphAttachesTo = "attaches to"
+	// ConfigGraphSelects links a gateway or a policy to the proxies it selects.
+	ConfigGraphSelects = "selects"
+)
+
+// ConfigGraphNode is a configuration resource, a service or a proxy.
+type ConfigGraphNode struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ConfigGraphEdge is a relationship between two nodes, referenced by their ID.
+type ConfigGraphEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Relation string `json:"relation"`
+}
+
+// ConfigGraph is the graph of the configuration resources which apply to a proxy, and of the relationships between
+// them, as computed by the push context.
+type ConfigGraph struct {
+	Proxy string            `json:"proxy"`
+	Nodes []ConfigGraphNode `json:"nodes"`
+	Edges []ConfigGraphEdge `json:"edges"`
+
+	nodeIDs sets.String
+	edgeSet sets.Set[ConfigGraphEdge]
+}
+
+// ConfigGraph returns the graph of the configuration resources which apply to the proxy. It is built from the
+// indexes of the push context and the SidecarScope of the proxy, so it reflects the scoping and exportTo rules.
+// Resources generated from Gateway API resources are reported as the resources they are generated from.
+func (ps *PushContext) ConfigGraph(proxy *Proxy) *ConfigGraph {
+	g := &ConfigGraph{
+		Proxy:   proxy.ID,
+		Nodes:   []ConfigGraphNode{},
+		Edges:   []ConfigGraphEdge{},
+		nodeIDs: sets.New[string](),
+		edgeSet: sets.New[ConfigGraphEdge](),
+	}
+	p := g.addNode("Proxy", proxy.ID, "")
+
+	sc := proxy.SidecarScope
+	if sc != nil {
+		if sc.Sidecar != nil {
+			// A Sidecar of the root namespace applies to the namespaces without a Sidecar.
+			namespace := sc.Namespace
+			if !sc.configDependencies.Contains(ConfigKey{Kind: kind.Sidecar, Name: sc.Name, Namespace: namespace}.HashCode()) {
+				namespace = ps.Mesh.GetRootNamespace()
+			}
+			g.addEdge(g.addNode(gvk.Sidecar.Kind, sc.Name, namespace), p, ConfigGraphScopes)
+		}
+		for _, svc := range sc.Services() {
+			s := g.addService(svc)
+			g.addEdge(p, s, ConfigGraphImports)
+			dr := sc.DestinationRule(TrafficDirectionOutbound, proxy, svc.Hostname)
+			if dr == nil {
+				continue
+			}
+			for _, from := range dr.from {
+				c := sc.DestinationRuleByName(from.Name, from.Namespace)
+				for _, n := range g.addConfig(gvk.DestinationRule.Kind, from.Name, from.Namespace, c) {
+					g.addEdge(n, s, ConfigGraphConfigures)
+				}
+			}
+		}
+		for _, l := range sc.EgressListeners {
+			for _, vs := range l.VirtualServices() {
+				for _, n := range g.addVirtualService(ps, sc, vs) {
+					g.addEdge(p, n, ConfigGraphImports)
+				}
+			}
+		}
+	}
+
+	if proxy.MergedGateway != nil {
+		gateways := sets.New[string]()
+		for _, name := range proxy.MergedGateway.GatewayNameForServer {
+			gateways.Insert(name)
+		}
+		for _, name := range sets.SortedList(gateways) {
+			namespace, gwName, _ := strings.Cut(name, "/")
+			var gw *config.Config
+			for i, c := range ps.gatewayIndex.namespace[namespace] {
+				if c.Name == gwName {
+					gw = &ps.gatewayIndex.namespace[namespace][i]
+					break
+				}
+			}
+			gwNodes := g.addConfig(gvk.Gateway.Kind, gwName, namespace, gw)
+			for _, n := range gwNodes {
+				g.addEdge(n, p, ConfigGraphSelects)
+			}
+			for _, vs := range ps.VirtualServicesForGateway(proxy.ConfigNamespace, name) {
+				for _, n := range g.addVirtualService(ps, sc, vs) {
+					for _, gwn := range gwNodes {
+						g.addEdge(n, gwn, ConfigGraphAttachesTo)
+					}
+				}
+			}
+		}
+	}
+
+	matcher := PolicyMatcherForProxy(proxy).WithRootNamespace(ps.Mesh.GetRootNamespace())
+	authz := ps.AuthzPolicies.ListAuthorizationPolicies(matcher)
+	for _, policies := range [][]AuthorizationPolicy{authz.Custom, authz.Deny, authz.Allow, authz.Audit} {
+		for _, policy := range policies {
+			c := &config.Config{Meta: config.Meta{Name: policy.Name, Namespace: policy.Namespace, Annotations: policy.Annotations}}
+			for _, n := range g.addConfig(gvk.AuthorizationPolicy.Kind, policy.Name, policy.Namespace, c) {
+				g.addEdge(n, p, ConfigGraphSelects)
+			}
+		}
+	}
+	if ps.AuthnPolicies != nil {
+		for _, c := range ps.AuthnPolicies.GetPeerAuthenticationsForWorkload(matcher) {
+			g.addEdge(g.addNode(gvk.PeerAuthentication.Kind, c.Name, c.Namespace), p, ConfigGraphSelects)
+		}
+		for _, c := range ps.AuthnPolicies.GetJwtPoliciesForWorkload(matcher) {
+			g.addEdge(g.addNode(gvk.RequestAuthentication.Kind, c.Name, c.Namespace), p, ConfigGraphSelects)
+		}
+	}
+	telemetry := ps.Telemetry.applicableTelemetries(proxy, nil)
+	for _, t := range []types.NamespacedName{telemetry.Root, telemetry.Namespace, telemetry.Workload} {
+		if t.Name != "" {
+			g.addEdge(g.addNode(gvk.Telemetry.Kind, t.Name, t.Namespace), p, ConfigGraphSelects)
+		}
+	}
+	for _, plugins := range ps.WasmPlugins(proxy) {
+		for _, plugin := range plugins {
+			g.addEdge(g.addNode(gvk.WasmPlugin.Kind, plugin.Name, plugin.Namespace), p, ConfigGraphSelects)
+		}
+	}
+	if efw := ps.EnvoyFilters(proxy); efw != nil {
+		for _, patches := range efw.Patches {
+			for _, patch := range patches {
+				g.addEdge(g.addNode(gvk.EnvoyFilter.Kind, patch.Name, patch.Namespace), p, ConfigGraphSelects)
+			}
+		}
+	}
+
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Relation < b.Relation
+	})
+	return g
+}
+
+// addVirtualService adds the nodes of a VirtualService, linked to the services and delegates it routes to, and
+// returns them.
+func (g *ConfigGraph) addVirtualService(ps *PushContext, sc *SidecarScope, vs config.Config) []string {
+	nodes := g.addConfig(gvk.VirtualService.Kind, vs.Name, vs.Namespace, &vs)
+	var services []string
+	if spec, ok := vs.Spec.(*networking.VirtualService); ok && sc != nil {
+		for _, h := range slices.Sort(maps.Keys(virtualServiceDestinations(spec))) {
+			for _, svc := range sc.ServicesForHostname(host.Name(h)) {
+				services = append(services, g.addService(svc))
+			}
+		}
+	}
+	var delegates []string
+	for _, d := range ps.virtualServiceIndex.delegates[ConfigKey{Kind: kind.VirtualService, Namespace: vs.Namespace, Name: vs.Name}] {
+		delegates = append(delegates, g.addNode(gvk.VirtualService.Kind, d.Name, d.Namespace))
+	}
+	for _, n := range nodes {
+		for _, s := range services {
+			g.addEdge(n, s, ConfigGraphRoutesTo)
+		}
+		for _, d := range delegates {
+			g.addEdge(n, d, ConfigGraphDelegatesTo)
+		}
+	}
+	return nodes
+}
+
+// addConfig adds the nodes of a configuration resource, and returns them. A resource generated from Gateway API
+// resources is added as those resources.
+func (g *ConfigGraph) addConfig(kind, name, namespace string, c *config.Config) []string {
+	if c != nil {
+		if parents := c.Annotations[constants.InternalParentNames]; parents != "" {
+			var nodes []string
+			for _, parent := range strings.Split(parents, ",") {
+				// Parents are formatted as Kind/name.namespace, or Kind/name/section.namespace.
+				parentKind, rest, ok := strings.Cut(parent, "/")
+				idx := strings.LastIndex(rest, ".")
+				if !ok || idx < 0 {
+					continue
+				}
+				parentName, _, _ := strings.Cut(rest[:idx], "/")
+				nodes = append(nodes, g.addNode(parentKind, parentName, rest[idx+1:]))
+			}
+			if len(nodes) > 0 {
+				return nodes
+			}
+		}
+	}
+	return []string{g.addNode(kind, name, namespace)}
+}
+
+// addService adds the node of the Service or ServiceEntry declaring a service, and returns it.
+func (g *ConfigGraph) addService(svc *Service) string {
+	kind := gvk.Service.Kind
+	if svc.Attributes.ServiceRegistry == provider.External {
+		kind = gvk.ServiceEntry.Kind
+	}
+	return g.addNode(kind, ptr.NonEmptyOrDefault(svc.Attributes.ObjectName, svc.Attributes.Name), svc.Attributes.Namespace)
+}
+
+func (g *ConfigGraph) addNode(kind, name, namespace string) string {
+	id := kind + "/" + name
+	if namespace != "" {
+		id = kind + "/" + namespace + "/" + name
+	}
+	if !g.nodeIDs.InsertContains(id) {
+		g.Nodes = append(g.Nodes, ConfigGraphNode{ID: id, Kind: kind, Name: name, Namespace: namespace})
+	}
+	return id
+}
+
+func (g *ConfigGraph) addEdge(from, to, relation string) {
+	e := ConfigGraphEdge{From: from, To: to, Relation: relation}
+	if !g.edgeSet.InsertContains(e) {
+		g.Edges = append(g.Edges, e)
+	}
+}
+
+// DOT renders the graph in the Graphviz DOT language.
+func (g *ConfigGraph) DOT() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", strconv.Quote(g.Proxy))
+	b.WriteString("  rankdir=LR;\n  node [shape=box];\n")
+	for _, n := range g.Nodes {
+		label := n.Name
+		if n.Namespace != "" {
+			label = n.Namespace + "/" + n.Name
+		}
+		fmt.Fprintf(&b, "  %s [label=%s];\n", strconv.Quote(n.ID), strconv.Quote(n.Kind+"\n"+label))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", strconv.Quote(e.From), strconv.Quote(e.To), strconv.Quote(e.Relation))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,50 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/test/util/assert"
+	"istio.io/istio/pkg/util/sets"
+)
+
+func TestConfigGraphAddConfig(t *testing.T) {
+	g := &ConfigGraph{Proxy: "gw.istio-system", nodeIDs: sets.New[string](), edgeSet: sets.New[ConfigGraphEdge]()}
+	generated := &config.Config{Meta: config.Meta{
+		Name:        "gateway-istio-autogenerated-k8s-gateway-http",
+		Namespace:   "default",
+		Annotations: map[string]string{constants.InternalParentNames: "HTTPRoute/reviews.default,HTTPRoute/ratings/http.bookinfo"},
+	}}
+	assert.Equal(t, g.addConfig("VirtualService", generated.Name, generated.Namespace, generated),
+		[]string{"HTTPRoute/default/reviews", "HTTPRoute/bookinfo/ratings"})
+	assert.Equal(t, g.addConfig("VirtualService", "reviews", "default", nil), []string{"VirtualService/default/reviews"})
+
+	g.addEdge("HTTPRoute/default/reviews", g.addNode("Gateway", "gateway", "default"), ConfigGraphAttachesTo)
+	g.addEdge("HTTPRoute/default/reviews", "Gateway/default/gateway", ConfigGraphAttachesTo)
+	assert.Equal(t, len(g.Edges), 1)
+	assert.Equal(t, g.DOT(), `digraph "gw.istio-system" {
+  rankdir=LR;
+  node [shape=box];
+  "HTTPRoute/default/reviews" [label="HTTPRoute\ndefault/reviews"];
+  "HTTPRoute/bookinfo/ratings" [label="HTTPRoute\nbookinfo/ratings"];
+  "VirtualService/default/reviews" [label="VirtualService\ndefault/reviews"];
+  "Gateway/default/gateway" [label="Gateway\ndefault/gateway"];
+  "HTTPRoute/default/reviews" -> "Gateway/default/gateway" [label="attaches to"];
+}
+`)
+}
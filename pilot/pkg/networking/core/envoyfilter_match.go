@@ -0,0 +1,182 @@
+//go:build !agent
+
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/config/memory"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/serviceregistry/aggregate"
+	"istio.io/istio/pilot/pkg/serviceregistry/serviceentry"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/config/mesh/meshwatcher"
+	"istio.io/istio/pkg/config/schema/collections"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/kube/krt"
+)
+
+// UnmatchedEnvoyFilterPatches returns the indexes of the patches of an EnvoyFilter which select no element of a
+// reference proxy configuration, and thus have no effect on it. The reference configuration is generated from the
+// given configuration, for a proxy in the namespace of the EnvoyFilter with the labels it selects: a gateway for the
+// patches of the GATEWAY context, and a sidecar otherwise. Each patch is applied on its own, and is unmatched if the
+// listeners, clusters and routes of the proxy are unchanged.
+//
+// Patches of extension configurations, of the bootstrap and of waypoints are not verified.
+func UnmatchedEnvoyFilterPatches(ef config.Config, configs []config.Config) ([]int, error) {
+	spec, ok := ef.Spec.(*networking.EnvoyFilter)
+	if !ok {
+		return nil, nil
+	}
+	reference := make([]config.Config, 0, len(configs))
+	for _, c := range configs {
+		if c.GroupVersionKind == gvk.EnvoyFilter && c.Name == ef.Name && c.Namespace == ef.Namespace {
+			continue
+		}
+		reference = append(reference, c)
+	}
+
+	proxyTypes := map[networking.EnvoyFilter_PatchContext][]model.NodeType{
+		networking.EnvoyFilter_ANY:              {model.SidecarProxy, model.Router},
+		networking.EnvoyFilter_SIDECAR_INBOUND:  {model.SidecarProxy},
+		networking.EnvoyFilter_SIDECAR_OUTBOUND: {model.SidecarProxy},
+		networking.EnvoyFilter_GATEWAY:          {model.Router},
+	}
+	baselines := map[model.NodeType][]proto.Message{}
+	var unmatched []int
+	for i, cp := range spec.ConfigPatches {
+		if cp == nil || cp.ApplyTo == networking.EnvoyFilter_EXTENSION_CONFIG || cp.ApplyTo == networking.EnvoyFilter_BOOTSTRAP {
+			continue
+		}
+		types, f := proxyTypes[cp.GetMatch().GetContext()]
+		if !f {
+			continue
+		}
+		single := ef.DeepCopy()
+		single.Spec.(*networking.EnvoyFilter).ConfigPatches = []*networking.EnvoyFilter_EnvoyConfigObjectPatch{cp}
+		matched := false
+		for _, nodeType := range types {
+			baseline, f := baselines[nodeType]
+			if !f {
+				var err error
+				if baseline, err = generateReference(reference, ef, spec, nodeType); err != nil {
+					return nil, err
+				}
+				baselines[nodeType] = baseline
+			}
+			patched, err := generateReference(append(reference, single), ef, spec, nodeType)
+			if err != nil {
+				return nil, err
+			}
+			if !equalMessages(baseline, patched) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unmatched = append(unmatched, i)
+		}
+	}
+	return unmatched, nil
+}
+
+// generateReference generates the listeners, clusters and routes of a proxy selected by an EnvoyFilter, from the
+// push context of the given configuration.
+func generateReference(configs []config.Config, ef config.Config, spec *networking.EnvoyFilter, nodeType model.NodeType) ([]proto.Message, error) {
+	stop := make(chan struct{})
+	defer close(stop)
+	env, err := referenceEnvironment(configs, stop)
+	if err != nil {
+		return nil, err
+	}
+	push := env.PushContext()
+	labels := spec.GetWorkloadSelector().GetLabels()
+	proxy := &model.Proxy{
+		Type:            nodeType,
+		ID:              "reference." + ef.Namespace,
+		ConfigNamespace: ef.Namespace,
+		DNSDomain:       ef.Namespace + ".svc." + env.DomainSuffix,
+		IPAddresses:     []string{"1.1.1.1"},
+		IstioVersion:    model.MaxIstioVersion,
+		Labels:          labels,
+		Metadata:        &model.NodeMetadata{Namespace: ef.Namespace, Labels: labels},
+	}
+	proxy.SetSidecarScope(push)
+	proxy.SetServiceTargets(env.ServiceDiscovery)
+	proxy.SetGatewaysForProxy(push)
+	proxy.DiscoverIPMode()
+
+	cg := NewConfigGenerator(&model.DisabledCache{})
+	req := &model.PushRequest{Push: push}
+	var out []proto.Message
+	listeners := cg.BuildListeners(proxy, push)
+	for _, l := range listeners {
+		out = append(out, l)
+	}
+	clusters, _ := cg.BuildClusters(proxy, req)
+	routes, _ := cg.BuildHTTPRoutes(proxy, req, ExtractRoutesFromListeners(listeners))
+	for _, r := range append(clusters, routes...) {
+		m, err := r.Resource.UnmarshalNew()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// referenceEnvironment returns an environment holding the given configuration, with the services of its
+// ServiceEntries, and its initialized push context. Its service registries run until stop is closed.
+func referenceEnvironment(configs []config.Config, stop <-chan struct{}) (*model.Environment, error) {
+	store := memory.NewSyncController(memory.MakeSkipValidation(collections.PilotGatewayAPI()))
+	env := model.NewEnvironment()
+	env.Watcher = meshwatcher.ConfigAdapter(krt.NewStatic(&meshwatcher.MeshConfigResource{MeshConfig: mesh.DefaultMeshConfig()}, true))
+	env.NetworksWatcher = meshwatcher.NetworksAdapter(krt.NewStatic(&meshwatcher.MeshNetworksResource{}, true))
+	updater := model.NewEndpointIndexUpdater(env.EndpointIndex)
+	discovery := aggregate.NewController(aggregate.Options{})
+	discovery.AddRegistry(serviceentry.NewController(store, updater, env.Watcher))
+	env.ServiceDiscovery = discovery
+	env.ConfigStore = store
+	env.Init()
+	go discovery.Run(stop)
+	for _, c := range configs {
+		if _, err := store.Create(c); err != nil {
+			return nil, fmt.Errorf("failed to create config %s/%s: %v", c.Namespace, c.Name, err)
+		}
+	}
+	if err := env.InitNetworksManager(updater); err != nil {
+		return nil, err
+	}
+	env.PushContext().InitContext(env, nil, nil)
+	return env, nil
+}
+
+func equalMessages(a, b []proto.Message) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !proto.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,379 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package revocation maintains the certificate revocation list (CRL) of the istiod CA.
+//
+// Operators revoke workload certificates by serial number, or whole identities by SPIFFE ID, in a ConfigMap
+// in the istiod namespace. istiod signs a CRL of the revoked serial numbers with its signing certificate and
+// distributes it to proxies, which reject the revoked certificates. Revoked identities are no longer issued
+// certificates by the CA.
+package revocation
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/istio/pkg/log"
+	"istio.io/istio/pkg/maps"
+	"istio.io/istio/pkg/slices"
+	"istio.io/istio/pkg/spiffe"
+	"istio.io/istio/pkg/util/sets"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+var revocationLog = log.RegisterScope("revocation", "CA certificate revocation")
+
+const (
+	// ConfigMapName is the name of the ConfigMap, in the istiod namespace, listing the revoked certificates.
+	ConfigMapName = "istio-revoked-certificates"
+	// IdentitiesPolicyName is the name of the policy, in the root namespace, denying the revoked identities
+	// in ztunnel.
+	IdentitiesPolicyName = "istio-revoked-identities"
+	// ConfigMapKey is the key of the ConfigMap holding the revocation entries, as a YAML list.
+	ConfigMapKey = "revocations"
+
+	// crlValidity is how long a CRL is valid for. It is re-signed once half of it has elapsed.
+	crlValidity = 24 * time.Hour
+	// resyncPeriod is how often the CRL is checked for re-signing and CA certificate changes.
+	resyncPeriod = 5 * time.Minute
+)
+
+// Reasons are the supported revocation reasons, with their RFC 5280 reason codes.
+var Reasons = map[string]int{
+	"unspecified":          0,
+	"keyCompromise":        1,
+	"affiliationChanged":   3,
+	"superseded":           4,
+	"cessationOfOperation": 5,
+}
+
+// Entry revokes a certificate by serial number, or an identity by SPIFFE ID.
+type Entry struct {
+	// SerialNumber is the serial number of the revoked certificate, in hex.
+	SerialNumber string `json:"serialNumber,omitempty"`
+	// SpiffeID is a revoked identity. The CA no longer issues certificates for it.
+	SpiffeID  string    `json:"spiffeID,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	RevokedAt time.Time `json:"revokedAt"`
+}
+
+// Validate checks that the entry revokes exactly one serial number or identity.
+func (e Entry) Validate() error {
+	if (e.SerialNumber == "") == (e.SpiffeID == "") {
+		return fmt.Errorf("exactly one of serialNumber or spiffeID must be set")
+	}
+	if e.SerialNumber != "" {
+		if _, ok := new(big.Int).SetString(e.SerialNumber, 16); !ok {
+			return fmt.Errorf("serial number %q is not hex", e.SerialNumber)
+		}
+	}
+	if e.SpiffeID != "" {
+		if _, err := spiffe.ParseIdentity(e.SpiffeID); err != nil {
+			return err
+		}
+	}
+	if _, f := Reasons[e.Reason]; e.Reason != "" && !f {
+		return fmt.Errorf("unknown revocation reason %q", e.Reason)
+	}
+	return nil
+}
+
+// EntriesFromConfigMap parses the revocation entries of the ConfigMap.
+func EntriesFromConfigMap(cm *corev1.ConfigMap) ([]Entry, error) {
+	var entries []Entry
+	if err := yaml.Unmarshal([]byte(cm.Data[ConfigMapKey]), &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", ConfigMapKey, err)
+	}
+	for i, e := range entries {
+		if err := e.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid revocation entry %d: %v", i, err)
+		}
+	}
+	return entries, nil
+}
+
+// ConfigMapData encodes the revocation entries as ConfigMap data.
+func ConfigMapData(entries []Entry) (map[string]string, error) {
+	b, err := yaml.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{ConfigMapKey: string(b)}, nil
+}
+
+// CheckCA returns an error if the signing certificate of the CA cannot sign CRLs. CA certificates generated
+// before revocation was supported lack the cRLSign key usage, and must be reissued.
+func CheckCA(ca CA) error {
+	certPEM, _, _, _ := ca.GetCAKeyCertBundle().GetAllPem()
+	_, err := crlIssuer(certPEM)
+	return err
+}
+
+// crlIssuer parses the CA signing certificate, and checks that it can sign CRLs.
+func crlIssuer(certPEM []byte) (*x509.Certificate, error) {
+	issuer, err := util.ParsePemEncodedCertificate(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	if issuer.KeyUsage&x509.KeyUsageCRLSign == 0 {
+		return nil, fmt.Errorf("the CA certificate %q does not have the cRLSign key usage", issuer.Subject)
+	}
+	return issuer, nil
+}
+
+// CA is the CA whose certificates are revoked.
+type CA interface {
+	GetCAKeyCertBundle() *util.KeyCertBundle
+}
+
+// Status is the revocation state of an istiod instance, as exposed on the istiod debug endpoint.
+type Status struct {
+	RevokedSerialNumbers []string  `json:"revokedSerialNumbers"`
+	RevokedIdentities    []string  `json:"revokedIdentities"`
+	CRLNumber            string    `json:"crlNumber,omitempty"`
+	ThisUpdate           time.Time `json:"thisUpdate,omitempty"`
+	NextUpdate           time.Time `json:"nextUpdate,omitempty"`
+	Error                string    `json:"error,omitempty"`
+}
+
+// Controller signs the CRL of the revoked certificates with the CA signing certificate, and re-signs it
+// before it expires or when the CA certificate changes.
+type Controller struct {
+	ca       CA
+	onChange func()
+	now      func() time.Time
+
+	mu         sync.RWMutex
+	serials    map[string]Entry
+	identities sets.String
+	// crl holds the PEM encoded CRLs distributed to proxies: the CRL signed by istiod, followed by the
+	// plugged in CRLs of the other certificates of the CA chain.
+	crl        []byte
+	number     *big.Int
+	thisUpdate time.Time
+	// signedWith is the CA certificate and plugged in CRLs the CRL was built from.
+	signedWith []byte
+	err        error
+}
+
+// NewController creates a controller for the CRL of ca. onChange is called when the CRL or the revoked
+// identities change.
+func NewController(ca CA, onChange func()) *Controller {
+	return &Controller{
+		ca:         ca,
+		onChange:   onChange,
+		now:        time.Now,
+		serials:    map[string]Entry{},
+		identities: sets.New[string](),
+		number:     big.NewInt(0),
+	}
+}
+
+// Update replaces the revocation entries, and re-signs the CRL.
+func (c *Controller) Update(entries []Entry) {
+	serials := map[string]Entry{}
+	identities := sets.New[string]()
+	for _, e := range entries {
+		if e.SpiffeID != "" {
+			identities.Insert(e.SpiffeID)
+			continue
+		}
+		// Serial numbers are compared in their canonical form.
+		n, _ := new(big.Int).SetString(e.SerialNumber, 16)
+		e.SerialNumber = n.Text(16)
+		serials[e.SerialNumber] = e
+	}
+	c.mu.Lock()
+	c.serials = serials
+	identitiesChanged := !c.identities.Equals(identities)
+	c.identities = identities
+	c.mu.Unlock()
+	revocationLog.Infof("updated revocations: %d certificates, %d identities", len(serials), len(identities))
+	// The revoked identities are distributed to ztunnel, so a change is notified even if the CRL is unchanged.
+	if (c.resign(true) || identitiesChanged) && c.onChange != nil {
+		c.onChange()
+	}
+}
+
+// CRL returns the PEM encoded CRLs to distribute to proxies, or nil if no certificate is revoked.
+func (c *Controller) CRL() []byte {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.crl
+}
+
+// IsRevoked returns true if the identity is revoked.
+func (c *Controller) IsRevoked(identity string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.identities.Contains(identity)
+}
+
+// RevokedIdentities returns the revoked identities, sorted.
+func (c *Controller) RevokedIdentities() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return sets.SortedList(c.identities)
+}
+
+// Status returns the revocation state.
+func (c *Controller) Status() Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	st := Status{
+		RevokedSerialNumbers: slices.Sort(maps.Keys(c.serials)),
+		RevokedIdentities:    sets.SortedList(c.identities),
+	}
+	if c.crl != nil {
+		st.CRLNumber = c.number.String()
+		st.ThisUpdate = c.thisUpdate
+		st.NextUpdate = c.thisUpdate.Add(crlValidity)
+	}
+	if c.err != nil {
+		st.Error = c.err.Error()
+	}
+	return st
+}
+
+// Run periodically re-signs the CRL, until stop is closed.
+func (c *Controller) Run(stop <-chan struct{}) {
+	t := time.NewTicker(resyncPeriod)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+			c.refresh(false)
+		}
+	}
+}
+
+// refresh re-signs the CRL if forced, if it is halfway through its validity, or if the CA certificate
+// or the plugged in CRLs changed.
+func (c *Controller) refresh(force bool) {
+	if c.resign(force) && c.onChange != nil {
+		c.onChange()
+	}
+}
+
+// resign re-signs the CRL as refresh does, and returns true if the CRL changed.
+func (c *Controller) resign(force bool) bool {
+	bundle := c.ca.GetCAKeyCertBundle()
+	certPEM, keyPEM, _, _ := bundle.GetAllPem()
+	pluggedCRL := bundle.GetCRLPem()
+	signedWith := append(append([]byte{}, certPEM...), pluggedCRL...)
+	now := c.now()
+
+	c.mu.Lock()
+	if !force && c.err == nil && bytes.Equal(c.signedWith, signedWith) &&
+		(len(c.serials) == 0 || now.Before(c.thisUpdate.Add(crlValidity/2))) {
+		c.mu.Unlock()
+		return false
+	}
+	old := c.crl
+	c.crl, c.err = nil, nil
+	if len(c.serials) > 0 {
+		c.crl, c.err = c.sign(certPEM, keyPEM, pluggedCRL, now)
+		if c.err != nil {
+			revocationLog.Errorf("failed to sign CRL: %v", c.err)
+		}
+	}
+	c.signedWith = signedWith
+	changed := !bytes.Equal(old, c.crl)
+	c.mu.Unlock()
+	return changed
+}
+
+// sign builds the CRL of the revoked serial numbers. The entries of a plugged in CRL issued by the signing
+// certificate are merged into it, so proxies get a single CRL per issuer. Must be called with the lock held.
+func (c *Controller) sign(certPEM, keyPEM, pluggedCRL []byte, now time.Time) ([]byte, error) {
+	issuer, err := crlIssuer(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	key, err := util.ParsePemEncodedKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("the CA key cannot sign")
+	}
+
+	entries := map[string]x509.RevocationListEntry{}
+	for serial, e := range c.serials {
+		n, _ := new(big.Int).SetString(serial, 16)
+		revokedAt := e.RevokedAt
+		if revokedAt.IsZero() {
+			// Entries edited by hand may omit the revocation time.
+			revokedAt = now
+		}
+		entries[serial] = x509.RevocationListEntry{SerialNumber: n, RevocationTime: revokedAt, ReasonCode: Reasons[e.Reason]}
+	}
+	var others []byte
+	for rest := pluggedCRL; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		plugged, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse plugged in CRL: %v", err)
+		}
+		if plugged.CheckSignatureFrom(issuer) != nil {
+			others = append(others, pem.EncodeToMemory(block)...)
+			continue
+		}
+		for _, e := range plugged.RevokedCertificateEntries {
+			if _, f := entries[e.SerialNumber.Text(16)]; !f {
+				entries[e.SerialNumber.Text(16)] = e
+			}
+		}
+	}
+
+	revoked := make([]x509.RevocationListEntry, 0, len(entries))
+	for _, e := range entries {
+		revoked = append(revoked, e)
+	}
+	sort.Slice(revoked, func(i, j int) bool { return revoked[i].SerialNumber.Cmp(revoked[j].SerialNumber) < 0 })
+	// CRL numbers must increase, also across restarts.
+	number := big.NewInt(now.Unix())
+	if number.Cmp(c.number) <= 0 {
+		number = new(big.Int).Add(c.number, big.NewInt(1))
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:                    number,
+		ThisUpdate:                now,
+		NextUpdate:                now.Add(crlValidity),
+		RevokedCertificateEntries: revoked,
+	}, issuer, signer)
+	if err != nil {
+		return nil, err
+	}
+	c.number, c.thisUpdate = number, now
+	return append(pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}), others...), nil
+}
@@ -0,0 +1,269 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revocation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"istio.io/istio/pkg/test/util/assert"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+type fakeCA struct {
+	bundle *util.KeyCertBundle
+}
+
+func (f fakeCA) GetCAKeyCertBundle() *util.KeyCertBundle {
+	return f.bundle
+}
+
+func newCA(t *testing.T, org string) (*x509.Certificate, []byte, []byte) {
+	t.Helper()
+	certPEM, keyPEM, err := util.GenCertKeyFromOptions(util.CertOptions{
+		TTL:          time.Hour,
+		Org:          org,
+		IsCA:         true,
+		IsSelfSigned: true,
+		ECSigAlg:     util.EcdsaSigAlg,
+	})
+	assert.NoError(t, err)
+	cert, err := util.ParsePemEncodedCertificate(certPEM)
+	assert.NoError(t, err)
+	return cert, certPEM, keyPEM
+}
+
+// parseCRLs parses the PEM encoded CRLs, and checks the first one is signed by issuer.
+func parseCRLs(t *testing.T, crl []byte, issuer *x509.Certificate) []*x509.RevocationList {
+	t.Helper()
+	var out []*x509.RevocationList
+	for rest := crl; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		l, err := x509.ParseRevocationList(block.Bytes)
+		assert.NoError(t, err)
+		out = append(out, l)
+	}
+	if len(out) == 0 {
+		t.Fatalf("no CRL in %q", crl)
+	}
+	assert.NoError(t, out[0].CheckSignatureFrom(issuer))
+	return out
+}
+
+func revokedSerials(l *x509.RevocationList) []string {
+	out := []string{}
+	for _, e := range l.RevokedCertificateEntries {
+		out = append(out, e.SerialNumber.Text(16))
+	}
+	return out
+}
+
+func TestEntriesFromConfigMap(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		want []Entry
+		err  string
+	}{
+		{name: "empty", data: ""},
+		{
+			name: "valid",
+			data: `
+- serialNumber: 0A1b
+  reason: keyCompromise
+  revokedAt: 2026-01-02T03:04:05Z
+- spiffeID: spiffe://cluster.local/ns/foo/sa/bar
+  revokedAt: 2026-01-02T03:04:05Z`,
+			want: []Entry{
+				{SerialNumber: "0A1b", Reason: "keyCompromise", RevokedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)},
+				{SpiffeID: "spiffe://cluster.local/ns/foo/sa/bar", RevokedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)},
+			},
+		},
+		{name: "both set", data: `[{serialNumber: "1", spiffeID: "spiffe://cluster.local/ns/foo/sa/bar"}]`, err: "exactly one"},
+		{name: "none set", data: `[{reason: keyCompromise}]`, err: "exactly one"},
+		{name: "bad serial", data: `[{serialNumber: "xyz"}]`, err: "not hex"},
+		{name: "bad identity", data: `[{spiffeID: "spiffe://cluster.local/foo"}]`, err: "entry 0"},
+		{name: "bad reason", data: `[{serialNumber: "1", reason: lost}]`, err: "unknown revocation reason"},
+		{name: "not a list", data: `foo: bar`, err: "failed to parse"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EntriesFromConfigMap(&corev1.ConfigMap{Data: map[string]string{ConfigMapKey: tt.data}})
+			if tt.err != "" {
+				assert.Error(t, err)
+				if !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, got, tt.want)
+
+			// Entries round trip through the ConfigMap data.
+			data, err := ConfigMapData(got)
+			assert.NoError(t, err)
+			again, err := EntriesFromConfigMap(&corev1.ConfigMap{Data: data})
+			assert.NoError(t, err)
+			assert.Equal(t, again, got)
+		})
+	}
+}
+
+func TestController(t *testing.T) {
+	issuer, certPEM, keyPEM := newCA(t, "istio")
+	now := time.Now().Truncate(time.Second)
+	changes := 0
+	ca := fakeCA{util.NewKeyCertBundleFromPem(certPEM, keyPEM, nil, certPEM, nil)}
+	assert.NoError(t, CheckCA(ca))
+	c := NewController(ca, func() { changes++ })
+	c.now = func() time.Time { return now }
+
+	// Nothing is distributed while nothing is revoked.
+	c.Update(nil)
+	assert.Equal(t, c.CRL(), nil)
+	assert.Equal(t, changes, 0)
+
+	c.Update([]Entry{
+		{SerialNumber: "0A", Reason: "keyCompromise", RevokedAt: now},
+		{SpiffeID: "spiffe://cluster.local/ns/foo/sa/bar", RevokedAt: now},
+	})
+	assert.Equal(t, changes, 1)
+	crls := parseCRLs(t, c.CRL(), issuer)
+	assert.Equal(t, len(crls), 1)
+	assert.Equal(t, revokedSerials(crls[0]), []string{"a"})
+	assert.Equal(t, crls[0].RevokedCertificateEntries[0].ReasonCode, 1)
+	assert.Equal(t, crls[0].NextUpdate, now.Add(crlValidity))
+	assert.Equal(t, c.IsRevoked("spiffe://cluster.local/ns/foo/sa/bar"), true)
+	assert.Equal(t, c.IsRevoked("spiffe://cluster.local/ns/foo/sa/other"), false)
+	st := c.Status()
+	assert.Equal(t, st.RevokedSerialNumbers, []string{"a"})
+	assert.Equal(t, st.RevokedIdentities, []string{"spiffe://cluster.local/ns/foo/sa/bar"})
+
+	// The CRL is only re-signed once half of its validity elapsed.
+	now = now.Add(time.Hour)
+	c.refresh(false)
+	assert.Equal(t, changes, 1)
+	now = now.Add(crlValidity / 2)
+	c.refresh(false)
+	assert.Equal(t, changes, 2)
+	resigned := parseCRLs(t, c.CRL(), issuer)
+	if resigned[0].Number.Cmp(crls[0].Number) <= 0 {
+		t.Fatalf("expected CRL number %v to increase from %v", resigned[0].Number, crls[0].Number)
+	}
+
+	// Removing the revocations removes the CRL.
+	c.Update(nil)
+	assert.Equal(t, c.CRL(), nil)
+	assert.Equal(t, changes, 3)
+	assert.Equal(t, c.IsRevoked("spiffe://cluster.local/ns/foo/sa/bar"), false)
+
+	// Revoking an identity is notified, although the CRL is unchanged.
+	c.Update([]Entry{{SpiffeID: "spiffe://cluster.local/ns/foo/sa/bar", RevokedAt: now}})
+	assert.Equal(t, c.CRL(), nil)
+	assert.Equal(t, changes, 4)
+	assert.Equal(t, c.RevokedIdentities(), []string{"spiffe://cluster.local/ns/foo/sa/bar"})
+	c.Update([]Entry{{SpiffeID: "spiffe://cluster.local/ns/foo/sa/bar", RevokedAt: now}})
+	assert.Equal(t, changes, 4)
+}
+
+func TestControllerCACertificateChange(t *testing.T) {
+	_, certPEM, keyPEM := newCA(t, "old")
+	bundle := util.NewKeyCertBundleFromPem(certPEM, keyPEM, nil, certPEM, nil)
+	c := NewController(fakeCA{bundle}, nil)
+	c.Update([]Entry{{SerialNumber: "1"}})
+
+	issuer, certPEM, keyPEM := newCA(t, "new")
+	assert.NoError(t, bundle.VerifyAndSetAll(certPEM, keyPEM, nil, certPEM, nil))
+	c.refresh(false)
+	parseCRLs(t, c.CRL(), issuer)
+}
+
+func TestControllerPluggedCRL(t *testing.T) {
+	issuer, certPEM, keyPEM := newCA(t, "intermediate")
+	issuerKey, err := util.ParsePemEncodedKey(keyPEM)
+	assert.NoError(t, err)
+	plugged, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:                    big.NewInt(1),
+		ThisUpdate:                time.Now(),
+		NextUpdate:                time.Now().Add(time.Hour),
+		RevokedCertificateEntries: []x509.RevocationListEntry{{SerialNumber: big.NewInt(11), RevocationTime: time.Now()}},
+	}, issuer, issuerKey.(*ecdsa.PrivateKey))
+	assert.NoError(t, err)
+	root, _, rootKeyPEM := newCA(t, "root")
+	rootKey, err := util.ParsePemEncodedKey(rootKeyPEM)
+	assert.NoError(t, err)
+	rootCRL, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now(),
+		NextUpdate: time.Now().Add(time.Hour),
+	}, root, rootKey.(*ecdsa.PrivateKey))
+	assert.NoError(t, err)
+	pluggedPEM := append(pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: plugged}),
+		pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: rootCRL})...)
+
+	c := NewController(fakeCA{util.NewKeyCertBundleFromPem(certPEM, keyPEM, nil, certPEM, pluggedPEM)}, nil)
+	c.Update([]Entry{{SerialNumber: "a"}})
+	// The plugged in CRL of the signing certificate is merged, the other CRLs are passed through.
+	crls := parseCRLs(t, c.CRL(), issuer)
+	assert.Equal(t, len(crls), 2)
+	assert.Equal(t, revokedSerials(crls[0]), []string{"a", "b"})
+	assert.NoError(t, crls[1].CheckSignatureFrom(root))
+}
+
+func TestControllerNoCRLSign(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{"legacy"}},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	assert.NoError(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM, err := util.EncodePrivateKeyPem(key, false)
+	assert.NoError(t, err)
+
+	ca := fakeCA{util.NewKeyCertBundleFromPem(certPEM, keyPEM, nil, certPEM, nil)}
+	// istiod refuses to start with revocation enabled.
+	assert.Error(t, CheckCA(ca))
+
+	c := NewController(ca, nil)
+	c.Update([]Entry{{SerialNumber: "1"}, {SpiffeID: "spiffe://cluster.local/ns/foo/sa/bar"}})
+	// Proxies would reject a CRL signed by a certificate without the cRLSign key usage.
+	assert.Equal(t, c.CRL(), nil)
+	if !strings.Contains(c.Status().Error, "cRLSign") {
+		t.Fatalf("expected a cRLSign error, got %q", c.Status().Error)
+	}
+	// Identities are still revoked.
+	assert.Equal(t, c.IsRevoked("spiffe://cluster.local/ns/foo/sa/bar"), true)
+}
@@ -38,6 +38,8 @@ const (
 	SourceIstioCA Source = iota
 	SourceMeshConfig
 	SourceIstioRA
+	// SourceRotation holds the new root of an in progress CA rotation
+	SourceRotation
 	sourceSpiffeEndpoints
 
 	RemoteDefaultPollPeriod = 30 * time.Minute
@@ -51,6 +53,8 @@ func (s Source) String() string {
 		return "MeshConfig"
 	case SourceIstioRA:
 		return "IstioRA"
+	case SourceRotation:
+		return "Rotation"
 	case sourceSpiffeEndpoints:
 		return "SpiffeEndpoints"
 	default:
@@ -92,6 +96,7 @@ func NewTrustBundle(remoteCaCertPool *x509.CertPool, meshConfig mesh.Watcher) *T
 			SourceIstioCA:         {Certs: []string{}},
 			SourceMeshConfig:      {Certs: []string{}},
 			SourceIstioRA:         {Certs: []string{}},
+			SourceRotation:        {Certs: []string{}},
 			sourceSpiffeEndpoints: {Certs: []string{}},
 		},
 		mergedCerts:        []string{},
@@ -0,0 +1,90 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xds
+
+import (
+	"strings"
+
+	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/revocation"
+	"istio.io/istio/pilot/pkg/util/protoconv"
+	"istio.io/istio/pkg/config/schema/kind"
+	"istio.io/istio/pkg/spiffe"
+	"istio.io/istio/pkg/workloadapi/security"
+)
+
+// CrlGenerator distributes the certificate revocation lists of the istiod CA to agents, which add them
+// to the validation context they serve to Envoy over SDS. An empty CRL means no certificate is revoked.
+type CrlGenerator struct {
+	Server *DiscoveryServer
+}
+
+var _ model.XdsResourceGenerator = &CrlGenerator{}
+
+func crlNeedsPush(req *model.PushRequest) bool {
+	if req == nil || req.Forced || req.IsRequest() {
+		return true
+	}
+	return revocationsUpdated(req)
+}
+
+// revocationsUpdated returns true if the revoked certificates ConfigMap is updated by the push.
+func revocationsUpdated(req *model.PushRequest) bool {
+	for config := range req.ConfigsUpdated {
+		if config.Kind == kind.ConfigMap && config.Name == revocation.ConfigMapName {
+			return true
+		}
+	}
+	return false
+}
+
+// revokedIdentitiesPolicy returns the policy denying the revoked identities in ztunnel, or nil if no identity
+// is revoked. Ztunnel does not consume the CRL, so it enforces the revocation of identities through a mesh
+// wide DENY policy, sent with the other ztunnel policies.
+func revokedIdentitiesPolicy(rootNamespace string, identities []string) *security.Authorization {
+	if len(identities) == 0 {
+		return nil
+	}
+	principals := make([]*security.StringMatch, 0, len(identities))
+	for _, id := range identities {
+		// Principals are matched without the SPIFFE scheme, as in AuthorizationPolicy.
+		principals = append(principals, &security.StringMatch{
+			MatchType: &security.StringMatch_Exact{Exact: strings.TrimPrefix(id, spiffe.URIPrefix)},
+		})
+	}
+	return &security.Authorization{
+		Name:      revocation.IdentitiesPolicyName,
+		Namespace: rootNamespace,
+		Scope:     security.Scope_GLOBAL,
+		Action:    security.Action_DENY,
+		Groups: []*security.Group{{
+			Rules: []*security.Rules{{
+				Matches: []*security.Match{{Principals: principals}},
+			}},
+		}},
+	}
+}
+
+// Generate returns the PEM encoded CRLs of the istiod CA.
+func (c *CrlGenerator) Generate(_ *model.Proxy, _ *model.WatchedResource, req *model.PushRequest) (model.Resources, model.XdsLogDetails, error) {
+	if c.Server.Revocations == nil || !crlNeedsPush(req) {
+		return nil, model.DefaultXdsLogDetails, nil
+	}
+	crl := wrapperspb.Bytes(c.Server.Revocations.CRL())
+	return model.Resources{&discovery.Resource{Name: "crl", Resource: protoconv.MessageToAny(crl)}}, model.DefaultXdsLogDetails, nil
+}
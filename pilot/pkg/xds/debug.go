@@ -52,6 +52,7 @@ import (
 	"istio.io/istio/pkg/util/protomarshal"
 	"istio.io/istio/pkg/util/sets"
 	"istio.io/istio/pkg/workloadapi"
+	caserver "istio.io/istio/security/pkg/server/ca"
 )
 
 var indexTmpl = template.Must(template.New("index").Parse(`<html>
@@ -202,6 +203,8 @@ func (s *DiscoveryServer) AddDebugHandlers(mux, internalMux *http.ServeMux, enab
 	s.addDebugHandler(mux, internalMux, "/debug/cachez?clear=true", "Clear the XDS caches", s.cachez)
 	s.addDebugHandler(mux, internalMux, "/debug/configz", "Debug support for config", s.configz)
 	s.addDebugHandler(mux, internalMux, "/debug/sidecarz", "Debug sidecar scope for a proxy", s.sidecarz)
+	s.addDebugHandler(mux, internalMux, "/debug/configgraph", "Graph of the configuration which applies to a proxy, as JSON or with format=dot as DOT",
+		s.ConfigGraph)
 	s.addDebugHandler(mux, internalMux, "/debug/resourcesz", "Debug support for watched resources", s.resourcez)
 	s.addDebugHandler(mux, internalMux, "/debug/instancesz", "Debug support for service instances", s.instancesz)
 	s.addDebugHandler(mux, internalMux, "/debug/ambientz", "Debug support for ambient", s.ambientz)
@@ -211,6 +214,8 @@ func (s *DiscoveryServer) AddDebugHandlers(mux, internalMux *http.ServeMux, enab
 	s.addDebugHandler(mux, internalMux, "/debug/telemetryz", "Debug Telemetry configuration", s.telemetryz)
 	s.addDebugHandler(mux, internalMux, "/debug/config_dump", "ConfigDump in the form of the Envoy admin config dump API for passed in proxyID", s.ConfigDump)
 	s.addDebugHandler(mux, internalMux, "/debug/push_status", "Last PushContext Details", s.pushStatusHandler)
+	s.addDebugHandler(mux, internalMux, "/debug/push_impact", "Proxies which an update of the POSTed configuration would push, without pushing",
+		s.pushImpactz)
 	s.addDebugHandler(mux, internalMux, "/debug/pushcontext", "Debug support for current push context", s.pushContextHandler)
 	s.addDebugHandler(mux, internalMux, "/debug/connections", "Info about the connected XDS clients", s.connectionsHandler)
 
@@ -219,6 +224,9 @@ func (s *DiscoveryServer) AddDebugHandlers(mux, internalMux *http.ServeMux, enab
 	s.addDebugHandler(mux, internalMux, "/debug/clusterz", "List remote clusters where istiod reads endpoints", s.clusterz)
 	s.addDebugHandler(mux, internalMux, "/debug/networkz", "List cross-network gateways", s.networkz)
 	s.addDebugHandler(mux, internalMux, "/debug/mcsz", "List information about Kubernetes MCS services", s.mcsz)
+	s.addDebugHandler(mux, internalMux, "/debug/ca_rotation", "Status of the root CA rotation", s.caRotationz)
+	s.addDebugHandler(mux, internalMux, "/debug/certz", "Workload certificates issued by the istiod CA", s.certz)
+	s.addDebugHandler(mux, internalMux, "/debug/revocationz", "Certificates revoked in the istiod CA", s.revocationz)
 
 	s.addDebugHandler(mux, internalMux, "/debug/list", "List all supported debug commands in json", s.list)
 }
@@ -415,6 +423,27 @@ func (s *DiscoveryServer) sidecarz(w http.ResponseWriter, req *http.Request) {
 	writeJSON(w, con.proxy.SidecarScope, req)
 }
 
+// ConfigGraph returns the graph of the configuration resources which apply to the specified proxy, and of their
+// relationships, as JSON or, with the format=dot query parameter, in the Graphviz DOT language.
+func (s *DiscoveryServer) ConfigGraph(w http.ResponseWriter, req *http.Request) {
+	proxyID, con := s.getDebugConnection(req)
+	if con == nil {
+		s.errorHandler(w, proxyID, con)
+		return
+	}
+	push := con.proxy.LastPushContext
+	if push == nil {
+		push = s.globalPushContext()
+	}
+	graph := push.ConfigGraph(con.proxy)
+	if req.URL.Query().Get("format") == "dot" {
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+		_, _ = w.Write([]byte(graph.DOT()))
+		return
+	}
+	writeJSON(w, graph, req)
+}
+
 // Resource debugging.
 func (s *DiscoveryServer) resourcez(w http.ResponseWriter, req *http.Request) {
 	schemas := make([]config.GroupVersionKind, 0)
@@ -1067,6 +1096,48 @@ func (s *DiscoveryServer) clusterz(w http.ResponseWriter, req *http.Request) {
 	writeJSON(w, s.ListRemoteClusters(), req)
 }
 
+func (s *DiscoveryServer) caRotationz(w http.ResponseWriter, req *http.Request) {
+	if s.CARotationStatus == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("CA rotation is not enabled\n"))
+		return
+	}
+	writeJSON(w, s.CARotationStatus(), req)
+}
+
+// certz lists the workload certificates issued by the istiod CA. The namespace, serial and
+// expiringWithin (a duration) query parameters filter the certificates.
+func (s *DiscoveryServer) certz(w http.ResponseWriter, req *http.Request) {
+	if s.IssuedCertificates == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("istiod CA is not enabled\n"))
+		return
+	}
+	filter := caserver.CertFilter{
+		Namespace:    req.URL.Query().Get("namespace"),
+		SerialNumber: req.URL.Query().Get("serial"),
+	}
+	if v := req.URL.Query().Get("expiringWithin"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(fmt.Sprintf("invalid expiringWithin: %v\n", err)))
+			return
+		}
+		filter.ExpiringWithin = d
+	}
+	writeJSON(w, s.IssuedCertificates(filter), req)
+}
+
+func (s *DiscoveryServer) revocationz(w http.ResponseWriter, req *http.Request) {
+	if s.Revocations == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("certificate revocation is not enabled\n"))
+		return
+	}
+	writeJSON(w, s.Revocations.Status(), req)
+}
+
 // handlePushRequest handles a ?push=true query param and triggers a push.
 // A boolean response is returned to indicate if the caller should continue
 func (s *DiscoveryServer) handlePushRequest(w http.ResponseWriter, req *http.Request) bool {
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
@@ -28,6 +29,7 @@ import (
 	"istio.io/istio/pilot/pkg/xds"
 	v3 "istio.io/istio/pilot/pkg/xds/v3"
 	xdsfake "istio.io/istio/pilot/test/xds"
+	"istio.io/istio/pkg/test/util/assert"
 )
 
 func TestSyncz(t *testing.T) {
@@ -233,3 +235,114 @@ func TestDebugHandlers(t *testing.T) {
 		t.Errorf("Error in generatating debug endpoint list")
 	}
 }
+
+const configGraphConfig = `
+apiVersion: networking.istio.io/v1
+kind: ServiceEntry
+metadata:
+  name: external
+  namespace: default
+spec:
+  hosts:
+  - example.com
+  ports:
+  - number: 80
+    name: http
+    protocol: HTTP
+  resolution: DNS
+---
+apiVersion: networking.istio.io/v1
+kind: VirtualService
+metadata:
+  name: external
+  namespace: default
+spec:
+  hosts:
+  - example.com
+  http:
+  - route:
+    - destination:
+        host: example.com
+---
+apiVersion: networking.istio.io/v1
+kind: VirtualService
+metadata:
+  name: private
+  namespace: other
+spec:
+  exportTo:
+  - .
+  hosts:
+  - example.com
+  http:
+  - route:
+    - destination:
+        host: example.com
+---
+apiVersion: networking.istio.io/v1
+kind: DestinationRule
+metadata:
+  name: external
+  namespace: default
+spec:
+  host: example.com
+---
+apiVersion: networking.istio.io/v1
+kind: Sidecar
+metadata:
+  name: default
+  namespace: istio-system
+spec:
+  egress:
+  - hosts:
+    - "*/*"
+---
+apiVersion: security.istio.io/v1
+kind: AuthorizationPolicy
+metadata:
+  name: allow
+  namespace: default
+spec:
+  action: ALLOW
+  rules:
+  - {}
+`
+
+func TestConfigGraph(t *testing.T) {
+	s := xdsfake.NewFakeDiscoveryServer(t, xdsfake.FakeOptions{ConfigString: configGraphConfig})
+	ads := s.ConnectADS()
+	ads.RequestResponseAck(t, &discovery.DiscoveryRequest{TypeUrl: v3.ClusterType})
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/configgraph?proxyID=test.default", nil)
+	rr := httptest.NewRecorder()
+	s.Discovery.ConfigGraph(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("wanted response code 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	got := &model.ConfigGraph{}
+	if err := json.Unmarshal(rr.Body.Bytes(), got); err != nil {
+		t.Fatal(err)
+	}
+	// The VirtualService of the other namespace is not exported to the proxy.
+	assert.Equal(t, got.Edges, []model.ConfigGraphEdge{
+		{From: "AuthorizationPolicy/default/allow", To: "Proxy/test.default", Relation: model.ConfigGraphSelects},
+		{From: "DestinationRule/default/external", To: "ServiceEntry/default/external", Relation: model.ConfigGraphConfigures},
+		{From: "Proxy/test.default", To: "ServiceEntry/default/external", Relation: model.ConfigGraphImports},
+		{From: "Proxy/test.default", To: "VirtualService/default/external", Relation: model.ConfigGraphImports},
+		{From: "Sidecar/istio-system/default", To: "Proxy/test.default", Relation: model.ConfigGraphScopes},
+		{From: "VirtualService/default/external", To: "ServiceEntry/default/external", Relation: model.ConfigGraphRoutesTo},
+	})
+	assert.Equal(t, len(got.Nodes), 6)
+
+	req = httptest.NewRequest(http.MethodGet, "/debug/configgraph?proxyID=test.default&format=dot", nil)
+	rr = httptest.NewRecorder()
+	s.Discovery.ConfigGraph(rr, req)
+	if !strings.Contains(rr.Body.String(), `"VirtualService/default/external" -> "ServiceEntry/default/external" [label="routes to"];`) {
+		t.Fatalf("unexpected DOT graph: %s", rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/debug/configgraph?proxyID=not-found", nil)
+	rr = httptest.NewRecorder()
+	s.Discovery.ConfigGraph(rr, req)
+	assert.Equal(t, rr.Code, http.StatusNotFound)
+}
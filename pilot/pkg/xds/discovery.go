@@ -29,9 +29,11 @@ import (
 	"google.golang.org/grpc"
 
 	"istio.io/istio/pilot/pkg/autoregistration"
+	"istio.io/istio/pilot/pkg/carotation"
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/networking/core/envoyfilter"
+	"istio.io/istio/pilot/pkg/revocation"
 	"istio.io/istio/pkg/cluster"
 	"istio.io/istio/pkg/config/schema/kind"
 	"istio.io/istio/pkg/kube/krt"
@@ -39,6 +41,7 @@ import (
 	"istio.io/istio/pkg/maps"
 	"istio.io/istio/pkg/security"
 	"istio.io/istio/pkg/slices"
+	caserver "istio.io/istio/security/pkg/server/ca"
 )
 
 var periodicRefreshMetrics = 10 * time.Second
@@ -124,6 +127,15 @@ type DiscoveryServer struct {
 	// ListRemoteClusters collects debug information about other clusters this istiod reads from.
 	ListRemoteClusters func() []cluster.DebugInfo
 
+	// CARotationStatus returns the state of the root CA rotation, if the istiod CA supports rotation.
+	CARotationStatus func() carotation.Status
+
+	// IssuedCertificates returns the workload certificates issued by the istiod CA, if it is enabled.
+	IssuedCertificates func(filter caserver.CertFilter) caserver.CertInventoryStatus
+
+	// Revocations holds the certificates revoked in the istiod CA, if revocation is enabled.
+	Revocations *revocation.Controller
+
 	// ClusterAliases are alias names for cluster. When a proxy connects with a cluster ID
 	// and if it has a different alias we should use that a cluster ID for proxy.
 	ClusterAliases map[cluster.ID]cluster.ID
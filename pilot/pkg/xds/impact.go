@@ -0,0 +1,241 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xds
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/config/kube/crd"
+	"istio.io/istio/pilot/pkg/model"
+	v3 "istio.io/istio/pilot/pkg/xds/v3"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/config/schema/kind"
+	"istio.io/istio/pkg/slices"
+	"istio.io/istio/pkg/util/sets"
+)
+
+// PushImpact is the impact an update of configuration resources would have on the proxies connected to an istiod
+// instance.
+type PushImpact struct {
+	// ConfigsUpdated are the configs the update would set in PushRequest.ConfigsUpdated.
+	ConfigsUpdated []string `json:"configsUpdated"`
+	// ConnectedProxies is the number of proxies connected to the istiod instance.
+	ConnectedProxies int `json:"connectedProxies"`
+	// Proxies are the proxies which would be pushed.
+	Proxies []ProxyPushImpact `json:"proxies"`
+	// UnrecognizedKinds are the kinds of the resources of the request which are not Istio configuration, and are
+	// ignored.
+	UnrecognizedKinds []string `json:"unrecognizedKinds,omitempty"`
+}
+
+// maxPushImpactBodySize is the maximum size of the configuration resources sent to the push impact endpoint.
+const maxPushImpactBodySize = 10 * 1024 * 1024
+
+// ProxyPushImpact is a proxy which would be pushed, with the xDS types which would be pushed to it.
+type ProxyPushImpact struct {
+	Proxy string   `json:"proxy"`
+	Types []string `json:"types"`
+}
+
+// typeNeedsPush holds the checks the generators of each xDS type make to skip a push. Types without a check are
+// assumed to be pushed.
+var typeNeedsPush = map[string]func(proxy *model.Proxy, req *model.PushRequest) bool{
+	v3.ClusterType: func(proxy *model.Proxy, req *model.PushRequest) bool {
+		_, needsPush := cdsNeedsPush(req, proxy)
+		return needsPush
+	},
+	v3.ListenerType: ldsNeedsPush,
+	v3.RouteType: func(proxy *model.Proxy, req *model.PushRequest) bool {
+		return rdsNeedsPush(req, proxy)
+	},
+	v3.EndpointType: func(proxy *model.Proxy, req *model.PushRequest) bool {
+		return edsNeedsPush(req, proxy)
+	},
+	v3.ExtensionConfigurationType: func(proxy *model.Proxy, req *model.PushRequest) bool {
+		return ecdsNeedsPush(req, proxy)
+	},
+	v3.NameTableType: func(proxy *model.Proxy, req *model.PushRequest) bool {
+		return ndsNeedsPush(req, proxy)
+	},
+	v3.SecretType: func(_ *model.Proxy, req *model.PushRequest) bool {
+		return sdsNeedsPush(req.Forced, req.ConfigsUpdated)
+	},
+	v3.ProxyConfigType: func(_ *model.Proxy, req *model.PushRequest) bool {
+		return pcdsNeedsPush(req)
+	},
+	v3.CRLType: func(_ *model.Proxy, req *model.PushRequest) bool {
+		return crlNeedsPush(req)
+	},
+}
+
+// pushImpactz reports the proxies which an update of the configuration resources in the body of the request would
+// push, without pushing. Resources without a namespace default to the namespace query parameter.
+func (s *DiscoveryServer) pushImpactz(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, _ = w.Write([]byte("The configuration resources must be sent in the body of a POST request\n"))
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxPushImpactBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			_, _ = w.Write([]byte(fmt.Sprintf("the configuration resources exceed %d bytes\n", tooLarge.Limit)))
+			return
+		}
+		handleHTTPError(w, err)
+		return
+	}
+	configs, unrecognized, err := crd.ParseInputs(string(body))
+	kinds := sets.New[string]()
+	for _, k := range unrecognized {
+		kinds.Insert(k.APIVersion + "/" + k.Kind)
+	}
+	if err == nil && len(configs) == 0 {
+		err = fmt.Errorf("no configuration resources found")
+		if len(kinds) > 0 {
+			err = fmt.Errorf("no configuration resources found, unrecognized kinds: %s", strings.Join(sets.SortedList(kinds), ", "))
+		}
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(fmt.Sprintf("invalid configuration resources: %v\n", err)))
+		return
+	}
+	for i := range configs {
+		if configs[i].Namespace == "" {
+			configs[i].Namespace = req.URL.Query().Get("namespace")
+		}
+	}
+	impact := s.PushImpact(configs)
+	impact.UnrecognizedKinds = sets.SortedList(kinds)
+	writeJSON(w, impact, req)
+}
+
+// PushImpact computes the impact an update of the configs would have on the connected proxies, without pushing. It
+// computes the ConfigKeys the update would set in PushRequest.ConfigsUpdated, and runs the ProxyNeedsPush check of
+// each connected proxy and the checks of the generators of its watched types against them.
+//
+// Proxies are checked against their current SidecarScope. As a config which does not exist yet is not part of any
+// scope, a sidecar is also considered to depend on a new DestinationRule or VirtualService for a host it imports,
+// and on a new Sidecar of its namespace or of the root namespace.
+func (s *DiscoveryServer) PushImpact(configs []config.Config) PushImpact {
+	push := s.globalPushContext()
+	updates := sets.New[model.ConfigKey]()
+	var created []config.Config
+	for _, c := range configs {
+		updates.InsertAll(configKeys(c)...)
+		if s.Env.ConfigStore != nil && s.Env.ConfigStore.Get(c.GroupVersionKind, c.Name, c.Namespace) == nil {
+			created = append(created, c)
+		}
+	}
+	out := PushImpact{
+		ConfigsUpdated: slices.Sort(slices.Map(updates.UnsortedList(), model.ConfigKey.String)),
+		Proxies:        []ProxyPushImpact{},
+	}
+	for _, con := range s.Clients() {
+		out.ConnectedProxies++
+		proxy := cloneProxy(con.proxy)
+		pushRequest := &model.PushRequest{
+			Full:           true,
+			Push:           push,
+			ConfigsUpdated: updates.Copy(),
+			Reason:         model.NewReasonStats(model.ConfigUpdate),
+		}
+		pushRequest, needsPush := s.ProxyNeedsPush(proxy, pushRequest)
+		if newUpdates := dependsOnCreatedConfigs(proxy, created, push); len(newUpdates) > 0 {
+			filtered := *pushRequest
+			filtered.ConfigsUpdated = pushRequest.ConfigsUpdated.Copy().InsertAll(newUpdates...)
+			pushRequest, needsPush = &filtered, true
+		}
+		if !needsPush {
+			continue
+		}
+		var types []string
+		for typeURL := range proxy.ShallowCloneWatchedResources() {
+			if strings.HasPrefix(typeURL, v3.DebugType) {
+				continue
+			}
+			if check, f := typeNeedsPush[typeURL]; f && !check(proxy, pushRequest) {
+				continue
+			}
+			types = append(types, v3.GetShortType(typeURL))
+		}
+		if len(types) > 0 {
+			out.Proxies = append(out.Proxies, ProxyPushImpact{Proxy: proxy.ID, Types: slices.Sort(types)})
+		}
+	}
+	sort.Slice(out.Proxies, func(i, j int) bool {
+		return out.Proxies[i].Proxy < out.Proxies[j].Proxy
+	})
+	return out
+}
+
+// configKeys returns the ConfigKeys an update of the config sets in PushRequest.ConfigsUpdated.
+func configKeys(c config.Config) []model.ConfigKey {
+	if c.GroupVersionKind == gvk.ServiceEntry {
+		// ServiceEntries are pushed as the services they declare, named after their hostname.
+		if se, ok := c.Spec.(*networking.ServiceEntry); ok {
+			return slices.Map(se.Hosts, func(h string) model.ConfigKey {
+				return model.ConfigKey{Kind: kind.ServiceEntry, Name: h, Namespace: c.Namespace}
+			})
+		}
+	}
+	k, f := gvk.ToKind(c.GroupVersionKind)
+	if !f {
+		return nil
+	}
+	return []model.ConfigKey{{Kind: k, Name: c.Name, Namespace: c.Namespace}}
+}
+
+// dependsOnCreatedConfigs returns the ConfigKeys of the configs which do not exist yet, which the SidecarScope of a
+// sidecar would depend on once created.
+func dependsOnCreatedConfigs(proxy *model.Proxy, created []config.Config, push *model.PushContext) []model.ConfigKey {
+	if proxy.Type != model.SidecarProxy || proxy.SidecarScope == nil {
+		return nil
+	}
+	var out []model.ConfigKey
+	for _, c := range created {
+		var hosts []string
+		switch spec := c.Spec.(type) {
+		case *networking.DestinationRule:
+			hosts = []string{spec.Host}
+		case *networking.VirtualService:
+			hosts = spec.Hosts
+		case *networking.Sidecar:
+			if c.Namespace == proxy.ConfigNamespace || c.Namespace == push.Mesh.GetRootNamespace() {
+				out = append(out, configKeys(c)...)
+			}
+			continue
+		default:
+			continue
+		}
+		for _, h := range hosts {
+			if len(proxy.SidecarScope.ServicesForHostname(model.ResolveShortnameToFQDN(h, c.Meta))) > 0 {
+				out = append(out, configKeys(c)...)
+				break
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,202 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xds_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+
+	"istio.io/istio/pilot/pkg/config/kube/crd"
+	"istio.io/istio/pilot/pkg/xds"
+	v3 "istio.io/istio/pilot/pkg/xds/v3"
+	xdsfake "istio.io/istio/pilot/test/xds"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+const pushImpactConfig = `
+apiVersion: networking.istio.io/v1
+kind: ServiceEntry
+metadata:
+  name: external
+  namespace: default
+spec:
+  hosts:
+  - example.com
+  ports:
+  - number: 80
+    name: http
+    protocol: HTTP
+  resolution: DNS
+---
+apiVersion: networking.istio.io/v1
+kind: DestinationRule
+metadata:
+  name: external
+  namespace: default
+spec:
+  host: example.com
+---
+apiVersion: networking.istio.io/v1
+kind: Sidecar
+metadata:
+  name: default
+  namespace: other
+spec:
+  egress:
+  - hosts:
+    - "./*"
+`
+
+func TestPushImpact(t *testing.T) {
+	s := xdsfake.NewFakeDiscoveryServer(t, xdsfake.FakeOptions{ConfigString: pushImpactConfig})
+	for _, id := range []string{"sidecar~1.1.1.1~test.default~default.svc.cluster.local", "sidecar~1.1.1.2~test.other~other.svc.cluster.local"} {
+		ads := s.ConnectADS().WithID(id)
+		ads.RequestResponseAck(t, &discovery.DiscoveryRequest{TypeUrl: v3.ClusterType})
+		ads.RequestResponseAck(t, &discovery.DiscoveryRequest{TypeUrl: v3.ListenerType})
+	}
+
+	cases := []struct {
+		name   string
+		config string
+		want   xds.PushImpact
+	}{
+		{
+			name: "existing destination rule",
+			config: `apiVersion: networking.istio.io/v1
+kind: DestinationRule
+metadata:
+  name: external
+  namespace: default
+spec:
+  host: example.com
+  trafficPolicy:
+    tls:
+      mode: SIMPLE
+`,
+			want: xds.PushImpact{
+				ConfigsUpdated:   []string{"DestinationRule/default/external"},
+				ConnectedProxies: 2,
+				Proxies:          []xds.ProxyPushImpact{{Proxy: "test.default", Types: []string{"CDS", "LDS"}}},
+			},
+		},
+		{
+			name: "new destination rule",
+			config: `apiVersion: networking.istio.io/v1
+kind: DestinationRule
+metadata:
+  name: new
+  namespace: default
+spec:
+  host: example.com
+`,
+			want: xds.PushImpact{
+				ConfigsUpdated:   []string{"DestinationRule/default/new"},
+				ConnectedProxies: 2,
+				Proxies:          []xds.ProxyPushImpact{{Proxy: "test.default", Types: []string{"CDS", "LDS"}}},
+			},
+		},
+		{
+			name: "authorization policy",
+			config: `apiVersion: security.istio.io/v1
+kind: AuthorizationPolicy
+metadata:
+  name: deny
+  namespace: other
+spec:
+  action: DENY
+  rules:
+  - {}
+`,
+			want: xds.PushImpact{
+				ConfigsUpdated:   []string{"AuthorizationPolicy/other/deny"},
+				ConnectedProxies: 2,
+				Proxies:          []xds.ProxyPushImpact{{Proxy: "test.other", Types: []string{"LDS"}}},
+			},
+		},
+		{
+			name: "service entry",
+			config: `apiVersion: networking.istio.io/v1
+kind: ServiceEntry
+metadata:
+  name: external
+  namespace: default
+spec:
+  hosts:
+  - example.com
+  - example.org
+  ports:
+  - number: 80
+    name: http
+    protocol: HTTP
+  resolution: DNS
+`,
+			want: xds.PushImpact{
+				ConfigsUpdated:   []string{"ServiceEntry/default/example.com", "ServiceEntry/default/example.org"},
+				ConnectedProxies: 2,
+				Proxies:          []xds.ProxyPushImpact{{Proxy: "test.default", Types: []string{"CDS", "LDS"}}},
+			},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			configs, _, err := crd.ParseInputs(tt.config)
+			assert.NoError(t, err)
+			assert.Equal(t, s.Discovery.PushImpact(configs), tt.want)
+		})
+	}
+}
+
+func TestPushImpactz(t *testing.T) {
+	s := xdsfake.NewFakeDiscoveryServer(t, xdsfake.FakeOptions{ConfigString: pushImpactConfig})
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/debug/push_impact?namespace=default", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		s.DiscoveryDebug.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := post(`apiVersion: networking.istio.io/v1
+kind: DestinationRule
+metadata:
+  name: external
+spec:
+  host: example.com
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unrelated
+`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("wanted response code 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	got := xds.PushImpact{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+	assert.Equal(t, got.ConfigsUpdated, []string{"DestinationRule/default/external"})
+	assert.Equal(t, got.UnrecognizedKinds, []string{"v1/ConfigMap"})
+
+	rr = post("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: unrelated\n")
+	assert.Equal(t, rr.Code, http.StatusBadRequest)
+	assert.Equal(t, rr.Body.String(), "invalid configuration resources: no configuration resources found, unrecognized kinds: v1/ConfigMap\n")
+
+	rr = post(strings.Repeat("#", 10*1024*1024+1))
+	assert.Equal(t, rr.Code, http.StatusRequestEntityTooLarge)
+}
@@ -15,6 +15,9 @@
 package xds
 
 import (
+	"sort"
+	"time"
+
 	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
 
 	mesh "istio.io/api/mesh/v1alpha1"
@@ -22,6 +25,7 @@ import (
 	"istio.io/istio/pilot/pkg/model"
 	tb "istio.io/istio/pilot/pkg/trustbundle"
 	"istio.io/istio/pilot/pkg/util/protoconv"
+	v3 "istio.io/istio/pilot/pkg/xds/v3"
 )
 
 // PcdsGenerator generates proxy configuration for proxies to consume
@@ -66,3 +70,25 @@ func (e *PcdsGenerator) Generate(proxy *model.Proxy, w *model.WatchedResource, r
 	}
 	return model.Resources{&discovery.Resource{Resource: protoconv.MessageToAny(pc)}}, model.DefaultXdsLogDetails, nil
 }
+
+// TrustBundleAcks returns the number of connected proxies that acknowledged a trust bundle sent after since,
+// and the IDs of the proxies that have not. The agent acknowledges the trust bundle after it updated
+// the roots it serves to Envoy over SDS. Proxies that do not watch PCDS, such as ztunnel and proxyless gRPC
+// clients, cannot acknowledge the trust bundle, so they are always reported as pending.
+func (s *DiscoveryServer) TrustBundleAcks(since time.Time) (int, []string) {
+	acked := 0
+	pending := []string{}
+	for _, con := range s.Clients() {
+		con.proxy.RLock()
+		w := con.proxy.WatchedResources[v3.ProxyConfigType]
+		ok := w != nil && w.NonceSent != "" && w.NonceSent == w.NonceAcked && !w.LastSendTime.Before(since)
+		con.proxy.RUnlock()
+		if ok {
+			acked++
+		} else {
+			pending = append(pending, con.proxy.ID)
+		}
+	}
+	sort.Strings(pending)
+	return acked, pending
+}
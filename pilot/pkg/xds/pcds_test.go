@@ -0,0 +1,51 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xds_test
+
+import (
+	"testing"
+	"time"
+
+	"istio.io/istio/pilot/pkg/features"
+	tb "istio.io/istio/pilot/pkg/trustbundle"
+	"istio.io/istio/pilot/pkg/xds"
+	v3 "istio.io/istio/pilot/pkg/xds/v3"
+	xdsfake "istio.io/istio/pilot/test/xds"
+	"istio.io/istio/pkg/test"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestTrustBundleAcks(t *testing.T) {
+	test.SetForTest(t, &features.MultiRootMesh, true)
+	s := xdsfake.NewFakeDiscoveryServer(t, xdsfake.FakeOptions{})
+	s.Discovery.Generators[v3.ProxyConfigType] = &xds.PcdsGenerator{TrustBundle: tb.NewTrustBundle(nil, nil)}
+	since := time.Now()
+
+	// A proxy acknowledging the trust bundle over PCDS
+	s.ConnectADS().WithType(v3.ProxyConfigType).RequestResponseAck(t, nil)
+	// A proxy that does not watch PCDS, such as ztunnel, cannot acknowledge the trust bundle
+	s.ConnectADS().WithID("sidecar~1.1.1.2~other.default~default.svc.cluster.local").WithType(v3.ClusterType).RequestResponseAck(t, nil)
+
+	type acks struct {
+		Acked   int
+		Pending []string
+	}
+	assert.EventuallyEqual(t, func() acks {
+		acked, pending := s.Discovery.TrustBundleAcks(since)
+		return acks{acked, pending}
+	}, acks{1, []string{"other.default"}})
+	acked, pending := s.Discovery.TrustBundleAcks(time.Now())
+	assert.Equal(t, acks{acked, pending}, acks{0, []string{"other.default", "test.default"}})
+}
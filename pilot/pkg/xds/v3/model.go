@@ -29,6 +29,7 @@ const (
 	HealthInfoType             = model.HealthInfoType
 	ProxyConfigType            = model.ProxyConfigType
 	DebugType                  = model.DebugType
+	CRLType                    = model.CRLType
 	BootstrapType              = model.BootstrapType
 	AddressType                = model.AddressType
 	WorkloadType               = model.WorkloadType
@@ -18,6 +18,7 @@ import (
 	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
 
 	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/revocation"
 	"istio.io/istio/pilot/pkg/util/protoconv"
 	v3 "istio.io/istio/pilot/pkg/xds/v3"
 	"istio.io/istio/pkg/config/schema/kind"
@@ -185,6 +186,9 @@ func (e WorkloadRBACGenerator) GenerateDeltas(
 ) (model.Resources, model.DeletedResources, model.XdsLogDetails, bool, error) {
 	var updatedPolicies sets.Set[model.ConfigKey]
 	expected := sets.New[string]()
+	revocations := e.Server.Revocations != nil && (req.Forced || revocationsUpdated(req))
+	rootNamespace := e.Server.Env.Mesh().GetRootNamespace()
+	revokedName := rootNamespace + "/" + revocation.IdentitiesPolicyName
 	if req.Forced {
 		// Full update, expect everything
 		expected.Merge(w.ResourceNames)
@@ -193,7 +197,7 @@ func (e WorkloadRBACGenerator) GenerateDeltas(
 		// so we can only fetch these ones.
 		updatedPolicies = model.ConfigsOfKind(req.ConfigsUpdated, kind.AuthorizationPolicy)
 
-		if len(updatedPolicies) == 0 {
+		if len(updatedPolicies) == 0 && !revocations {
 			// This was a incremental push for a resource we don't watch... skip
 			return nil, nil, model.DefaultXdsLogDetails, false, nil
 		}
@@ -201,17 +205,31 @@ func (e WorkloadRBACGenerator) GenerateDeltas(
 		for k := range updatedPolicies {
 			expected.Insert(k.Namespace + "/" + k.Name)
 		}
+		if revocations {
+			expected.Insert(revokedName)
+		}
 	}
 
 	resources := make(model.Resources, 0)
-	policies := e.Server.Env.ServiceDiscovery.Policies(updatedPolicies)
-	for _, p := range policies {
-		n := p.ResourceName()
-		expected.Delete(n) // delete the generated policy name, left the removed ones
-		resources = append(resources, &discovery.Resource{
-			Name:     n,
-			Resource: protoconv.MessageToAny(p.Authorization),
-		})
+	if req.Forced || len(updatedPolicies) > 0 {
+		policies := e.Server.Env.ServiceDiscovery.Policies(updatedPolicies)
+		for _, p := range policies {
+			n := p.ResourceName()
+			expected.Delete(n) // delete the generated policy name, left the removed ones
+			resources = append(resources, &discovery.Resource{
+				Name:     n,
+				Resource: protoconv.MessageToAny(p.Authorization),
+			})
+		}
+	}
+	if revocations {
+		if p := revokedIdentitiesPolicy(rootNamespace, e.Server.Revocations.RevokedIdentities()); p != nil {
+			expected.Delete(revokedName)
+			resources = append(resources, &discovery.Resource{
+				Name:     revokedName,
+				Resource: protoconv.MessageToAny(p),
+			})
+		}
 	}
 
 	return resources, sets.SortedList(expected), model.XdsLogDetails{}, true, nil
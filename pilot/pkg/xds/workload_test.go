@@ -34,16 +34,20 @@ import (
 	securityclient "istio.io/client-go/pkg/apis/security/v1"
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/revocation"
 	v3 "istio.io/istio/pilot/pkg/xds/v3"
 	"istio.io/istio/pilot/test/xds"
 	"istio.io/istio/pkg/config"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/config/schema/kind"
 	"istio.io/istio/pkg/kube/kclient/clienttest"
 	"istio.io/istio/pkg/log"
 	"istio.io/istio/pkg/test"
 	"istio.io/istio/pkg/test/util/assert"
 	"istio.io/istio/pkg/util/sets"
+	"istio.io/istio/pkg/workloadapi/security"
+	"istio.io/istio/security/pkg/pki/util"
 )
 
 func init() {
@@ -460,6 +464,61 @@ func TestWorkloadAuthorizationPolicy(t *testing.T) {
 	ads.ExpectNoResponse()
 }
 
+func TestWorkloadAuthorizationRevokedIdentities(t *testing.T) {
+	expect := buildExpect(t)
+	expectRemoved := buildExpectExpectRemoved(t)
+	s := xds.NewFakeDiscoveryServer(t, xds.FakeOptions{})
+	certPEM, keyPEM, err := util.GenCertKeyFromOptions(util.CertOptions{
+		TTL:          time.Hour,
+		Org:          "istio",
+		IsCA:         true,
+		IsSelfSigned: true,
+		ECSigAlg:     util.EcdsaSigAlg,
+	})
+	assert.NoError(t, err)
+	key := model.ConfigKey{Kind: kind.ConfigMap, Name: revocation.ConfigMapName, Namespace: constants.IstioSystemNamespace}
+	revocations := revocation.NewController(fakeRevocationCA{util.NewKeyCertBundleFromPem(certPEM, keyPEM, nil, certPEM, nil)}, func() {
+		s.Discovery.ConfigUpdate(&model.PushRequest{ConfigsUpdated: sets.New(key), Reason: model.NewReasonStats(model.SecretTrigger)})
+	})
+	s.Discovery.Revocations = revocations
+	ads := s.ConnectDeltaADS().WithType(v3.WorkloadAuthorizationType).WithTimeout(time.Second * 10).WithNodeType(model.Ztunnel)
+
+	ads.Request(&discovery.DeltaDiscoveryRequest{
+		ResourceNamesSubscribe: []string{"*"},
+	})
+	ads.ExpectEmptyResponse()
+
+	// Revoked identities are denied by a mesh wide policy.
+	revocations.Update([]revocation.Entry{{SpiffeID: "spiffe://cluster.local/ns/ns/sa/compromised"}})
+	resp := ads.ExpectResponse()
+	expect(resp, "istio-system/istio-revoked-identities")
+	policy := &security.Authorization{}
+	assert.NoError(t, resp.Resources[0].Resource.UnmarshalTo(policy))
+	assert.Equal(t, policy.Scope, security.Scope_GLOBAL)
+	assert.Equal(t, policy.Action, security.Action_DENY)
+	assert.Equal(t, policy.Groups[0].Rules[0].Matches[0].Principals, []*security.StringMatch{
+		{MatchType: &security.StringMatch_Exact{Exact: "cluster.local/ns/ns/sa/compromised"}},
+	})
+
+	// Revoking a certificate only does not change the policy.
+	revocations.Update([]revocation.Entry{
+		{SpiffeID: "spiffe://cluster.local/ns/ns/sa/compromised"},
+		{SerialNumber: "0a"},
+	})
+	expect(ads.ExpectResponse(), "istio-system/istio-revoked-identities")
+
+	revocations.Update(nil)
+	expectRemoved(ads.ExpectResponse(), "istio-system/istio-revoked-identities")
+}
+
+type fakeRevocationCA struct {
+	bundle *util.KeyCertBundle
+}
+
+func (f fakeRevocationCA) GetCAKeyCertBundle() *util.KeyCertBundle {
+	return f.bundle
+}
+
 func TestWorkloadPeerAuthentication(t *testing.T) {
 	expect := buildExpect(t)
 	expectAddedAndRemoved := buildExpectAddedAndRemoved(t)
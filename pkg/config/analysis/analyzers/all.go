@@ -59,6 +59,7 @@ func All() []analysis.Analyzer {
 		&injection.Analyzer{},
 		&injection.ImageAnalyzer{},
 		&injection.ImageAutoAnalyzer{},
+		&k8sgateway.RouteConflictAnalyzer{},
 		&k8sgateway.SelectorAnalyzer{},
 		&multicluster.MeshNetworksAnalyzer{},
 		&multicluster.ServiceAnalyzer{},
@@ -100,16 +101,22 @@ func AllMultiClusterCombined() analysis.CombinedAnalyzer {
 }
 
 func NamedCombined(names ...string) analysis.CombinedAnalyzer {
-	selected := make([]analysis.Analyzer, 0, len(All()))
+	return NamedCombinedFrom(All(), names...)
+}
+
+// NamedCombinedFrom combines the analyzers with the given names, such as the built-in and custom analyzers. All the
+// analyzers are combined if none of them has one of the names.
+func NamedCombinedFrom(analyzers []analysis.Analyzer, names ...string) analysis.CombinedAnalyzer {
+	selected := make([]analysis.Analyzer, 0, len(analyzers))
 	nameSet := sets.New(names...)
-	for _, a := range All() {
+	for _, a := range analyzers {
 		if nameSet.Contains(a.Metadata().Name) {
 			selected = append(selected, a)
 		}
 	}
 
 	if len(selected) == 0 {
-		return AllCombined()
+		return analysis.Combine("all", analyzers...)
 	}
 
 	return analysis.Combine("named", selected...)
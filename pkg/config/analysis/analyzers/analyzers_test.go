@@ -973,6 +973,18 @@ var testGrid = []testCase{
 			{msg.IneffectiveSelector, "Telemetry default/telemetry-ineffective"},
 		},
 	},
+	{
+		name:       "KubernetesGatewayRouteConflict",
+		inputFiles: []string{"testdata/k8sgateway-route-conflict.yaml"},
+		analyzer:   &k8sgateway.RouteConflictAnalyzer{},
+		expected: []message{
+			{msg.PartiallyOverriddenRoute, "HTTPRoute default/api-v2"},
+			{msg.ShadowedRoute, "HTTPRoute default/api-v3"},
+			{msg.ShadowedRoute, "TLSRoute default/passthrough-v2"},
+			{msg.ConflictingRoutesAcrossNamespaces, "HTTPRoute default/reviews"},
+			{msg.ConflictingRoutesAcrossNamespaces, "VirtualService apps/reviews"},
+		},
+	},
 	{
 		name:       "ServiceEntry Addresses Required Lowercase Protocol",
 		inputFiles: []string{"testdata/serviceentry-address-required-lowercase.yaml"},
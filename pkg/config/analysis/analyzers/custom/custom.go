@@ -0,0 +1,293 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package custom implements user-defined analyzers, declared in YAML files with CEL expressions evaluated against
+// each resource of a kind. For example:
+//
+//	analyzers:
+//	- name: org.VirtualServiceTimeoutAnalyzer
+//	  description: Checks that HTTP routes set a timeout
+//	  input: VirtualService
+//	  match: resource.metadata.namespace.startsWith("prod-")
+//	  condition: '!has(resource.spec.http) || resource.spec.http.all(r, has(r.timeout))'
+//	  message:
+//	    code: ORG0001
+//	    level: Warning
+//	    template: "VirtualService %s has HTTP routes without a timeout"
+//	    parameters: [resource.metadata.name]
+//
+// The message is reported for the resources matching the match expression, if set, for which the condition is false.
+// Accessing a missing field is an error, logged and skipping the resource; use has(), "in" or optional field selection,
+// such as resource.metadata.labels[?"app"].orValue(""), for optional fields. Expressions whose evaluation exceeds
+// celeval.CostLimit fail the same way.
+package custom
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/analysis"
+	"istio.io/istio/pkg/config/analysis/diag"
+	"istio.io/istio/pkg/config/analysis/scope"
+	"istio.io/istio/pkg/config/celeval"
+	"istio.io/istio/pkg/config/resource"
+	"istio.io/istio/pkg/config/schema/collections"
+	sresource "istio.io/istio/pkg/config/schema/resource"
+	"istio.io/istio/pkg/util/sets"
+)
+
+// File is the format of a file of custom analyzers.
+type File struct {
+	Analyzers []Definition `json:"analyzers"`
+}
+
+// Definition declares a custom analyzer.
+type Definition struct {
+	// Name of the analyzer, used to select it with istioctl analyze --analyzer.
+	Name string `json:"name"`
+	// Description is displayed by istioctl analyze --list-analyzers.
+	Description string `json:"description,omitempty"`
+	// Input is the kind of the analyzed resources, such as VirtualService, qualified by its group if the kind is
+	// ambiguous, such as gateway.networking.k8s.io/Gateway.
+	Input string `json:"input"`
+	// Match is an optional CEL expression selecting the analyzed resources.
+	Match string `json:"match,omitempty"`
+	// Condition is a CEL expression the analyzed resources must satisfy; the message is reported if it is false.
+	Condition string `json:"condition"`
+	// Message is the message reported for the resources failing the condition.
+	Message MessageDefinition `json:"message"`
+}
+
+// MessageDefinition declares the message of a custom analyzer.
+type MessageDefinition struct {
+	// Code of the message. Codes starting with IST are reserved for the built-in analyzers.
+	Code string `json:"code"`
+	// Level of the message: Info, Warning or Error.
+	Level string `json:"level"`
+	// Template is the text of the message, formatted with the parameters as by fmt.Sprintf.
+	Template string `json:"template"`
+	// Parameters are CEL expressions providing the parameters of the template.
+	Parameters []string `json:"parameters,omitempty"`
+}
+
+// Analyzer is an analyzer evaluating the CEL expressions of a definition against each resource of its input.
+type Analyzer struct {
+	def        Definition
+	input      sresource.Schema
+	msgType    *diag.MessageType
+	match      cel.Program
+	condition  cel.Program
+	parameters []cel.Program
+}
+
+var _ analysis.Analyzer = &Analyzer{}
+
+// Metadata implements analysis.Analyzer
+func (a *Analyzer) Metadata() analysis.Metadata {
+	return analysis.Metadata{
+		Name:        a.def.Name,
+		Description: a.def.Description,
+		Inputs:      []config.GroupVersionKind{a.input.GroupVersionKind()},
+	}
+}
+
+// Analyze implements analysis.Analyzer
+func (a *Analyzer) Analyze(ctx analysis.Context) {
+	ctx.ForEach(a.input.GroupVersionKind(), func(r *resource.Instance) bool {
+		vars, err := a.activation(r)
+		if err != nil {
+			scope.Analysis.Warnf("custom analyzer %s skipped %s: %v", a.def.Name, r.Metadata.FullName, err)
+			return true
+		}
+		if a.match != nil {
+			matched, err := celeval.EvalBool(a.match, vars)
+			if err != nil {
+				scope.Analysis.Warnf("custom analyzer %s failed to evaluate match for %s: %v", a.def.Name, r.Metadata.FullName, err)
+				return true
+			}
+			if !matched {
+				return true
+			}
+		}
+		ok, err := celeval.EvalBool(a.condition, vars)
+		if err != nil {
+			scope.Analysis.Warnf("custom analyzer %s failed to evaluate condition for %s: %v", a.def.Name, r.Metadata.FullName, err)
+			return true
+		}
+		if ok {
+			return true
+		}
+		params := make([]any, 0, len(a.parameters))
+		for i, p := range a.parameters {
+			v, _, err := p.Eval(vars)
+			if err != nil {
+				scope.Analysis.Warnf("custom analyzer %s failed to evaluate parameter %d for %s: %v", a.def.Name, i, r.Metadata.FullName, err)
+				params = append(params, "<unknown>")
+				continue
+			}
+			if s, err := v.ConvertToNative(stringType); err == nil {
+				params = append(params, s)
+			} else {
+				params = append(params, v.Value())
+			}
+		}
+		ctx.Report(a.input.GroupVersionKind(), diag.NewMessage(a.msgType, r, params...))
+		return true
+	})
+}
+
+// activation returns the variables of the CEL expressions for a resource.
+func (a *Analyzer) activation(r *resource.Instance) (map[string]any, error) {
+	return celeval.Activation(config.Meta{
+		GroupVersionKind: a.input.GroupVersionKind(),
+		Name:             r.Metadata.FullName.Name.String(),
+		Namespace:        r.Metadata.FullName.Namespace.String(),
+		Labels:           r.Metadata.Labels,
+		Annotations:      r.Metadata.Annotations,
+		Generation:       r.Metadata.Generation,
+	}, r.Message, r.Status)
+}
+
+var stringType = reflect.TypeOf("")
+
+// New compiles the expressions of a definition into an analyzer.
+func New(def Definition) (*Analyzer, error) {
+	if def.Name == "" {
+		return nil, fmt.Errorf("analyzer name is required")
+	}
+	fail := func(format string, args ...any) (*Analyzer, error) {
+		return nil, fmt.Errorf("analyzer %s: %s", def.Name, fmt.Sprintf(format, args...))
+	}
+	input, err := findSchema(def.Input)
+	if err != nil {
+		return fail("%v", err)
+	}
+	m := def.Message
+	if m.Code == "" || m.Template == "" {
+		return fail("message code and template are required")
+	}
+	if strings.HasPrefix(m.Code, "IST") {
+		return fail("message code %s is reserved, codes starting with IST are used by the built-in analyzers", m.Code)
+	}
+	level, ok := diag.GetUppercaseStringToLevelMap()[strings.ToUpper(m.Level)]
+	if !ok {
+		return fail("invalid message level %q, expected one of %v", m.Level, diag.GetAllLevelStrings())
+	}
+	a := &Analyzer{
+		def:     def,
+		input:   input,
+		msgType: diag.NewMessageType(level, m.Code, m.Template),
+	}
+	if def.Condition == "" {
+		return fail("condition is required")
+	}
+	if a.condition, err = celeval.Compile(def.Condition, true); err != nil {
+		return fail("invalid condition: %v", err)
+	}
+	if def.Match != "" {
+		if a.match, err = celeval.Compile(def.Match, true); err != nil {
+			return fail("invalid match: %v", err)
+		}
+	}
+	for i, p := range m.Parameters {
+		prg, err := celeval.Compile(p, false)
+		if err != nil {
+			return fail("invalid parameter %d: %v", i, err)
+		}
+		a.parameters = append(a.parameters, prg)
+	}
+	return a, nil
+}
+
+// findSchema returns the schema of a kind, optionally qualified by its group.
+func findSchema(input string) (sresource.Schema, error) {
+	if input == "" {
+		return nil, fmt.Errorf("input is required")
+	}
+	group, kind, qualified := strings.Cut(input, "/")
+	if !qualified {
+		kind = group
+	}
+	var found []sresource.Schema
+	for _, s := range collections.All.All() {
+		if s.Kind() == kind && (!qualified || s.Group() == group) {
+			found = append(found, s)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("unknown input %s", input)
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("ambiguous input %s, qualify it with its group, such as %s/%s", input, found[0].Group(), kind)
+	}
+}
+
+// Parse parses a file of custom analyzers.
+func Parse(data []byte) ([]*Analyzer, error) {
+	f := File{}
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		return nil, err
+	}
+	out := make([]*Analyzer, 0, len(f.Analyzers))
+	for _, def := range f.Analyzers {
+		a, err := New(def)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// Key is the key of the custom analyzers in a ConfigMap.
+const Key = "analyzers"
+
+// Load loads the custom analyzers of files, or of the YAML and JSON files of directories. The files may be
+// ConfigMaps, holding the analyzers under their analyzers key. Analyzer names must be unique.
+func Load(paths ...string) ([]analysis.Analyzer, error) {
+	var out []analysis.Analyzer
+	names := sets.New[string]()
+	err := celeval.ReadFiles(paths, Key, func(name string, data []byte) error {
+		analyzers, err := Parse(data)
+		if err != nil {
+			return fmt.Errorf("invalid custom analyzers in %s: %v", name, err)
+		}
+		for _, a := range analyzers {
+			if names.InsertContains(a.def.Name) {
+				return fmt.Errorf("invalid custom analyzers in %s: analyzer %s is defined more than once", name, a.def.Name)
+			}
+			out = append(out, a)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// MessageTypes returns the types of the messages reported by custom analyzers.
+func MessageTypes(analyzers []analysis.Analyzer) []*diag.MessageType {
+	var out []*diag.MessageType
+	for _, a := range analyzers {
+		if c, ok := a.(*Analyzer); ok {
+			out = append(out, c.msgType)
+		}
+	}
+	return out
+}
@@ -0,0 +1,178 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"istio.io/istio/pkg/config/analysis"
+	"istio.io/istio/pkg/config/analysis/diag"
+	"istio.io/istio/pkg/config/analysis/local"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+const analyzers = `
+analyzers:
+- name: org.WildcardHostAnalyzer
+  description: Checks that production VirtualServices have no wildcard hosts
+  input: VirtualService
+  match: resource.metadata.namespace.startsWith("prod") && resource.metadata.labels[?"org.example.com/exempt"].orValue("") != "true"
+  condition: '!resource.spec.hosts.exists(h, h.startsWith("*"))'
+  message:
+    code: ORG0002
+    level: warning
+    template: "VirtualService %s/%s has wildcard hosts %v"
+    parameters:
+    - resource.metadata.namespace
+    - resource.metadata.name
+    - resource.spec.hosts.filter(h, h.startsWith("*")).join(",")
+- name: org.KubernetesGatewayListenerAnalyzer
+  input: gateway.networking.k8s.io/Gateway
+  condition: resource.spec.listeners.size() > 0
+  message:
+    code: ORG0003
+    level: Error
+    template: Gateway has no listener
+`
+
+const resources = `
+apiVersion: networking.istio.io/v1
+kind: VirtualService
+metadata:
+  name: wildcard
+  namespace: prod-a
+spec:
+  hosts: ["*.example.com", "*.example.org", "foo.example.com"]
+---
+apiVersion: networking.istio.io/v1
+kind: VirtualService
+metadata:
+  name: exempt
+  namespace: prod-a
+  labels:
+    org.example.com/exempt: "true"
+spec:
+  hosts: ["*.example.com"]
+---
+apiVersion: networking.istio.io/v1
+kind: VirtualService
+metadata:
+  name: wildcard
+  namespace: dev
+spec:
+  hosts: ["*.example.com"]
+---
+apiVersion: networking.istio.io/v1
+kind: VirtualService
+metadata:
+  name: exact
+  namespace: prod-b
+spec:
+  hosts: ["foo.example.com"]
+`
+
+func TestAnalyze(t *testing.T) {
+	custom, err := Parse([]byte(analyzers))
+	assert.NoError(t, err)
+	assert.Equal(t, custom[0].Metadata().Inputs[0], gvk.VirtualService)
+	assert.Equal(t, custom[1].Metadata().Inputs[0], gvk.KubernetesGateway)
+
+	sa := local.NewSourceAnalyzer(analysis.Combine("custom", custom[0]), "", "istio-system", nil)
+	assert.NoError(t, sa.AddTestReaderKubeSource([]local.ReaderSource{{Name: "resources.yaml", Reader: strings.NewReader(resources)}}))
+	result, err := sa.Analyze(make(chan struct{}))
+	assert.NoError(t, err)
+	assert.Equal(t, len(result.Messages), 1)
+	m := result.Messages[0]
+	assert.Equal(t, m.Type.Level().String(), diag.Warning.String())
+	assert.Equal(t, m.Type.Code(), "ORG0002")
+	assert.Equal(t, m.Resource.Metadata.FullName.String(), "prod-a/wildcard")
+	assert.Equal(t, m.Unstructured(false)["message"], "VirtualService prod-a/wildcard has wildcard hosts *.example.com,*.example.org")
+}
+
+func TestNewInvalid(t *testing.T) {
+	valid := func() Definition {
+		return Definition{
+			Name:      "org.Analyzer",
+			Input:     "VirtualService",
+			Condition: "true",
+			Message:   MessageDefinition{Code: "ORG0001", Level: "Info", Template: "message"},
+		}
+	}
+	_, err := New(valid())
+	assert.NoError(t, err)
+
+	cases := map[string]func(d *Definition){
+		"name":              func(d *Definition) { d.Name = "" },
+		"unknown input":     func(d *Definition) { d.Input = "Unknown" },
+		"ambiguous input":   func(d *Definition) { d.Input = "Gateway" },
+		"missing condition": func(d *Definition) { d.Condition = "" },
+		"invalid condition": func(d *Definition) { d.Condition = "resource.spec.hosts.exists(" },
+		"non bool match":    func(d *Definition) { d.Match = "resource.metadata.name.size()" },
+		"invalid parameter": func(d *Definition) { d.Message.Parameters = []string{"unknown.name"} },
+		"reserved code":     func(d *Definition) { d.Message.Code = "IST0101" },
+		"invalid level":     func(d *Definition) { d.Message.Level = "Fatal" },
+		"missing template":  func(d *Definition) { d.Message.Template = "" },
+	}
+	for name, modify := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := valid()
+			modify(&d)
+			_, err := New(d)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "org.yaml"), []byte(analyzers), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not an analyzer"), 0o644))
+	loaded, err := Load(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, len(loaded), 2)
+	assert.Equal(t, len(MessageTypes(loaded)), 2)
+
+	// Names must be unique.
+	_, err = Load(dir, filepath.Join(dir, "org.yaml"))
+	assert.Error(t, err)
+
+	// ConfigMaps hold the analyzers under their analyzers key.
+	cm := filepath.Join(t.TempDir(), "configmap.yaml")
+	assert.NoError(t, os.WriteFile(cm, []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: custom-analyzers
+data:
+  analyzers: |
+`+indent(analyzers, "    ")), 0o644))
+	loaded, err = Load(cm)
+	assert.NoError(t, err)
+	assert.Equal(t, len(loaded), 2)
+}
+
+func indent(s, prefix string) string {
+	lines := strings.Split(strings.TrimPrefix(s, "\n"), "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = prefix + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,703 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sgateway
+
+import (
+	"cmp"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	k8s "sigs.k8s.io/gateway-api/apis/v1"
+	k8salpha "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/config/kube/gateway"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/analysis"
+	"istio.io/istio/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/pkg/config/analysis/diag"
+	"istio.io/istio/pkg/config/analysis/msg"
+	"istio.io/istio/pkg/config/constants"
+	kubegateway "istio.io/istio/pkg/config/gateway/kube"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/resource"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/config/visibility"
+	"istio.io/istio/pkg/maps"
+	"istio.io/istio/pkg/ptr"
+	"istio.io/istio/pkg/slices"
+	"istio.io/istio/pkg/util/sets"
+)
+
+// RouteConflictAnalyzer checks the routes of VirtualServices and Gateway API routes bound to the same host, on a
+// Gateway listener or in the mesh. Gateway API routes are translated to VirtualServices, and compete with the
+// VirtualServices bound to the same host:
+//   - On a gateway, the routes of all the resources are evaluated in order. Routes never matching because the routes of
+//     another resource take precedence are reported as shadowed, or partially overridden if only some of the rules of
+//     a resource are affected.
+//   - In the mesh, only the routes of the first resource apply. Resources which never apply are reported as shadowed,
+//     and resources from different namespaces applying depending on the namespace of the client are reported as
+//     conflicting.
+//
+// Only hosts with Gateway API routes are checked; VirtualServices alone are checked by the virtualservice analyzers.
+type RouteConflictAnalyzer struct{}
+
+var _ analysis.Analyzer = &RouteConflictAnalyzer{}
+
+// Metadata implements analysis.Analyzer
+func (a *RouteConflictAnalyzer) Metadata() analysis.Metadata {
+	return analysis.Metadata{
+		Name:        "k8sgateway.RouteConflictAnalyzer",
+		Description: "Checks for conflicts between the VirtualServices and Gateway API routes bound to the same host",
+		Inputs: []config.GroupVersionKind{
+			gvk.VirtualService,
+			gvk.HTTPRoute,
+			gvk.GRPCRoute,
+			gvk.TLSRoute,
+			gvk.KubernetesGateway,
+			gvk.Namespace,
+		},
+	}
+}
+
+// routeKey identifies the routes competing for the requests to a host, or the TLS connections to an SNI host.
+type routeKey struct {
+	// parent is the Istio name of the gateway ("namespace/name"), or "mesh".
+	parent string
+	host   string
+	tls    bool
+}
+
+// routeUnit is a set of routes applied as a single VirtualService: a VirtualService, or the Gateway API routes merged
+// for a host.
+type routeUnit struct {
+	// resources are the resources the routes come from, oldest first.
+	resources  []*resource.Instance
+	gatewayAPI bool
+	// exportTo holds the namespaces the routes are visible in, if they are not visible in all namespaces.
+	exportTo sets.String
+	rules    []routeRule
+	// mergeKey identifies the Gateway API routes merged in the unit.
+	mergeKey string
+}
+
+// routeRule is a rule of a resource.
+type routeRule struct {
+	r          *resource.Instance
+	index      int
+	gatewayAPI bool
+	// matches are the matches of the rule, or nil if the rule matches all requests.
+	matches []*networking.HTTPMatchRequest
+}
+
+type listener struct {
+	hostname string
+	gateway  string
+}
+
+type routeTable map[routeKey][]*routeUnit
+
+// Analyze implements analysis.Analyzer
+func (a *RouteConflictAnalyzer) Analyze(c analysis.Context) {
+	listeners := map[string]listener{}
+	routes := routeTable{}
+	a.addGatewayAPIRoutes(c, routes, listeners)
+	a.addVirtualServices(c, routes, listeners)
+
+	for _, key := range slices.SortFunc(maps.Keys(routes), func(a, b routeKey) int {
+		return cmp.Or(cmp.Compare(a.parent, b.parent), cmp.Compare(a.host, b.host), cmp.Compare(strconv.FormatBool(a.tls), strconv.FormatBool(b.tls)))
+	}) {
+		units := routes[key]
+		if slices.IndexFunc(units, func(u *routeUnit) bool { return u.gatewayAPI }) < 0 {
+			continue
+		}
+		parent := parentName(key.parent, listeners)
+		if key.parent == constants.IstioMeshGateway {
+			analyzeMeshRoutes(c, key, parent, units)
+		} else {
+			analyzeGatewayRoutes(c, key, parent, units)
+		}
+	}
+}
+
+// addGatewayAPIRoutes adds the HTTPRoutes, GRPCRoutes and TLSRoutes to the route table, merged as on translation.
+func (a *RouteConflictAnalyzer) addGatewayAPIRoutes(c analysis.Context, routes routeTable, listeners map[string]listener) {
+	namespaces := map[string]map[string]string{}
+	c.ForEach(gvk.Namespace, func(r *resource.Instance) bool {
+		namespaces[r.Metadata.FullName.Name.String()] = r.Metadata.Labels
+		return true
+	})
+	gateways := map[string]*resource.Instance{}
+	c.ForEach(gvk.KubernetesGateway, func(r *resource.Instance) bool {
+		gateways[r.Metadata.FullName.String()] = r
+		spec := r.Message.(*k8s.GatewaySpec)
+		for _, l := range spec.Listeners {
+			listeners[internalName(r, l)] = listener{
+				hostname: string(ptr.OrEmpty(l.Hostname)),
+				gateway:  fmt.Sprintf("listener %s of Gateway %s", l.Name, r.Metadata.FullName),
+			}
+		}
+		return true
+	})
+
+	merged := map[string]*routeUnit{}
+	add := func(key routeKey, mergeKey string, r *resource.Instance, rules []routeRule) {
+		if len(rules) == 0 {
+			return
+		}
+		u := merged[mergeKey]
+		if u == nil {
+			u = &routeUnit{gatewayAPI: true, mergeKey: mergeKey}
+			merged[mergeKey] = u
+			routes[key] = append(routes[key], u)
+		}
+		if !slices.Contains(u.resources, r) {
+			u.resources = append(u.resources, r)
+		}
+		u.rules = append(u.rules, rules...)
+	}
+
+	for _, kind := range []config.GroupVersionKind{gvk.HTTPRoute, gvk.GRPCRoute, gvk.TLSRoute} {
+		c.ForEach(kind, func(r *resource.Instance) bool {
+			parentRefs, hostnames := routeInfo(r.Message)
+			ns := r.Metadata.FullName.Namespace.String()
+			for _, ref := range parentRefs {
+				refNs := string(ptr.OrDefault(ref.Namespace, k8s.Namespace(ns)))
+				refKind := ptr.OrDefault(ref.Kind, k8s.Kind(gvk.KubernetesGateway.Kind))
+				refGroup := ptr.OrDefault(ref.Group, k8s.Group(gvk.KubernetesGateway.Group))
+				switch {
+				case refKind == k8s.Kind(gvk.Service.Kind) && refGroup == "" && kind != gvk.TLSRoute:
+					// Mesh routes are merged by namespace for the host of the service.
+					h := fmt.Sprintf("%s.%s.%s", ref.Name, refNs, util.DefaultClusterLocalDomain)
+					add(routeKey{parent: constants.IstioMeshGateway, host: h}, ns+"/"+h, r, gatewayAPIRules(r, uint32(ptr.OrEmpty(ref.Port))))
+				case refKind == k8s.Kind(gvk.KubernetesGateway.Kind) && refGroup == k8s.Group(gvk.KubernetesGateway.Group):
+					gw := gateways[refNs+"/"+string(ref.Name)]
+					if gw == nil {
+						continue
+					}
+					for _, l := range gw.Message.(*k8s.GatewaySpec).Listeners {
+						if !listenerAccepts(gw, l, ref, kind, ns, namespaces) {
+							continue
+						}
+						parent := internalName(gw, l)
+						for _, h := range gateway.RouteHosts(hostnames, string(ptr.OrEmpty(l.Hostname))) {
+							key := routeKey{parent: parent, host: h, tls: kind == gvk.TLSRoute}
+							if key.tls {
+								// TLS routes are not merged.
+								add(key, fmt.Sprintf("%s/%s/%s/%s", kind.Kind, r.Metadata.FullName, parent, h), r, []routeRule{{r: r, gatewayAPI: true}})
+								continue
+							}
+							add(key, parent+"/"+h, r, gatewayAPIRules(r, 0))
+						}
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	// Merged routes are ordered by creation time, then by the precedence defined by the Gateway API.
+	for _, u := range merged {
+		sort.SliceStable(u.resources, func(i, j int) bool {
+			return olderThan(u.resources[i], u.resources[j])
+		})
+		sort.SliceStable(u.rules, func(i, j int) bool {
+			return u.rules[i].r != u.rules[j].r && olderThan(u.rules[i].r, u.rules[j].r)
+		})
+		translated := make([]*networking.HTTPRoute, 0, len(u.rules))
+		rules := map[*networking.HTTPRoute]routeRule{}
+		for _, rule := range u.rules {
+			route := &networking.HTTPRoute{Match: rule.matches}
+			rules[route] = rule
+			translated = append(translated, route)
+		}
+		gateway.SortHTTPRoutes(translated)
+		u.rules = slices.Map(translated, func(route *networking.HTTPRoute) routeRule {
+			return rules[route]
+		})
+	}
+}
+
+// routeInfo returns the parents and hostnames of the spec of a Gateway API route.
+func routeInfo(spec any) ([]k8s.ParentReference, []k8s.Hostname) {
+	switch t := spec.(type) {
+	case *k8s.HTTPRouteSpec:
+		return t.ParentRefs, t.Hostnames
+	case *k8s.GRPCRouteSpec:
+		return t.ParentRefs, t.Hostnames
+	case *k8salpha.TLSRouteSpec:
+		return t.ParentRefs, t.Hostnames
+	}
+	return nil, nil
+}
+
+// gatewayAPIRules returns the rules of a Gateway API route, split to have up to one match as on translation.
+func gatewayAPIRules(r *resource.Instance, port uint32) []routeRule {
+	var rules []routeRule
+	add := func(index int, m *networking.HTTPMatchRequest) {
+		if port != 0 {
+			if m == nil {
+				m = &networking.HTTPMatchRequest{}
+			}
+			m.Port = port
+		}
+		rule := routeRule{r: r, index: index, gatewayAPI: true}
+		if m != nil {
+			rule.matches = []*networking.HTTPMatchRequest{m}
+		}
+		rules = append(rules, rule)
+	}
+	switch spec := r.Message.(type) {
+	case *k8s.HTTPRouteSpec:
+		for i, rule := range spec.Rules {
+			if len(rule.Matches) == 0 {
+				add(i, nil)
+			}
+			for _, match := range rule.Matches {
+				m, err := gateway.ConvertHTTPRouteMatch(match)
+				if err != nil {
+					continue
+				}
+				add(i, m)
+			}
+		}
+	case *k8s.GRPCRouteSpec:
+		for i, rule := range spec.Rules {
+			if len(rule.Matches) == 0 {
+				add(i, nil)
+			}
+			for _, match := range rule.Matches {
+				m, err := gateway.ConvertGRPCRouteMatch(match)
+				if err != nil {
+					continue
+				}
+				add(i, m)
+			}
+		}
+	}
+	return rules
+}
+
+// listenerAccepts returns true if a route of the given kind and namespace attaches to a listener of a Gateway.
+func listenerAccepts(gw *resource.Instance, l k8s.Listener, ref k8s.ParentReference, kind config.GroupVersionKind, ns string,
+	namespaces map[string]map[string]string,
+) bool {
+	if ref.SectionName != nil && *ref.SectionName != l.Name {
+		return false
+	}
+	if ref.Port != nil && *ref.Port != l.Port {
+		return false
+	}
+	return gateway.RouteKindAllowed(l, kind) &&
+		gateway.RouteNamespaceAllowed(l.AllowedRoutes, gw.Metadata.FullName.Namespace.String(), ns, namespaces[ns])
+}
+
+// addVirtualServices adds the VirtualServices to the route table.
+func (a *RouteConflictAnalyzer) addVirtualServices(c analysis.Context, routes routeTable, listeners map[string]listener) {
+	c.ForEach(gvk.VirtualService, func(r *resource.Instance) bool {
+		vs := r.Message.(*networking.VirtualService)
+		ns := r.Metadata.FullName.Namespace
+		var exportTo sets.String
+		if !util.IsExportToAllNamespaces(vs.ExportTo) {
+			exportTo = sets.New[string]()
+			for _, e := range vs.ExportTo {
+				if e == string(visibility.Private) {
+					e = ns.String()
+				}
+				exportTo.Insert(e)
+			}
+		}
+		gateways := vs.Gateways
+		if len(gateways) == 0 {
+			gateways = []string{constants.IstioMeshGateway}
+		}
+		for _, gw := range gateways {
+			parent := gw
+			if gw != constants.IstioMeshGateway && !strings.Contains(gw, "/") {
+				parent = ns.String() + "/" + gw
+			}
+			var hosts []string
+			for _, h := range vs.Hosts {
+				if parent == constants.IstioMeshGateway {
+					hosts = append(hosts, util.ConvertHostToFQDN(ns, h))
+					continue
+				}
+				if l, ok := listeners[parent]; ok {
+					hosts = append(hosts, gateway.RouteHosts([]k8s.Hostname{k8s.Hostname(h)}, l.hostname)...)
+					continue
+				}
+				hosts = append(hosts, h)
+			}
+			rules := virtualServiceRules(r, vs, gw, parent)
+			for _, h := range hosts {
+				if len(rules) > 0 {
+					key := routeKey{parent: parent, host: h}
+					routes[key] = append(routes[key], &routeUnit{resources: []*resource.Instance{r}, exportTo: exportTo, rules: rules})
+				}
+				if hasTLSRoute(vs, gw, h) {
+					key := routeKey{parent: parent, host: h, tls: true}
+					routes[key] = append(routes[key], &routeUnit{resources: []*resource.Instance{r}, exportTo: exportTo, rules: []routeRule{{r: r}}})
+				}
+			}
+		}
+		return true
+	})
+}
+
+// virtualServiceRules returns the HTTP rules of a VirtualService applying to a gateway.
+func virtualServiceRules(r *resource.Instance, vs *networking.VirtualService, gw, parent string) []routeRule {
+	var rules []routeRule
+	for i, h := range vs.Http {
+		if h.Delegate != nil {
+			// The routes of delegates are not known.
+			continue
+		}
+		rule := routeRule{r: r, index: i}
+		for _, m := range h.Match {
+			if len(m.Gateways) == 0 || slices.Contains(m.Gateways, gw) || slices.Contains(m.Gateways, parent) {
+				rule.matches = append(rule.matches, m)
+			}
+		}
+		if len(h.Match) > 0 && len(rule.matches) == 0 {
+			// The rule does not apply to this gateway.
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func hasTLSRoute(vs *networking.VirtualService, gw, h string) bool {
+	for _, t := range vs.Tls {
+		for _, m := range t.Match {
+			if len(m.Gateways) > 0 && !slices.Contains(m.Gateways, gw) {
+				continue
+			}
+			for _, sni := range m.SniHosts {
+				if host.Name(h).SubsetOf(host.Name(sni)) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// analyzeGatewayRoutes reports the routes of a gateway host shadowed by the routes of other resources. The routes of
+// all the resources visible to the gateway are evaluated in order: first the VirtualServices exported to specific
+// namespaces, then the Gateway API routes of the namespace of the gateway, then the other routes, oldest first.
+func analyzeGatewayRoutes(c analysis.Context, key routeKey, parent string, units []*routeUnit) {
+	gwNamespace, _, _ := strings.Cut(key.parent, "/")
+	var visible []*routeUnit
+	for _, u := range units {
+		if u.exportTo == nil || u.exportTo.Contains(gwNamespace) || u.exportTo.Contains(string(visibility.Public)) {
+			visible = append(visible, u)
+		}
+	}
+	if len(visible) == 0 {
+		return
+	}
+	sortUnits(visible, func(u *routeUnit) int {
+		switch {
+		case u.exportTo != nil && !u.exportTo.Contains(string(visibility.Public)):
+			return 0
+		case u.gatewayAPI && unitNamespace(u) == gwNamespace:
+			return 1
+		default:
+			return 2
+		}
+	})
+
+	if key.tls {
+		// The first route for an SNI host applies; the others are ignored.
+		for _, u := range visible[1:] {
+			for _, r := range u.resources {
+				report(c, r, msg.NewShadowedRoute(r, key.host, parent, resourceName(visible[0].resources[0])))
+			}
+		}
+		return
+	}
+
+	var rules []routeRule
+	for _, u := range visible {
+		rules = append(rules, u.rules...)
+	}
+	// shadowed holds, for each resource, the winners of each of its rules shadowed by the rules of another resource.
+	shadowed := map[*resource.Instance]map[int]sets.String{}
+	// unshadowed holds the rules having at least a match which is not shadowed.
+	unshadowed := map[*resource.Instance]sets.Set[int]{}
+	for i, rule := range rules {
+		winners := sets.New[string]()
+		covered := true
+		matches := rule.matches
+		if matches == nil {
+			matches = []*networking.HTTPMatchRequest{nil}
+		}
+		for _, m := range matches {
+			winner := slices.FindFunc(rules[:i], func(prev routeRule) bool {
+				return prev.r != rule.r && slices.IndexFunc(ruleMatches(prev), func(pm *networking.HTTPMatchRequest) bool {
+					return covers(pm, m, prev.gatewayAPI)
+				}) >= 0
+			})
+			if winner == nil {
+				covered = false
+				break
+			}
+			winners.Insert(resourceName(winner.r))
+		}
+		if !covered {
+			if unshadowed[rule.r] == nil {
+				unshadowed[rule.r] = sets.New[int]()
+			}
+			unshadowed[rule.r].Insert(rule.index)
+			continue
+		}
+		if shadowed[rule.r] == nil {
+			shadowed[rule.r] = map[int]sets.String{}
+		}
+		if shadowed[rule.r][rule.index] == nil {
+			shadowed[rule.r][rule.index] = sets.New[string]()
+		}
+		shadowed[rule.r][rule.index].Merge(winners)
+	}
+
+	for _, u := range visible {
+		for _, r := range u.resources {
+			byRule := shadowed[r]
+			for i := range byRule {
+				// Gateway API rules are split by match, a rule is only shadowed if all of its matches are.
+				if unshadowed[r].Contains(i) {
+					delete(byRule, i)
+				}
+			}
+			if len(byRule) == 0 {
+				continue
+			}
+			winners := sets.New[string]()
+			for _, w := range byRule {
+				winners.Merge(w)
+			}
+			if len(unshadowed[r]) == 0 {
+				report(c, r, msg.NewShadowedRoute(r, key.host, parent, strings.Join(sets.SortedList(winners), ", ")))
+				continue
+			}
+			indexes := slices.Sort(maps.Keys(byRule))
+			report(c, r, msg.NewPartiallyOverriddenRoute(r, strings.Join(slices.Map(indexes, func(i int) string {
+				return "#" + strconv.Itoa(i)
+			}), ", "), key.host, parent, strings.Join(sets.SortedList(winners), ", ")))
+		}
+	}
+}
+
+// analyzeMeshRoutes reports the routes of a mesh host which never apply, and the routes applying depending on the
+// namespace of the client. Sidecars only apply the routes of the first resource visible in their namespace: the
+// VirtualServices exported to their namespace, then the Gateway API routes of their namespace, then the other
+// routes, oldest first.
+func analyzeMeshRoutes(c analysis.Context, key routeKey, parent string, units []*routeUnit) {
+	// Clients are grouped in the namespaces of the routes, and the other namespaces.
+	clients := sets.New[string]("")
+	for _, u := range units {
+		clients.Insert(unitNamespace(u))
+		clients.Merge(u.exportTo)
+	}
+	clients.Delete(string(visibility.Public))
+	winners := map[*routeUnit][]string{}
+	for _, ns := range sets.SortedList(clients) {
+		var visible []*routeUnit
+		for _, u := range units {
+			if u.exportTo == nil || u.exportTo.Contains(ns) || u.exportTo.Contains(string(visibility.Public)) {
+				visible = append(visible, u)
+			}
+		}
+		if len(visible) == 0 {
+			continue
+		}
+		sortUnits(visible, func(u *routeUnit) int {
+			switch {
+			case u.exportTo != nil && !u.exportTo.Contains(string(visibility.Public)):
+				return 0
+			case u.gatewayAPI && unitNamespace(u) == ns:
+				return 1
+			default:
+				return 2
+			}
+		})
+		winners[visible[0]] = append(winners[visible[0]], ns)
+	}
+
+	var outcome []string
+	for _, u := range units {
+		ns, ok := winners[u]
+		if !ok {
+			continue
+		}
+		// Namespaces are sorted, other namespaces ("") come first.
+		var clients []string
+		if named := slices.Filter(ns, func(n string) bool { return n != "" }); len(named) == 1 {
+			clients = append(clients, "clients in namespace "+named[0])
+		} else if len(named) > 1 {
+			clients = append(clients, "clients in namespaces "+strings.Join(named, ", "))
+		}
+		if ns[0] == "" && len(clients) == 0 {
+			clients = append(clients, "clients in other namespaces")
+		} else if ns[0] == "" {
+			clients = append(clients, "other namespaces")
+		}
+		outcome = append(outcome, fmt.Sprintf("%s for %s", unitName(u), strings.Join(clients, " and ")))
+	}
+	sort.Strings(outcome)
+
+	for _, u := range units {
+		if _, ok := winners[u]; ok {
+			if len(winners) > 1 {
+				for _, r := range u.resources {
+					report(c, r, msg.NewConflictingRoutesAcrossNamespaces(r, key.host, parent, strings.Join(outcome, "; ")))
+				}
+			}
+			continue
+		}
+		names := sets.New[string]()
+		for w := range winners {
+			names.Insert(unitName(w))
+		}
+		for _, r := range u.resources {
+			report(c, r, msg.NewShadowedRoute(r, key.host, parent, strings.Join(sets.SortedList(names), ", ")))
+		}
+	}
+}
+
+// covers returns true if all the requests matched by b are matched by a. A nil match matches all requests. Gateway
+// API path prefixes match whole path segments.
+func covers(a, b *networking.HTTPMatchRequest, gatewaySemantics bool) bool {
+	if a == nil {
+		return true
+	}
+	if b == nil {
+		b = &networking.HTTPMatchRequest{}
+	}
+	if a.Port != 0 && a.Port != b.Port {
+		return false
+	}
+	if a.SourceNamespace != "" && a.SourceNamespace != b.SourceNamespace {
+		return false
+	}
+	if !maps.Contains(b.SourceLabels, a.SourceLabels) || len(a.WithoutHeaders) > 0 {
+		return false
+	}
+	if a.IgnoreUriCase && !b.IgnoreUriCase && a.Uri != nil {
+		return false
+	}
+	if !stringMatchCovers(a.Uri, b.Uri, gatewaySemantics) || !stringMatchCovers(a.Method, b.Method, false) ||
+		!stringMatchCovers(a.Scheme, b.Scheme, false) || !stringMatchCovers(a.Authority, b.Authority, false) {
+		return false
+	}
+	for k, v := range a.Headers {
+		if !stringMatchCovers(v, b.Headers[k], false) {
+			return false
+		}
+	}
+	for k, v := range a.QueryParams {
+		if !stringMatchCovers(v, b.QueryParams[k], false) {
+			return false
+		}
+	}
+	return true
+}
+
+func stringMatchCovers(a, b *networking.StringMatch, pathSegments bool) bool {
+	if a == nil || a.MatchType == nil {
+		return true
+	}
+	if b == nil || b.MatchType == nil {
+		return false
+	}
+	switch {
+	case a.GetExact() != "":
+		return b.GetExact() == a.GetExact()
+	case a.GetPrefix() != "":
+		p := a.GetPrefix()
+		v := b.GetExact() + b.GetPrefix()
+		if !strings.HasPrefix(v, p) {
+			return false
+		}
+		return !pathSegments || len(v) == len(p) || strings.HasSuffix(p, "/") || v[len(p)] == '/'
+	case a.GetRegex() != "":
+		return b.GetRegex() == a.GetRegex()
+	}
+	return false
+}
+
+func ruleMatches(r routeRule) []*networking.HTTPMatchRequest {
+	if r.matches == nil {
+		return []*networking.HTTPMatchRequest{nil}
+	}
+	return r.matches
+}
+
+func internalName(gw *resource.Instance, l k8s.Listener) string {
+	return gw.Metadata.FullName.Namespace.String() + "/" + kubegateway.InternalGatewayName(gw.Metadata.FullName.Name.String(), string(l.Name))
+}
+
+func parentName(parent string, listeners map[string]listener) string {
+	if parent == constants.IstioMeshGateway {
+		return "the mesh"
+	}
+	if l, ok := listeners[parent]; ok {
+		return l.gateway
+	}
+	return "Gateway " + parent
+}
+
+// sortUnits sorts route units by bucket, then oldest first.
+func sortUnits(units []*routeUnit, bucket func(u *routeUnit) int) {
+	sort.SliceStable(units, func(i, j int) bool {
+		if bi, bj := bucket(units[i]), bucket(units[j]); bi != bj {
+			return bi < bj
+		}
+		return olderThan(units[i].resources[0], units[j].resources[0])
+	})
+}
+
+func olderThan(a, b *resource.Instance) bool {
+	if r := a.Metadata.CreateTime.Compare(b.Metadata.CreateTime); r != 0 {
+		return r < 0
+	}
+	if r := cmp.Compare(a.Metadata.FullName.Namespace, b.Metadata.FullName.Namespace); r != 0 {
+		return r < 0
+	}
+	return a.Metadata.FullName.Name < b.Metadata.FullName.Name
+}
+
+// unitNamespace returns the namespace of a unit. Merged Gateway API routes take the namespace of the oldest route.
+func unitNamespace(u *routeUnit) string {
+	return u.resources[0].Metadata.FullName.Namespace.String()
+}
+
+func unitName(u *routeUnit) string {
+	return strings.Join(slices.Map(u.resources, resourceName), ", ")
+}
+
+func resourceName(r *resource.Instance) string {
+	return r.Metadata.Schema.Kind() + " " + r.Metadata.FullName.String()
+}
+
+func report(c analysis.Context, r *resource.Instance, m diag.Message) {
+	if line, ok := util.ErrorLine(r, util.MetadataName); ok {
+		m.Line = line
+	}
+	c.Report(r.Metadata.Schema.GroupVersionKind(), m)
+}
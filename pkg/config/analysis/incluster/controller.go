@@ -17,21 +17,30 @@
 package incluster
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	"istio.io/istio/pilot/pkg/config/kube/crdclient"
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/status"
 	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/analysis"
 	"istio.io/istio/pkg/config/analysis/analyzers"
+	"istio.io/istio/pkg/config/analysis/analyzers/custom"
 	"istio.io/istio/pkg/config/analysis/diag"
 	"istio.io/istio/pkg/config/analysis/legacy/util/kuberesource"
 	"istio.io/istio/pkg/config/analysis/local"
 	"istio.io/istio/pkg/config/resource"
 	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/kube/watcher/configmapwatcher"
 	"istio.io/istio/pkg/log"
 	"istio.io/istio/pkg/util/concurrent"
 	"istio.io/istio/pkg/util/sets"
@@ -42,12 +51,44 @@ import (
 type Controller struct {
 	analyzer  *local.IstiodAnalyzer
 	statusctl *status.Controller
+	// customAnalyzers watches the ConfigMap of custom analyzers, if one is configured.
+	customAnalyzers *configmapwatcher.Controller
+
+	// mu serializes the analyses with the replacement of the analyzers when the custom analyzers change.
+	mu sync.Mutex
+	// analyzers holds the names of the analyzers run.
+	analyzers sets.String
+	// customData holds the custom analyzers of the ConfigMap the analyzers were built with.
+	customData string
+	// reanalyze is signaled when the analyzers are replaced.
+	reanalyze chan struct{}
 }
 
 func NewController(stop <-chan struct{}, rwConfigStore model.ConfigStoreController,
 	kubeClient kube.Client, revision, namespace string, statusManager *status.Manager, domainSuffix string,
 ) (*Controller, error) {
-	analyzer := analyzers.AllCombined()
+	c := &Controller{reanalyze: make(chan struct{}, 1)}
+	// The ConfigMap is read now, so the resources analyzed by its analyzers are watched. Later changes are
+	// watched, and the analyzers are rebuilt.
+	var cm *corev1.ConfigMap
+	if name := features.AnalysisCustomAnalyzersConfigMap; name != "" {
+		got, err := kubeClient.Kube().CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		switch {
+		case err == nil:
+			cm = got
+		case !kerrors.IsNotFound(err):
+			log.Errorf("Failed to read ConfigMap %s, only running the built-in analyzers until it changes: %v", name, err)
+		}
+		c.customAnalyzers = configmapwatcher.NewController(kubeClient, namespace, name, c.updateCustomAnalyzers)
+	}
+	customAnalyzers, err := loadCustomAnalyzers(cm)
+	if err != nil {
+		log.Errorf("Failed to load custom analyzers, only running the built-in analyzers: %v", err)
+	} else {
+		c.customData = customAnalyzersData(cm)
+	}
+	analyzer := combineAnalyzers(customAnalyzers)
+	c.analyzers = sets.New(analyzer.AnalyzerNames()...)
 	all := kuberesource.ConvertInputsToSchemas(analyzer.Metadata().Inputs)
 
 	ia := local.NewIstiodAnalyzer(analyzer, "", resource.Namespace(namespace), func(name config.GroupVersionKind) {})
@@ -65,7 +106,7 @@ func NewController(stop <-chan struct{}, rwConfigStore model.ConfigStoreControll
 
 	ia.AddSource(store)
 	kubeClient.RunAndWait(stop)
-	err := ia.Init(stop)
+	err = ia.Init(stop)
 	if err != nil {
 		return nil, fmt.Errorf("unable to initialize analysis controller, releasing lease: %s", err)
 	}
@@ -73,7 +114,88 @@ func NewController(stop <-chan struct{}, rwConfigStore model.ConfigStoreControll
 		msgs := context.(diag.Messages)
 		status.SetValidationMessages(msgs)
 	})
-	return &Controller{analyzer: ia, statusctl: ctl}, nil
+	c.analyzer = ia
+	c.statusctl = ctl
+	return c, nil
+}
+
+// combineAnalyzers combines the built-in analyzers with the custom analyzers.
+func combineAnalyzers(customAnalyzers []analysis.Analyzer) analysis.CombinedAnalyzer {
+	if len(customAnalyzers) == 0 {
+		return analyzers.AllCombined()
+	}
+	return analysis.Combine("all", append(analyzers.All(), customAnalyzers...)...)
+}
+
+func customAnalyzersData(cm *corev1.ConfigMap) string {
+	if cm == nil {
+		return ""
+	}
+	return cm.Data[custom.Key]
+}
+
+// updateCustomAnalyzers rebuilds the analyzers when the ConfigMap of custom analyzers changes. Invalid custom
+// analyzers are ignored, keeping the previous analyzers.
+func (c *Controller) updateCustomAnalyzers(cm *corev1.ConfigMap) {
+	data := customAnalyzersData(cm)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if data == c.customData {
+		return
+	}
+	customAnalyzers, err := loadCustomAnalyzers(cm)
+	if err != nil {
+		log.Errorf("Failed to load custom analyzers, keeping the previous analyzers: %v", err)
+		return
+	}
+	analyzer := combineAnalyzers(customAnalyzers)
+	watched := sets.New[config.GroupVersionKind]()
+	for _, s := range c.analyzer.Schemas().All() {
+		watched.Insert(s.GroupVersionKind())
+	}
+	for _, a := range customAnalyzers {
+		for _, in := range a.Metadata().Inputs {
+			if !watched.Contains(in) {
+				log.Warnf("Custom analyzer %s analyzes %v, which is only watched after istiod restarts", a.Metadata().Name, in)
+			}
+		}
+	}
+	c.analyzer.SetAnalyzer(analyzer)
+	c.analyzers = sets.New(analyzer.AnalyzerNames()...)
+	c.customData = data
+	log.Infof("Updated the custom analyzers: %d analyzers", len(customAnalyzers))
+	select {
+	case c.reanalyze <- struct{}{}:
+	default:
+	}
+}
+
+// loadCustomAnalyzers loads the custom analyzers of the files configured for istiod, and of the ConfigMap, if any.
+func loadCustomAnalyzers(cm *corev1.ConfigMap) ([]analysis.Analyzer, error) {
+	var out []analysis.Analyzer
+	if features.AnalysisCustomAnalyzers != "" {
+		loaded, err := custom.Load(strings.Split(features.AnalysisCustomAnalyzers, ",")...)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, loaded...)
+	}
+	if cm != nil {
+		parsed, err := custom.Parse([]byte(cm.Data[custom.Key]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid custom analyzers in ConfigMap %s: %v", cm.Name, err)
+		}
+		for _, a := range parsed {
+			out = append(out, a)
+		}
+	}
+	names := sets.New[string]()
+	for _, a := range out {
+		if names.InsertContains(a.Metadata().Name) {
+			return nil, fmt.Errorf("analyzer %s is defined more than once", a.Metadata().Name)
+		}
+	}
+	return out, nil
 }
 
 // Run is blocking
@@ -90,8 +212,26 @@ func (c *Controller) Run(stop <-chan struct{}) {
 			chKind <- gvk
 		})
 	}
+	if c.customAnalyzers != nil {
+		go c.customAnalyzers.Run(stop)
+	}
+	// Every resource is analyzed again when the analyzers are replaced.
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-c.reanalyze:
+				for _, k := range c.analyzer.Schemas().All() {
+					chKind <- k.GroupVersionKind()
+				}
+			}
+		}
+	}()
 	oldmsgs := map[string]diag.Messages{}
 	pushFn := func(combinedKinds sets.Set[config.GroupVersionKind]) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
 		res, err := c.analyzer.ReAnalyzeSubset(combinedKinds, stop)
 		if err != nil {
 			log.Errorf("In-cluster analysis has failed: %s", err)
@@ -103,6 +243,19 @@ func (c *Controller) Run(stop <-chan struct{}) {
 			key := status.ResourceFromMetadata(m.Resource.Metadata)
 			index[key] = append(index[key], m)
 		}
+		// the messages of the analyzers which were removed are cleared
+		for a, msgs := range oldmsgs {
+			if c.analyzers.Contains(a) {
+				continue
+			}
+			for _, m := range msgs {
+				key := status.ResourceFromMetadata(m.Resource.Metadata)
+				if _, ok := index[key]; !ok {
+					index[key] = diag.Messages{}
+				}
+			}
+			delete(oldmsgs, a)
+		}
 		// if we previously had a message that has been removed, ensure it is removed
 		// TODO: this creates a state destruction problem when istiod crashes
 		// in that old messages may not be removed.  Not sure how to fix this
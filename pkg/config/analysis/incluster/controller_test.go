@@ -0,0 +1,97 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package incluster
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/pkg/config/analysis/analyzers"
+	"istio.io/istio/pkg/config/analysis/analyzers/custom"
+	"istio.io/istio/pkg/config/analysis/local"
+	"istio.io/istio/pkg/test/util/assert"
+	"istio.io/istio/pkg/util/sets"
+)
+
+func customAnalyzersConfigMap(analyzers string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "custom-analyzers", Namespace: "istio-system"},
+		Data:       map[string]string{custom.Key: analyzers},
+	}
+}
+
+const gatewayListenerAnalyzer = `
+analyzers:
+- name: org.GatewayListenerAnalyzer
+  input: gateway.networking.k8s.io/Gateway
+  condition: resource.spec.listeners.size() > 0
+  message:
+    code: ORG0001
+    level: Error
+    template: Gateway has no listener
+`
+
+func TestLoadCustomAnalyzers(t *testing.T) {
+	loaded, err := loadCustomAnalyzers(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, len(loaded), 0)
+
+	loaded, err = loadCustomAnalyzers(customAnalyzersConfigMap(gatewayListenerAnalyzer))
+	assert.NoError(t, err)
+	assert.Equal(t, len(loaded), 1)
+	assert.Equal(t, loaded[0].Metadata().Name, "org.GatewayListenerAnalyzer")
+
+	_, err = loadCustomAnalyzers(customAnalyzersConfigMap("analyzers: invalid"))
+	assert.Error(t, err)
+}
+
+func TestUpdateCustomAnalyzers(t *testing.T) {
+	analyzer := combineAnalyzers(nil)
+	c := &Controller{
+		analyzer:  local.NewIstiodAnalyzer(analyzer, "", "istio-system", nil),
+		analyzers: sets.New(analyzer.AnalyzerNames()...),
+		reanalyze: make(chan struct{}, 1),
+	}
+	reanalyzed := func() bool {
+		select {
+		case <-c.reanalyze:
+			return true
+		default:
+			return false
+		}
+	}
+
+	// The analyzers are rebuilt when the custom analyzers change.
+	c.updateCustomAnalyzers(customAnalyzersConfigMap(gatewayListenerAnalyzer))
+	assert.Equal(t, c.analyzers.Contains("org.GatewayListenerAnalyzer"), true)
+	assert.Equal(t, reanalyzed(), true)
+
+	// Unchanged custom analyzers are not analyzed again.
+	c.updateCustomAnalyzers(customAnalyzersConfigMap(gatewayListenerAnalyzer))
+	assert.Equal(t, reanalyzed(), false)
+
+	// Invalid custom analyzers keep the previous analyzers.
+	c.updateCustomAnalyzers(customAnalyzersConfigMap("analyzers: invalid"))
+	assert.Equal(t, c.analyzers.Contains("org.GatewayListenerAnalyzer"), true)
+	assert.Equal(t, reanalyzed(), false)
+
+	// The custom analyzers are removed with the ConfigMap.
+	c.updateCustomAnalyzers(nil)
+	assert.Equal(t, c.analyzers.Contains("org.GatewayListenerAnalyzer"), false)
+	assert.Equal(t, c.analyzers.Len(), len(analyzers.All()))
+	assert.Equal(t, reanalyzed(), true)
+}
@@ -263,6 +263,12 @@ func (sa *IstiodAnalyzer) SetSuppressions(suppressions []AnalysisSuppression) {
 	sa.suppressions = suppressions
 }
 
+// SetAnalyzer replaces the analyzer run by the next analyses. The sources are unchanged, so the analyzers
+// requiring a collection they do not hold are skipped. It must not be called during an analysis.
+func (sa *IstiodAnalyzer) SetAnalyzer(analyzer analysis.CombinedAnalyzer) {
+	sa.analyzer = analyzer
+}
+
 // AddTestReaderKubeSource adds a yaml source to the analyzer, which will analyze
 // runtime resources like pods and namespaces for use in tests.
 func (sa *IstiodAnalyzer) AddTestReaderKubeSource(readers []ReaderSource) error {
@@ -267,6 +267,18 @@ var (
 	// UnknownDestinationRuleHost defines a diag.MessageType for message "UnknownDestinationRuleHost".
 	// Description: Host defined in destination rule does not match any services in the mesh.
 	UnknownDestinationRuleHost = diag.NewMessageType(diag.Warning, "IST0174", "The host %s defined in the DestinationRule does not match any services in the mesh.")
+
+	// ShadowedRoute defines a diag.MessageType for message "ShadowedRoute".
+	// Description: All the routes of a resource for a host are shadowed by the routes of another resource.
+	ShadowedRoute = diag.NewMessageType(diag.Warning, "IST0175", "The routes for host %s on %s never match: they are shadowed by %s, which takes precedence.")
+
+	// PartiallyOverriddenRoute defines a diag.MessageType for message "PartiallyOverriddenRoute".
+	// Description: Some of the routes of a resource for a host are shadowed by the routes of another resource.
+	PartiallyOverriddenRoute = diag.NewMessageType(diag.Warning, "IST0176", "The rules %s for host %s on %s never match: they are shadowed by %s, which takes precedence.")
+
+	// ConflictingRoutesAcrossNamespaces defines a diag.MessageType for message "ConflictingRoutesAcrossNamespaces".
+	// Description: Routes for the same host from different namespaces take precedence depending on the namespace of the client.
+	ConflictingRoutesAcrossNamespaces = diag.NewMessageType(diag.Warning, "IST0177", "The routes for host %s on %s are defined in multiple namespaces, and the routes in effect depend on the namespace of the client: %s.")
 )
 
 // All returns a list of all known message types.
@@ -337,6 +349,9 @@ func All() []*diag.MessageType {
 		NegativeConditionStatus,
 		DestinationRuleSubsetNotSelectPods,
 		UnknownDestinationRuleHost,
+		ShadowedRoute,
+		PartiallyOverriddenRoute,
+		ConflictingRoutesAcrossNamespaces,
 	}
 }
 
@@ -978,3 +993,37 @@ func NewUnknownDestinationRuleHost(r *resource.Instance, host string) diag.Messa
 		host,
 	)
 }
+
+// NewShadowedRoute returns a new diag.Message based on ShadowedRoute.
+func NewShadowedRoute(r *resource.Instance, host string, parent string, winner string) diag.Message {
+	return diag.NewMessage(
+		ShadowedRoute,
+		r,
+		host,
+		parent,
+		winner,
+	)
+}
+
+// NewPartiallyOverriddenRoute returns a new diag.Message based on PartiallyOverriddenRoute.
+func NewPartiallyOverriddenRoute(r *resource.Instance, rules string, host string, parent string, winners string) diag.Message {
+	return diag.NewMessage(
+		PartiallyOverriddenRoute,
+		r,
+		rules,
+		host,
+		parent,
+		winners,
+	)
+}
+
+// NewConflictingRoutesAcrossNamespaces returns a new diag.Message based on ConflictingRoutesAcrossNamespaces.
+func NewConflictingRoutesAcrossNamespaces(r *resource.Instance, host string, parent string, outcome string) diag.Message {
+	return diag.NewMessage(
+		ConflictingRoutesAcrossNamespaces,
+		r,
+		host,
+		parent,
+		outcome,
+	)
+}
@@ -0,0 +1,171 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package celeval evaluates CEL expressions against Istio resources. It is shared by the custom analyzers and the
+// validation policies, so that their expressions see resources the same way.
+package celeval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/util/sets"
+)
+
+// CostLimit bounds the cost of evaluating an expression against a resource, so that an expression iterating over
+// large lists fails instead of stalling the analysis or the admission of the resource.
+const CostLimit = 1_000_000
+
+var env = func() *cel.Env {
+	e, err := cel.NewEnv(
+		cel.Variable("resource", cel.MapType(cel.StringType, cel.DynType)),
+		cel.OptionalTypes(),
+		ext.Strings(),
+		ext.Lists(),
+		ext.Sets(),
+	)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}()
+
+// Compile compiles an expression on the resource variable. If boolean is true, the expression must evaluate to a bool.
+func Compile(expr string, boolean bool) (cel.Program, error) {
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if boolean && ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("expected a bool expression, got %s", ast.OutputType())
+	}
+	return env.Program(ast, cel.CostLimit(CostLimit))
+}
+
+// EvalBool evaluates a boolean expression.
+func EvalBool(p cel.Program, vars map[string]any) (bool, error) {
+	v, _, err := p.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(types.Bool)
+	if !ok {
+		return false, fmt.Errorf("expected a bool, got %s", v.Type().TypeName())
+	}
+	return bool(b), nil
+}
+
+// Activation returns the variables of the expressions for a resource: the resource as an object, with its
+// apiVersion, kind, metadata, spec and status, if set.
+func Activation(meta config.Meta, spec config.Spec, status config.Status) (map[string]any, error) {
+	obj := map[string]any{
+		"apiVersion": meta.GroupVersionKind.GroupVersion(),
+		"kind":       meta.GroupVersionKind.Kind,
+		"metadata": map[string]any{
+			"name":        meta.Name,
+			"namespace":   meta.Namespace,
+			"labels":      stringMap(meta.Labels),
+			"annotations": stringMap(meta.Annotations),
+			"generation":  meta.Generation,
+		},
+	}
+	var err error
+	if obj["spec"], err = toMap(spec); err != nil {
+		return nil, err
+	}
+	if status != nil {
+		if obj["status"], err = toMap(status); err != nil {
+			return nil, err
+		}
+	}
+	return map[string]any{"resource": obj}, nil
+}
+
+func toMap(s any) (map[string]any, error) {
+	out := map[string]any{}
+	if s == nil {
+		return out, nil
+	}
+	b, err := config.ToJSON(s)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func stringMap(m map[string]string) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+var fileExtensions = sets.New(".yaml", ".yml", ".json")
+
+// ReadFiles reads files, or the YAML and JSON files of directories, and calls read with the name and content of
+// each. A file holding a ConfigMap is read as the value of its key, so that the ConfigMaps deployed to the cluster
+// can be checked offline.
+func ReadFiles(paths []string, key string, read func(name string, data []byte) error) error {
+	for _, p := range paths {
+		files := []string{p}
+		if info, err := os.Stat(p); err != nil {
+			return err
+		} else if info.IsDir() {
+			entries, err := os.ReadDir(p)
+			if err != nil {
+				return err
+			}
+			files = nil
+			for _, e := range entries {
+				if !e.IsDir() && fileExtensions.Contains(filepath.Ext(e.Name())) {
+					files = append(files, filepath.Join(p, e.Name()))
+				}
+			}
+		}
+		for _, file := range files {
+			b, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			if err := read(file, configMapData(b, key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// configMapData returns the value of the key of a ConfigMap, or data if it is not a ConfigMap.
+func configMapData(data []byte, key string) []byte {
+	cm := struct {
+		Kind string            `json:"kind"`
+		Data map[string]string `json:"data"`
+	}{}
+	if err := yaml.Unmarshal(data, &cm); err == nil && cm.Kind == "ConfigMap" {
+		return []byte(cm.Data[key])
+	}
+	return data
+}
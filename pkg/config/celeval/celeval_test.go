@@ -0,0 +1,79 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package celeval
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	networking "istio.io/api/networking/v1"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestEvalBool(t *testing.T) {
+	hosts := make([]string, 1000)
+	for i := range hosts {
+		hosts[i] = "host"
+	}
+	vars, err := Activation(config.Meta{
+		GroupVersionKind: gvk.VirtualService,
+		Name:             "reviews",
+		Namespace:        "default",
+		Labels:           map[string]string{"app": "reviews"},
+	}, &networking.VirtualService{Hosts: hosts}, nil)
+	assert.NoError(t, err)
+
+	p, err := Compile(`resource.metadata.labels.app == "reviews" && resource.apiVersion == "networking.istio.io/v1"`, true)
+	assert.NoError(t, err)
+	ok, err := EvalBool(p, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, ok, true)
+
+	_, err = Compile(`"a" + "b"`, true)
+	assert.Error(t, err)
+
+	// Expressions exceeding the cost limit fail.
+	p, err = Compile(`resource.spec.hosts.all(a, resource.spec.hosts.all(b, a == b))`, true)
+	assert.NoError(t, err)
+	_, err = EvalBool(p, vars)
+	if err == nil || !strings.Contains(err.Error(), "cost limit") {
+		t.Fatalf("expected the cost limit to be exceeded, got %v", err)
+	}
+}
+
+func TestReadFiles(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "rules.yaml"), []byte("rules: []\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "configmap.yaml"), []byte(`
+kind: ConfigMap
+data:
+  rules: |
+    rules: [a]
+`), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not rules"), 0o644))
+	read := map[string]string{}
+	assert.NoError(t, ReadFiles([]string{dir}, "rules", func(name string, data []byte) error {
+		read[filepath.Base(name)] = string(data)
+		return nil
+	}))
+	assert.Equal(t, read, map[string]string{
+		"configmap.yaml": "rules: [a]\n",
+		"rules.yaml":     "rules: []\n",
+	})
+}
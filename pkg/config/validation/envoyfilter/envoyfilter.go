@@ -64,7 +64,7 @@ func validateEnvoyFilter(cfg config.Config, errs Validation) (Warning, error) {
 			validation.WrapWarning(fmt.Errorf("Envoy filter: %s, will be applied to all services in namespace", warning))) // nolint: stylecheck
 	}
 
-	for _, cp := range rule.ConfigPatches {
+	for i, cp := range rule.ConfigPatches {
 		if cp == nil {
 			errs = validation.AppendValidation(errs, fmt.Errorf("Envoy filter: null config patch")) // nolint: stylecheck
 			continue
@@ -162,7 +162,7 @@ func validateEnvoyFilter(cfg config.Config, errs Validation) (Warning, error) {
 			}
 		}
 		// ensure that the struct is valid
-		if _, err := xds.BuildXDSObjectFromStruct(cp.ApplyTo, cp.Patch.Value, false); err != nil {
+		if lenient, err := xds.BuildXDSObjectFromStruct(cp.ApplyTo, cp.Patch.Value, false); err != nil {
 			if strings.Contains(err.Error(), "could not resolve Any message type") {
 				if strings.Contains(err.Error(), ".v2.") {
 					err = fmt.Errorf("referenced type unknown (hint: try using the v3 XDS API): %v", err)
@@ -176,7 +176,12 @@ func validateEnvoyFilter(cfg config.Config, errs Validation) (Warning, error) {
 			// We do not want to reject in case the proto is valid but our libraries are outdated
 			obj, err := xds.BuildXDSObjectFromStruct(cp.ApplyTo, cp.Patch.Value, true)
 			if err != nil {
+				// Report the path of unknown fields, which the unmarshalling error lacks.
+				if unknown := validateUnknownFields(i, cp, lenient); unknown != nil {
+					err = unknown
+				}
 				errs = validation.AppendValidation(errs, validation.WrapWarning(err))
+				obj = lenient
 			}
 
 			// Append any deprecation notices
@@ -184,6 +189,9 @@ func validateEnvoyFilter(cfg config.Config, errs Validation) (Warning, error) {
 				// Note: since we no longer import v2 protos, v2 references will fail during BuildXDSObjectFromStruct.
 				errs = validation.AppendValidation(errs, validateDeprecatedFilterTypes(obj))
 				errs = validation.AppendValidation(errs, validateMissingTypedConfigFilterTypes(obj))
+				if err := validateFilterCategory(cp.ApplyTo, obj); err != nil {
+					errs = validation.AppendValidation(errs, validation.WrapWarning(err))
+				}
 			}
 		}
 	}
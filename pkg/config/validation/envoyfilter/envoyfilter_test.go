@@ -368,7 +368,8 @@ func TestValidateEnvoyFilter(t *testing.T) {
 					},
 				},
 			},
-		}, error: ""},
+		}, error: "", warning: "typed_config envoy.extensions.filters.http.ext_authz.v3.ExtAuthz is a http filter, " +
+			"which cannot be used with applyTo NETWORK_FILTER"},
 		{name: "deprecated config", in: &networking.EnvoyFilter{
 			ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
 				{
@@ -445,6 +446,41 @@ func TestValidateEnvoyFilter(t *testing.T) {
 				},
 			},
 		}, error: "", warning: ""},
+		{name: "unknown field", in: &networking.EnvoyFilter{
+			ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+				{
+					ApplyTo: networking.EnvoyFilter_HTTP_FILTER,
+					Patch: &networking.EnvoyFilter_Patch{
+						Operation: networking.EnvoyFilter_Patch_INSERT_FIRST,
+						Value: newStruct(map[string]any{
+							"name": "envoy.filters.http.ext_authz",
+							"typed_config": map[string]any{
+								"@type":        "type.googleapis.com/envoy.extensions.filters.http.ext_authz.v3.ExtAuthz",
+								"grpc_service": map[string]any{"envoy_grpc": map[string]any{"cluster_name": "authz", "clusterName2": "x"}},
+							},
+						}),
+					},
+				},
+			},
+		}, error: "", warning: "configPatches[0].patch.value.typed_config.grpc_service.envoy_grpc.clusterName2 " +
+			"(unknown field of envoy.config.core.v3.GrpcService.EnvoyGrpc)"},
+		{name: "filter of another kind", in: &networking.EnvoyFilter{
+			ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+				{
+					ApplyTo: networking.EnvoyFilter_HTTP_FILTER,
+					Patch: &networking.EnvoyFilter_Patch{
+						Operation: networking.EnvoyFilter_Patch_INSERT_FIRST,
+						Value: newStruct(map[string]any{
+							"name": "envoy.filters.network.ext_authz",
+							"typed_config": map[string]any{
+								"@type": "type.googleapis.com/envoy.extensions.filters.network.ext_authz.v3.ExtAuthz",
+							},
+						}),
+					},
+				},
+			},
+		}, error: "", warning: "typed_config envoy.extensions.filters.network.ext_authz.v3.ExtAuthz is a network filter, " +
+			"which cannot be used with applyTo HTTP_FILTER"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -476,3 +512,11 @@ func TestRecurseMissingTypedConfig(t *testing.T) {
 	assert.Equal(t, recurseMissingTypedConfig(ecds.ProtoReflect()), []string{}, "config discovery set")
 	assert.Equal(t, recurseMissingTypedConfig(bad.ProtoReflect()), []string{wellknown.TCPProxy}, "typed config not set")
 }
+
+func newStruct(m map[string]any) *structpb.Struct {
+	s, err := structpb.NewStruct(m)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
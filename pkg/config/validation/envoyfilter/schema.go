@@ -0,0 +1,179 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoyfilter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+// unknownField is a field of a patch value which is not a field of its message.
+type unknownField struct {
+	path    string
+	message protoreflect.FullName
+}
+
+// unknownFields returns the fields of a patch value, decoded as JSON, which are unknown to its message, with their
+// path. Any messages are resolved through their @type against the types linked into the binary; types which cannot
+// be resolved are skipped, as they are reported when the value is unmarshalled.
+func unknownFields(path string, value map[string]any, md protoreflect.MessageDescriptor) []unknownField {
+	if md.FullName() == anyFullName {
+		typeURL, _ := value["@type"].(string)
+		mt, err := protoregistry.GlobalTypes.FindMessageByURL(typeURL)
+		if err != nil {
+			return nil
+		}
+		md = mt.Descriptor()
+		if isWellKnown(md) {
+			// Well known types are embedded as a value field, with their own JSON mapping.
+			return nil
+		}
+		inner := make(map[string]any, len(value))
+		for k, v := range value {
+			if k != "@type" {
+				inner[k] = v
+			}
+		}
+		value = inner
+	} else if isWellKnown(md) {
+		return nil
+	}
+
+	keys := make([]string, 0, len(value))
+	for k := range value {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var out []unknownField
+	fields := md.Fields()
+	for _, k := range keys {
+		fieldPath := path + "." + k
+		fd := fields.ByJSONName(k)
+		if fd == nil {
+			fd = fields.ByTextName(k)
+		}
+		if fd == nil {
+			out = append(out, unknownField{path: fieldPath, message: md.FullName()})
+			continue
+		}
+		out = append(out, unknownFieldsOf(fieldPath, fd, value[k])...)
+	}
+	return out
+}
+
+func unknownFieldsOf(path string, fd protoreflect.FieldDescriptor, value any) []unknownField {
+	switch {
+	case fd.IsMap():
+		entries, _ := value.(map[string]any)
+		if fd.MapValue().Message() == nil {
+			return nil
+		}
+		keys := make([]string, 0, len(entries))
+		for k := range entries {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var out []unknownField
+		for _, k := range keys {
+			if m, ok := entries[k].(map[string]any); ok {
+				out = append(out, unknownFields(fmt.Sprintf("%s[%s]", path, k), m, fd.MapValue().Message())...)
+			}
+		}
+		return out
+	case fd.Message() == nil:
+		return nil
+	case fd.IsList():
+		items, _ := value.([]any)
+		var out []unknownField
+		for i, item := range items {
+			if m, ok := item.(map[string]any); ok {
+				out = append(out, unknownFields(fmt.Sprintf("%s[%d]", path, i), m, fd.Message())...)
+			}
+		}
+		return out
+	default:
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		return unknownFields(path, m, fd.Message())
+	}
+}
+
+var anyFullName = (&anypb.Any{}).ProtoReflect().Descriptor().FullName()
+
+// isWellKnown returns true for the well known types with a special JSON mapping, such as Struct or Duration.
+func isWellKnown(md protoreflect.MessageDescriptor) bool {
+	return md.ParentFile().Package() == "google.protobuf"
+}
+
+// validateUnknownFields reports the unknown fields of a patch value, which are ignored by istiod.
+func validateUnknownFields(index int, cp *networking.EnvoyFilter_EnvoyConfigObjectPatch, obj proto.Message) error {
+	fields := unknownFields(fmt.Sprintf("configPatches[%d].patch.value", index), cp.Patch.Value.AsMap(), obj.ProtoReflect().Descriptor())
+	if len(fields) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(fields))
+	for _, f := range fields {
+		msgs = append(msgs, fmt.Sprintf("%s (unknown field of %s)", f.path, f.message))
+	}
+	return fmt.Errorf("Envoy filter: unknown fields: %s", strings.Join(msgs, ", ")) // nolint: stylecheck
+}
+
+// filterCategories are the categories of the Envoy filter extensions each filter patch accepts.
+var filterCategories = map[networking.EnvoyFilter_ApplyTo][]string{
+	networking.EnvoyFilter_HTTP_FILTER:     {"http"},
+	networking.EnvoyFilter_NETWORK_FILTER:  {"network"},
+	networking.EnvoyFilter_LISTENER_FILTER: {"listener", "udp"},
+}
+
+// validateFilterCategory checks that the typed_config of a filter patch is a filter of the kind the patch applies to,
+// for example that an HTTP filter is not configured with a network filter.
+func validateFilterCategory(applyTo networking.EnvoyFilter_ApplyTo, obj proto.Message) error {
+	categories, f := filterCategories[applyTo]
+	if !f || obj == nil {
+		return nil
+	}
+	m := obj.ProtoReflect()
+	fd := m.Descriptor().Fields().ByName("typed_config")
+	if fd == nil || !m.Has(fd) {
+		return nil
+	}
+	a, ok := m.Get(fd).Message().Interface().(*anypb.Any)
+	if !ok {
+		return nil
+	}
+	name := string(a.MessageName())
+	rest, found := strings.CutPrefix(name, "envoy.extensions.filters.")
+	if !found {
+		// Not a filter of Envoy, such as a TypedStruct or a Wasm filter.
+		return nil
+	}
+	category, _, _ := strings.Cut(rest, ".")
+	for _, c := range categories {
+		if c == category {
+			return nil
+		}
+	}
+	return fmt.Errorf("Envoy filter: typed_config %s is a %s filter, which cannot be used with applyTo %v", name, category, applyTo) // nolint: stylecheck
+}
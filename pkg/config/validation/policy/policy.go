@@ -0,0 +1,239 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package policy implements organization policies on Istio resources, declared as rules with CEL expressions. They
+// are enforced by the validation webhook of istiod, and checked offline by istioctl validate. For example:
+//
+//	rules:
+//	- name: envoyfilter-namespace
+//	  kinds: [EnvoyFilter]
+//	  condition: resource.metadata.namespace == "istio-system"
+//	  message: EnvoyFilters are only allowed in istio-system
+//	- name: no-global-export
+//	  kinds: [VirtualService, DestinationRule, ServiceEntry]
+//	  namespaces: [prod]
+//	  condition: '!has(resource.spec.exportTo) || !("*" in resource.spec.exportTo)'
+//	  message: resources of the prod namespace must not be exported to all namespaces
+//	- name: strict-mtls
+//	  kinds: [PeerAuthentication]
+//	  condition: has(resource.spec.mtls) && resource.spec.mtls.mode == "STRICT"
+//	  action: Warn
+//	  message: peer authentication should require mutual TLS
+//
+// A rule applies to the resources of its kinds and namespaces, if set, matching its match expression, if set. These
+// resources violate the rule if its condition is false, and are denied, or admitted with a warning if the action of
+// the rule is Warn.
+package policy
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	multierror "github.com/hashicorp/go-multierror"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/celeval"
+	"istio.io/istio/pkg/config/validation"
+	"istio.io/istio/pkg/util/sets"
+)
+
+// Key is the key of the rules in a policy ConfigMap.
+const Key = "policies"
+
+// Action is the action taken on the resources violating a rule.
+type Action string
+
+const (
+	// Deny rejects the resources violating the rule.
+	Deny Action = "Deny"
+	// Warn admits the resources violating the rule, with a warning.
+	Warn Action = "Warn"
+)
+
+// File is the format of a file of rules.
+type File struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Rule declares a policy rule.
+type Rule struct {
+	// Name of the rule, reported with its violations.
+	Name string `json:"name"`
+	// Description of the rule.
+	Description string `json:"description,omitempty"`
+	// Kinds the rule applies to, such as EnvoyFilter, optionally qualified by their group, such as
+	// networking.istio.io/Gateway. The rule applies to all kinds if it is empty.
+	Kinds []string `json:"kinds,omitempty"`
+	// Namespaces the rule applies to. The rule applies to all namespaces if it is empty.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Match is an optional CEL expression selecting the resources the rule applies to.
+	Match string `json:"match,omitempty"`
+	// Condition is a CEL expression the resources the rule applies to must satisfy.
+	Condition string `json:"condition"`
+	// Action is Deny, the default, or Warn.
+	Action Action `json:"action,omitempty"`
+	// Message describes a violation of the rule.
+	Message string `json:"message"`
+}
+
+type rule struct {
+	Rule
+	kinds      sets.String
+	namespaces sets.String
+	match      cel.Program
+	condition  cel.Program
+}
+
+// Policy is a set of compiled rules. A nil policy has no rules.
+type Policy struct {
+	rules []*rule
+}
+
+// New compiles rules into a policy. Rule names must be unique.
+func New(rules ...Rule) (*Policy, error) {
+	p := &Policy{}
+	names := sets.New[string]()
+	for _, r := range rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("rule name is required")
+		}
+		if names.InsertContains(r.Name) {
+			return nil, fmt.Errorf("rule %s is defined more than once", r.Name)
+		}
+		fail := func(format string, args ...any) (*Policy, error) {
+			return nil, fmt.Errorf("rule %s: %s", r.Name, fmt.Sprintf(format, args...))
+		}
+		switch r.Action {
+		case "":
+			r.Action = Deny
+		case Deny, Warn:
+		default:
+			return fail("invalid action %q, expected %s or %s", r.Action, Deny, Warn)
+		}
+		if r.Condition == "" {
+			return fail("condition is required")
+		}
+		if r.Message == "" {
+			return fail("message is required")
+		}
+		c := &rule{Rule: r, kinds: sets.New(r.Kinds...), namespaces: sets.New(r.Namespaces...)}
+		var err error
+		if c.condition, err = celeval.Compile(r.Condition, true); err != nil {
+			return fail("invalid condition: %v", err)
+		}
+		if r.Match != "" {
+			if c.match, err = celeval.Compile(r.Match, true); err != nil {
+				return fail("invalid match: %v", err)
+			}
+		}
+		p.rules = append(p.rules, c)
+	}
+	return p, nil
+}
+
+// Parse parses a file of rules.
+func Parse(data []byte) (*Policy, error) {
+	f := File{}
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		return nil, err
+	}
+	return New(f.Rules...)
+}
+
+// Load loads the rules of files, or of the YAML and JSON files of directories. The files may be policy ConfigMaps,
+// as enforced by istiod.
+func Load(paths ...string) (*Policy, error) {
+	var rules []Rule
+	err := celeval.ReadFiles(paths, Key, func(name string, data []byte) error {
+		f := File{}
+		if err := yaml.UnmarshalStrict(data, &f); err != nil {
+			return fmt.Errorf("invalid policy rules in %s: %v", name, err)
+		}
+		rules = append(rules, f.Rules...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return New(rules...)
+}
+
+// Rules returns the number of rules of the policy.
+func (p *Policy) Rules() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.rules)
+}
+
+// Evaluate checks a resource against the rules of the policy. It returns the violations of Deny rules, and the Deny
+// rules which could not be evaluated, as an error, and the violations of Warn rules, and the Warn rules which could
+// not be evaluated, as a warning. The evaluation of each expression is bounded by celeval.CostLimit.
+func (p *Policy) Evaluate(cfg config.Config) (validation.Warning, error) {
+	if p == nil || len(p.rules) == 0 {
+		return nil, nil
+	}
+	var warnings, errs error
+	var vars map[string]any
+	for _, r := range p.rules {
+		if !r.appliesTo(cfg) {
+			continue
+		}
+		if vars == nil {
+			var err error
+			if vars, err = celeval.Activation(cfg.Meta, cfg.Spec, nil); err != nil {
+				return nil, fmt.Errorf("cannot evaluate policy: %v", err)
+			}
+		}
+		// A rule which cannot be evaluated fails closed: the resource is denied, unless the action of the rule is Warn.
+		report := func(err error) {
+			if r.Action == Warn {
+				warnings = multierror.Append(warnings, err)
+			} else {
+				errs = multierror.Append(errs, err)
+			}
+		}
+		if r.match != nil {
+			matched, err := celeval.EvalBool(r.match, vars)
+			if err != nil {
+				report(fmt.Errorf("policy rule %s: cannot evaluate match: %v", r.Name, err))
+				continue
+			}
+			if !matched {
+				continue
+			}
+		}
+		ok, err := celeval.EvalBool(r.condition, vars)
+		if err != nil {
+			report(fmt.Errorf("policy rule %s: cannot evaluate condition: %v", r.Name, err))
+			continue
+		}
+		if !ok {
+			report(fmt.Errorf("policy rule %s: %s", r.Name, r.Message))
+		}
+	}
+	return warnings, errs
+}
+
+func (r *rule) appliesTo(cfg config.Config) bool {
+	if len(r.namespaces) > 0 && !r.namespaces.Contains(cfg.Namespace) {
+		return false
+	}
+	if len(r.kinds) == 0 {
+		return true
+	}
+	kind := cfg.GroupVersionKind
+	return r.kinds.Contains(kind.Kind) || r.kinds.Contains(kind.Group+"/"+kind.Kind)
+}
@@ -0,0 +1,286 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	multierror "github.com/hashicorp/go-multierror"
+
+	networking "istio.io/api/networking/v1alpha3"
+	securityv1beta1 "istio.io/api/security/v1beta1"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+const rules = `
+rules:
+- name: envoyfilter-namespace
+  kinds: [EnvoyFilter]
+  condition: resource.metadata.namespace == "istio-system"
+  message: EnvoyFilters are only allowed in istio-system
+- name: no-global-export
+  kinds: [VirtualService, networking.istio.io/DestinationRule]
+  namespaces: [prod]
+  condition: '!has(resource.spec.exportTo) || !("*" in resource.spec.exportTo)'
+  message: resources of the prod namespace must not be exported to all namespaces
+- name: strict-mtls
+  kinds: [PeerAuthentication]
+  match: '!has(resource.metadata.labels.legacy)'
+  condition: has(resource.spec.mtls) && resource.spec.mtls.mode == "STRICT"
+  action: Warn
+  message: peer authentication should require mutual TLS
+`
+
+func errorList(err error) []string {
+	if err == nil {
+		return nil
+	}
+	var out []string
+	for _, e := range err.(*multierror.Error).Errors {
+		out = append(out, e.Error())
+	}
+	return out
+}
+
+func TestEvaluate(t *testing.T) {
+	p, err := Parse([]byte(rules))
+	assert.NoError(t, err)
+	assert.Equal(t, p.Rules(), 3)
+
+	cases := []struct {
+		name     string
+		cfg      config.Config
+		errors   []string
+		warnings []string
+	}{
+		{
+			name: "envoyfilter in istio-system",
+			cfg: config.Config{
+				Meta: config.Meta{GroupVersionKind: gvk.EnvoyFilter, Name: "ef", Namespace: "istio-system"},
+				Spec: &networking.EnvoyFilter{},
+			},
+		},
+		{
+			name: "envoyfilter outside istio-system",
+			cfg: config.Config{
+				Meta: config.Meta{GroupVersionKind: gvk.EnvoyFilter, Name: "ef", Namespace: "default"},
+				Spec: &networking.EnvoyFilter{},
+			},
+			errors: []string{"policy rule envoyfilter-namespace: EnvoyFilters are only allowed in istio-system"},
+		},
+		{
+			name: "exported to all namespaces from prod",
+			cfg: config.Config{
+				Meta: config.Meta{GroupVersionKind: gvk.DestinationRule, Name: "dr", Namespace: "prod"},
+				Spec: &networking.DestinationRule{Host: "reviews", ExportTo: []string{"*"}},
+			},
+			errors: []string{"policy rule no-global-export: resources of the prod namespace must not be exported to all namespaces"},
+		},
+		{
+			name: "exported to all namespaces from another namespace",
+			cfg: config.Config{
+				Meta: config.Meta{GroupVersionKind: gvk.VirtualService, Name: "vs", Namespace: "dev"},
+				Spec: &networking.VirtualService{Hosts: []string{"reviews"}, ExportTo: []string{"*"}},
+			},
+		},
+		{
+			name: "exported to its namespace from prod",
+			cfg: config.Config{
+				Meta: config.Meta{GroupVersionKind: gvk.VirtualService, Name: "vs", Namespace: "prod"},
+				Spec: &networking.VirtualService{Hosts: []string{"reviews"}, ExportTo: []string{"."}},
+			},
+		},
+		{
+			name: "permissive mtls",
+			cfg: config.Config{
+				Meta: config.Meta{GroupVersionKind: gvk.PeerAuthentication, Name: "pa", Namespace: "prod"},
+				Spec: &securityv1beta1.PeerAuthentication{
+					Mtls: &securityv1beta1.PeerAuthentication_MutualTLS{Mode: securityv1beta1.PeerAuthentication_MutualTLS_PERMISSIVE},
+				},
+			},
+			warnings: []string{"policy rule strict-mtls: peer authentication should require mutual TLS"},
+		},
+		{
+			name: "permissive mtls not matched",
+			cfg: config.Config{
+				Meta: config.Meta{
+					GroupVersionKind: gvk.PeerAuthentication,
+					Name:             "pa",
+					Namespace:        "prod",
+					Labels:           map[string]string{"legacy": "true"},
+				},
+				Spec: &securityv1beta1.PeerAuthentication{},
+			},
+		},
+		{
+			name: "strict mtls",
+			cfg: config.Config{
+				Meta: config.Meta{GroupVersionKind: gvk.PeerAuthentication, Name: "pa", Namespace: "prod"},
+				Spec: &securityv1beta1.PeerAuthentication{
+					Mtls: &securityv1beta1.PeerAuthentication_MutualTLS{Mode: securityv1beta1.PeerAuthentication_MutualTLS_STRICT},
+				},
+			},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := p.Evaluate(tt.cfg)
+			assert.Equal(t, errorList(err), tt.errors)
+			assert.Equal(t, errorList(warnings), tt.warnings)
+		})
+	}
+}
+
+func TestEvaluateError(t *testing.T) {
+	vs := config.Config{
+		Meta: config.Meta{GroupVersionKind: gvk.VirtualService, Name: "vs", Namespace: "default"},
+		Spec: &networking.VirtualService{},
+	}
+	p, err := New(Rule{
+		Name:      "missing-field",
+		Condition: `resource.spec.host == "reviews"`,
+		Message:   "only reviews",
+	})
+	assert.NoError(t, err)
+	// Deny rules which cannot be evaluated, such as a rule referencing a missing field, deny the resource.
+	warnings, err := p.Evaluate(vs)
+	assert.Equal(t, errorList(err), []string{"policy rule missing-field: cannot evaluate condition: no such key: host"})
+	assert.Equal(t, warnings, nil)
+
+	p, err = New(Rule{
+		Name:      "missing-field",
+		Match:     `resource.spec.host == "reviews"`,
+		Condition: "true",
+		Message:   "only reviews",
+	})
+	assert.NoError(t, err)
+	warnings, err = p.Evaluate(vs)
+	assert.Equal(t, errorList(err), []string{"policy rule missing-field: cannot evaluate match: no such key: host"})
+	assert.Equal(t, warnings, nil)
+
+	// Warn rules which cannot be evaluated only warn.
+	p, err = New(Rule{
+		Name:      "missing-field",
+		Condition: `resource.spec.host == "reviews"`,
+		Action:    Warn,
+		Message:   "only reviews",
+	})
+	assert.NoError(t, err)
+	warnings, err = p.Evaluate(vs)
+	assert.NoError(t, err)
+	assert.Equal(t, errorList(warnings), []string{"policy rule missing-field: cannot evaluate condition: no such key: host"})
+
+	// Expressions exceeding the cost limit cannot be evaluated.
+	hosts := make([]string, 1000)
+	for i := range hosts {
+		hosts[i] = "host"
+	}
+	p, err = New(Rule{
+		Name:      "expensive",
+		Condition: "resource.spec.hosts.all(a, resource.spec.hosts.all(b, a == b))",
+		Message:   "same hosts",
+	})
+	assert.NoError(t, err)
+	warnings, err = p.Evaluate(config.Config{
+		Meta: vs.Meta,
+		Spec: &networking.VirtualService{Hosts: hosts},
+	})
+	assert.Equal(t, warnings, nil)
+	if err == nil || !strings.Contains(err.Error(), "cost limit") {
+		t.Fatalf("expected the cost limit to be exceeded, got %v", err)
+	}
+
+	// A nil policy has no rules.
+	var empty *Policy
+	warnings, err = empty.Evaluate(config.Config{})
+	assert.NoError(t, err)
+	assert.Equal(t, warnings, nil)
+}
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		name string
+		rule Rule
+		err  string
+	}{
+		{
+			name: "missing name",
+			rule: Rule{Condition: "true", Message: "m"},
+			err:  "rule name is required",
+		},
+		{
+			name: "missing condition",
+			rule: Rule{Name: "r", Message: "m"},
+			err:  "rule r: condition is required",
+		},
+		{
+			name: "missing message",
+			rule: Rule{Name: "r", Condition: "true"},
+			err:  "rule r: message is required",
+		},
+		{
+			name: "invalid action",
+			rule: Rule{Name: "r", Condition: "true", Message: "m", Action: "Audit"},
+			err:  `rule r: invalid action "Audit", expected Deny or Warn`,
+		},
+		{
+			name: "not a bool",
+			rule: Rule{Name: "r", Condition: "1 + 1", Message: "m"},
+			err:  "rule r: invalid condition: expected a bool expression, got int",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.rule)
+			assert.Error(t, err)
+			assert.Equal(t, err.Error(), tt.err)
+		})
+	}
+
+	_, err := New(Rule{Name: "r", Condition: "true", Message: "m"}, Rule{Name: "r", Condition: "false", Message: "m"})
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), "rule r is defined more than once")
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "rules.yaml"), []byte(rules), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "configmap.yaml"), []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: istio-validation-policy
+data:
+  policies: |
+    rules:
+    - name: team-label
+      condition: '"team" in resource.metadata.labels'
+      action: Warn
+      message: resources should have a team label
+`), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not rules"), 0o644))
+
+	p, err := Load(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, p.Rules(), 4)
+
+	_, err = Load(filepath.Join(dir, "rules.yaml"), filepath.Join(dir, "rules.yaml"))
+	assert.Error(t, err)
+}
@@ -48,6 +48,7 @@ import (
 	"istio.io/istio/pkg/util/protomarshal"
 	"istio.io/istio/pkg/wasm"
 	"istio.io/istio/security/pkg/nodeagent/cache"
+	"istio.io/istio/security/pkg/nodeagent/spiffeapi"
 )
 
 const (
@@ -136,6 +137,10 @@ type Agent struct {
 
 	sdsServer   SDSService
 	secretCache *cache.SecretManagerClient
+	caClient    security.Client
+
+	// SPIFFE Workload API server, if enabled.
+	workloadAPI *spiffeapi.Server
 
 	// Used when proxying envoy xds via istio-agent is enabled.
 	xdsProxy    *XdsProxy
@@ -191,6 +196,9 @@ type AgentOptions struct {
 	// Ability to retrieve ProxyConfig dynamically through XDS
 	EnableDynamicProxyConfig bool
 
+	// Ability to retrieve the certificate revocation lists of the istiod CA through XDS
+	EnableCRL bool
+
 	// All of the proxy's IP Addresses
 	ProxyIPAddresses []string
 
@@ -231,6 +239,10 @@ type AgentOptions struct {
 	// Note that the path is not configurable by design - only the socket file name.
 	WorkloadIdentitySocketFile string
 
+	// SpiffeWorkloadAPISocketPath, if set, is the path of the Unix domain socket the agent serves the
+	// SPIFFE Workload API on, for applications that need their own SVIDs.
+	SpiffeWorkloadAPISocketPath string
+
 	EnvoySkipDeprecatedLogs bool
 }
 
@@ -463,28 +475,64 @@ func (a *Agent) initSdsServer() error {
 		return fmt.Errorf("failed to start workload secret manager %v", err)
 	}
 
+	if err := a.initWorkloadAPIServer(); err != nil {
+		return err
+	}
+
+	var handler func(resourceName string)
 	if a.cfg.DisableEnvoy {
 		// For proxyless we don't need an SDS server, but still need the keys and
 		// we need them refreshed periodically.
 		//
 		// This is based on the code from newSDSService, but customized to have explicit rotation.
+		st := a.secretCache
+		handler = func(resourceName string) {
+			// The secret handler is called when a secret should be renewed, after invalidating the cache.
+			// The handler does not call GenerateSecret - it is a side-effect of the SDS generate() method, which
+			// is called by sdsServer.OnSecretUpdate, which triggers a push and eventually calls sdsservice.Generate
+			// TODO: extract the logic to detect expiration time, and use a simpler code to rotate to files.
+			_, _ = a.getWorkloadCerts(st)
+		}
 		go func() {
-			st := a.secretCache
-			st.RegisterSecretHandler(func(resourceName string) {
-				// The secret handler is called when a secret should be renewed, after invalidating the cache.
-				// The handler does not call GenerateSecret - it is a side-effect of the SDS generate() method, which
-				// is called by sdsServer.OnSecretUpdate, which triggers a push and eventually calls sdsservice.Generate
-				// TODO: extract the logic to detect expiration time, and use a simpler code to rotate to files.
-				_, _ = a.getWorkloadCerts(st)
-			})
 			_, _ = a.getWorkloadCerts(st)
 		}()
 	} else {
 		pkpConf := a.proxyConfig.GetPrivateKeyProvider()
 		a.sdsServer = a.cfg.SDSFactory(a.secOpts, a.secretCache, pkpConf)
-		a.secretCache.RegisterSecretHandler(a.sdsServer.OnSecretUpdate)
+		handler = a.sdsServer.OnSecretUpdate
+	}
+	if a.workloadAPI != nil {
+		h := handler
+		handler = func(resourceName string) {
+			h(resourceName)
+			a.workloadAPI.OnSecretUpdate(resourceName)
+		}
 	}
+	a.secretCache.RegisterSecretHandler(handler)
+
+	return nil
+}
 
+// initWorkloadAPIServer starts the SPIFFE Workload API server, if enabled. JWT-SVIDs are minted by the CA,
+// when the CA client supports it.
+func (a *Agent) initWorkloadAPIServer() error {
+	path := a.cfg.SpiffeWorkloadAPISocketPath
+	if path == "" {
+		return nil
+	}
+	if a.secOpts.ServeOnlyFiles {
+		log.Warnf("SPIFFE Workload API is not served, as workload certificates are provided by an external SDS server")
+		return nil
+	}
+	var jwtSource spiffeapi.JWTSource
+	if js, ok := a.caClient.(spiffeapi.JWTSource); ok {
+		jwtSource = js
+	}
+	var err error
+	a.workloadAPI, err = spiffeapi.NewServer(path, a.secretCache, jwtSource)
+	if err != nil {
+		return fmt.Errorf("failed to start SPIFFE Workload API server: %v", err)
+	}
 	return nil
 }
 
@@ -594,6 +642,23 @@ func (a *Agent) isDNSServerEnabled() bool {
 	return (a.cfg.DNSCapture && a.cfg.ProxyType == model.SidecarProxy) || a.cfg.DNSAtGateway
 }
 
+// GetCertificates summarizes the workload certificate and trust bundle served over SDS, used in debugging interface.
+func (a *Agent) GetCertificates() *security.AgentCertificates {
+	if a.secretCache == nil {
+		return nil
+	}
+	chain, roots := a.secretCache.CachedCertificates()
+	out := &security.AgentCertificates{RootFingerprints: security.RootFingerprints(roots)}
+	if len(chain) > 0 {
+		info, err := security.SummarizeCertificate(chain, roots)
+		if err != nil {
+			log.Warnf("failed to summarize workload certificate: %v", err)
+		}
+		out.Workload = info
+	}
+	return out
+}
+
 // GetDNSTable builds DNS table used in debugging interface.
 func (a *Agent) GetDNSTable() *dnsProto.NameTable {
 	if a.localDNSServer != nil && a.localDNSServer.NameTable() != nil {
@@ -631,9 +696,13 @@ func (a *Agent) Close() {
 	if a.sdsServer != nil {
 		a.sdsServer.Stop()
 	}
+	a.workloadAPI.Stop()
 	if a.secretCache != nil {
 		a.secretCache.Close()
 	}
+	if a.secOpts != nil && a.secOpts.KeyProvider != nil {
+		a.secOpts.KeyProvider.Close()
+	}
 	if a.fileWatcher != nil {
 		_ = a.fileWatcher.Close()
 	}
@@ -814,6 +883,7 @@ func (a *Agent) newSecretManager(createCaClient bool) (*cache.SecretManagerClien
 	if err != nil {
 		return nil, err
 	}
+	a.caClient = caClient
 	return cache.NewSecretManagerClient(caClient, a.secOpts)
 }
 
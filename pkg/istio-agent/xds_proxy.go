@@ -30,6 +30,7 @@ import (
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
 	anypb "google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	meshconfig "istio.io/api/mesh/v1alpha1"
 	"istio.io/istio/pilot/cmd/pilot-agent/status/ready"
@@ -175,6 +176,16 @@ func initXdsProxy(ia *Agent) (*XdsProxy, error) {
 			return ia.secretCache.UpdateConfigTrustBundle(trustBundle)
 		}
 	}
+	if ia.cfg.EnableCRL && ia.secretCache != nil {
+		proxy.handlers[model.CRLType] = func(resp *anypb.Any) error {
+			crl := &wrapperspb.BytesValue{}
+			if err := resp.UnmarshalTo(crl); err != nil {
+				log.Errorf("failed to unmarshal certificate revocation list: %v", err)
+				return err
+			}
+			return ia.secretCache.UpdateCRL(crl.GetValue())
+		}
+	}
 
 	proxyLog.Infof("Initializing with upstream address %q and cluster %q", proxy.istiodAddress, proxy.clusterID)
 
@@ -430,6 +441,12 @@ func (p *XdsProxy) handleUpstreamRequest(con *ProxyConnection) {
 						TypeUrl: model.ProxyConfigType,
 					})
 				}
+				// fire off an initial CRL request
+				if _, f := p.handlers[model.CRLType]; f {
+					con.sendRequest(&discovery.DiscoveryRequest{
+						TypeUrl: model.CRLType,
+					})
+				}
 				// set flag before sending the initial request to prevent race.
 				initialRequestsSent.Store(true)
 				// Fire of a configured initial request, if there is one
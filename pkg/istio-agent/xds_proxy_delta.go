@@ -156,6 +156,12 @@ func (p *XdsProxy) handleUpstreamDeltaRequest(con *ProxyConnection) {
 						TypeUrl: model.ProxyConfigType,
 					})
 				}
+				// fire off an initial CRL request
+				if _, f := p.handlers[model.CRLType]; f {
+					con.sendDeltaRequest(&discovery.DeltaDiscoveryRequest{
+						TypeUrl: model.CRLType,
+					})
+				}
 				// set flag before sending the initial request to prevent race.
 				initialRequestsSent.Store(true)
 				// Fire of a configured initial request, if there is one
@@ -0,0 +1,171 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fielddiff compares Kubernetes objects, in their unstructured form, field by field.
+package fielddiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"istio.io/istio/pkg/maps"
+	"istio.io/istio/pkg/util/sets"
+)
+
+// Change is a field whose live value differs from its desired value.
+type Change struct {
+	// Path of the field, such as spec.template.spec.containers[0].image. Keys containing separators are quoted,
+	// as in metadata.labels["app.kubernetes.io/name"].
+	Path string
+	// Live is the value of the field in the cluster, or nil if it is not set.
+	Live any
+	// Desired is the desired value of the field, or nil if it is not set.
+	Desired any
+	// Drifted is true if the desired value is the last applied value, so the field was changed in the cluster
+	// since. It is only set by ThreeWay.
+	Drifted bool
+}
+
+// quantityParents are the keys of the objects whose fields are resource quantities.
+var quantityParents = sets.New("limits", "requests", "hard", "capacity", "allocatable")
+
+// quantityFields are the keys of fields which are resource quantities.
+var quantityFields = sets.New("sizeLimit", "storage")
+
+// Diff returns the fields whose live and desired values differ, sorted by path. Objects are compared key by key and
+// lists of the same length item by item; a list whose length changed is reported as a whole. Unset fields and
+// empty objects or lists are equal, as are numbers of different types and equivalent quantities, such as 2048Mi
+// and 2Gi.
+func Diff(live, desired any) []Change {
+	d := &differ{}
+	d.diff("", "", "", live, desired, nil)
+	return d.changes
+}
+
+// ThreeWay is Diff, which also sets the Drifted field of the changes by comparing the desired values to the last
+// applied values.
+func ThreeWay(live, desired, last any) []Change {
+	d := &differ{hasLast: true}
+	d.diff("", "", "", live, desired, last)
+	return d.changes
+}
+
+type differ struct {
+	hasLast bool
+	changes []Change
+}
+
+// diff compares the field at path, whose key is key in the object whose key is parent.
+func (d *differ) diff(path, parent, key string, live, desired, last any) {
+	if isEmpty(live) && isEmpty(desired) {
+		return
+	}
+	lm, liveMap := live.(map[string]any)
+	dm, desiredMap := desired.(map[string]any)
+	// Descend into objects set on one side only, to report each of their fields.
+	if (liveMap || isEmpty(live)) && (desiredMap || isEmpty(desired)) {
+		lastMap, _ := last.(map[string]any)
+		keys := sets.New(maps.Keys(lm)...).InsertAll(maps.Keys(dm)...)
+		for _, k := range sets.SortedList(keys) {
+			d.diff(Path(path, k), key, k, lm[k], dm[k], lastMap[k])
+		}
+		return
+	}
+	ll, liveList := live.([]any)
+	dl, desiredList := desired.([]any)
+	if liveList && desiredList && len(ll) == len(dl) {
+		lastList, _ := last.([]any)
+		for i := range dl {
+			var lastItem any
+			if len(lastList) == len(dl) {
+				lastItem = lastList[i]
+			}
+			d.diff(fmt.Sprintf("%s[%d]", path, i), key, "", ll[i], dl[i], lastItem)
+		}
+		return
+	}
+	if equal(parent, key, live, desired) {
+		return
+	}
+	d.changes = append(d.changes, Change{
+		Path:    path,
+		Live:    live,
+		Desired: desired,
+		Drifted: d.hasLast && equal(parent, key, desired, last),
+	})
+}
+
+// Path returns the path of the field key of the object at path.
+func Path(path, key string) string {
+	if strings.ContainsAny(key, "./[]") {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func isEmpty(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case map[string]any:
+		return len(t) == 0
+	case []any:
+		return len(t) == 0
+	}
+	return false
+}
+
+// equal compares values through their JSON encoding, which ignores the differences between numeric types, and
+// compares the fields holding quantities by their amount.
+func equal(parent, key string, a, b any) bool {
+	if isEmpty(a) && isEmpty(b) {
+		return true
+	}
+	if quantityParents.Contains(parent) || quantityFields.Contains(key) {
+		if qa, ok := quantity(a); ok {
+			if qb, ok := quantity(b); ok {
+				return qa.Cmp(qb) == 0
+			}
+		}
+	}
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(ja) == string(jb)
+}
+
+func quantity(v any) (resource.Quantity, bool) {
+	var s string
+	switch t := v.(type) {
+	case string:
+		s = t
+	case int64, float64, int, json.Number:
+		s = fmt.Sprint(t)
+	default:
+		return resource.Quantity{}, false
+	}
+	q, err := resource.ParseQuantity(s)
+	return q, err == nil
+}
@@ -0,0 +1,74 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fielddiff
+
+import (
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func parse(t *testing.T, y string) map[string]any {
+	t.Helper()
+	out := map[string]any{}
+	assert.NoError(t, yaml.Unmarshal([]byte(y), &out))
+	return out
+}
+
+func TestDiff(t *testing.T) {
+	live := parse(t, `
+metadata:
+  labels: {app: reviews}
+spec:
+  replicas: 2
+  hosts: [a, c]
+  ports: [80]
+  resources:
+    limits: {memory: 2Gi, cpu: "1"}
+  version: "1.0"
+  selector: {}
+`)
+	desired := parse(t, `
+metadata:
+  labels: {app: reviews, app.kubernetes.io/name: reviews}
+spec:
+  replicas: 2.0
+  hosts: [a, b]
+  ports: [80, 443]
+  resources:
+    limits: {memory: 2048Mi, cpu: 1000m}
+  version: "1"
+  gateways: []
+`)
+	assert.Equal(t, Diff(live, desired), []Change{
+		{Path: `metadata.labels["app.kubernetes.io/name"]`, Desired: "reviews"},
+		{Path: "spec.hosts[1]", Live: "c", Desired: "b"},
+		{Path: "spec.ports", Live: []any{float64(80)}, Desired: []any{float64(80), float64(443)}},
+		{Path: "spec.version", Live: "1.0", Desired: "1"},
+	})
+}
+
+func TestThreeWay(t *testing.T) {
+	live := parse(t, `{spec: {image: drifted, replicas: 1, resources: {requests: {memory: 1Gi}}}}`)
+	desired := parse(t, `{spec: {image: istiod, replicas: 2, resources: {requests: {memory: 2Gi}}}}`)
+	last := parse(t, `{spec: {image: istiod, replicas: 1, resources: {requests: {memory: 2048Mi}}}}`)
+	assert.Equal(t, ThreeWay(live, desired, last), []Change{
+		{Path: "spec.image", Live: "drifted", Desired: "istiod", Drifted: true},
+		{Path: "spec.replicas", Live: float64(1), Desired: float64(2)},
+		{Path: "spec.resources.requests.memory", Live: "1Gi", Desired: "2Gi", Drifted: true},
+	})
+}
@@ -0,0 +1,316 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/api/annotation"
+	meshconfig "istio.io/api/mesh/v1alpha1"
+	proxyConfig "istio.io/api/networking/v1beta1"
+	opconfig "istio.io/istio/operator/pkg/apis"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/log"
+)
+
+// ExplainPath is the path of the webhook endpoint explaining the injection of a pod. It accepts a pod, as JSON or YAML,
+// and the optional "namespace" and "path" query parameters: the namespace of the pod, if it is not set, and the
+// injection path of the webhook configuration, which may set proxy environment variables.
+const ExplainPath = "/inject/explain"
+
+// Patch origins, in the order they are applied.
+const (
+	// PatchOriginTemplate marks the changes made by the injection templates.
+	PatchOriginTemplate = "template"
+	// PatchOriginOverride marks the changes made by the containers of the pod overriding the injected containers.
+	PatchOriginOverride = "override"
+	// PatchOriginRewrite marks the changes made after templating, such as probe rewrites.
+	PatchOriginRewrite = "rewrite"
+	// PatchOriginMetadata marks the injection status and the annotations added by the injector configuration.
+	PatchOriginMetadata = "metadata"
+	// PatchOriginReorder marks the changes made by ordering the injected containers among the containers of the pod.
+	PatchOriginReorder = "reorder"
+)
+
+// Explanation describes how a pod is injected: the webhooks matching it, the decisions taken from its annotations and
+// labels, the mesh config and the injector configuration, the templates selected, and the resulting patch.
+type Explanation struct {
+	// Pod is the namespace and name of the pod.
+	Pod string `json:"pod"`
+	// Revision is the revision of the injector explaining the injection.
+	Revision string `json:"revision"`
+	// Webhooks are the injection webhook configurations of the cluster, and whether they match the pod. They are not
+	// known by the injector.
+	Webhooks []WebhookMatch `json:"webhooks,omitempty"`
+	// Injected is true if the pod is injected.
+	Injected  bool                `json:"injected"`
+	Decisions []Decision          `json:"decisions"`
+	Templates []TemplateSelection `json:"templates,omitempty"`
+	Patch     []PatchOperation    `json:"patch,omitempty"`
+	// Error is the error failing the injection, if any.
+	Error string `json:"error,omitempty"`
+
+	// stages holds the paths changed by each step of the injection, to annotate the patch.
+	stages []patchStage
+	// last is the pod after the last step.
+	last []byte
+}
+
+// WebhookMatch describes whether an injection webhook configuration matches a pod.
+type WebhookMatch struct {
+	Name     string `json:"name"`
+	Revision string `json:"revision"`
+	Matched  bool   `json:"matched"`
+	Reason   string `json:"reason"`
+}
+
+// Decision is a setting of the injection, and the reason of its value.
+type Decision struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Reason string `json:"reason"`
+}
+
+// TemplateSelection is a template applied to the pod.
+type TemplateSelection struct {
+	Name string `json:"name"`
+	// Alias is the alias the template was selected with, if any.
+	Alias  string `json:"alias,omitempty"`
+	Source string `json:"source"`
+}
+
+// PatchOperation is an operation of the JSON patch of the pod, with the steps of the injection changing its path.
+type PatchOperation struct {
+	Op      string   `json:"op"`
+	Path    string   `json:"path"`
+	Value   any      `json:"value,omitempty"`
+	Origins []string `json:"origins"`
+}
+
+type patchStage struct {
+	origin string
+	paths  []string
+}
+
+// decide records a decision. It is a no-op if the injection is not explained.
+func (e *Explanation) decide(name, value, reason string, args ...any) {
+	if e == nil {
+		return
+	}
+	e.Decisions = append(e.Decisions, Decision{Name: name, Value: value, Reason: fmt.Sprintf(reason, args...)})
+}
+
+// selectTemplate records a selected template. It is a no-op if the injection is not explained.
+func (e *Explanation) selectTemplate(name, alias, source string) {
+	if e == nil {
+		return
+	}
+	e.Templates = append(e.Templates, TemplateSelection{Name: name, Alias: alias, Source: source})
+}
+
+func (e *Explanation) explainProxyConfig(annotations map[string]string) {
+	if e == nil {
+		return
+	}
+	if _, f := annotations[annotation.ProxyConfig.Name]; f {
+		e.decide("proxyConfig", "annotation", "annotation %s is merged over the ProxyConfig resources and the mesh config defaultConfig",
+			annotation.ProxyConfig.Name)
+		return
+	}
+	e.decide("proxyConfig", "mesh", "the ProxyConfig resources are merged over the mesh config defaultConfig")
+}
+
+func (e *Explanation) explainProxyImage(image string, values *opconfig.Values, proxyImage *proxyConfig.ProxyImage, annotations map[string]string) {
+	if e == nil {
+		return
+	}
+	imageType := "values global.variant"
+	if proxyImage != nil {
+		imageType = "the proxy config image"
+	}
+	if _, f := annotations[annotation.SidecarProxyImageType.Name]; f {
+		imageType = "annotation " + annotation.SidecarProxyImageType.Name
+	}
+	name := "proxyv2"
+	if values.GetGlobal().GetProxy().GetImage() != "" {
+		name = "values global.proxy.image"
+	}
+	e.decide("proxyImage", image, "built from values global.hub and global.tag, image %s and the image type of %s, unless the template "+
+		"uses annotation %s", name, imageType, annotation.SidecarProxyImage.Name)
+}
+
+func (e *Explanation) explainPrometheusMerge(pod *corev1.Pod, mesh *meshconfig.MeshConfig) {
+	if e == nil {
+		return
+	}
+	if !getPrometheusScrape(pod) {
+		e.decide("prometheusMerge", "false", "the pod disables scraping with annotation prometheus.io/scrape")
+		return
+	}
+	value := strconv.FormatBool(enablePrometheusMerge(mesh, pod.Annotations))
+	switch {
+	case pod.Annotations[annotation.PrometheusMergeMetrics.Name] != "":
+		e.decide("prometheusMerge", value, "annotation %s", annotation.PrometheusMergeMetrics.Name)
+	case mesh.GetEnablePrometheusMerge() != nil:
+		e.decide("prometheusMerge", value, "mesh config enablePrometheusMerge")
+	default:
+		e.decide("prometheusMerge", value, "enabled by default")
+	}
+}
+
+func (e *Explanation) explainRewriteAppHTTPProbers(annotations map[string]string, rewrite bool) {
+	if e == nil {
+		return
+	}
+	if _, err := strconv.ParseBool(annotations[annotation.SidecarRewriteAppHTTPProbers.Name]); err == nil {
+		e.decide("rewriteAppHTTPProbers", strconv.FormatBool(rewrite), "annotation %s", annotation.SidecarRewriteAppHTTPProbers.Name)
+		return
+	}
+	e.decide("rewriteAppHTTPProbers", strconv.FormatBool(rewrite), "values sidecarInjectorWebhook.rewriteAppHTTPProbe")
+}
+
+// stage records the paths changed by a step of the injection, from the pod as it was after the previous step. It is a
+// no-op if the injection is not explained.
+func (e *Explanation) stage(origin string, pod *corev1.Pod) error {
+	if e == nil {
+		return nil
+	}
+	ops, err := patchOperations(pod, e.last)
+	if err != nil {
+		return err
+	}
+	paths := make([]string, 0, len(ops))
+	for _, op := range ops {
+		paths = append(paths, op.Path)
+	}
+	e.stages = append(e.stages, patchStage{origin: origin, paths: paths})
+	e.last, err = json.Marshal(pod)
+	return err
+}
+
+// annotatePatch sets the patch of the explanation, with the origins of its operations. An operation originates from
+// the steps changing its path, one of its parents or one of its children.
+func (e *Explanation) annotatePatch(patch []byte) error {
+	ops := []PatchOperation{}
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return err
+	}
+	for i, op := range ops {
+		for _, s := range e.stages {
+			for _, p := range s.paths {
+				if isPathPrefix(op.Path, p) || isPathPrefix(p, op.Path) {
+					op.Origins = append(op.Origins, s.origin)
+					break
+				}
+			}
+		}
+		if len(op.Origins) == 0 {
+			op.Origins = []string{PatchOriginTemplate}
+		}
+		ops[i] = op
+	}
+	e.Patch = ops
+	return nil
+}
+
+func patchOperations(pod *corev1.Pod, before []byte) ([]PatchOperation, error) {
+	patch, err := createPatch(pod, before)
+	if err != nil {
+		return nil, err
+	}
+	ops := []PatchOperation{}
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// isPathPrefix returns true if prefix is path, or one of the parents of the JSON pointer path.
+func isPathPrefix(prefix, path string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// Explain injects a pod without admitting it, and explains the injection. path is the injection path of the webhook
+// configuration, which may set proxy environment variables. The pod is not modified.
+func (wh *Webhook) Explain(pod *corev1.Pod, path string) *Explanation {
+	pod = pod.DeepCopy()
+	pod.ManagedFields = nil
+	revision := wh.revision
+	if revision == "" {
+		revision = "default"
+	}
+	e := &Explanation{
+		Pod:      pod.Namespace + "/" + potentialPodName(pod.ObjectMeta),
+		Revision: revision,
+	}
+	log := log.WithLabels("path", path, "pod", e.Pod)
+
+	wh.mu.RLock()
+	params, required := wh.injectionParameters(pod, path, log, e)
+	wh.mu.RUnlock()
+	if !required {
+		return e
+	}
+	e.Injected = true
+	if _, err := injectPod(params); err != nil {
+		e.Injected = false
+		e.Error = err.Error()
+	}
+	return e
+}
+
+func (wh *Webhook) serveExplain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := kube.HTTPConfigReader(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pod := &corev1.Pod{}
+	if err := yaml.Unmarshal(body, pod); err != nil {
+		http.Error(w, fmt.Sprintf("could not decode pod: %v", err), http.StatusBadRequest)
+		return
+	}
+	if pod.Namespace == "" {
+		pod.Namespace = r.URL.Query().Get("namespace")
+	}
+	if pod.Namespace == "" {
+		http.Error(w, "the namespace of the pod is required", http.StatusBadRequest)
+		return
+	}
+	path := r.URL.Query().Get("path")
+	if path == "" {
+		path = "/inject"
+	}
+	resp, err := json.Marshal(wh.Explain(pod, path))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not encode response: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(resp); err != nil {
+		log.Errorf("Could not write response: %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/slices"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func explainWebhook(t *testing.T) *Webhook {
+	templates, err := ParseTemplates(map[string]string{
+		"sidecar": `
+spec:
+  containers:
+  - name: istio-proxy
+    image: proxy
+`,
+		"init": `
+spec:
+  initContainers:
+  - name: istio-init
+    image: proxy
+`,
+	})
+	assert.NoError(t, err)
+	env := &model.Environment{}
+	env.SetPushContext(&model.PushContext{
+		ProxyConfigs: &model.ProxyConfigs{},
+	})
+	vc, err := NewValuesConfig("{}")
+	assert.NoError(t, err)
+	return &Webhook{
+		Config: &Config{
+			Templates:        templates,
+			Aliases:          map[string][]string{"both": {"sidecar", "init"}},
+			Policy:           InjectionPolicyEnabled,
+			DefaultTemplates: []string{"sidecar"},
+		},
+		meshConfig:   mesh.DefaultMeshConfig(),
+		valuesConfig: vc,
+		env:          env,
+		revision:     "canary",
+	}
+}
+
+func explainPod(t *testing.T, in string) *corev1.Pod {
+	pod := &corev1.Pod{}
+	assert.NoError(t, yaml.Unmarshal([]byte(in), pod))
+	return pod
+}
+
+func decision(e *Explanation, name string) Decision {
+	d := slices.FindFunc(e.Decisions, func(d Decision) bool { return d.Name == name })
+	if d == nil {
+		return Decision{}
+	}
+	return *d
+}
+
+func TestExplain(t *testing.T) {
+	wh := explainWebhook(t)
+
+	e := wh.Explain(explainPod(t, `
+metadata:
+  name: skipped
+  namespace: default
+  labels:
+    sidecar.istio.io/inject: "false"
+spec:
+  containers:
+  - name: app
+    image: app
+`), "/inject")
+	assert.Equal(t, e.Injected, false)
+	assert.Equal(t, e.Revision, "canary")
+	assert.Equal(t, decision(e, "injection"), Decision{Name: "injection", Value: "false", Reason: `label sidecar.istio.io/inject="false"`})
+	assert.Equal(t, len(e.Patch), 0)
+
+	e = wh.Explain(explainPod(t, `
+metadata:
+  name: app
+  namespace: default
+  annotations:
+    inject.istio.io/templates: both
+spec:
+  containers:
+  - name: app
+    image: app
+  - name: istio-proxy
+    image: custom-proxy
+`), "/inject/cluster/cluster-1/net/network-1")
+	assert.Equal(t, e.Error, "")
+	assert.Equal(t, e.Injected, true)
+	assert.Equal(t, e.Pod, "default/app")
+	assert.Equal(t, decision(e, "injection").Reason, "the injection policy of the injector configuration is enabled")
+	assert.Equal(t, e.Templates, []TemplateSelection{
+		{Name: "sidecar", Alias: "both", Source: "annotation inject.istio.io/templates"},
+		{Name: "init", Alias: "both", Source: "annotation inject.istio.io/templates"},
+	})
+	assert.Equal(t, decision(e, "override"), Decision{
+		Name:   "override",
+		Value:  "istio-proxy",
+		Reason: "container istio-proxy of the template is overridden by the containers of the pod",
+	})
+	assert.Equal(t, decision(e, "network").Value, "network-1")
+	assert.Equal(t, decision(e, "holdApplicationUntilProxyStarts").Value, "false")
+
+	origins := map[string][]string{}
+	for _, op := range e.Patch {
+		origins[op.Path] = op.Origins
+	}
+	assert.Equal(t, origins["/spec/initContainers"], []string{PatchOriginTemplate})
+	assert.Equal(t, origins["/metadata/annotations/sidecar.istio.io~1status"], []string{PatchOriginMetadata})
+	assert.Equal(t, origins["/metadata/annotations/proxy.istio.io~1overrides"], []string{PatchOriginOverride})
+
+	e = wh.Explain(explainPod(t, `
+metadata:
+  name: reordered
+  namespace: default
+spec:
+  containers:
+  - name: istio-proxy
+    image: custom-proxy
+  - name: app
+    image: app
+`), "/inject")
+	assert.Equal(t, e.Error, "")
+	origins = map[string][]string{}
+	for _, op := range e.Patch {
+		origins[op.Path] = op.Origins
+	}
+	assert.Equal(t, origins["/spec/containers/0/name"], []string{PatchOriginReorder})
+	assert.Equal(t, origins["/spec/containers/1/name"], []string{PatchOriginReorder})
+}
+
+func TestServeExplain(t *testing.T) {
+	wh := explainWebhook(t)
+	body := `
+metadata:
+  name: app
+spec:
+  containers:
+  - name: app
+    image: app
+`
+	req := httptest.NewRequest(http.MethodPost, ExplainPath+"?namespace=default", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	wh.serveExplain(rec, req)
+	assert.Equal(t, rec.Code, http.StatusOK)
+	e := &Explanation{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), e))
+	assert.Equal(t, e.Pod, "default/app")
+	assert.Equal(t, e.Injected, true)
+	assert.Equal(t, e.Templates, []TemplateSelection{{Name: "sidecar", Source: "default templates of the injector configuration"}})
+
+	// The namespace is required.
+	rec = httptest.NewRecorder()
+	wh.serveExplain(rec, httptest.NewRequest(http.MethodPost, ExplainPath, strings.NewReader(body)))
+	assert.Equal(t, rec.Code, http.StatusBadRequest)
+}
@@ -49,6 +49,7 @@ import (
 	"istio.io/istio/pkg/config/mesh"
 	common_features "istio.io/istio/pkg/features"
 	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/kube/inject/proxyimage"
 	"istio.io/istio/pkg/kube/kclient"
 	"istio.io/istio/pkg/log"
 	"istio.io/istio/tools/istio-iptables/pkg/constants"
@@ -91,11 +92,11 @@ const (
 
 const (
 	// ImageTypeDebug is the suffix of the debug image.
-	ImageTypeDebug = "debug"
+	ImageTypeDebug = proxyimage.Debug
 	// ImageTypeDistroless is the suffix of the distroless image.
-	ImageTypeDistroless = "distroless"
+	ImageTypeDistroless = proxyimage.Distroless
 	// ImageTypeDefault is the type name of the default image, suffix is elided.
-	ImageTypeDefault = "default"
+	ImageTypeDefault = proxyimage.Default
 )
 
 // SidecarTemplateData is the data object to which the templated
@@ -197,6 +198,11 @@ func UnmarshalConfig(yml []byte) (Config, error) {
 }
 
 func injectRequired(ignored []string, config *Config, podSpec *corev1.PodSpec, metadata metav1.ObjectMeta) bool { // nolint: lll
+	return explainInjectRequired(ignored, config, podSpec, metadata, nil)
+}
+
+// explainInjectRequired is injectRequired, recording the reason of the decision in e, if set.
+func explainInjectRequired(ignored []string, config *Config, podSpec *corev1.PodSpec, metadata metav1.ObjectMeta, e *Explanation) bool {
 	log := log.WithLabels("pod", metadata.Namespace+"/"+potentialPodName(metadata))
 	// Skip injection when host networking is enabled. The problem is
 	// that the iptables changes are assumed to be within the pod when,
@@ -205,12 +211,14 @@ func injectRequired(ignored []string, config *Config, podSpec *corev1.PodSpec, m
 	// affect the network provider within the cluster causing
 	// additional pod failures.
 	if podSpec.HostNetwork {
+		e.decide("injection", "false", "the pod uses the host network")
 		return false
 	}
 
 	// skip special kubernetes system namespaces
 	for _, namespace := range ignored {
 		if metadata.Namespace == namespace {
+			e.decide("injection", "false", "namespace %s is never injected", namespace)
 			return false
 		}
 	}
@@ -221,9 +229,11 @@ func injectRequired(ignored []string, config *Config, podSpec *corev1.PodSpec, m
 	var inject bool
 
 	objectSelector := annos[annotation.SidecarInject.Name]
+	reason := fmt.Sprintf("annotation %s=%q", annotation.SidecarInject.Name, objectSelector)
 	if lbl, labelPresent := metadata.GetLabels()[label.SidecarInject.Name]; labelPresent {
 		// The label is the new API; if both are present we prefer the label
 		objectSelector = lbl
+		reason = fmt.Sprintf("label %s=%q", label.SidecarInject.Name, objectSelector)
 	}
 	switch objectSelector {
 	case "true":
@@ -235,6 +245,7 @@ func injectRequired(ignored []string, config *Config, podSpec *corev1.PodSpec, m
 	default:
 		log.Warnf("Invalid value for %s: %q. Only 'true' and 'false' are accepted. Falling back to default injection policy.",
 			label.SidecarInject.Name, objectSelector)
+		e.decide("injectionSelector", objectSelector, "%s is invalid, only true and false are accepted", reason)
 		useDefault = true
 	}
 
@@ -249,6 +260,7 @@ func injectRequired(ignored []string, config *Config, podSpec *corev1.PodSpec, m
 					metadata.Namespace, potentialPodName(metadata))
 				inject = false
 				useDefault = false
+				reason = fmt.Sprintf("the labels of the pod match the neverInjectSelector %s", selector)
 				break
 			}
 		}
@@ -265,17 +277,22 @@ func injectRequired(ignored []string, config *Config, podSpec *corev1.PodSpec, m
 					metadata.Namespace, potentialPodName(metadata))
 				inject = true
 				useDefault = false
+				reason = fmt.Sprintf("the labels of the pod match the alwaysInjectSelector %s", selector)
 				break
 			}
 		}
 	}
 
+	if useDefault {
+		reason = fmt.Sprintf("the injection policy of the injector configuration is %s", config.Policy)
+	}
 	var required bool
 	switch config.Policy {
 	default: // InjectionPolicyOff
 		log.Errorf("Illegal value for autoInject:%s, must be one of [%s,%s]. Auto injection disabled!",
 			config.Policy, InjectionPolicyDisabled, InjectionPolicyEnabled)
 		required = false
+		reason = fmt.Sprintf("the injection policy %q of the injector configuration is invalid", config.Policy)
 	case InjectionPolicyDisabled:
 		if useDefault {
 			required = false
@@ -311,6 +328,7 @@ func injectRequired(ignored []string, config *Config, podSpec *corev1.PodSpec, m
 			annotationStr)
 	}
 
+	e.decide("injection", strconv.FormatBool(required), "%s", reason)
 	return required
 }
 
@@ -338,7 +356,7 @@ func ProxyImage(values *opconfig.Values, image *proxyConfig.ProxyImage, annotati
 		imageType = it
 	}
 
-	return imageURL(global.GetHub(), imageName, tag, imageType)
+	return proxyimage.URL(global.GetHub(), imageName, tag, imageType)
 }
 
 func InboundTrafficPolicyMode(meshConfig *meshconfig.MeshConfig) string {
@@ -351,37 +369,8 @@ func InboundTrafficPolicyMode(meshConfig *meshconfig.MeshConfig) string {
 	return "passthrough"
 }
 
-// imageURL creates url from parts.
-// imageType is appended if not empty
-// if imageType is already present in the tag, then it is replaced.
-// docker.io/istio/proxyv2:1.12-distroless
-// gcr.io/gke-release/asm/proxyv2:1.11.2-asm.17-distroless
-// docker.io/istio/proxyv2:1.12
-func imageURL(hub, imageName, tag, imageType string) string {
-	return hub + "/" + imageName + ":" + updateImageTypeIfPresent(tag, imageType)
-}
-
 // KnownImageTypes are image types that istio pubishes.
-var KnownImageTypes = []string{ImageTypeDistroless, ImageTypeDebug}
-
-func updateImageTypeIfPresent(tag string, imageType string) string {
-	if imageType == "" {
-		return tag
-	}
-
-	for _, i := range KnownImageTypes {
-		if strings.HasSuffix(tag, "-"+i) {
-			tag = tag[:len(tag)-(len(i)+1)]
-			break
-		}
-	}
-
-	if imageType == ImageTypeDefault {
-		return tag
-	}
-
-	return tag + "-" + imageType
-}
+var KnownImageTypes = proxyimage.KnownTypes
 
 func extractClusterAndNetwork(params InjectionParameters) (string, string) {
 	metadata := &params.pod.ObjectMeta
@@ -418,9 +407,11 @@ func RunTemplate(params InjectionParameters) (mergedPod *corev1.Pod, templatePod
 	// use network in values for template, and proxy env variables
 	if cluster != "" {
 		params.proxyEnvs["ISTIO_META_CLUSTER_ID"] = cluster
+		params.explanation.decide("cluster", cluster, "set by the injection path or values global.multiCluster.clusterName")
 	}
 	if network != "" {
 		params.proxyEnvs["ISTIO_META_NETWORK"] = network
+		params.explanation.decide("network", network, "set by the injection path or values global.network")
 	}
 
 	strippedPod, err := reinsertOverrides(stripPod(params))
@@ -450,10 +441,11 @@ func RunTemplate(params InjectionParameters) (mergedPod *corev1.Pod, templatePod
 	if params.valuesConfig.asMap == nil {
 		return nil, nil, fmt.Errorf("failed to parse values.yaml; check Istiod logs for errors")
 	}
+	params.explanation.explainProxyImage(data.ProxyImage, params.valuesConfig.asStruct, params.proxyConfig.Image, strippedPod.Annotations)
 
 	mergedPod = params.pod
 	templatePod = &corev1.Pod{}
-	for _, templateName := range selectTemplates(params) {
+	for i, templateName := range selectTemplates(params) {
 		parsedTemplate, f := params.templates[templateName]
 		if !f {
 			return nil, nil, fmt.Errorf("requested template %q not found; have %v",
@@ -475,10 +467,16 @@ func RunTemplate(params InjectionParameters) (mergedPod *corev1.Pod, templatePod
 		// move the container.
 		// The sidecar.istio.io/nativeSidecar annotation takes precedence over the global feature flag.
 		native := params.nativeSidecar
+		nativeReason := "detected from the ENABLE_NATIVE_SIDECARS setting of istiod and the Kubernetes version of the nodes"
 		if mergedPod.Annotations["sidecar.istio.io/nativeSidecar"] == "true" {
 			native = true
+			nativeReason = "annotation sidecar.istio.io/nativeSidecar"
 		} else if mergedPod.Annotations["sidecar.istio.io/nativeSidecar"] == "false" {
 			native = false
+			nativeReason = "annotation sidecar.istio.io/nativeSidecar"
+		}
+		if i == 0 && params.explanation != nil {
+			params.explanation.decide("nativeSidecar", strconv.FormatBool(native), "%s", nativeReason)
 		}
 		if native &&
 			FindContainer(ProxyContainerName, templatePod.Spec.InitContainers) != nil &&
@@ -510,18 +508,22 @@ func selectTemplates(params InjectionParameters) []string {
 			name := strings.TrimSpace(tmplName)
 			names = append(names, name)
 		}
-		return resolveAliases(params, names)
+		return resolveAliases(params, names, "annotation "+annotation.InjectTemplates.Name)
 	}
-	return resolveAliases(params, params.defaultTemplate)
+	return resolveAliases(params, params.defaultTemplate, "default templates of the injector configuration")
 }
 
-func resolveAliases(params InjectionParameters, names []string) []string {
+func resolveAliases(params InjectionParameters, names []string, source string) []string {
 	ret := []string{}
 	for _, name := range names {
 		if al, f := params.aliases[name]; f {
 			ret = append(ret, al...)
+			for _, t := range al {
+				params.explanation.selectTemplate(t, name, source)
+			}
 		} else {
 			ret = append(ret, name)
+			params.explanation.selectTemplate(name, "", source)
 		}
 	}
 	return ret
@@ -535,6 +537,10 @@ func stripPod(req InjectionParameters) *corev1.Pod {
 	}
 	// We found a previous status annotation. Possibly we are re-injecting the pod
 	// To ensure idempotency, remove our injected containers first
+	if req.explanation != nil {
+		req.explanation.decide("reinjection", "true", "annotation %s is set: the containers previously injected are removed first",
+			annotation.SidecarStatus.Name)
+	}
 	for _, c := range prevStatus.Containers {
 		pod.Spec.Containers = modifyContainers(pod.Spec.Containers, c, Remove)
 	}
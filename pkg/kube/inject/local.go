@@ -0,0 +1,73 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/log"
+)
+
+// LocalInjector injects pods in process, the way the webhook does: unlike kube-inject, it honors the injection policy,
+// default templates, aliases and injected annotations of the injector configuration. It needs no cluster, which allows
+// testing injection templates before installing them.
+type LocalInjector struct {
+	wh   *Webhook
+	path string
+}
+
+var _ Injector = &LocalInjector{}
+
+// NewLocalInjector returns an injector using the given injector configuration, values and mesh config. The
+// configuration templates must be parsed. path is the injection path of the webhook configuration, which may set
+// proxy environment variables.
+func NewLocalInjector(config *Config, values ValuesConfig, meshConfig *meshconfig.MeshConfig, revision, path string) *LocalInjector {
+	env := &model.Environment{}
+	env.SetPushContext(&model.PushContext{ProxyConfigs: &model.ProxyConfigs{}})
+	if path == "" {
+		path = "/inject"
+	}
+	return &LocalInjector{
+		wh: &Webhook{
+			Config:       config,
+			meshConfig:   meshConfig,
+			valuesConfig: values,
+			env:          env,
+			revision:     revision,
+		},
+		path: path,
+	}
+}
+
+// Inject returns the patch of the pod. The patch is empty if the pod is not injected.
+func (l *LocalInjector) Inject(pod *corev1.Pod, namespace string) ([]byte, error) {
+	pod = pod.DeepCopy()
+	if pod.Namespace == "" {
+		pod.Namespace = namespace
+	}
+	log := log.WithLabels("path", l.path, "pod", pod.Namespace+"/"+potentialPodName(pod.ObjectMeta))
+	params, required := l.wh.injectionParameters(pod, l.path, log, nil)
+	if !required {
+		return []byte("[]"), nil
+	}
+	return injectPod(params)
+}
+
+func (l *LocalInjector) GetKubeClient() kube.Client {
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package proxyimage builds the reference of the proxy image the injector uses. It has no dependencies, so that the
+// installer can build the same reference without importing the injector.
+package proxyimage
+
+import "strings"
+
+const (
+	// Debug is the suffix of the debug image.
+	Debug = "debug"
+	// Distroless is the suffix of the distroless image.
+	Distroless = "distroless"
+	// Default is the type name of the default image, suffix is elided.
+	Default = "default"
+)
+
+// KnownTypes are image types that istio publishes.
+var KnownTypes = []string{Distroless, Debug}
+
+// URL creates url from parts.
+// imageType is appended if not empty
+// if imageType is already present in the tag, then it is replaced.
+// docker.io/istio/proxyv2:1.12-distroless
+// gcr.io/gke-release/asm/proxyv2:1.11.2-asm.17-distroless
+// docker.io/istio/proxyv2:1.12
+func URL(hub, imageName, tag, imageType string) string {
+	return hub + "/" + imageName + ":" + updateImageTypeIfPresent(tag, imageType)
+}
+
+func updateImageTypeIfPresent(tag string, imageType string) string {
+	if imageType == "" {
+		return tag
+	}
+
+	for _, i := range KnownTypes {
+		if strings.HasSuffix(tag, "-"+i) {
+			tag = tag[:len(tag)-(len(i)+1)]
+			break
+		}
+	}
+
+	if imageType == Default {
+		return tag
+	}
+
+	return tag + "-" + imageType
+}
@@ -236,6 +236,7 @@ func NewWebhook(p WebhookParameters) (*Webhook, error) {
 
 	p.Mux.HandleFunc("/inject", wh.serveInject)
 	p.Mux.HandleFunc("/inject/", wh.serveInject)
+	p.Mux.HandleFunc(ExplainPath, wh.serveExplain)
 
 	p.Env.Watcher.AddMeshHandler(func() {
 		wh.mu.Lock()
@@ -400,6 +401,8 @@ type InjectionParameters struct {
 	revision            string
 	proxyEnvs           map[string]string
 	injectedAnnotations map[string]string
+	// explanation records the decisions of the injection, if it is explained.
+	explanation *Explanation
 }
 
 func checkPreconditions(params InjectionParameters) {
@@ -469,16 +472,26 @@ func injectPod(req InjectionParameters) ([]byte, error) {
 		return nil, err
 	}
 
+	if req.explanation != nil {
+		req.explanation.last = originalPodSpec
+	}
+
 	// Run the injection template, giving us a partial pod spec
 	mergedPod, injectedPodData, err := RunTemplate(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to run injection template: %v", err)
 	}
+	if err := req.explanation.stage(PatchOriginTemplate, mergedPod); err != nil {
+		return nil, err
+	}
 
-	mergedPod, err = reapplyOverwrittenContainers(mergedPod, req.pod, injectedPodData, req.proxyConfig)
+	mergedPod, err = reapplyOverwrittenContainers(mergedPod, req.pod, injectedPodData, req.proxyConfig, req.explanation)
 	if err != nil {
 		return nil, fmt.Errorf("failed to re apply container: %v", err)
 	}
+	if err := req.explanation.stage(PatchOriginOverride, mergedPod); err != nil {
+		return nil, err
+	}
 
 	// Apply some additional transformations to the pod
 	if err := postProcessPod(mergedPod, *injectedPodData, req); err != nil {
@@ -490,6 +503,12 @@ func injectPod(req InjectionParameters) ([]byte, error) {
 		return nil, fmt.Errorf("failed to create patch: %v", err)
 	}
 
+	if req.explanation != nil {
+		if err := req.explanation.annotatePatch(patch); err != nil {
+			return nil, err
+		}
+	}
+
 	log.Debugf("AdmissionResponse: patch=%v\n", string(patch))
 	return patch, nil
 }
@@ -507,7 +526,7 @@ func injectPod(req InjectionParameters) ([]byte, error) {
 // Where "overlap" is a container defined in both the original and template pod. Typically, this would mean
 // the user has defined an `istio-proxy` container in their own pod spec.
 func reapplyOverwrittenContainers(finalPod *corev1.Pod, originalPod *corev1.Pod, templatePod *corev1.Pod,
-	proxyConfig *meshconfig.ProxyConfig,
+	proxyConfig *meshconfig.ProxyConfig, e *Explanation,
 ) (*corev1.Pod, error) {
 	overrides := ParsedContainers{}
 	existingOverrides := ParsedContainers{}
@@ -529,12 +548,15 @@ func reapplyOverwrittenContainers(finalPod *corev1.Pod, originalPod *corev1.Pod,
 			continue
 		}
 		match := FindContainer(c.Name, existingOverrides.Containers)
+		source := "annotation " + annotation.ProxyOverrides.Name
 		if match == nil {
 			match = FindContainer(c.Name, originalPod.Spec.Containers)
+			source = "the containers of the pod"
 		}
 		if match == nil {
 			continue
 		}
+		e.decide("override", c.Name, "container %s of the template is overridden by %s", c.Name, source)
 		overlay := *match.DeepCopy()
 		if overlay.Image == AutoImage {
 			overlay.Image = ""
@@ -552,12 +574,15 @@ func reapplyOverwrittenContainers(finalPod *corev1.Pod, originalPod *corev1.Pod,
 			continue
 		}
 		match := FindContainer(c.Name, existingOverrides.InitContainers)
+		source := "annotation " + annotation.ProxyOverrides.Name
 		if match == nil {
 			match = FindContainerFromPod(c.Name, originalPod)
+			source = "the containers of the pod"
 		}
 		if match == nil {
 			continue
 		}
+		e.decide("override", c.Name, "init container %s of the template is overridden by %s", c.Name, source)
 		overlay := *match.DeepCopy()
 		if overlay.Image == AutoImage {
 			overlay.Image = ""
@@ -747,6 +772,7 @@ func postProcessPod(pod *corev1.Pod, injectedPod corev1.Pod, req InjectionParame
 
 	overwriteClusterInfo(pod, req)
 
+	req.explanation.explainPrometheusMerge(pod, req.meshConfig)
 	if err := applyPrometheusMerge(pod, req.meshConfig); err != nil {
 		return err
 	}
@@ -754,14 +780,20 @@ func postProcessPod(pod *corev1.Pod, injectedPod corev1.Pod, req InjectionParame
 	if err := applyRewrite(pod, req); err != nil {
 		return err
 	}
+	if err := req.explanation.stage(PatchOriginRewrite, pod); err != nil {
+		return err
+	}
 
 	applyMetadata(pod, injectedPod, req)
+	if err := req.explanation.stage(PatchOriginMetadata, pod); err != nil {
+		return err
+	}
 
 	if err := reorderPod(pod, req); err != nil {
 		return err
 	}
 
-	return nil
+	return req.explanation.stage(PatchOriginReorder, pod)
 }
 
 func applyMetadata(pod *corev1.Pod, injectedPodData corev1.Pod, req InjectionParameters) {
@@ -792,6 +824,14 @@ func reorderPod(pod *corev1.Pod, req InjectionParameters) error {
 	// nolint: staticcheck
 	holdPod := mc.GetDefaultConfig().GetHoldApplicationUntilProxyStarts().GetValue() ||
 		req.valuesConfig.asStruct.GetGlobal().GetProxy().GetHoldApplicationUntilProxyStarts().GetValue()
+	switch {
+	case mc.GetDefaultConfig().GetHoldApplicationUntilProxyStarts().GetValue():
+		req.explanation.decide("holdApplicationUntilProxyStarts", "true", "set in the proxy config: the proxy container is first")
+	case holdPod:
+		req.explanation.decide("holdApplicationUntilProxyStarts", "true", "set in values global.proxy: the proxy container is first")
+	default:
+		req.explanation.decide("holdApplicationUntilProxyStarts", "false", "not set in the proxy config or values: the proxy container is last")
+	}
 
 	proxyLocation := MoveLast
 	// If HoldApplicationUntilProxyStarts is set, reorder the proxy location
@@ -830,6 +870,7 @@ func applyRewrite(pod *corev1.Pod, req InjectionParameters) error {
 	}
 
 	rewrite := ShouldRewriteAppHTTPProbers(pod.Annotations, req.valuesConfig.asStruct.GetSidecarInjectorWebhook().GetRewriteAppHTTPProbe().GetValue())
+	req.explanation.explainRewriteAppHTTPProbers(pod.Annotations, rewrite)
 	// We don't have to escape json encoding here when using golang libraries.
 	if rewrite {
 		if prober := DumpAppProbers(pod, req.meshConfig.GetDefaultConfig().GetStatusPort()); prober != "" {
@@ -1126,20 +1167,45 @@ func (wh *Webhook) inject(ar *kube.AdmissionReview, path string) *kube.Admission
 	log.Debugf("OldObject: %v", string(req.OldObject.Raw))
 
 	wh.mu.RLock()
-	if !injectRequired(IgnoredNamespaces.UnsortedList(), wh.Config, &pod.Spec, pod.ObjectMeta) {
+	params, required := wh.injectionParameters(&pod, path, log, nil)
+	wh.mu.RUnlock()
+	if !required {
 		log.Infof("Skipping due to policy check")
 		totalSkippedInjections.Increment()
-		wh.mu.RUnlock()
 		return &kube.AdmissionResponse{
 			Allowed: true,
 		}
 	}
 
+	patchBytes, err := injectPod(params)
+	if err != nil {
+		handleError(log, fmt.Sprintf("Pod injection failed: %v", err))
+		return toAdmissionResponse(err)
+	}
+
+	reviewResponse := kube.AdmissionResponse{
+		Allowed: true,
+		Patch:   patchBytes,
+		PatchType: func() *string {
+			pt := "JSONPatch"
+			return &pt
+		}(),
+	}
+	return &reviewResponse
+}
+
+// injectionParameters returns the parameters of the injection of a pod, and false if the pod is not injected. The
+// decisions are recorded in e, if set. It must be called with the read lock held.
+func (wh *Webhook) injectionParameters(pod *corev1.Pod, path string, log *log.Scope, e *Explanation) (InjectionParameters, bool) {
+	if !explainInjectRequired(IgnoredNamespaces.UnsortedList(), wh.Config, &pod.Spec, pod.ObjectMeta, e) {
+		return InjectionParameters{}, false
+	}
+
 	proxyConfig := wh.env.GetProxyConfigOrDefault(pod.Namespace, pod.Labels, pod.Annotations, wh.meshConfig)
-	deploy, typeMeta := kube.GetDeployMetaFromPod(&pod)
+	deploy, typeMeta := kube.GetDeployMetaFromPod(pod)
 
 	params := InjectionParameters{
-		pod:                 &pod,
+		pod:                 pod,
 		deployMeta:          deploy,
 		typeMeta:            typeMeta,
 		templates:           wh.Config.Templates,
@@ -1151,7 +1217,9 @@ func (wh *Webhook) inject(ar *kube.AdmissionReview, path string) *kube.Admission
 		revision:            wh.revision,
 		injectedAnnotations: wh.Config.InjectedAnnotations,
 		proxyEnvs:           parseInjectEnvs(path),
+		explanation:         e,
 	}
+	e.explainProxyConfig(pod.Annotations)
 
 	clusterID, _ := extractClusterAndNetwork(params)
 	if clusterID == "" {
@@ -1193,23 +1261,7 @@ func (wh *Webhook) inject(ar *kube.AdmissionReview, path string) *kube.Admission
 		params.nativeSidecar = (features.EnableNativeSidecars == features.NativeSidecarModeEnabled)
 	}
 
-	wh.mu.RUnlock()
-
-	patchBytes, err := injectPod(params)
-	if err != nil {
-		handleError(log, fmt.Sprintf("Pod injection failed: %v", err))
-		return toAdmissionResponse(err)
-	}
-
-	reviewResponse := kube.AdmissionResponse{
-		Allowed: true,
-		Patch:   patchBytes,
-		PatchType: func() *string {
-			pt := "JSONPatch"
-			return &pt
-		}(),
-	}
-	return &reviewResponse
+	return params, true
 }
 
 func isSidecarUserMatchingAppUser(pod *corev1.Pod) bool {
@@ -38,6 +38,8 @@ const (
 	AddressType               = APITypePrefix + "istio.workload.Address"
 	WorkloadType              = APITypePrefix + "istio.workload.Workload"
 	WorkloadAuthorizationType = APITypePrefix + "istio.security.Authorization"
+	// CRLType distributes the certificate revocation lists of the istiod CA to agents, as PEM encoded bytes.
+	CRLType = "istio.io/crl"
 )
 
 // GetShortType returns an abbreviated form of a type, useful for logging or human friendly messages
@@ -57,6 +59,8 @@ func GetShortType(typeURL string) string {
 		return "NDS"
 	case ProxyConfigType:
 		return "PCDS"
+	case CRLType:
+		return "CRL"
 	case ExtensionConfigurationType:
 		return "ECDS"
 	case AddressType, WorkloadType:
@@ -85,6 +89,8 @@ func GetMetricType(typeURL string) string {
 		return "nds"
 	case ProxyConfigType:
 		return "pcds"
+	case CRLType:
+		return "crl"
 	case ExtensionConfigurationType:
 		return "ecds"
 	case BootstrapType:
@@ -116,6 +122,8 @@ func GetResourceType(shortType string) string {
 		return NameTableType
 	case "PCDS":
 		return ProxyConfigType
+	case "CRL":
+		return CRLType
 	case "ECDS":
 		return ExtensionConfigurationType
 	case "WDS":
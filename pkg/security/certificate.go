@@ -0,0 +1,176 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CertificateInfo summarizes a workload certificate, as reported by the certificate debug
+// endpoints of istiod and the istio-agent.
+type CertificateInfo struct {
+	SerialNumber string    `json:"serialNumber"`
+	SANs         []string  `json:"sans,omitempty"`
+	NotBefore    time.Time `json:"notBefore"`
+	NotAfter     time.Time `json:"notAfter"`
+	// RootFingerprint is the fingerprint of the root the certificate chains to, if it chains to a known root.
+	RootFingerprint string `json:"rootFingerprint,omitempty"`
+}
+
+// AgentCertificates are the workload certificate and trust bundle an agent serves over SDS.
+type AgentCertificates struct {
+	Workload         *CertificateInfo `json:"workload,omitempty"`
+	RootFingerprints []string         `json:"rootFingerprints,omitempty"`
+}
+
+// CertFingerprint returns the hex encoded SHA-256 fingerprint of a certificate.
+func CertFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// RootFingerprints returns the fingerprints of the certificates in a PEM encoded trust bundle.
+func RootFingerprints(rootsPEM []byte) []string {
+	var out []string
+	for _, c := range parseCertificates(rootsPEM) {
+		out = append(out, CertFingerprint(c))
+	}
+	return out
+}
+
+// SummarizeCertificate summarizes the leaf of a PEM encoded certificate chain, and finds which
+// of the PEM encoded roots it chains to.
+func SummarizeCertificate(chainPEM, rootsPEM []byte) (*CertificateInfo, error) {
+	chain := parseCertificates(chainPEM)
+	info, err := summarizeLeaf(chain)
+	if err != nil {
+		return nil, err
+	}
+	info.RootFingerprint = findRoot(chain, parseCertificates(rootsPEM))
+	return info, nil
+}
+
+// maxRootFinderIssuers bounds the number of issuers a RootFinder remembers.
+const maxRootFinderIssuers = 100
+
+// RootFinder summarizes the certificates issued by a CA. It remembers the root each issuer chains to, so
+// that a chain is verified once per issuer and trust bundle, rather than once per certificate.
+// The zero value is ready to use.
+type RootFinder struct {
+	mu      sync.Mutex
+	roots   string
+	issuers map[string]string
+}
+
+// Summarize is like SummarizeCertificate, but only verifies the chain if its issuer was not seen before.
+func (r *RootFinder) Summarize(chainPEM, rootsPEM []byte) (*CertificateInfo, error) {
+	chain := parseCertificates(chainPEM)
+	info, err := summarizeLeaf(chain)
+	if err != nil {
+		return nil, err
+	}
+	key := issuerKey(chain)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.issuers == nil || r.roots != string(rootsPEM) || len(r.issuers) >= maxRootFinderIssuers {
+		r.roots = string(rootsPEM)
+		r.issuers = map[string]string{}
+	}
+	fingerprint, f := r.issuers[key]
+	if !f {
+		fingerprint = findRoot(chain, parseCertificates(rootsPEM))
+		r.issuers[key] = fingerprint
+	}
+	info.RootFingerprint = fingerprint
+	return info, nil
+}
+
+// issuerKey identifies the issuer of the leaf of a certificate chain.
+func issuerKey(chain []*x509.Certificate) string {
+	var b strings.Builder
+	b.Write(chain[0].RawIssuer)
+	b.Write(chain[0].AuthorityKeyId)
+	for _, c := range chain[1:] {
+		b.Write(c.Raw)
+	}
+	return b.String()
+}
+
+func summarizeLeaf(chain []*x509.Certificate) (*CertificateInfo, error) {
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("no certificate found in the certificate chain")
+	}
+	leaf := chain[0]
+	info := &CertificateInfo{
+		SerialNumber: leaf.SerialNumber.Text(16),
+		NotBefore:    leaf.NotBefore,
+		NotAfter:     leaf.NotAfter,
+	}
+	for _, u := range leaf.URIs {
+		info.SANs = append(info.SANs, u.String())
+	}
+	info.SANs = append(info.SANs, leaf.DNSNames...)
+	return info, nil
+}
+
+// findRoot returns the fingerprint of the root the leaf of a certificate chain chains to, if any.
+func findRoot(chain []*x509.Certificate, roots []*x509.Certificate) string {
+	leaf := chain[0]
+	intermediates := x509.NewCertPool()
+	for _, c := range chain[1:] {
+		intermediates.AddCert(c)
+	}
+	for _, root := range roots {
+		pool := x509.NewCertPool()
+		pool.AddCert(root)
+		_, err := leaf.Verify(x509.VerifyOptions{
+			Roots:         pool,
+			Intermediates: intermediates,
+			// Find the root of expired certificates as well.
+			CurrentTime: leaf.NotBefore,
+			KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		})
+		if err == nil {
+			return CertFingerprint(root)
+		}
+	}
+	return ""
+}
+
+func parseCertificates(pemBytes []byte) []*x509.Certificate {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, pemBytes = pem.Decode(pemBytes)
+		if block == nil {
+			return certs
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		certs = append(certs, c)
+	}
+}
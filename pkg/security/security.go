@@ -16,6 +16,7 @@ package security
 
 import (
 	"context"
+	"crypto"
 	"fmt"
 	"net/http"
 	"os"
@@ -28,6 +29,7 @@ import (
 
 	"istio.io/istio/pkg/env"
 	istiolog "istio.io/istio/pkg/log"
+	"istio.io/istio/security/pkg/pki/util"
 )
 
 var securityLog = istiolog.RegisterScope("security", "security debugging")
@@ -114,6 +116,15 @@ const (
 	// Mock is Credential fetcher type of mock plugin
 	Mock = "Mock" // testing only
 
+	// InMemoryKeyProvider is the key provider type generating workload private keys in the agent memory
+	InMemoryKeyProvider = "memory"
+
+	// KeystoreKeyProvider is the key provider type keeping workload private keys in an encrypted keystore on disk
+	KeystoreKeyProvider = "keystore"
+
+	// ExternalKeyProvider is the key provider type delegating workload private keys to a signer process
+	ExternalKeyProvider = "external"
+
 	// GoogleCAProvider uses the Google CA for workload certificate signing
 	GoogleCAProvider = "GoogleCA"
 
@@ -241,6 +252,9 @@ type Options struct {
 	// credential fetcher.
 	CredFetcher CredFetcher
 
+	// KeyProvider creates the private keys of workload certificates. If nil, keys are generated in memory.
+	KeyProvider KeyProvider
+
 	// credential identity provider
 	CredIdentityProvider string
 
@@ -303,6 +317,10 @@ type SecretItem struct {
 
 	RootCert []byte
 
+	// CRL holds the PEM encoded certificate revocation lists distributed by istiod. It is only set
+	// on the root certificate.
+	CRL []byte
+
 	// ResourceName passed from envoy SDS discovery request.
 	// "ROOTCA" for root cert request, "default" for key/cert request.
 	ResourceName string
@@ -310,6 +328,41 @@ type SecretItem struct {
 	CreatedTime time.Time
 
 	ExpireTime time.Time
+
+	// ExternalPrivateKey identifies the private key when it cannot leave its key provider,
+	// in which case PrivateKey is empty.
+	ExternalPrivateKey *ExternalPrivateKey
+}
+
+// KeyProvider creates and holds the private keys of workload certificates.
+type KeyProvider interface {
+	// GenerateKey creates a private key of the key type in the options. Providers keep the keys of the
+	// current and previous certificates, older keys are deleted.
+	GenerateKey(options util.CertOptions) (PrivateKey, error)
+
+	// Close releases resources and cleans up.
+	Close()
+}
+
+// PrivateKey is a workload private key held by a KeyProvider.
+type PrivateKey interface {
+	crypto.Signer
+
+	// PEM returns the PEM encoded private key, or nil if the key cannot leave its provider.
+	PEM() ([]byte, error)
+
+	// External identifies the key to Envoy when it cannot leave its provider, or returns nil.
+	External() *ExternalPrivateKey
+}
+
+// ExternalPrivateKey identifies a private key held by an external signer, for an Envoy private key provider.
+type ExternalPrivateKey struct {
+	// ProviderName is the name of the Envoy private key provider which signs with the key.
+	ProviderName string
+	// SignerAddress is the address of the signer holding the key.
+	SignerAddress string
+	// KeyID identifies the key in the signer.
+	KeyID string
 }
 
 type CredFetcher interface {
@@ -329,6 +382,7 @@ type AuthSource int
 const (
 	AuthSourceClientCertificate AuthSource = iota
 	AuthSourceIDToken
+	AuthSourceAttestation
 )
 
 const (
@@ -370,6 +424,10 @@ type Caller struct {
 	Identities []string
 
 	KubernetesInfo KubernetesInfo
+
+	// Consume, if set, is called once the request authenticated by the caller succeeded, and fails the request
+	// if it fails. It consumes single use credentials, such as join tokens, only when they were used.
+	Consume func(ctx context.Context) error
 }
 
 // KubernetesInfo defines Kubernetes specific information extracted from the caller.
@@ -93,4 +93,5 @@ const (
 	reasonUnknownType          = "unknown_type"
 	reasonCRDConversionError   = "crd_conversion_error"
 	reasonInvalidConfig        = "invalid_resource"
+	reasonPolicyDenied         = "policy_denied"
 )
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sync/atomic"
 
 	multierror "github.com/hashicorp/go-multierror"
 	admissionv1 "k8s.io/api/admission/v1"
@@ -33,6 +34,7 @@ import (
 	"istio.io/istio/pkg/config/schema/collection"
 	"istio.io/istio/pkg/config/schema/resource"
 	"istio.io/istio/pkg/config/validation"
+	"istio.io/istio/pkg/config/validation/policy"
 	"istio.io/istio/pkg/kube"
 	"istio.io/istio/pkg/log"
 )
@@ -100,6 +102,9 @@ type Webhook struct {
 	// pilot
 	schemas      collection.Schemas
 	domainSuffix string
+
+	// policy holds the organization policy enforced on top of the validation of the resources, if any.
+	policy atomic.Pointer[policy.Policy]
 }
 
 // New creates a new instance of the admission webhook server.
@@ -119,6 +124,11 @@ func New(o Options) (*Webhook, error) {
 	return wh, nil
 }
 
+// SetPolicy sets the organization policy enforced on the admitted resources. A nil policy enforces no rules.
+func (wh *Webhook) SetPolicy(p *policy.Policy) {
+	wh.policy.Store(p)
+}
+
 func toAdmissionResponse(err error) *kube.AdmissionResponse {
 	return &kube.AdmissionResponse{Result: &metav1.Status{Message: err.Error()}}
 }
@@ -250,6 +260,16 @@ func (wh *Webhook) validate(request *kube.AdmissionRequest) *kube.AdmissionRespo
 		return toAdmissionResponse(err)
 	}
 
+	policyWarnings, err := wh.policy.Load().Evaluate(*out)
+	if err != nil {
+		scope.Infof("configuration is denied by policy: %v", addDryRunMessageIfNeeded(err.Error()))
+		reportValidationFailed(request, reasonPolicyDenied, isDryRun)
+		return toAdmissionResponse(fmt.Errorf("configuration is denied by policy: %v", err))
+	}
+	if policyWarnings != nil {
+		warnings = multierror.Append(warnings, policyWarnings)
+	}
+
 	reportValidationPass(request)
 	return &kube.AdmissionResponse{Allowed: true, Warnings: toKubeWarnings(warnings)}
 }
@@ -33,8 +33,10 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"istio.io/istio/pkg/config/schema/collections"
+	"istio.io/istio/pkg/config/validation/policy"
 	"istio.io/istio/pkg/kube"
 	"istio.io/istio/pkg/test/config"
+	"istio.io/istio/pkg/test/util/assert"
 	"istio.io/istio/pkg/testcerts"
 )
 
@@ -208,6 +210,51 @@ func TestAdmitPilot(t *testing.T) {
 	}
 }
 
+func TestAdmitPilotPolicy(t *testing.T) {
+	valid := makePilotConfig(t, 0, true, false)
+	request := &kube.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Kind: collections.Mock.Kind()},
+		Object:    runtime.RawExtension{Raw: valid},
+		Operation: kube.Create,
+	}
+	wh := createTestWebhook(t)
+
+	p, err := policy.New(policy.Rule{
+		Name:      "value",
+		Kinds:     []string{collections.Mock.Kind()},
+		Condition: `resource.spec.pairs.all(p, p.value != "0")`,
+		Message:   "value 0 is forbidden",
+	}, policy.Rule{
+		Name:      "label",
+		Condition: `"team" in resource.metadata.labels`,
+		Action:    policy.Warn,
+		Message:   "resources should have a team label",
+	})
+	assert.NoError(t, err)
+	wh.SetPolicy(p)
+	got := wh.validate(request)
+	assert.Equal(t, got.Allowed, false)
+	assert.Equal(t, got.Result.Message,
+		"configuration is denied by policy: 1 error occurred:\n\t* policy rule value: value 0 is forbidden\n\n")
+
+	p, err = policy.New(policy.Rule{
+		Name:      "label",
+		Condition: `"team" in resource.metadata.labels`,
+		Action:    policy.Warn,
+		Message:   "resources should have a team label",
+	})
+	assert.NoError(t, err)
+	wh.SetPolicy(p)
+	got = wh.validate(request)
+	assert.Equal(t, got.Allowed, true)
+	assert.Equal(t, got.Warnings, []string{"policy rule label: resources should have a team label"})
+
+	wh.SetPolicy(nil)
+	got = wh.validate(request)
+	assert.Equal(t, got.Allowed, true)
+	assert.Equal(t, len(got.Warnings), 0)
+}
+
 func makeTestReview(t *testing.T, valid bool, apiVersion string) []byte {
 	t.Helper()
 	review := admissionv1.AdmissionReview{
@@ -0,0 +1,257 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chiron
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/kube/controllers"
+	"istio.io/istio/pkg/kube/kclient"
+	"istio.io/istio/pkg/log"
+	"istio.io/istio/pkg/slices"
+	"istio.io/istio/pkg/util/sets"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+const (
+	// ServiceCertSecretAnnotation on a Service names the Secret, in the namespace of the Service, provisioned
+	// with a serving certificate for the DNS names of the Service.
+	ServiceCertSecretAnnotation = "security.istio.io/dns-cert-secret"
+
+	// ServiceCertDNSNamesAnnotation on a Service lists extra DNS names of the certificate, comma separated.
+	// They must be within the DNS domain of the Service namespace.
+	ServiceCertDNSNamesAnnotation = "security.istio.io/dns-cert-names"
+
+	// serviceCertLabel marks the Secrets provisioned by the ServiceCertController, with the name of their Service.
+	serviceCertLabel = "security.istio.io/dns-cert-service"
+
+	// CACertKey is the Secret key holding the root certificate of the mesh.
+	CACertKey = "ca.crt"
+
+	serviceCertMaxRetries = 5
+)
+
+var serviceCertLog = log.RegisterScope("servicecert", "service DNS certificate controller debugging")
+
+// CertIssuer issues certificates from the mesh CA.
+type CertIssuer interface {
+	GenKeyCert(hostnames []string, certTTL time.Duration, checkLifetime bool) ([]byte, []byte, error)
+	GetCAKeyCertBundle() *util.KeyCertBundle
+}
+
+// ServiceCertOptions configures the ServiceCertController.
+type ServiceCertOptions struct {
+	DomainSuffix string
+	CertTTL      time.Duration
+	// GracePeriodRatio is the ratio of the certificate lifetime left when it is renewed.
+	GracePeriodRatio float64
+	// ResyncPeriod is how often all certificates are checked for renewal and root changes.
+	ResyncPeriod time.Duration
+}
+
+// ServiceCertController provisions serving certificates from the mesh CA into Secrets, for the Services
+// annotated with ServiceCertSecretAnnotation, and renews them. This allows components outside of the mesh,
+// such as webhooks or databases, to get certificates from the mesh trust domain. The Secrets are owned by
+// their Service, so they are deleted with it.
+type ServiceCertController struct {
+	issuer CertIssuer
+	opts   ServiceCertOptions
+	now    func() time.Time
+
+	queue    controllers.Queue
+	services kclient.Client[*corev1.Service]
+	secrets  kclient.Client[*corev1.Secret]
+}
+
+// NewServiceCertController creates a ServiceCertController.
+func NewServiceCertController(client kube.Client, issuer CertIssuer, opts ServiceCertOptions) *ServiceCertController {
+	c := &ServiceCertController{
+		issuer: issuer,
+		opts:   opts,
+		now:    time.Now,
+	}
+	c.queue = controllers.NewQueue("service cert controller",
+		controllers.WithReconciler(c.reconcile),
+		controllers.WithMaxAttempts(serviceCertMaxRetries))
+	c.services = kclient.NewFiltered[*corev1.Service](client, kclient.Filter{ObjectFilter: client.ObjectFilter()})
+	c.secrets = kclient.NewFiltered[*corev1.Secret](client, kclient.Filter{
+		LabelSelector: serviceCertLabel,
+		ObjectFilter:  client.ObjectFilter(),
+	})
+	c.services.AddEventHandler(controllers.FilteredObjectHandler(c.queue.AddObject, func(o controllers.Object) bool {
+		// Services without the annotation are enqueued too, to clean up when it is removed.
+		return o.GetAnnotations()[ServiceCertSecretAnnotation] != "" || len(c.managedSecrets(o.GetNamespace(), o.GetName())) > 0
+	}))
+	c.secrets.AddEventHandler(controllers.ObjectHandler(func(o controllers.Object) {
+		c.queue.Add(types.NamespacedName{Namespace: o.GetNamespace(), Name: o.GetLabels()[serviceCertLabel]})
+	}))
+	return c
+}
+
+// Run starts the controller until stop is closed.
+func (c *ServiceCertController) Run(stop <-chan struct{}) {
+	if !kube.WaitForCacheSync("service cert controller", stop, c.services.HasSynced, c.secrets.HasSynced) {
+		c.queue.ShutDownEarly()
+		return
+	}
+	go c.resync(stop)
+	c.queue.Run(stop)
+	controllers.ShutdownAll(c.services, c.secrets)
+}
+
+// resync periodically enqueues all annotated Services, to renew their certificates before they expire
+// and when the root certificate changes.
+func (c *ServiceCertController) resync(stop <-chan struct{}) {
+	ticker := time.NewTicker(c.opts.ResyncPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, svc := range c.services.List(metav1.NamespaceAll, klabels.Everything()) {
+				if svc.Annotations[ServiceCertSecretAnnotation] != "" {
+					c.queue.AddObject(svc)
+				}
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (c *ServiceCertController) reconcile(key types.NamespacedName) error {
+	svc := c.services.Get(key.Name, key.Namespace)
+	secretName := ""
+	if svc != nil {
+		secretName = svc.Annotations[ServiceCertSecretAnnotation]
+	}
+	// Delete the Secrets no longer requested by the Service.
+	for _, s := range c.managedSecrets(key.Namespace, key.Name) {
+		if s.Name != secretName {
+			serviceCertLog.Infof("deleting certificate secret %s/%s of service %s", s.Namespace, s.Name, key.Name)
+			if err := c.secrets.Delete(s.Name, s.Namespace); err != nil && !kerrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+	if secretName == "" {
+		return nil
+	}
+
+	dnsNames, err := c.dnsNames(svc)
+	if err != nil {
+		// Retrying does not help until the Service changes.
+		serviceCertLog.Warnf("service %s: %v", key, err)
+		return nil
+	}
+	existing := c.secrets.Get(secretName, key.Namespace)
+	if existing != nil && existing.Labels[serviceCertLabel] != svc.Name {
+		serviceCertLog.Warnf("service %s: secret %s is managed for service %s", key, secretName, existing.Labels[serviceCertLabel])
+		return nil
+	}
+	rootCert := c.issuer.GetCAKeyCertBundle().GetRootCertPem()
+	if existing != nil && !c.needsRenewal(existing, dnsNames, rootCert) {
+		return nil
+	}
+
+	certChain, keyPEM, err := c.issuer.GenKeyCert(dnsNames, c.opts.CertTTL, false)
+	if err != nil {
+		return fmt.Errorf("failed to issue certificate for service %s: %v", key, err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: key.Namespace,
+			Labels:    map[string]string{serviceCertLabel: svc.Name},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "v1",
+				Kind:       "Service",
+				Name:       svc.Name,
+				UID:        svc.UID,
+			}},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       certChain,
+			corev1.TLSPrivateKeyKey: keyPEM,
+			CACertKey:               rootCert,
+		},
+	}
+	if existing == nil {
+		serviceCertLog.Infof("issuing certificate for %v into secret %s/%s", dnsNames, key.Namespace, secretName)
+		_, err = c.secrets.Create(secret)
+		if kerrors.IsAlreadyExists(err) {
+			// Only the managed Secrets are watched, others are never overwritten.
+			serviceCertLog.Warnf("service %s: secret %s is not managed by istiod, not overwriting it", key, secretName)
+			return nil
+		}
+	} else {
+		serviceCertLog.Infof("renewing certificate for %v in secret %s/%s", dnsNames, key.Namespace, secretName)
+		secret.ResourceVersion = existing.ResourceVersion
+		_, err = c.secrets.Update(secret)
+	}
+	return err
+}
+
+// dnsNames returns the DNS names of the certificate of the Service. Only the names within the cluster domain are
+// issued: the bare name and name.namespace resolve through search paths, and could be owned outside of the cluster.
+func (c *ServiceCertController) dnsNames(svc *corev1.Service) ([]string, error) {
+	base := svc.Name + "." + svc.Namespace + ".svc"
+	names := []string{base, base + "." + c.opts.DomainSuffix}
+	namespaceDomains := []string{"." + svc.Namespace + ".svc", "." + svc.Namespace + ".svc." + c.opts.DomainSuffix}
+	for _, name := range strings.Split(svc.Annotations[ServiceCertDNSNamesAnnotation], ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		// Extra names are restricted to the namespace, so a Service cannot get a certificate for another namespace.
+		if slices.FindFunc(namespaceDomains, func(d string) bool { return strings.HasSuffix(name, d) }) == nil {
+			return nil, fmt.Errorf("DNS name %q is not within the domains %v of the namespace", name, namespaceDomains)
+		}
+		names = append(names, name)
+	}
+	return sets.SortedList(sets.New(names...)), nil
+}
+
+// needsRenewal returns true if the certificate in the Secret is invalid, does not match the DNS names or the
+// root certificate, or is within its grace period.
+func (c *ServiceCertController) needsRenewal(secret *corev1.Secret, dnsNames []string, rootCert []byte) bool {
+	if !bytes.Equal(secret.Data[CACertKey], rootCert) || len(secret.Data[corev1.TLSPrivateKeyKey]) == 0 {
+		return true
+	}
+	cert, err := util.ParsePemEncodedCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return true
+	}
+	if !slices.Equal(sets.SortedList(sets.New(cert.DNSNames...)), dnsNames) {
+		return true
+	}
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	grace := time.Duration(c.opts.GracePeriodRatio * float64(lifetime))
+	return c.now().After(cert.NotAfter.Add(-grace))
+}
+
+func (c *ServiceCertController) managedSecrets(namespace, service string) []*corev1.Secret {
+	return c.secrets.List(namespace, klabels.SelectorFromSet(map[string]string{serviceCertLabel: service}))
+}
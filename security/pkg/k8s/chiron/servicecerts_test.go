@@ -0,0 +1,244 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chiron
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/kube/kclient/clienttest"
+	"istio.io/istio/pkg/test"
+	"istio.io/istio/pkg/test/util/assert"
+	"istio.io/istio/pkg/test/util/retry"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+// fakeIssuer issues certificates from a self-signed root, which can be rotated.
+type fakeIssuer struct {
+	mu     sync.Mutex
+	bundle *util.KeyCertBundle
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	f := &fakeIssuer{}
+	f.rotate(t)
+	return f
+}
+
+func (f *fakeIssuer) rotate(t *testing.T) {
+	t.Helper()
+	rootPEM, keyPEM, err := util.GenCertKeyFromOptions(util.CertOptions{
+		Org:          "cluster.local",
+		IsCA:         true,
+		IsSelfSigned: true,
+		TTL:          24 * time.Hour,
+		RSAKeySize:   2048,
+	})
+	assert.NoError(t, err)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.bundle = util.NewKeyCertBundleFromPem(rootPEM, keyPEM, nil, rootPEM, nil)
+}
+
+func (f *fakeIssuer) GenKeyCert(hostnames []string, certTTL time.Duration, _ bool) ([]byte, []byte, error) {
+	bundle := f.GetCAKeyCertBundle()
+	cert, key, _, _ := bundle.GetAll()
+	return util.GenCertKeyFromOptions(util.CertOptions{
+		Host:       strings.Join(hostnames, ","),
+		TTL:        certTTL,
+		RSAKeySize: 2048,
+		SignerCert: cert,
+		SignerPriv: *key,
+	})
+}
+
+func (f *fakeIssuer) GetCAKeyCertBundle() *util.KeyCertBundle {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.bundle
+}
+
+func setupServiceCerts(t *testing.T, issuer CertIssuer) (kube.Client, clienttest.TestClient[*corev1.Service], clienttest.TestClient[*corev1.Secret]) {
+	client := kube.NewFakeClient()
+	t.Cleanup(client.Shutdown)
+	stop := test.NewStop(t)
+	c := NewServiceCertController(client, issuer, ServiceCertOptions{
+		DomainSuffix:     "cluster.local",
+		CertTTL:          time.Hour,
+		GracePeriodRatio: 0.5,
+		ResyncPeriod:     50 * time.Millisecond,
+	})
+	client.RunAndWait(stop)
+	go c.Run(stop)
+	retry.UntilOrFail(t, c.queue.HasSynced)
+	return client, clienttest.NewDirectClient[*corev1.Service, corev1.Service, *corev1.ServiceList](t, client),
+		clienttest.NewDirectClient[*corev1.Secret, corev1.Secret, *corev1.SecretList](t, client)
+}
+
+func annotatedService(name string, annotations map[string]string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "db", UID: types.UID("uid-" + name), Annotations: annotations},
+	}
+}
+
+func TestServiceCertController(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	_, services, secrets := setupServiceCerts(t, issuer)
+
+	services.Create(annotatedService("postgres", map[string]string{
+		ServiceCertSecretAnnotation:   "postgres-tls",
+		ServiceCertDNSNamesAnnotation: "postgres-0.postgres.db.svc.cluster.local",
+	}))
+	var leaf []byte
+	retry.UntilSuccessOrFail(t, func() error {
+		s := secrets.Get("postgres-tls", "db")
+		if s == nil {
+			return fmt.Errorf("secret not created")
+		}
+		leaf = s.Data[corev1.TLSCertKey]
+		return nil
+	}, retry.Timeout(5*time.Second))
+	s := secrets.Get("postgres-tls", "db")
+	assert.Equal(t, s.Type, corev1.SecretTypeTLS)
+	assert.Equal(t, s.OwnerReferences[0].Name, "postgres")
+	assert.Equal(t, s.Data[CACertKey], issuer.GetCAKeyCertBundle().GetRootCertPem())
+	cert, err := util.ParsePemEncodedCertificate(leaf)
+	assert.NoError(t, err)
+	assert.Equal(t, cert.DNSNames, []string{
+		"postgres-0.postgres.db.svc.cluster.local",
+		"postgres.db.svc",
+		"postgres.db.svc.cluster.local",
+	})
+	_, err = util.ParsePemEncodedKey(s.Data[corev1.TLSPrivateKeyKey])
+	assert.NoError(t, err)
+
+	// Certificates are reissued when the root changes.
+	issuer.rotate(t)
+	retry.UntilSuccessOrFail(t, func() error {
+		s := secrets.Get("postgres-tls", "db")
+		if !bytes.Equal(s.Data[CACertKey], issuer.GetCAKeyCertBundle().GetRootCertPem()) {
+			return fmt.Errorf("root not updated")
+		}
+		if bytes.Equal(s.Data[corev1.TLSCertKey], leaf) {
+			return fmt.Errorf("certificate not reissued")
+		}
+		return nil
+	}, retry.Timeout(5*time.Second))
+
+	// The Secret is deleted when the annotation is removed.
+	services.Update(annotatedService("postgres", nil))
+	retry.UntilSuccessOrFail(t, func() error {
+		if secrets.Get("postgres-tls", "db") != nil {
+			return fmt.Errorf("secret not deleted")
+		}
+		return nil
+	}, retry.Timeout(5*time.Second))
+}
+
+func TestServiceCertControllerRejected(t *testing.T) {
+	_, services, secrets := setupServiceCerts(t, newFakeIssuer(t))
+
+	// Secrets not managed by the controller are never overwritten.
+	secrets.Create(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "db"},
+		Data:       map[string][]byte{"key": []byte("value")},
+	})
+	services.Create(annotatedService("existing", map[string]string{ServiceCertSecretAnnotation: "existing"}))
+	// DNS names outside of the namespace are rejected.
+	services.Create(annotatedService("impersonate", map[string]string{
+		ServiceCertSecretAnnotation:   "impersonate-tls",
+		ServiceCertDNSNamesAnnotation: "istiod.istio-system.svc",
+	}))
+	// A valid Service is processed after the others.
+	services.Create(annotatedService("valid", map[string]string{ServiceCertSecretAnnotation: "valid-tls"}))
+	retry.UntilSuccessOrFail(t, func() error {
+		if secrets.Get("valid-tls", "db") == nil {
+			return fmt.Errorf("secret not created")
+		}
+		return nil
+	}, retry.Timeout(5*time.Second))
+	assert.Equal(t, secrets.Get("existing", "db").Data, map[string][]byte{"key": []byte("value")})
+	assert.Equal(t, secrets.Get("impersonate-tls", "db"), nil)
+}
+
+func TestServiceCertDNSNames(t *testing.T) {
+	c := &ServiceCertController{opts: ServiceCertOptions{DomainSuffix: "cluster.local"}}
+	cases := []struct {
+		name     string
+		extra    string
+		expected []string
+		err      bool
+	}{
+		{
+			name:     "service names",
+			expected: []string{"api.db.svc", "api.db.svc.cluster.local"},
+		},
+		{
+			name:     "extra names",
+			extra:    "api-0.api.db.svc.cluster.local, api.db.svc",
+			expected: []string{"api-0.api.db.svc.cluster.local", "api.db.svc", "api.db.svc.cluster.local"},
+		},
+		{
+			name:  "short name",
+			extra: "api.db",
+			err:   true,
+		},
+		{
+			name:  "other namespace",
+			extra: "api.other.svc.cluster.local",
+			err:   true,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			names, err := c.dnsNames(annotatedService("api", map[string]string{ServiceCertDNSNamesAnnotation: tt.extra}))
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, names, tt.expected)
+		})
+	}
+}
+
+func TestServiceCertNeedsRenewal(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	c := &ServiceCertController{issuer: issuer, opts: ServiceCertOptions{GracePeriodRatio: 0.5}, now: time.Now}
+	dnsNames := []string{"a.db.svc", "a.db.svc.cluster.local"}
+	certPEM, keyPEM, err := issuer.GenKeyCert(dnsNames, time.Hour, false)
+	assert.NoError(t, err)
+	root := issuer.GetCAKeyCertBundle().GetRootCertPem()
+	secret := &corev1.Secret{Data: map[string][]byte{
+		corev1.TLSCertKey:       certPEM,
+		corev1.TLSPrivateKeyKey: keyPEM,
+		CACertKey:               root,
+	}}
+
+	assert.Equal(t, c.needsRenewal(secret, dnsNames, root), false)
+	assert.Equal(t, c.needsRenewal(secret, []string{"a.db.svc"}, root), true)
+	assert.Equal(t, c.needsRenewal(secret, dnsNames, []byte("other root")), true)
+	c.now = func() time.Time { return time.Now().Add(31 * time.Minute) }
+	assert.Equal(t, c.needsRenewal(secret, dnsNames, root), true)
+}
@@ -0,0 +1,206 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyprovider
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+// ExternalSignerProviderName is the default name of the Envoy private key provider signing over the external
+// signer protocol. Envoy does not ship such a provider, it must be built with one.
+const ExternalSignerProviderName = "istio.external_signer"
+
+const externalSignerTimeout = 10 * time.Second
+
+// The external signer serves JSON over HTTP on a unix domain socket:
+//
+//	POST   /v1/keys            GenerateKeyRequest -> GenerateKeyResponse
+//	POST   /v1/keys/{id}/sign  SignRequest -> SignResponse
+//	DELETE /v1/keys/{id}
+//
+// Errors are reported with a non 2xx status and a plain text body.
+
+// GenerateKeyRequest asks the external signer to generate a key.
+type GenerateKeyRequest struct {
+	// Algorithm is RSA or EC.
+	Algorithm  string `json:"algorithm"`
+	RSAKeySize int    `json:"rsaKeySize,omitempty"`
+	// Curve is P256 or P384.
+	Curve string `json:"curve,omitempty"`
+}
+
+// GenerateKeyResponse identifies a generated key.
+type GenerateKeyResponse struct {
+	ID string `json:"id"`
+	// PublicKey is the PKIX, ASN.1 DER encoded public key.
+	PublicKey []byte `json:"publicKey"`
+}
+
+// SignRequest asks the external signer to sign a digest.
+type SignRequest struct {
+	Digest []byte `json:"digest"`
+	// Hash names the hash function of the digest, as crypto.Hash.String.
+	Hash string `json:"hash"`
+	// PSS requests a RSA-PSS signature, rather than PKCS #1 v1.5.
+	PSS        bool `json:"pss,omitempty"`
+	SaltLength int  `json:"saltLength,omitempty"`
+}
+
+// SignResponse holds a signature.
+type SignResponse struct {
+	Signature []byte `json:"signature"`
+}
+
+// ExternalSigner keeps private keys in a separate process, such as a TPM daemon, reached over a unix
+// domain socket. Private keys never enter the agent: Envoy signs with them through its private key
+// provider, given the signer address and the key ID.
+type ExternalSigner struct {
+	socket        string
+	envoyProvider string
+	client        *http.Client
+	ring          keyRing
+}
+
+// NewExternalSigner creates a key provider for the external signer listening on socket. envoyProvider is the
+// name of the Envoy private key provider signing with the keys of the signer.
+func NewExternalSigner(socket, envoyProvider string) *ExternalSigner {
+	return &ExternalSigner{
+		socket:        socket,
+		envoyProvider: envoyProvider,
+		client: &http.Client{
+			Timeout: externalSignerTimeout,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socket)
+				},
+			},
+		},
+	}
+}
+
+func (e *ExternalSigner) GenerateKey(options util.CertOptions) (security.PrivateKey, error) {
+	req := GenerateKeyRequest{Algorithm: "RSA", RSAKeySize: options.RSAKeySize}
+	if options.ECSigAlg != "" {
+		if options.ECSigAlg != util.EcdsaSigAlg {
+			return nil, fmt.Errorf("cert options contain unsupported signature algorithm %s", options.ECSigAlg)
+		}
+		req = GenerateKeyRequest{Algorithm: "EC", Curve: string(util.P256Curve)}
+		if options.ECCCurve == util.P384Curve {
+			req.Curve = string(util.P384Curve)
+		}
+	}
+	var resp GenerateKeyResponse
+	if err := e.call(http.MethodPost, "/v1/keys", req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %v", err)
+	}
+	pub, err := x509.ParsePKIXPublicKey(resp.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("external signer returned an invalid public key: %v", err)
+	}
+	for _, expired := range e.ring.add(resp.ID) {
+		if err := e.call(http.MethodDelete, "/v1/keys/"+url.PathEscape(expired), nil, nil); err != nil {
+			keyLog.Warnf("failed to delete key %s from external signer: %v", expired, err)
+		}
+	}
+	return &externalKey{signer: e, id: resp.ID, public: pub}, nil
+}
+
+func (e *ExternalSigner) Close() {
+	e.client.CloseIdleConnections()
+}
+
+func (e *ExternalSigner) call(method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	// The host is ignored, requests are sent to the socket.
+	req, err := http.NewRequest(method, "http://signer"+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("external signer returned %d: %s", resp.StatusCode, bytes.TrimSpace(b))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(b, out)
+}
+
+type externalKey struct {
+	signer *ExternalSigner
+	id     string
+	public crypto.PublicKey
+}
+
+func (k *externalKey) Public() crypto.PublicKey {
+	return k.public
+}
+
+func (k *externalKey) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	req := SignRequest{Digest: digest, Hash: opts.HashFunc().String()}
+	if pss, ok := opts.(*rsa.PSSOptions); ok {
+		req.PSS = true
+		req.SaltLength = pss.SaltLength
+	}
+	var resp SignResponse
+	if err := k.signer.call(http.MethodPost, "/v1/keys/"+url.PathEscape(k.id)+"/sign", req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to sign with key %s: %v", k.id, err)
+	}
+	return resp.Signature, nil
+}
+
+// PEM returns nil, the key cannot leave the external signer.
+func (k *externalKey) PEM() ([]byte, error) {
+	return nil, nil
+}
+
+func (k *externalKey) External() *security.ExternalPrivateKey {
+	return &security.ExternalPrivateKey{
+		ProviderName:  k.signer.envoyProvider,
+		SignerAddress: k.signer.socket,
+		KeyID:         k.id,
+	}
+}
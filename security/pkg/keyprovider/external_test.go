@@ -0,0 +1,110 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyprovider
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"path/filepath"
+	"testing"
+
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/pkg/test/util/assert"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+// startStubSigner runs a stub external signer, keeping keys in memory.
+func startStubSigner(t *testing.T) (*memoryKeys, string) {
+	t.Helper()
+	keys := NewMemoryKeys().(*memoryKeys)
+	socket := filepath.Join(t.TempDir(), "signer.sock")
+	s, err := NewSignerServer(socket, keys)
+	assert.NoError(t, err)
+	t.Cleanup(s.Close)
+	return keys, socket
+}
+
+func (m *memoryKeys) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.keys)
+}
+
+func TestExternalSigner(t *testing.T) {
+	stub, socket := startStubSigner(t)
+	p, err := NewKeyProvider(Options{Type: security.ExternalKeyProvider, SignerSocket: socket})
+	assert.NoError(t, err)
+	defer p.Close()
+
+	key, err := p.GenerateKey(util.CertOptions{RSAKeySize: 2048})
+	assert.NoError(t, err)
+	verifySignature(t, key)
+	assert.Equal(t, key.External(), &security.ExternalPrivateKey{
+		ProviderName:  ExternalSignerProviderName,
+		SignerAddress: socket,
+		KeyID:         "key-1",
+	})
+	// The key never leaves the signer.
+	keyPEM, err := key.PEM()
+	assert.NoError(t, err)
+	assert.Equal(t, keyPEM, nil)
+
+	// A CSR signed by the external key is valid.
+	csrPEM, err := util.GenCSRWithSigner(util.CertOptions{Host: "spiffe://cluster.local/ns/foo/sa/bar"}, key)
+	assert.NoError(t, err)
+	csr, err := util.ParsePemEncodedCSR(csrPEM)
+	assert.NoError(t, err)
+	assert.NoError(t, csr.CheckSignature())
+
+	ec, err := p.GenerateKey(util.CertOptions{ECSigAlg: util.EcdsaSigAlg, ECCCurve: util.P384Curve})
+	assert.NoError(t, err)
+	if pub, ok := ec.Public().(*ecdsa.PublicKey); !ok || pub.Curve != elliptic.P384() {
+		t.Fatalf("expected a P384 key, got %v", ec.Public())
+	}
+	verifySignature(t, ec)
+
+	// Only the current and previous keys are kept.
+	assert.Equal(t, stub.count(), 2)
+	_, err = p.GenerateKey(util.CertOptions{ECSigAlg: util.EcdsaSigAlg})
+	assert.NoError(t, err)
+	assert.Equal(t, stub.count(), 2)
+	if stub.Key("key-1") != nil {
+		t.Fatalf("expected the oldest key to be deleted")
+	}
+	_, err = key.Sign(nil, make([]byte, 32), crypto.SHA256)
+	assert.Error(t, err)
+}
+
+func TestExternalSignerPSS(t *testing.T) {
+	_, socket := startStubSigner(t)
+	p := NewExternalSigner(socket, ExternalSignerProviderName)
+	defer p.Close()
+	key, err := p.GenerateKey(util.CertOptions{RSAKeySize: 2048})
+	assert.NoError(t, err)
+	digest := make([]byte, 32)
+	opts := &rsa.PSSOptions{Hash: crypto.SHA256, SaltLength: rsa.PSSSaltLengthEqualsHash}
+	sig, err := key.Sign(nil, digest, opts)
+	assert.NoError(t, err)
+	assert.NoError(t, rsa.VerifyPSS(key.Public().(*rsa.PublicKey), crypto.SHA256, digest, sig, opts))
+}
+
+func TestExternalSignerUnavailable(t *testing.T) {
+	p := NewExternalSigner(filepath.Join(t.TempDir(), "missing.sock"), ExternalSignerProviderName)
+	defer p.Close()
+	_, err := p.GenerateKey(util.CertOptions{RSAKeySize: 2048})
+	assert.Error(t, err)
+}
@@ -0,0 +1,215 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyprovider
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+const (
+	keystoreFileSuffix = ".key"
+	// keystoreSocket is the name of the socket, in the keystore directory, Envoy signs with the keys on.
+	keystoreSocket = "signer.sock"
+)
+
+// Keystore keeps private keys in a directory, encrypted with AES-256-GCM. Keys are only decrypted in
+// memory while they sign, and never leave the agent: Envoy signs with them through its private key
+// provider, over a signer socket served by the keystore.
+type Keystore struct {
+	dir           string
+	aead          cipher.AEAD
+	envoyProvider string
+	ring          keyRing
+	server        *SignerServer
+
+	mu   sync.Mutex
+	keys map[string]*keystoreKey
+}
+
+var _ SignerKeys = &Keystore{}
+
+// NewKeystore creates a keystore in dir. keyFile holds the 32 byte key encrypting the private keys,
+// raw or base64 encoded. Keys left in the keystore by a previous run are deleted. envoyProvider is the name
+// of the Envoy private key provider signing over the signer socket of the keystore.
+func NewKeystore(dir, keyFile, envoyProvider string) (*Keystore, error) {
+	b, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore key: %v", err)
+	}
+	kek := b
+	if len(kek) != 32 {
+		if kek, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(b))); err != nil || len(kek) != 32 {
+			return nil, fmt.Errorf("keystore key %s must be 32 bytes, raw or base64 encoded", keyFile)
+		}
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create keystore: %v", err)
+	}
+	stale, _ := filepath.Glob(filepath.Join(dir, "*"+keystoreFileSuffix))
+	for _, f := range stale {
+		_ = os.Remove(f)
+	}
+	k := &Keystore{dir: dir, aead: aead, envoyProvider: envoyProvider, keys: map[string]*keystoreKey{}}
+	if k.server, err = NewSignerServer(k.socket(), k); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func (k *Keystore) GenerateKey(options util.CertOptions) (security.PrivateKey, error) {
+	priv, err := util.GenPrivateKey(options)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := util.EncodePrivateKeyPem(priv, options.PKCS8Key)
+	if err != nil {
+		return nil, err
+	}
+	id, err := keyID(priv.Public())
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	sealed := k.aead.Seal(nonce, nonce, keyPEM, []byte(id))
+	if err := os.WriteFile(k.path(id), sealed, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write key to keystore: %v", err)
+	}
+	key := &keystoreKey{keystore: k, id: id, public: priv.Public()}
+	k.mu.Lock()
+	k.keys[id] = key
+	k.mu.Unlock()
+	for _, expired := range k.ring.add(id) {
+		k.Delete(expired)
+	}
+	return key, nil
+}
+
+func (k *Keystore) Close() {
+	k.server.Close()
+}
+
+// Generate refuses to create keys for the signer socket, the keys of the keystore are generated by the agent.
+func (k *Keystore) Generate(GenerateKeyRequest) (string, crypto.Signer, error) {
+	return "", nil, fmt.Errorf("the keystore does not generate keys over its signer socket")
+}
+
+// Key returns the key with the given ID, if it is one of the current and previous keys.
+func (k *Keystore) Key(id string) crypto.Signer {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if key, f := k.keys[id]; f {
+		return key
+	}
+	return nil
+}
+
+// Delete removes a key from the keystore.
+func (k *Keystore) Delete(id string) {
+	k.mu.Lock()
+	delete(k.keys, id)
+	k.mu.Unlock()
+	if err := os.Remove(k.path(id)); err != nil && !os.IsNotExist(err) {
+		keyLog.Warnf("failed to delete key %s from keystore: %v", id, err)
+	}
+}
+
+func (k *Keystore) socket() string {
+	return filepath.Join(k.dir, keystoreSocket)
+}
+
+func (k *Keystore) path(id string) string {
+	return filepath.Join(k.dir, id+keystoreFileSuffix)
+}
+
+// load decrypts a key from the keystore.
+func (k *Keystore) load(id string) ([]byte, error) {
+	sealed, err := os.ReadFile(k.path(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %s from keystore: %v", id, err)
+	}
+	n := k.aead.NonceSize()
+	if len(sealed) < n {
+		return nil, fmt.Errorf("key %s in keystore is corrupted", id)
+	}
+	keyPEM, err := k.aead.Open(nil, sealed[:n], sealed[n:], []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt key %s: %v", id, err)
+	}
+	return keyPEM, nil
+}
+
+type keystoreKey struct {
+	keystore *Keystore
+	id       string
+	public   crypto.PublicKey
+}
+
+func (k *keystoreKey) Public() crypto.PublicKey {
+	return k.public
+}
+
+func (k *keystoreKey) Sign(r io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	keyPEM, err := k.keystore.load(k.id)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := util.ParsePemEncodedKey(bytes.TrimSpace(keyPEM))
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("key %s cannot sign", k.id)
+	}
+	return signer.Sign(r, digest, opts)
+}
+
+// PEM returns nil, the key cannot leave the keystore.
+func (k *keystoreKey) PEM() ([]byte, error) {
+	return nil, nil
+}
+
+func (k *keystoreKey) External() *security.ExternalPrivateKey {
+	return &security.ExternalPrivateKey{
+		ProviderName:  k.keystore.envoyProvider,
+		SignerAddress: k.keystore.socket(),
+		KeyID:         k.id,
+	}
+}
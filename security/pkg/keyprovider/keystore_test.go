@@ -0,0 +1,137 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyprovider
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/pkg/test/util/assert"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+func writeKeystoreKey(t *testing.T, encode bool) string {
+	t.Helper()
+	kek := make([]byte, 32)
+	_, err := rand.Read(kek)
+	assert.NoError(t, err)
+	if encode {
+		kek = []byte(base64.StdEncoding.EncodeToString(kek) + "\n")
+	}
+	keyFile := filepath.Join(t.TempDir(), "kek")
+	assert.NoError(t, os.WriteFile(keyFile, kek, 0o600))
+	return keyFile
+}
+
+func keystoreFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*"+keystoreFileSuffix))
+	assert.NoError(t, err)
+	return files
+}
+
+// envoySigner signs with a key the way Envoy does, over the signer socket given in the SDS secret.
+func envoySigner(t *testing.T, key security.PrivateKey) security.PrivateKey {
+	t.Helper()
+	ext := key.External()
+	client := NewExternalSigner(ext.SignerAddress, ext.ProviderName)
+	t.Cleanup(client.Close)
+	return &externalKey{signer: client, id: ext.KeyID, public: key.Public()}
+}
+
+func TestKeystore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keystore")
+	assert.NoError(t, os.MkdirAll(dir, 0o700))
+	// Keys of a previous run are deleted.
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "stale"+keystoreFileSuffix), []byte("stale"), 0o600))
+	p, err := NewKeystore(dir, writeKeystoreKey(t, true), "envoy.signer")
+	assert.NoError(t, err)
+	defer p.Close()
+	assert.Equal(t, len(keystoreFiles(t, dir)), 0)
+
+	key, err := p.GenerateKey(util.CertOptions{RSAKeySize: 2048})
+	assert.NoError(t, err)
+	verifySignature(t, key)
+
+	// The key never leaves the keystore, Envoy signs with it over the signer socket.
+	keyPEM, err := key.PEM()
+	assert.NoError(t, err)
+	assert.Equal(t, keyPEM, nil)
+	ext := key.External()
+	assert.Equal(t, ext.ProviderName, "envoy.signer")
+	assert.Equal(t, ext.SignerAddress, filepath.Join(dir, keystoreSocket))
+	verifySignature(t, envoySigner(t, key))
+
+	// The key is encrypted at rest.
+	files := keystoreFiles(t, dir)
+	assert.Equal(t, len(files), 1)
+	sealed, err := os.ReadFile(files[0])
+	assert.NoError(t, err)
+	if bytes.Contains(sealed, []byte("PRIVATE KEY")) {
+		t.Fatalf("keystore holds a plaintext key")
+	}
+	keyPEM, err = p.load(ext.KeyID)
+	assert.NoError(t, err)
+	parsed, err := util.ParsePemEncodedKey(keyPEM)
+	assert.NoError(t, err)
+	if !parsed.(*rsa.PrivateKey).PublicKey.Equal(key.Public()) {
+		t.Fatalf("keystore returned another key")
+	}
+
+	// Only the current and previous keys are kept.
+	second, err := p.GenerateKey(util.CertOptions{ECSigAlg: util.EcdsaSigAlg})
+	assert.NoError(t, err)
+	verifySignature(t, envoySigner(t, second))
+	_, err = p.GenerateKey(util.CertOptions{ECSigAlg: util.EcdsaSigAlg})
+	assert.NoError(t, err)
+	assert.Equal(t, len(keystoreFiles(t, dir)), 2)
+	_, err = p.load(ext.KeyID)
+	assert.Error(t, err)
+	_, err = envoySigner(t, key).Sign(nil, make([]byte, 32), crypto.SHA256)
+	assert.Error(t, err)
+	verifySignature(t, envoySigner(t, second))
+}
+
+func TestKeystoreTampered(t *testing.T) {
+	dir := t.TempDir()
+	p, err := NewKeystore(dir, writeKeystoreKey(t, false), ExternalSignerProviderName)
+	assert.NoError(t, err)
+	defer p.Close()
+	key, err := p.GenerateKey(util.CertOptions{ECSigAlg: util.EcdsaSigAlg})
+	assert.NoError(t, err)
+	file := keystoreFiles(t, dir)[0]
+	sealed, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	sealed[len(sealed)-1] ^= 0xff
+	assert.NoError(t, os.WriteFile(file, sealed, 0o600))
+	_, err = key.Sign(nil, make([]byte, 32), crypto.SHA256)
+	assert.Error(t, err)
+}
+
+func TestKeystoreInvalidKey(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "kek")
+	assert.NoError(t, os.WriteFile(keyFile, []byte("too short"), 0o600))
+	_, err := NewKeystore(t.TempDir(), keyFile, ExternalSignerProviderName)
+	assert.Error(t, err)
+	_, err = NewKeystore(t.TempDir(), filepath.Join(t.TempDir(), "missing"), ExternalSignerProviderName)
+	assert.Error(t, err)
+}
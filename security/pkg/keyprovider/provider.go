@@ -0,0 +1,133 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package keyprovider creates and holds the private keys of workload certificates.
+package keyprovider
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"istio.io/istio/pkg/log"
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+var keyLog = log.RegisterScope("keyprovider", "workload key provider debugging")
+
+// Options configures a key provider.
+type Options struct {
+	// Type is the type of the key provider.
+	Type string
+	// KeystoreDir is the directory of the keystore key provider.
+	KeystoreDir string
+	// KeystoreKeyFile holds the key encrypting the keystore.
+	KeystoreKeyFile string
+	// SignerSocket is the socket of the signer of the external key provider.
+	SignerSocket string
+	// EnvoyProvider is the name of the Envoy private key provider signing with keys that cannot leave their
+	// key provider. Defaults to ExternalSignerProviderName.
+	EnvoyProvider string
+}
+
+// NewKeyProvider creates a key provider of the given type.
+func NewKeyProvider(opts Options) (security.KeyProvider, error) {
+	if opts.EnvoyProvider == "" {
+		opts.EnvoyProvider = ExternalSignerProviderName
+	}
+	switch opts.Type {
+	case security.InMemoryKeyProvider, "":
+		return NewInMemory(), nil
+	case security.KeystoreKeyProvider:
+		if opts.KeystoreKeyFile == "" {
+			return nil, fmt.Errorf("the %s key provider requires a keystore key file", opts.Type)
+		}
+		return NewKeystore(opts.KeystoreDir, opts.KeystoreKeyFile, opts.EnvoyProvider)
+	case security.ExternalKeyProvider:
+		if opts.SignerSocket == "" {
+			return nil, fmt.Errorf("the %s key provider requires a signer socket", opts.Type)
+		}
+		return NewExternalSigner(opts.SignerSocket, opts.EnvoyProvider), nil
+	default:
+		return nil, fmt.Errorf("invalid key provider type %s", opts.Type)
+	}
+}
+
+// inMemory generates private keys in process memory.
+type inMemory struct{}
+
+// NewInMemory creates a key provider generating private keys in process memory.
+func NewInMemory() security.KeyProvider {
+	return inMemory{}
+}
+
+func (inMemory) GenerateKey(options util.CertOptions) (security.PrivateKey, error) {
+	priv, err := util.GenPrivateKey(options)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := util.EncodePrivateKeyPem(priv, options.PKCS8Key)
+	if err != nil {
+		return nil, err
+	}
+	return &memoryKey{Signer: priv, pem: keyPEM}, nil
+}
+
+func (inMemory) Close() {}
+
+type memoryKey struct {
+	crypto.Signer
+	pem []byte
+}
+
+func (k *memoryKey) PEM() ([]byte, error) {
+	return k.pem, nil
+}
+
+func (k *memoryKey) External() *security.ExternalPrivateKey {
+	return nil
+}
+
+// keyRing tracks the keys of a provider, so all but the current and previous ones can be deleted.
+type keyRing struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+// add records a new key, and returns the keys to delete.
+func (r *keyRing) add(id string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ids = append(r.ids, id)
+	if len(r.ids) <= 2 {
+		return nil
+	}
+	expired := append([]string{}, r.ids[:len(r.ids)-2]...)
+	r.ids = append([]string{}, r.ids[len(r.ids)-2:]...)
+	return expired
+}
+
+// keyID identifies a key by the hash of its public key.
+func keyID(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:16]), nil
+}
@@ -0,0 +1,101 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyprovider
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"testing"
+
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/pkg/test/util/assert"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+func TestNewKeyProvider(t *testing.T) {
+	for _, providerType := range []string{"", security.InMemoryKeyProvider} {
+		p, err := NewKeyProvider(Options{Type: providerType})
+		assert.NoError(t, err)
+		assert.Equal(t, p, NewInMemory())
+	}
+	_, err := NewKeyProvider(Options{Type: security.KeystoreKeyProvider, KeystoreDir: t.TempDir()})
+	assert.Error(t, err)
+	_, err = NewKeyProvider(Options{Type: security.ExternalKeyProvider})
+	assert.Error(t, err)
+	_, err = NewKeyProvider(Options{Type: "tpm"})
+	assert.Error(t, err)
+
+	p, err := NewKeyProvider(Options{Type: security.ExternalKeyProvider, SignerSocket: "/var/run/signer.sock"})
+	assert.NoError(t, err)
+	defer p.Close()
+	assert.Equal(t, p.(*ExternalSigner).envoyProvider, ExternalSignerProviderName)
+}
+
+func TestInMemory(t *testing.T) {
+	p := NewInMemory()
+	defer p.Close()
+
+	key, err := p.GenerateKey(util.CertOptions{RSAKeySize: 2048})
+	assert.NoError(t, err)
+	if _, ok := key.Public().(*rsa.PublicKey); !ok {
+		t.Fatalf("expected a RSA key, got %T", key.Public())
+	}
+	assert.Equal(t, key.External(), nil)
+	keyPEM, err := key.PEM()
+	assert.NoError(t, err)
+	parsed, err := util.ParsePemEncodedKey(keyPEM)
+	assert.NoError(t, err)
+	assert.Equal(t, parsed.(*rsa.PrivateKey).Public(), key.Public())
+
+	key, err = p.GenerateKey(util.CertOptions{ECSigAlg: util.EcdsaSigAlg, ECCCurve: util.P384Curve, PKCS8Key: true})
+	assert.NoError(t, err)
+	if _, ok := key.Public().(*ecdsa.PublicKey); !ok {
+		t.Fatalf("expected an EC key, got %T", key.Public())
+	}
+	csrPEM, err := util.GenCSRWithSigner(util.CertOptions{Host: "spiffe://cluster.local/ns/foo/sa/bar"}, key)
+	assert.NoError(t, err)
+	csr, err := util.ParsePemEncodedCSR(csrPEM)
+	assert.NoError(t, err)
+	assert.NoError(t, csr.CheckSignature())
+}
+
+func TestKeyRing(t *testing.T) {
+	var r keyRing
+	assert.Equal(t, r.add("1"), nil)
+	assert.Equal(t, r.add("2"), nil)
+	assert.Equal(t, r.add("3"), []string{"1"})
+	assert.Equal(t, r.add("4"), []string{"2"})
+	assert.Equal(t, r.ids, []string{"3", "4"})
+}
+
+// verifySignature checks key signs with its public key.
+func verifySignature(t *testing.T, key security.PrivateKey) {
+	t.Helper()
+	digest := sha256.Sum256([]byte("hello"))
+	sig, err := key.Sign(nil, digest[:], crypto.SHA256)
+	assert.NoError(t, err)
+	switch pub := key.Public().(type) {
+	case *rsa.PublicKey:
+		assert.NoError(t, rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig))
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, digest[:], sig) {
+			t.Fatalf("invalid signature")
+		}
+	default:
+		t.Fatalf("unexpected key type %T", pub)
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyprovider
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// SignerKeys holds the keys served by a SignerServer.
+type SignerKeys interface {
+	// Generate creates a key, and returns its ID.
+	Generate(req GenerateKeyRequest) (string, crypto.Signer, error)
+	// Key returns the key with the given ID, or nil if there is none.
+	Key(id string) crypto.Signer
+	// Delete removes the key with the given ID.
+	Delete(id string)
+}
+
+// SignerServer serves the external signer protocol on a unix domain socket. The agent serves the keys of its
+// keystore with it, so Envoy signs with them through its private key provider rather than receiving them.
+type SignerServer struct {
+	keys   SignerKeys
+	server *http.Server
+}
+
+// NewSignerServer serves keys on socket, until the server is closed.
+func NewSignerServer(socket string, keys SignerKeys) (*SignerServer, error) {
+	if err := os.Remove(socket); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale signer socket: %v", err)
+	}
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on signer socket: %v", err)
+	}
+	s := &SignerServer{keys: keys}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/keys", s.generate)
+	mux.HandleFunc("POST /v1/keys/{id}/sign", s.sign)
+	mux.HandleFunc("DELETE /v1/keys/{id}", s.delete)
+	s.server = &http.Server{Handler: mux}
+	go func() {
+		if err := s.server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			keyLog.Errorf("signer server on %s failed: %v", socket, err)
+		}
+	}()
+	return s, nil
+}
+
+// Close stops serving keys.
+func (s *SignerServer) Close() {
+	_ = s.server.Close()
+}
+
+func (s *SignerServer) generate(w http.ResponseWriter, r *http.Request) {
+	var req GenerateKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id, key, err := s.keys.Generate(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, GenerateKeyResponse{ID: id, PublicKey: der})
+}
+
+func (s *SignerServer) sign(w http.ResponseWriter, r *http.Request) {
+	key := s.keys.Key(r.PathValue("id"))
+	if key == nil {
+		http.NotFound(w, r)
+		return
+	}
+	var req SignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	opts, err := signerOpts(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sig, err := key.Sign(rand.Reader, req.Digest, opts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, SignResponse{Signature: sig})
+}
+
+func (s *SignerServer) delete(_ http.ResponseWriter, r *http.Request) {
+	s.keys.Delete(r.PathValue("id"))
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// signerOpts returns the options to sign a request with.
+func signerOpts(req SignRequest) (crypto.SignerOpts, error) {
+	var hash crypto.Hash
+	switch req.Hash {
+	case crypto.SHA256.String():
+		hash = crypto.SHA256
+	case crypto.SHA384.String():
+		hash = crypto.SHA384
+	case crypto.SHA512.String():
+		hash = crypto.SHA512
+	default:
+		return nil, fmt.Errorf("unsupported hash %q", req.Hash)
+	}
+	if req.PSS {
+		return &rsa.PSSOptions{Hash: hash, SaltLength: req.SaltLength}, nil
+	}
+	return hash, nil
+}
+
+// memoryKeys holds the keys of a SignerServer in memory.
+type memoryKeys struct {
+	mu     sync.Mutex
+	keys   map[string]crypto.Signer
+	nextID int
+}
+
+// NewMemoryKeys creates signer keys held in memory, for a signer process without dedicated key storage.
+func NewMemoryKeys() SignerKeys {
+	return &memoryKeys{keys: map[string]crypto.Signer{}}
+}
+
+func (m *memoryKeys) Generate(req GenerateKeyRequest) (string, crypto.Signer, error) {
+	var key crypto.Signer
+	var err error
+	switch req.Algorithm {
+	case "RSA":
+		key, err = rsa.GenerateKey(rand.Reader, req.RSAKeySize)
+	case "EC":
+		curve := elliptic.P256()
+		if req.Curve == "P384" {
+			curve = elliptic.P384()
+		}
+		key, err = ecdsa.GenerateKey(curve, rand.Reader)
+	default:
+		err = fmt.Errorf("unsupported algorithm %q", req.Algorithm)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	id := "key-" + strconv.Itoa(m.nextID)
+	m.keys[id] = key
+	return id, key, nil
+}
+
+func (m *memoryKeys) Key(id string) crypto.Signer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.keys[id]
+}
+
+func (m *memoryKeys) Delete(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.keys, id)
+}
@@ -114,6 +114,10 @@ type SecretManagerClient struct {
 	// Dynamically configured Trust Bundle
 	configTrustBundle []byte
 
+	crlMutex sync.RWMutex
+	// Certificate revocation lists distributed by istiod
+	crl []byte
+
 	// queue maintains all certificate rotation events that need to be triggered when they are about to expire
 	queue queue.Delayed
 	stop  chan struct{}
@@ -226,6 +230,7 @@ func (sc *SecretManagerClient) getCachedSecret(resourceName string) (secret *sec
 			ns = &security.SecretItem{
 				ResourceName: resourceName,
 				RootCert:     rootCertBundle,
+				CRL:          sc.getCRL(),
 			}
 			cacheLog.WithLabels("ttl", time.Until(c.ExpireTime)).Info("returned workload trust anchor from cache")
 
@@ -308,6 +313,7 @@ func (sc *SecretManagerClient) GenerateSecret(resourceName string) (secret *secu
 
 	if resourceName == security.RootCertReqResourceName {
 		ns.RootCert = sc.mergeTrustAnchorBytes(ns.RootCert)
+		ns.CRL = sc.getCRL()
 	} else {
 		// If periodic cert refresh resulted in discovery of a new root, trigger a ROOTCA request to refresh trust anchor
 		oldRoot := sc.cache.GetRoot()
@@ -801,7 +807,7 @@ func (sc *SecretManagerClient) generateNewSecret(resourceName string) (*security
 	}
 
 	// Generate the cert/key, send CSR to CA.
-	csrPEM, keyPEM, err := pkiutil.GenCSR(options)
+	csrPEM, keyPEM, externalKey, err := sc.generateCSR(options)
 	if err != nil {
 		cacheLog.Errorf("%s failed to generate key and certificate for CSR: %v", logPrefix, err)
 		return nil, err
@@ -846,15 +852,38 @@ func (sc *SecretManagerClient) generateNewSecret(resourceName string) (*security
 	}
 
 	return &security.SecretItem{
-		CertificateChain: certChain,
-		PrivateKey:       keyPEM,
-		ResourceName:     resourceName,
-		CreatedTime:      time.Now(),
-		ExpireTime:       expireTime,
-		RootCert:         rootCertPEM,
+		CertificateChain:   certChain,
+		PrivateKey:         keyPEM,
+		ExternalPrivateKey: externalKey,
+		ResourceName:       resourceName,
+		CreatedTime:        time.Now(),
+		ExpireTime:         expireTime,
+		RootCert:           rootCertPEM,
 	}, nil
 }
 
+// generateCSR generates a private key and a CSR for it. The key comes from the configured key provider,
+// and is only returned PEM encoded if it can leave the provider.
+func (sc *SecretManagerClient) generateCSR(options pkiutil.CertOptions) ([]byte, []byte, *security.ExternalPrivateKey, error) {
+	if sc.configOptions.KeyProvider == nil {
+		csrPEM, keyPEM, err := pkiutil.GenCSR(options)
+		return csrPEM, keyPEM, nil, err
+	}
+	key, err := sc.configOptions.KeyProvider.GenerateKey(options)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	csrPEM, err := pkiutil.GenCSRWithSigner(options, key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	keyPEM, err := key.PEM()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return csrPEM, keyPEM, key.External(), nil
+}
+
 var rotateTime = func(secret security.SecretItem, graceRatio float64, graceRatioJitter float64) time.Duration {
 	// stagger rotation times to prevent large fleets of clients from renewing at the same moment.
 	jitter := (rand.Float64() * graceRatioJitter) * float64(rand.IntN(2)*2-1) // #nosec G404 -- crypto/rand not worth the cost
@@ -1119,6 +1148,40 @@ func (sc *SecretManagerClient) UpdateConfigTrustBundle(trustBundle []byte) error
 	return nil
 }
 
+// UpdateCRL updates the certificate revocation lists distributed by istiod, and pushes the root
+// certificate to the proxy. An empty CRL means no certificate is revoked.
+func (sc *SecretManagerClient) UpdateCRL(crl []byte) error {
+	if len(crl) == 0 {
+		crl = nil
+	}
+	sc.crlMutex.Lock()
+	if bytes.Equal(sc.crl, crl) {
+		sc.crlMutex.Unlock()
+		return nil
+	}
+	sc.crl = crl
+	sc.crlMutex.Unlock()
+	cacheLog.Infof("certificate revocation list updated")
+	sc.OnSecretUpdate(security.RootCertReqResourceName)
+	return nil
+}
+
+func (sc *SecretManagerClient) getCRL() []byte {
+	sc.crlMutex.RLock()
+	defer sc.crlMutex.RUnlock()
+	return sc.crl
+}
+
+// CachedCertificates returns the cached workload certificate chain, and the trust bundle served with it
+// over SDS. Both are empty if no workload certificate is cached.
+func (sc *SecretManagerClient) CachedCertificates() (certChain []byte, rootCerts []byte) {
+	c := sc.cache.GetWorkload()
+	if c == nil {
+		return nil, nil
+	}
+	return c.CertificateChain, sc.mergeTrustAnchorBytes(c.RootCert)
+}
+
 // mergeTrustAnchorBytes: Merge cert bytes with the cached TrustAnchors.
 func (sc *SecretManagerClient) mergeTrustAnchorBytes(caCerts []byte) []byte {
 	return sc.mergeConfigTrustBundle(pkiutil.PemCertBytestoString(caCerts))
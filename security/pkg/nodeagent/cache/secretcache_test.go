@@ -16,6 +16,7 @@ package cache
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
@@ -90,6 +91,61 @@ func TestWorkloadAgentGenerateSecret(t *testing.T) {
 	mt.Assert(certExpirySeconds.Name(), map[string]string{"resource_name": "default"}, monitortest.LessThan(certDefaultTTL))
 }
 
+type fakeExternalKey struct {
+	crypto.Signer
+}
+
+func (k fakeExternalKey) PEM() ([]byte, error) {
+	return nil, nil
+}
+
+func (k fakeExternalKey) External() *security.ExternalPrivateKey {
+	return &security.ExternalPrivateKey{ProviderName: "fake", KeyID: "key-1"}
+}
+
+type fakeKeyProvider struct {
+	keys []security.PrivateKey
+}
+
+func (f *fakeKeyProvider) GenerateKey(options pkiutil.CertOptions) (security.PrivateKey, error) {
+	priv, err := pkiutil.GenPrivateKey(options)
+	if err != nil {
+		return nil, err
+	}
+	key := fakeExternalKey{Signer: priv}
+	f.keys = append(f.keys, key)
+	return key, nil
+}
+
+func (f *fakeKeyProvider) Close() {}
+
+func TestWorkloadAgentGenerateSecretWithKeyProvider(t *testing.T) {
+	fakeCACli, err := mock.NewMockCAClient(time.Hour, true)
+	if err != nil {
+		t.Fatalf("Error creating Mock CA client: %v", err)
+	}
+	provider := &fakeKeyProvider{}
+	sc := createCache(t, fakeCACli, func(resourceName string) {}, security.Options{WorkloadRSAKeySize: 2048, KeyProvider: provider})
+	gotSecret, err := sc.GenerateSecret(security.WorkloadKeyCertResourceName)
+	if err != nil {
+		t.Fatalf("Failed to get secrets: %v", err)
+	}
+	if len(gotSecret.PrivateKey) != 0 {
+		t.Fatalf("expected the private key to stay in the key provider")
+	}
+	assert.Equal(t, gotSecret.ExternalPrivateKey, &security.ExternalPrivateKey{ProviderName: "fake", KeyID: "key-1"})
+	assert.Equal(t, len(provider.keys), 1)
+
+	// The certificate is issued for the key of the provider.
+	leaf, err := pkiutil.ParsePemEncodedCertificate(gotSecret.CertificateChain)
+	assert.NoError(t, err)
+	want, err := x509.MarshalPKIXPublicKey(provider.keys[0].Public())
+	assert.NoError(t, err)
+	got, err := x509.MarshalPKIXPublicKey(leaf.PublicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, got, want)
+}
+
 func createCache(t *testing.T, caClient security.Client, notifyCb func(resourceName string), options security.Options) *SecretManagerClient {
 	t.Helper()
 	sc, err := NewSecretManagerClient(caClient, &options)
@@ -770,6 +826,46 @@ func TestProxyConfigAnchors(t *testing.T) {
 	})
 }
 
+func TestCRL(t *testing.T) {
+	fakeCACli, err := mock.NewMockCAClient(time.Hour, false)
+	if err != nil {
+		t.Fatalf("Error creating Mock CA client: %v", err)
+	}
+	u := NewUpdateTracker(t)
+	sc := createCache(t, fakeCACli, u.Callback, security.Options{WorkloadRSAKeySize: 2048})
+	if _, err := sc.GenerateSecret(security.WorkloadKeyCertResourceName); err != nil {
+		t.Fatal(err)
+	}
+	u.Reset()
+
+	crl := []byte("-----BEGIN X509 CRL-----\nAQID\n-----END X509 CRL-----\n")
+	if err := sc.UpdateCRL(crl); err != nil {
+		t.Fatal(err)
+	}
+	// Only the root certificate is pushed, the workload certificate is not affected.
+	u.Expect(map[string]int{security.RootCertReqResourceName: 1})
+	root, err := sc.GenerateSecret(security.RootCertReqResourceName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(root.CRL, crl) {
+		t.Fatalf("expected the CRL on the root certificate, got %q", root.CRL)
+	}
+
+	// The same CRL does not trigger a push.
+	u.Reset()
+	_ = sc.UpdateCRL(crl)
+	u.Expect(map[string]int{})
+
+	// An empty CRL removes it.
+	_ = sc.UpdateCRL(nil)
+	u.Expect(map[string]int{security.RootCertReqResourceName: 1})
+	root, _ = sc.GenerateSecret(security.RootCertReqResourceName)
+	if root.CRL != nil {
+		t.Fatalf("expected no CRL, got %q", root.CRL)
+	}
+}
+
 func TestProxyConfigAnchorsTriggerWorkloadCertUpdate(t *testing.T) {
 	cacheLog.SetOutputLevel(log.DebugLevel)
 	fakeCACli, err := mock.NewMockCAClient(time.Millisecond*200, false)
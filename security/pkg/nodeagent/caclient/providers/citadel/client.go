@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
@@ -104,12 +105,7 @@ func (c *CitadelClient) CSRSign(csrPEM []byte, certValidTTLInSec int64) (res []s
 		}
 	}()
 
-	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("ClusterID", c.opts.ClusterID))
-	for k, v := range c.opts.CAHeaders {
-		ctx = metadata.AppendToOutgoingContext(ctx, k, v)
-	}
-
-	resp, err := c.client.CreateCertificate(ctx, req)
+	resp, err := c.client.CreateCertificate(c.outgoingContext(context.Background()), req)
 	if err != nil {
 		return nil, fmt.Errorf("create certificate: %v", err)
 	}
@@ -121,6 +117,36 @@ func (c *CitadelClient) CSRSign(csrPEM []byte, certValidTTLInSec int64) (res []s
 	return resp.CertChain, nil
 }
 
+// FetchJWTSVID requests JWT-SVIDs for the workload identity from Citadel.
+func (c *CitadelClient) FetchJWTSVID(ctx context.Context, req *workload.JWTSVIDRequest) (*workload.JWTSVIDResponse, error) {
+	resp, err := workload.NewSpiffeWorkloadAPIClient(c.conn).FetchJWTSVID(c.outgoingContext(ctx), req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWT-SVID: %v", err)
+	}
+	return resp, nil
+}
+
+// FetchJWTBundles returns the JWT bundles Citadel publishes, keyed by trust domain.
+func (c *CitadelClient) FetchJWTBundles(ctx context.Context) (map[string][]byte, error) {
+	stream, err := workload.NewSpiffeWorkloadAPIClient(c.conn).FetchJWTBundles(c.outgoingContext(ctx), &workload.JWTBundlesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWT bundles: %v", err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWT bundles: %v", err)
+	}
+	return resp.Bundles, nil
+}
+
+func (c *CitadelClient) outgoingContext(ctx context.Context) context.Context {
+	ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs("ClusterID", c.opts.ClusterID))
+	for k, v := range c.opts.CAHeaders {
+		ctx = metadata.AppendToOutgoingContext(ctx, k, v)
+	}
+	return ctx
+}
+
 func (c *CitadelClient) getTLSOptions() *istiogrpc.TLSOptions {
 	if c.tlsOpts != nil {
 		return &istiogrpc.TLSOptions{
@@ -17,6 +17,7 @@ package sds
 
 import (
 	"context"
+	"encoding/pem"
 	"fmt"
 	"os"
 	"strconv"
@@ -36,6 +37,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	mesh "istio.io/api/mesh/v1alpha1"
 	"istio.io/istio/pilot/pkg/features"
@@ -311,7 +313,21 @@ func toEnvoySecret(s *security.SecretItem, caRootPath string, pkpConf *mesh.Priv
 			},
 		}
 
-		if features.EnableCACRL {
+		if len(s.CRL) > 0 {
+			// The CRLs distributed by istiod include the plugged-in CA CRLs, so they supersede the CRL file.
+			secretValidationContext.ValidationContext.Crl = &core.DataSource{
+				Specifier: &core.DataSource_InlineBytes{
+					InlineBytes: s.CRL,
+				},
+			}
+			// A single CRL is signed by the CA issuing workload certificates; the other certificates of
+			// the chain are only checked when their CRLs are plugged in, unless configured otherwise.
+			onlyVerifyLeafCert := countCRLs(s.CRL) == 1
+			if features.CRLOnlyVerifyLeafCertSet {
+				onlyVerifyLeafCert = features.CRLOnlyVerifyLeafCert
+			}
+			secretValidationContext.ValidationContext.OnlyVerifyLeafCertCrl = onlyVerifyLeafCert
+		} else if features.EnableCACRL {
 			// Check if the plugged-in CA CRL file is present and update the secretValidationContext accordingly.
 			if isCrlFileProvided() {
 				secretValidationContext.ValidationContext.Crl = &core.DataSource{
@@ -323,6 +339,27 @@ func toEnvoySecret(s *security.SecretItem, caRootPath string, pkpConf *mesh.Priv
 		}
 
 		secret.Type = secretValidationContext
+	} else if s.ExternalPrivateKey != nil && len(s.PrivateKey) == 0 {
+		// The key cannot leave its key provider, Envoy signs with it through a private key provider.
+		msg := protoconv.MessageToAny(&structpb.Struct{Fields: map[string]*structpb.Value{
+			"signer_address": structpb.NewStringValue(s.ExternalPrivateKey.SignerAddress),
+			"key_id":         structpb.NewStringValue(s.ExternalPrivateKey.KeyID),
+		}})
+		secret.Type = &tls.Secret_TlsCertificate{
+			TlsCertificate: &tls.TlsCertificate{
+				CertificateChain: &core.DataSource{
+					Specifier: &core.DataSource_InlineBytes{
+						InlineBytes: s.CertificateChain,
+					},
+				},
+				PrivateKeyProvider: &tls.PrivateKeyProvider{
+					ProviderName: s.ExternalPrivateKey.ProviderName,
+					ConfigType: &tls.PrivateKeyProvider_TypedConfig{
+						TypedConfig: msg,
+					},
+				},
+			},
+		}
 	} else {
 		switch pkpConf.GetProvider().(type) {
 		case *mesh.PrivateKeyProvider_Cryptomb:
@@ -397,6 +434,17 @@ func toEnvoySecret(s *security.SecretItem, caRootPath string, pkpConf *mesh.Priv
 	return secret
 }
 
+// countCRLs returns the number of PEM encoded CRLs.
+func countCRLs(crl []byte) int {
+	n := 0
+	for rest := crl; ; n++ {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			return n
+		}
+	}
+}
+
 // isCrlFileProvided checks if the Plugged-in CA CRL file is present
 func isCrlFileProvided() bool {
 	_, err := os.Stat(security.CACRLFilePath)
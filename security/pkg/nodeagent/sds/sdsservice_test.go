@@ -14,10 +14,17 @@
 package sds
 
 import (
+	"bytes"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -28,13 +35,18 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
 	wrappers "google.golang.org/protobuf/types/known/wrapperspb"
 
 	meshconfig "istio.io/api/mesh/v1alpha1"
+	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/xds"
 	"istio.io/istio/pilot/test/xdstest"
 	"istio.io/istio/pkg/log"
 	ca2 "istio.io/istio/pkg/security"
+	"istio.io/istio/pkg/test"
+	"istio.io/istio/security/pkg/keyprovider"
+	pkiutil "istio.io/istio/security/pkg/pki/util"
 )
 
 var (
@@ -331,6 +343,80 @@ func TestSDS(t *testing.T) {
 		c.Cleanup()
 		usefakePrivateKeyProviderConf = false
 	})
+	t.Run("external private key", func(t *testing.T) {
+		// A stub signer process holds the key.
+		socket := filepath.Join(t.TempDir(), "signer.sock")
+		signer, err := keyprovider.NewSignerServer(socket, keyprovider.NewMemoryKeys())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer signer.Close()
+		provider := keyprovider.NewExternalSigner(socket, keyprovider.ExternalSignerProviderName)
+		defer provider.Close()
+		key, err := provider.GenerateKey(pkiutil.CertOptions{ECSigAlg: pkiutil.EcdsaSigAlg})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		s := setupSDS(t)
+		s.store.Set(testResourceName, &ca2.SecretItem{
+			CertificateChain:   fakeCertificateChain,
+			ResourceName:       testResourceName,
+			ExternalPrivateKey: key.External(),
+		})
+		resp := s.Connect().RequestResponseAck(t, &discovery.DiscoveryRequest{ResourceNames: []string{testResourceName}})
+		cert := xdstest.ExtractTLSSecrets(t, resp.Resources)[testResourceName].GetTlsCertificate()
+		if cert.GetPrivateKey() != nil {
+			t.Fatalf("expected no inline private key")
+		}
+		pkp := cert.GetPrivateKeyProvider()
+		if pkp.GetProviderName() != keyprovider.ExternalSignerProviderName {
+			t.Fatalf("expected the external signer private key provider, got %v", pkp)
+		}
+		var conf structpb.Struct
+		if err := pkp.GetTypedConfig().UnmarshalTo(&conf); err != nil {
+			t.Fatal(err)
+		}
+		if got := conf.Fields["signer_address"].GetStringValue(); got != socket {
+			t.Fatalf("expected signer address %q, got %q", socket, got)
+		}
+
+		// Envoy signs with the key over the signer given in the secret.
+		digest := sha256.Sum256([]byte("handshake"))
+		sig := signWithExternalSigner(t, conf.Fields["signer_address"].GetStringValue(), conf.Fields["key_id"].GetStringValue(), digest[:])
+		if !ecdsa.VerifyASN1(key.Public().(*ecdsa.PublicKey), digest[:], sig) {
+			t.Fatalf("the signer did not sign with the key of the certificate")
+		}
+	})
+	t.Run("certificate revocation list", func(t *testing.T) {
+		crl := []byte("-----BEGIN X509 CRL-----\nAQID\n-----END X509 CRL-----\n")
+		s := setupSDS(t)
+		s.store.Set(rootResourceName, &ca2.SecretItem{RootCert: fakeRootCert, CRL: crl, ResourceName: rootResourceName})
+		resp := s.Connect().RequestResponseAck(t, &discovery.DiscoveryRequest{ResourceNames: []string{rootResourceName}})
+		vc := xdstest.ExtractTLSSecrets(t, resp.Resources)[rootResourceName].GetValidationContext()
+		if !bytes.Equal(vc.GetCrl().GetInlineBytes(), crl) {
+			t.Fatalf("expected inline CRL, got %v", vc.GetCrl())
+		}
+		if !vc.GetOnlyVerifyLeafCertCrl() {
+			t.Fatalf("expected only the leaf certificate to be checked with a single CRL")
+		}
+
+		// With CRLs for the whole chain, all certificates are checked.
+		s.UpdateSecret(rootResourceName, &ca2.SecretItem{RootCert: fakeRootCert, CRL: append(crl, crl...), ResourceName: rootResourceName})
+		resp = s.Connect().RequestResponseAck(t, &discovery.DiscoveryRequest{ResourceNames: []string{rootResourceName}})
+		if xdstest.ExtractTLSSecrets(t, resp.Resources)[rootResourceName].GetValidationContext().GetOnlyVerifyLeafCertCrl() {
+			t.Fatalf("expected all certificates of the chain to be checked")
+		}
+
+		// The check of the leaf certificate only can be forced.
+		test.SetForTest(t, &features.CRLOnlyVerifyLeafCertSet, true)
+		test.SetForTest(t, &features.CRLOnlyVerifyLeafCert, true)
+		s.UpdateSecret(rootResourceName, &ca2.SecretItem{RootCert: fakeRootCert, CRL: append(crl, crl...), ResourceName: rootResourceName})
+		resp = s.Connect().RequestResponseAck(t, &discovery.DiscoveryRequest{ResourceNames: []string{rootResourceName}})
+		if !xdstest.ExtractTLSSecrets(t, resp.Resources)[rootResourceName].GetValidationContext().GetOnlyVerifyLeafCertCrl() {
+			t.Fatalf("expected only the leaf certificate to be checked")
+		}
+	})
 }
 
 func setupConnection(socket string) (*grpc.ClientConn, error) {
@@ -348,3 +434,33 @@ func setupConnection(socket string) (*grpc.ClientConn, error) {
 
 	return conn, nil
 }
+
+// signWithExternalSigner signs a SHA-256 digest the way an Envoy private key provider does, over the external
+// signer protocol.
+func signWithExternalSigner(t *testing.T, address, keyID string, digest []byte) []byte {
+	t.Helper()
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", address)
+		},
+	}
+	defer transport.CloseIdleConnections()
+	body, err := json.Marshal(keyprovider.SignRequest{Digest: digest, Hash: crypto.SHA256.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: transport}).Post("http://signer/v1/keys/"+keyID+"/sign", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("signer returned %d", resp.StatusCode)
+	}
+	var out keyprovider.SignResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	return out.Signature
+}
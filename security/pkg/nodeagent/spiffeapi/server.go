@@ -0,0 +1,407 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spiffeapi serves the SPIFFE Workload API to applications, from the workload secrets of the agent.
+package spiffeapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"istio.io/istio/pkg/log"
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/pkg/spiffe"
+	"istio.io/istio/pkg/uds"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+var spiffeLog = log.RegisterScope("spiffeapi", "SPIFFE Workload API server debugging")
+
+const (
+	// securityHeader is the metadata every Workload API client must send, per the SPIFFE specification.
+	securityHeader = "workload.spiffe.io"
+
+	// bundleRefreshInterval is how often JWT bundles are refreshed from the CA.
+	bundleRefreshInterval = time.Minute
+)
+
+var jwtAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512, jose.PS256, jose.PS384, jose.PS512, jose.ES256, jose.ES384, jose.ES512,
+}
+
+// JWTSource mints JWT-SVIDs and provides the JWT bundles to validate them, typically by calling istiod.
+type JWTSource interface {
+	FetchJWTSVID(ctx context.Context, req *workload.JWTSVIDRequest) (*workload.JWTSVIDResponse, error)
+	FetchJWTBundles(ctx context.Context) (map[string][]byte, error)
+}
+
+// Server serves the SPIFFE Workload API on a Unix domain socket. X.509-SVIDs and bundles come from the
+// same secret manager as the SDS server, so they rotate together with the certificates of the proxy.
+type Server struct {
+	workload.UnimplementedSpiffeWorkloadAPIServer
+
+	secrets security.SecretManager
+	jwt     JWTSource
+
+	grpcServer *grpc.Server
+	listener   net.Listener
+
+	mu       sync.Mutex
+	watchers map[chan struct{}]struct{}
+	// jwtBundles caches the JWT bundles fetched from the JWT source.
+	jwtBundles        map[string][]byte
+	jwtBundlesFetched time.Time
+}
+
+// NewServer creates a Workload API server listening on the given socket. jwtSource may be nil, in which
+// case JWT-SVIDs are not supported.
+func NewServer(path string, secrets security.SecretManager, jwtSource JWTSource) (*Server, error) {
+	s := newServer(secrets, jwtSource)
+	l, err := uds.NewListener(path)
+	if err != nil {
+		return nil, err
+	}
+	s.listener = l
+	s.grpcServer = grpc.NewServer()
+	workload.RegisterSpiffeWorkloadAPIServer(s.grpcServer, s)
+	go func() {
+		spiffeLog.Infof("Starting SPIFFE Workload API server, will listen on %q", path)
+		if err := s.grpcServer.Serve(l); err != nil {
+			spiffeLog.Errorf("SPIFFE Workload API server failed: %v", err)
+		}
+	}()
+	return s, nil
+}
+
+func newServer(secrets security.SecretManager, jwtSource JWTSource) *Server {
+	return &Server{
+		secrets:  secrets,
+		jwt:      jwtSource,
+		watchers: map[chan struct{}]struct{}{},
+	}
+}
+
+// OnSecretUpdate notifies the open streams that the workload certificate or trust bundle changed.
+func (s *Server) OnSecretUpdate(resourceName string) {
+	if resourceName != security.WorkloadKeyCertResourceName && resourceName != security.RootCertReqResourceName {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for w := range s.watchers {
+		select {
+		case w <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Stop closes the server and its streams.
+func (s *Server) Stop() {
+	if s == nil {
+		return
+	}
+	if s.grpcServer != nil {
+		s.grpcServer.Stop()
+	}
+	if s.listener != nil {
+		_ = s.listener.Close()
+	}
+}
+
+func (s *Server) watch() (chan struct{}, func()) {
+	w := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.watchers[w] = struct{}{}
+	s.mu.Unlock()
+	return w, func() {
+		s.mu.Lock()
+		delete(s.watchers, w)
+		s.mu.Unlock()
+	}
+}
+
+// stream sends the responses of build until the client goes away, whenever the secrets are updated or
+// the refresh interval (if any) passed. Responses identical to the previous one are not sent again.
+func (s *Server) stream(ctx context.Context, refresh time.Duration, build func() (proto.Message, error), send func(proto.Message) error) error {
+	if err := checkHeader(ctx); err != nil {
+		return err
+	}
+	updates, cancel := s.watch()
+	defer cancel()
+	var tick <-chan time.Time
+	if refresh > 0 {
+		t := time.NewTicker(refresh)
+		defer t.Stop()
+		tick = t.C
+	}
+	var last proto.Message
+	for {
+		resp, err := build()
+		if err != nil {
+			return err
+		}
+		if last == nil || !proto.Equal(last, resp) {
+			if err := send(resp); err != nil {
+				return err
+			}
+			last = resp
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-updates:
+		case <-tick:
+		}
+	}
+}
+
+// FetchX509SVID streams the workload X.509-SVID, with its private key and trust bundle.
+func (s *Server) FetchX509SVID(_ *workload.X509SVIDRequest, stream workload.SpiffeWorkloadAPI_FetchX509SVIDServer) error {
+	return s.stream(stream.Context(), 0, func() (proto.Message, error) {
+		return s.x509SVID()
+	}, func(m proto.Message) error {
+		return stream.Send(m.(*workload.X509SVIDResponse))
+	})
+}
+
+// FetchX509Bundles streams the X.509 trust bundle.
+func (s *Server) FetchX509Bundles(_ *workload.X509BundlesRequest, stream workload.SpiffeWorkloadAPI_FetchX509BundlesServer) error {
+	return s.stream(stream.Context(), 0, func() (proto.Message, error) {
+		svid, err := s.x509SVID()
+		if err != nil {
+			return nil, err
+		}
+		return &workload.X509BundlesResponse{Bundles: map[string][]byte{
+			trustDomainID(svid.Svids[0].SpiffeId): svid.Svids[0].Bundle,
+		}}, nil
+	}, func(m proto.Message) error {
+		return stream.Send(m.(*workload.X509BundlesResponse))
+	})
+}
+
+// FetchJWTSVID mints JWT-SVIDs for the workload identity.
+func (s *Server) FetchJWTSVID(ctx context.Context, req *workload.JWTSVIDRequest) (*workload.JWTSVIDResponse, error) {
+	if err := checkHeader(ctx); err != nil {
+		return nil, err
+	}
+	if s.jwt == nil {
+		return nil, status.Error(codes.Unimplemented, "JWT-SVIDs are not supported")
+	}
+	if len(req.Audience) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "audience must be specified")
+	}
+	resp, err := s.jwt.FetchJWTSVID(ctx, req)
+	if err != nil {
+		spiffeLog.Warnf("failed to fetch JWT-SVID: %v", err)
+		return nil, status.Errorf(codes.Unavailable, "failed to fetch JWT-SVID: %v", err)
+	}
+	return resp, nil
+}
+
+// FetchJWTBundles streams the JWT bundles.
+func (s *Server) FetchJWTBundles(_ *workload.JWTBundlesRequest, stream workload.SpiffeWorkloadAPI_FetchJWTBundlesServer) error {
+	if s.jwt == nil {
+		return status.Error(codes.Unimplemented, "JWT-SVIDs are not supported")
+	}
+	return s.stream(stream.Context(), bundleRefreshInterval, func() (proto.Message, error) {
+		bundles, err := s.fetchJWTBundles(stream.Context())
+		if err != nil {
+			return nil, err
+		}
+		return &workload.JWTBundlesResponse{Bundles: bundles}, nil
+	}, func(m proto.Message) error {
+		return stream.Send(m.(*workload.JWTBundlesResponse))
+	})
+}
+
+// ValidateJWTSVID validates a JWT-SVID against the JWT bundles.
+func (s *Server) ValidateJWTSVID(ctx context.Context, req *workload.ValidateJWTSVIDRequest) (*workload.ValidateJWTSVIDResponse, error) {
+	if err := checkHeader(ctx); err != nil {
+		return nil, err
+	}
+	if s.jwt == nil {
+		return nil, status.Error(codes.Unimplemented, "JWT-SVIDs are not supported")
+	}
+	if req.Audience == "" || req.Svid == "" {
+		return nil, status.Error(codes.InvalidArgument, "audience and svid must be specified")
+	}
+	bundles, err := s.fetchJWTBundles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, claims, err := validateJWTSVID(req.Svid, req.Audience, bundles, time.Now())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	st, err := structpb.NewStruct(claims)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode claims: %v", err)
+	}
+	return &workload.ValidateJWTSVIDResponse{SpiffeId: id, Claims: st}, nil
+}
+
+// fetchJWTBundles returns the JWT bundles, fetching them from the JWT source if the cached ones are stale.
+func (s *Server) fetchJWTBundles(ctx context.Context) (map[string][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.jwtBundles != nil && time.Since(s.jwtBundlesFetched) < bundleRefreshInterval {
+		return s.jwtBundles, nil
+	}
+	bundles, err := s.jwt.FetchJWTBundles(ctx)
+	if err != nil {
+		spiffeLog.Warnf("failed to fetch JWT bundles: %v", err)
+		return nil, status.Errorf(codes.Unavailable, "failed to fetch JWT bundles: %v", err)
+	}
+	s.jwtBundles, s.jwtBundlesFetched = bundles, time.Now()
+	return bundles, nil
+}
+
+// x509SVID builds the X.509-SVID response from the workload secrets.
+func (s *Server) x509SVID() (*workload.X509SVIDResponse, error) {
+	cert, err := s.secrets.GenerateSecret(security.WorkloadKeyCertResourceName)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to get workload certificate: %v", err)
+	}
+	if len(cert.PrivateKey) == 0 && cert.ExternalPrivateKey != nil {
+		return nil, status.Error(codes.FailedPrecondition, "workload private key cannot leave its key provider")
+	}
+	root, err := s.secrets.GenerateSecret(security.RootCertReqResourceName)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to get trust bundle: %v", err)
+	}
+	svid, err := newX509SVID(cert, root.RootCert)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "invalid workload certificate: %v", err)
+	}
+	return &workload.X509SVIDResponse{Svids: []*workload.X509SVID{svid}}, nil
+}
+
+// newX509SVID converts a PEM encoded workload secret and trust bundle to the DER encodings of the Workload API.
+func newX509SVID(cert *security.SecretItem, rootsPEM []byte) (*workload.X509SVID, error) {
+	chain, _, err := util.ParsePemEncodedCertificateChain(cert.CertificateChain)
+	if err != nil {
+		return nil, err
+	}
+	roots, _, err := util.ParsePemEncodedCertificateChain(rootsPEM)
+	if err != nil {
+		return nil, err
+	}
+	if len(chain[0].URIs) == 0 {
+		return nil, status.Error(codes.Internal, "workload certificate has no SPIFFE ID")
+	}
+	key, err := util.ParsePemEncodedKey(cert.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	svid := &workload.X509SVID{
+		SpiffeId:    chain[0].URIs[0].String(),
+		X509SvidKey: keyDER,
+	}
+	for _, c := range chain {
+		// The SVID holds the leaf and intermediates only.
+		if !containsCert(roots, c) {
+			svid.X509Svid = append(svid.X509Svid, c.Raw...)
+		}
+	}
+	for _, r := range roots {
+		svid.Bundle = append(svid.Bundle, r.Raw...)
+	}
+	return svid, nil
+}
+
+// validateJWTSVID verifies the signature of a JWT-SVID with the bundle of its trust domain, and checks
+// its audience and expiry. It returns the SPIFFE ID and claims of the JWT-SVID.
+func validateJWTSVID(token, audience string, bundles map[string][]byte, now time.Time) (string, map[string]any, error) {
+	tok, err := jwt.ParseSigned(token, jwtAlgorithms)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid JWT-SVID: %v", err)
+	}
+	var claims jwt.Claims
+	if err := tok.UnsafeClaimsWithoutVerification(&claims); err != nil {
+		return "", nil, fmt.Errorf("invalid JWT-SVID claims: %v", err)
+	}
+	if !strings.HasPrefix(claims.Subject, spiffe.URIPrefix) {
+		return "", nil, fmt.Errorf("JWT-SVID subject %q is not a SPIFFE ID", claims.Subject)
+	}
+	td := trustDomainID(claims.Subject)
+	bundle, f := bundles[td]
+	if !f {
+		return "", nil, fmt.Errorf("no JWT bundle for trust domain %s", td)
+	}
+	var keys jose.JSONWebKeySet
+	if err := json.Unmarshal(bundle, &keys); err != nil {
+		return "", nil, fmt.Errorf("invalid JWT bundle for trust domain %s: %v", td, err)
+	}
+	matching := keys.Key(tok.Headers[0].KeyID)
+	if len(matching) == 0 {
+		return "", nil, fmt.Errorf("JWT-SVID key %q is not in the bundle of trust domain %s", tok.Headers[0].KeyID, td)
+	}
+	all := map[string]any{}
+	if err := tok.Claims(matching[0].Key, &claims, &all); err != nil {
+		return "", nil, fmt.Errorf("invalid JWT-SVID signature: %v", err)
+	}
+	if err := claims.ValidateWithLeeway(jwt.Expected{AnyAudience: jwt.Audience{audience}, Time: now}, 0); err != nil {
+		return "", nil, fmt.Errorf("invalid JWT-SVID: %v", err)
+	}
+	return claims.Subject, all, nil
+}
+
+func containsCert(certs []*x509.Certificate, c *x509.Certificate) bool {
+	for _, cert := range certs {
+		if bytes.Equal(cert.Raw, c.Raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// trustDomainID returns the SPIFFE ID of the trust domain of a SPIFFE ID.
+func trustDomainID(id string) string {
+	u, err := url.Parse(id)
+	if err != nil {
+		return id
+	}
+	return spiffe.URIPrefix + u.Host
+}
+
+func checkHeader(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get(securityHeader); len(v) != 1 || v[0] != "true" {
+		return status.Error(codes.InvalidArgument, "security header missing from request")
+	}
+	return nil
+}
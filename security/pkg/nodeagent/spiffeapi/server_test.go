@@ -0,0 +1,237 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spiffeapi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/pkg/test/util/assert"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+const testID = "spiffe://cluster.local/ns/default/sa/app"
+
+type fakeJWTSource struct {
+	key *ecdsa.PrivateKey
+}
+
+func newFakeJWTSource(t *testing.T) *fakeJWTSource {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	return &fakeJWTSource{key: key}
+}
+
+func (f *fakeJWTSource) FetchJWTSVID(_ context.Context, req *workload.JWTSVIDRequest) (*workload.JWTSVIDResponse, error) {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: f.key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "test"))
+	if err != nil {
+		return nil, err
+	}
+	token, err := jwt.Signed(signer).Claims(jwt.Claims{
+		Subject:  testID,
+		Audience: req.Audience,
+		Expiry:   jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}).Serialize()
+	if err != nil {
+		return nil, err
+	}
+	return &workload.JWTSVIDResponse{Svids: []*workload.JWTSVID{{SpiffeId: testID, Svid: token}}}, nil
+}
+
+func (f *fakeJWTSource) FetchJWTBundles(context.Context) (map[string][]byte, error) {
+	b, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: f.key.Public(), KeyID: "test", Algorithm: "ES256"}}})
+	return map[string][]byte{"spiffe://cluster.local": b}, err
+}
+
+// genSecrets generates a root certificate and a workload certificate signed by it.
+func genSecrets(t *testing.T) (*security.SecretItem, *security.SecretItem, *x509.Certificate) {
+	t.Helper()
+	rootPEM, rootKeyPEM, err := util.GenCertKeyFromOptions(util.CertOptions{
+		Org:          "cluster.local",
+		IsCA:         true,
+		IsSelfSigned: true,
+		TTL:          time.Hour,
+		RSAKeySize:   2048,
+	})
+	assert.NoError(t, err)
+	root, err := util.ParsePemEncodedCertificate(rootPEM)
+	assert.NoError(t, err)
+	rootKey, err := util.ParsePemEncodedKey(rootKeyPEM)
+	assert.NoError(t, err)
+	certPEM, keyPEM, err := util.GenCertKeyFromOptions(util.CertOptions{
+		Host:       testID,
+		TTL:        time.Hour,
+		RSAKeySize: 2048,
+		SignerCert: root,
+		SignerPriv: rootKey,
+	})
+	assert.NoError(t, err)
+	return &security.SecretItem{CertificateChain: certPEM, PrivateKey: keyPEM, ResourceName: security.WorkloadKeyCertResourceName},
+		&security.SecretItem{RootCert: rootPEM, ResourceName: security.RootCertReqResourceName},
+		root
+}
+
+type testEnv struct {
+	server  *Server
+	secrets *security.DirectSecretManager
+	client  workload.SpiffeWorkloadAPIClient
+	// ctx carries the Workload API security header.
+	ctx context.Context
+}
+
+func setup(t *testing.T, jwtSource JWTSource) *testEnv {
+	t.Helper()
+	secrets := security.NewDirectSecretManager()
+	cert, root, _ := genSecrets(t)
+	secrets.Set(security.WorkloadKeyCertResourceName, cert)
+	secrets.Set(security.RootCertReqResourceName, root)
+
+	path := filepath.Join(t.TempDir(), "socket")
+	s, err := NewServer(path, secrets, jwtSource)
+	assert.NoError(t, err)
+	t.Cleanup(s.Stop)
+	conn, err := grpc.NewClient("unix://"+path, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return &testEnv{
+		server:  s,
+		secrets: secrets,
+		client:  workload.NewSpiffeWorkloadAPIClient(conn),
+		ctx:     metadata.AppendToOutgoingContext(ctx, securityHeader, "true"),
+	}
+}
+
+func TestFetchX509SVID(t *testing.T) {
+	env := setup(t, nil)
+	stream, err := env.client.FetchX509SVID(env.ctx, &workload.X509SVIDRequest{})
+	assert.NoError(t, err)
+	resp, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, len(resp.Svids), 1)
+	svid := resp.Svids[0]
+	assert.Equal(t, svid.SpiffeId, testID)
+	leaf, err := x509.ParseCertificate(svid.X509Svid)
+	assert.NoError(t, err)
+	_, err = x509.ParsePKCS8PrivateKey(svid.X509SvidKey)
+	assert.NoError(t, err)
+	roots, err := x509.ParseCertificates(svid.Bundle)
+	assert.NoError(t, err)
+	assert.Equal(t, len(roots), 1)
+
+	// Rotated certificates are streamed to the client.
+	cert, root, newRoot := genSecrets(t)
+	env.secrets.Set(security.WorkloadKeyCertResourceName, cert)
+	env.secrets.Set(security.RootCertReqResourceName, root)
+	env.server.OnSecretUpdate(security.WorkloadKeyCertResourceName)
+	resp, err = stream.Recv()
+	assert.NoError(t, err)
+	rotated, err := x509.ParseCertificate(resp.Svids[0].X509Svid)
+	assert.NoError(t, err)
+	if rotated.SerialNumber.Cmp(leaf.SerialNumber) == 0 {
+		t.Fatalf("expected the rotated certificate")
+	}
+	assert.Equal(t, resp.Svids[0].Bundle, newRoot.Raw)
+}
+
+func TestFetchX509SVIDExternalKey(t *testing.T) {
+	env := setup(t, nil)
+	cert, _, _ := genSecrets(t)
+	cert.PrivateKey = nil
+	cert.ExternalPrivateKey = &security.ExternalPrivateKey{ProviderName: "external", KeyID: "key-1"}
+	env.secrets.Set(security.WorkloadKeyCertResourceName, cert)
+	stream, err := env.client.FetchX509SVID(env.ctx, &workload.X509SVIDRequest{})
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, status.Code(err), codes.FailedPrecondition)
+}
+
+func TestFetchX509Bundles(t *testing.T) {
+	env := setup(t, nil)
+	stream, err := env.client.FetchX509Bundles(env.ctx, &workload.X509BundlesRequest{})
+	assert.NoError(t, err)
+	resp, err := stream.Recv()
+	assert.NoError(t, err)
+	roots, err := x509.ParseCertificates(resp.Bundles["spiffe://cluster.local"])
+	assert.NoError(t, err)
+	assert.Equal(t, len(roots), 1)
+}
+
+func TestSecurityHeader(t *testing.T) {
+	env := setup(t, nil)
+	stream, err := env.client.FetchX509SVID(context.Background(), &workload.X509SVIDRequest{})
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, status.Code(err), codes.InvalidArgument)
+}
+
+func TestJWTSVID(t *testing.T) {
+	t.Run("unsupported", func(t *testing.T) {
+		env := setup(t, nil)
+		_, err := env.client.FetchJWTSVID(env.ctx, &workload.JWTSVIDRequest{Audience: []string{"db"}})
+		assert.Equal(t, status.Code(err), codes.Unimplemented)
+	})
+
+	env := setup(t, newFakeJWTSource(t))
+	_, err := env.client.FetchJWTSVID(env.ctx, &workload.JWTSVIDRequest{})
+	assert.Equal(t, status.Code(err), codes.InvalidArgument)
+
+	resp, err := env.client.FetchJWTSVID(env.ctx, &workload.JWTSVIDRequest{Audience: []string{"db"}})
+	assert.NoError(t, err)
+	assert.Equal(t, len(resp.Svids), 1)
+	token := resp.Svids[0].Svid
+
+	valid, err := env.client.ValidateJWTSVID(env.ctx, &workload.ValidateJWTSVIDRequest{Audience: "db", Svid: token})
+	assert.NoError(t, err)
+	assert.Equal(t, valid.SpiffeId, testID)
+	assert.Equal(t, valid.Claims.Fields["sub"].GetStringValue(), testID)
+
+	_, err = env.client.ValidateJWTSVID(env.ctx, &workload.ValidateJWTSVIDRequest{Audience: "other", Svid: token})
+	assert.Equal(t, status.Code(err), codes.InvalidArgument)
+
+	// Tokens signed by another key are rejected.
+	forged, err := newFakeJWTSource(t).FetchJWTSVID(env.ctx, &workload.JWTSVIDRequest{Audience: []string{"db"}})
+	assert.NoError(t, err)
+	_, err = env.client.ValidateJWTSVID(env.ctx, &workload.ValidateJWTSVIDRequest{Audience: "db", Svid: forged.Svids[0].Svid})
+	assert.Equal(t, status.Code(err), codes.InvalidArgument)
+
+	stream, err := env.client.FetchJWTBundles(env.ctx, &workload.JWTBundlesRequest{})
+	assert.NoError(t, err)
+	bundles, err := stream.Recv()
+	assert.NoError(t, err)
+	var keys jose.JSONWebKeySet
+	assert.NoError(t, json.Unmarshal(bundles.Bundles["spiffe://cluster.local"], &keys))
+	assert.Equal(t, len(keys.Key("test")), 1)
+}
@@ -26,6 +26,7 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"os"
@@ -40,8 +41,27 @@ const MinimumRsaKeySize = 2048
 
 // GenCSR generates a X.509 certificate sign request and private key with the given options.
 func GenCSR(options CertOptions) ([]byte, []byte, error) {
-	var priv any
-	var err error
+	priv, err := GenPrivateKey(options)
+	if err != nil {
+		return nil, nil, err
+	}
+	template, err := GenCSRTemplate(options)
+	if err != nil {
+		return nil, nil, fmt.Errorf("CSR template creation failed (%v)", err)
+	}
+
+	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, template, crypto.PrivateKey(priv))
+	if err != nil {
+		return nil, nil, fmt.Errorf("CSR creation failed (%v)", err)
+	}
+
+	csr, privKey, err := encodePem(true, csrBytes, priv, options.PKCS8Key)
+	return csr, privKey, err
+}
+
+// GenPrivateKey generates a private key with the given options: an ECDSA key if ECSigAlg is set,
+// an RSA key of RSAKeySize otherwise.
+func GenPrivateKey(options CertOptions) (crypto.Signer, error) {
 	if options.ECSigAlg != "" {
 		switch options.ECSigAlg {
 		case EcdsaSigAlg:
@@ -52,35 +72,49 @@ func GenCSR(options CertOptions) ([]byte, []byte, error) {
 			default:
 				curve = elliptic.P256()
 			}
-			priv, err = ecdsa.GenerateKey(curve, rand.Reader)
+			priv, err := ecdsa.GenerateKey(curve, rand.Reader)
 			if err != nil {
-				return nil, nil, fmt.Errorf("EC key generation failed (%v)", err)
+				return nil, fmt.Errorf("EC key generation failed (%v)", err)
 			}
+			return priv, nil
 		default:
-			return nil, nil, errors.New("csr cert generation fails due to unsupported EC signature algorithm")
-		}
-	} else {
-		if options.RSAKeySize < MinimumRsaKeySize {
-			return nil, nil, fmt.Errorf("requested key size does not meet the minimum required size of %d (requested: %d)", MinimumRsaKeySize, options.RSAKeySize)
-		}
-
-		priv, err = rsa.GenerateKey(rand.Reader, options.RSAKeySize)
-		if err != nil {
-			return nil, nil, fmt.Errorf("RSA key generation failed (%v)", err)
+			return nil, errors.New("csr cert generation fails due to unsupported EC signature algorithm")
 		}
 	}
-	template, err := GenCSRTemplate(options)
+	if options.RSAKeySize < MinimumRsaKeySize {
+		return nil, fmt.Errorf("requested key size does not meet the minimum required size of %d (requested: %d)", MinimumRsaKeySize, options.RSAKeySize)
+	}
+	priv, err := rsa.GenerateKey(rand.Reader, options.RSAKeySize)
 	if err != nil {
-		return nil, nil, fmt.Errorf("CSR template creation failed (%v)", err)
+		return nil, fmt.Errorf("RSA key generation failed (%v)", err)
 	}
+	return priv, nil
+}
 
-	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, template, crypto.PrivateKey(priv))
+// GenCSRWithSigner generates a PEM encoded X.509 certificate sign request with the given options, signed
+// by a private key held elsewhere.
+func GenCSRWithSigner(options CertOptions, signer crypto.Signer) ([]byte, error) {
+	template, err := GenCSRTemplate(options)
 	if err != nil {
-		return nil, nil, fmt.Errorf("CSR creation failed (%v)", err)
+		return nil, fmt.Errorf("CSR template creation failed (%v)", err)
 	}
+	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, template, signer)
+	if err != nil {
+		return nil, fmt.Errorf("CSR creation failed (%v)", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrBytes}), nil
+}
 
-	csr, privKey, err := encodePem(true, csrBytes, priv, options.PKCS8Key)
-	return csr, privKey, err
+// EncodePrivateKeyPem returns the PEM encoding of an RSA or ECDSA private key.
+func EncodePrivateKeyPem(priv crypto.PrivateKey, pkcs8 bool) ([]byte, error) {
+	_, privPem, err := encodePem(false, nil, priv, pkcs8)
+	if err != nil {
+		return nil, err
+	}
+	if privPem == nil {
+		return nil, fmt.Errorf("unsupported private key type %T", priv)
+	}
+	return privPem, nil
 }
 
 // GenCSRTemplate generates a certificateRequest template with the given options.
@@ -0,0 +1,115 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package attestation authenticates workloads outside of Kubernetes, such as VMs, from platform evidence.
+// Plugins validate the evidence and map it to a namespace and service account.
+package attestation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/log"
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/pkg/spiffe"
+)
+
+const AuthenticatorType = "AttestationAuthenticator"
+
+var attestationLog = log.RegisterScope("attestation", "workload attestation debugging")
+
+// Identity is the workload identity attested by a plugin.
+type Identity struct {
+	Namespace      string
+	ServiceAccount string
+
+	// Consume, if set, consumes single use evidence once the request it authenticated succeeded.
+	Consume func(ctx context.Context) error
+}
+
+// Plugin validates a type of platform evidence, such as signed instance identity documents or join tokens.
+type Plugin interface {
+	// Type is the evidence type handled by the plugin.
+	Type() string
+	// Attest validates the evidence and returns the identity of the workload presenting it.
+	Attest(ctx context.Context, evidence string) (*Identity, error)
+}
+
+// Authenticator authenticates callers from the platform evidence sent as their bearer token, formatted
+// as <type>:<evidence>. The evidence is validated by the plugin of its type.
+type Authenticator struct {
+	meshHolder mesh.Holder
+	plugins    map[string]Plugin
+}
+
+var _ security.Authenticator = &Authenticator{}
+
+// NewAuthenticator creates an authenticator with the given plugins.
+func NewAuthenticator(meshHolder mesh.Holder, plugins ...Plugin) *Authenticator {
+	a := &Authenticator{meshHolder: meshHolder, plugins: map[string]Plugin{}}
+	for _, p := range plugins {
+		a.plugins[p.Type()] = p
+	}
+	return a
+}
+
+// FormatEvidence formats evidence of the given type as a bearer token.
+func FormatEvidence(evidenceType, evidence string) string {
+	return evidenceType + ":" + evidence
+}
+
+func (a *Authenticator) Authenticate(authRequest security.AuthContext) (*security.Caller, error) {
+	var token string
+	var err error
+	ctx := context.Background()
+	switch {
+	case authRequest.GrpcContext != nil:
+		ctx = authRequest.GrpcContext
+		token, err = security.ExtractBearerToken(authRequest.GrpcContext)
+	case authRequest.Request != nil:
+		ctx = authRequest.Request.Context()
+		token, err = security.ExtractRequestToken(authRequest.Request)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("attestation evidence extraction error: %v", err)
+	}
+	// Evidence types never contain a dot, unlike JWTs which are handled by other authenticators.
+	evidenceType, evidence, ok := strings.Cut(token, ":")
+	if !ok || strings.Contains(evidenceType, ".") {
+		return nil, fmt.Errorf("bearer token is not attestation evidence")
+	}
+	plugin, ok := a.plugins[evidenceType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported attestation evidence type %q", evidenceType)
+	}
+	id, err := plugin.Attest(ctx, evidence)
+	if err != nil {
+		attestationLog.Warnf("%s attestation from %s failed: %v", evidenceType, authRequest.RemoteAddress(), err)
+		return nil, fmt.Errorf("%s attestation failed: %v", evidenceType, err)
+	}
+	attestationLog.Infof("attested %s/%s from %s with %s", id.Namespace, id.ServiceAccount, authRequest.RemoteAddress(), evidenceType)
+	return &security.Caller{
+		AuthSource: security.AuthSourceAttestation,
+		Identities: []string{spiffe.MustGenSpiffeURI(a.meshHolder.Mesh(), id.Namespace, id.ServiceAccount)},
+		Consume:    id.Consume,
+	}, nil
+}
+
+func (a *Authenticator) AuthenticatorType() string {
+	return AuthenticatorType
+}
@@ -0,0 +1,88 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+type fakeMesh struct{}
+
+func (fakeMesh) Mesh() *meshconfig.MeshConfig {
+	return &meshconfig.MeshConfig{TrustDomain: "cluster.local"}
+}
+
+// fakePlugin attests the evidence "valid" as default/vm.
+type fakePlugin struct{}
+
+func (fakePlugin) Type() string {
+	return "fake"
+}
+
+func (fakePlugin) Attest(_ context.Context, evidence string) (*Identity, error) {
+	if evidence != "valid" {
+		return nil, fmt.Errorf("invalid evidence")
+	}
+	return &Identity{Namespace: "default", ServiceAccount: "vm"}, nil
+}
+
+func grpcContext(token string) security.AuthContext {
+	md := metadata.Pairs("authorization", security.BearerTokenPrefix+token)
+	return security.AuthContext{GrpcContext: metadata.NewIncomingContext(context.Background(), md)}
+}
+
+func TestAuthenticate(t *testing.T) {
+	a := NewAuthenticator(fakeMesh{}, fakePlugin{})
+	want := &security.Caller{
+		AuthSource: security.AuthSourceAttestation,
+		Identities: []string{"spiffe://cluster.local/ns/default/sa/vm"},
+	}
+
+	caller, err := a.Authenticate(grpcContext(FormatEvidence("fake", "valid")))
+	assert.NoError(t, err)
+	assert.Equal(t, caller, want)
+
+	req, err := http.NewRequest(http.MethodPost, "https://istiod", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", security.BearerTokenPrefix+FormatEvidence("fake", "valid"))
+	caller, err = a.Authenticate(security.AuthContext{Request: req})
+	assert.NoError(t, err)
+	assert.Equal(t, caller, want)
+
+	for name, token := range map[string]string{
+		"invalid evidence": FormatEvidence("fake", "forged"),
+		"unknown type":     FormatEvidence("other", "valid"),
+		"jwt":              "eyJhbGciOiJSUzI1NiJ9.eyJzdWIiOiJ4In0.c2ln",
+		"jwt with colon":   "eyJhbGciOiJSUzI1NiJ9.e:30.c2ln",
+	} {
+		t.Run(name, func(t *testing.T) {
+			caller, err := a.Authenticate(grpcContext(token))
+			assert.Error(t, err)
+			assert.Equal(t, caller, nil)
+		})
+	}
+
+	_, err = a.Authenticate(security.AuthContext{GrpcContext: context.Background()})
+	assert.Error(t, err)
+}
@@ -0,0 +1,161 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// JoinTokenType is the evidence type of one-time join tokens.
+	JoinTokenType = "join-token"
+
+	// JoinTokenSecretType is the type of the Secrets holding join tokens.
+	JoinTokenSecretType corev1.SecretType = "istio.io/join-token"
+
+	joinTokenSecretPrefix = "istio-join-token-"
+
+	joinTokenHashKey           = "tokenHash"
+	joinTokenNamespaceKey      = "namespace"
+	joinTokenServiceAccountKey = "serviceAccount"
+	joinTokenExpirationKey     = "expiration"
+)
+
+// JoinTokens attests workloads presenting a one-time join token. Join tokens are minted ahead of time,
+// and stored as a Secret in the istiod namespace holding the hash of the token and the identity it
+// grants. The Secret is deleted once the request authenticated by the token succeeded, so each token
+// authenticates a single certificate request.
+type JoinTokens struct {
+	client    kubernetes.Interface
+	namespace string
+	now       func() time.Time
+}
+
+var _ Plugin = &JoinTokens{}
+
+// NewJoinTokens creates the join token plugin, for the join tokens stored in namespace.
+func NewJoinTokens(client kubernetes.Interface, namespace string) *JoinTokens {
+	return &JoinTokens{client: client, namespace: namespace, now: time.Now}
+}
+
+func (j *JoinTokens) Type() string {
+	return JoinTokenType
+}
+
+func (j *JoinTokens) Attest(ctx context.Context, evidence string) (*Identity, error) {
+	id, _, ok := strings.Cut(evidence, ".")
+	if !ok || !isJoinTokenID(id) {
+		return nil, fmt.Errorf("malformed join token")
+	}
+	secret, err := j.client.CoreV1().Secrets(j.namespace).Get(ctx, joinTokenSecretPrefix+id, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, fmt.Errorf("join token %s is unknown or already used", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get join token %s: %v", id, err)
+	}
+	if secret.Type != JoinTokenSecretType {
+		return nil, fmt.Errorf("secret %s is not a join token", secret.Name)
+	}
+	sum := sha256.Sum256([]byte(evidence))
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), secret.Data[joinTokenHashKey]) != 1 {
+		return nil, fmt.Errorf("invalid join token %s", id)
+	}
+	expiration, err := time.Parse(time.RFC3339, string(secret.Data[joinTokenExpirationKey]))
+	if err != nil {
+		return nil, fmt.Errorf("join token %s has an invalid expiration: %v", id, err)
+	}
+	if j.now().After(expiration) {
+		// Expired tokens are useless, clean them up.
+		if err := j.consume(ctx, secret); err != nil {
+			attestationLog.Debugf("failed to delete expired join token %s: %v", id, err)
+		}
+		return nil, fmt.Errorf("join token %s expired at %v", id, expiration)
+	}
+	identity := &Identity{
+		Namespace:      string(secret.Data[joinTokenNamespaceKey]),
+		ServiceAccount: string(secret.Data[joinTokenServiceAccountKey]),
+	}
+	if identity.Namespace == "" || identity.ServiceAccount == "" {
+		return nil, fmt.Errorf("join token %s has no identity", id)
+	}
+	identity.Consume = func(ctx context.Context) error {
+		return j.consume(ctx, secret)
+	}
+	return identity, nil
+}
+
+// consume deletes the Secret of a join token. The preconditions ensure only one request consumes the
+// token, even with several istiod replicas.
+func (j *JoinTokens) consume(ctx context.Context, secret *corev1.Secret) error {
+	uid, rv := secret.UID, secret.ResourceVersion
+	err := j.client.CoreV1().Secrets(j.namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &uid, ResourceVersion: &rv},
+	})
+	if kerrors.IsNotFound(err) || kerrors.IsConflict(err) {
+		return fmt.Errorf("join token %s is already used", strings.TrimPrefix(secret.Name, joinTokenSecretPrefix))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to consume join token %s: %v", strings.TrimPrefix(secret.Name, joinTokenSecretPrefix), err)
+	}
+	return nil
+}
+
+// NewJoinToken mints a join token granting the identity of a service account until expiration. It returns
+// the token, formatted as a bearer token, and the Secret to create in the istiod namespace to enable it.
+func NewJoinToken(istioNamespace string, identity Identity, expiration time.Time) (string, *corev1.Secret, error) {
+	b := make([]byte, 40)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	id := hex.EncodeToString(b[:8])
+	token := id + "." + base64.RawURLEncoding.EncodeToString(b[8:])
+	sum := sha256.Sum256([]byte(token))
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      joinTokenSecretPrefix + id,
+			Namespace: istioNamespace,
+		},
+		Type: JoinTokenSecretType,
+		Data: map[string][]byte{
+			joinTokenHashKey:           []byte(hex.EncodeToString(sum[:])),
+			joinTokenNamespaceKey:      []byte(identity.Namespace),
+			joinTokenServiceAccountKey: []byte(identity.ServiceAccount),
+			joinTokenExpirationKey:     []byte(expiration.UTC().Format(time.RFC3339)),
+		},
+	}
+	return FormatEvidence(JoinTokenType, token), secret, nil
+}
+
+func isJoinTokenID(id string) bool {
+	if len(id) != 16 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
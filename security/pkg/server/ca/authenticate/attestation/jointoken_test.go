@@ -0,0 +1,92 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestJoinTokens(t *testing.T) {
+	now := time.Now()
+	client := fake.NewClientset()
+	j := NewJoinTokens(client, "istio-system")
+	j.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	mint := func(t *testing.T, expiration time.Time) string {
+		t.Helper()
+		token, secret, err := NewJoinToken("istio-system", Identity{Namespace: "vm", ServiceAccount: "app"}, expiration)
+		assert.NoError(t, err)
+		_, err = client.CoreV1().Secrets("istio-system").Create(ctx, secret, metav1.CreateOptions{})
+		assert.NoError(t, err)
+		// The secret only holds the token hash.
+		for _, v := range secret.Data {
+			if strings.Contains(token, string(v)) {
+				t.Fatalf("secret holds the token")
+			}
+		}
+		evidence, ok := strings.CutPrefix(token, JoinTokenType+":")
+		if !ok {
+			t.Fatalf("unexpected token format %q", token)
+		}
+		return evidence
+	}
+
+	t.Run("one time use", func(t *testing.T) {
+		token := mint(t, now.Add(time.Hour))
+		id, err := j.Attest(ctx, token)
+		assert.NoError(t, err)
+		assert.Equal(t, Identity{Namespace: id.Namespace, ServiceAccount: id.ServiceAccount}, Identity{Namespace: "vm", ServiceAccount: "app"})
+		// The token is only consumed once the request succeeded.
+		retry, err := j.Attest(ctx, token)
+		assert.NoError(t, err)
+		assert.NoError(t, id.Consume(ctx))
+		assert.Error(t, retry.Consume(ctx))
+		_, err = j.Attest(ctx, token)
+		assert.Error(t, err)
+	})
+	t.Run("expired", func(t *testing.T) {
+		token := mint(t, now.Add(-time.Minute))
+		_, err := j.Attest(ctx, token)
+		assert.Error(t, err)
+		// Expired tokens are deleted.
+		secrets, err := client.CoreV1().Secrets("istio-system").List(ctx, metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, len(secrets.Items), 0)
+	})
+	t.Run("wrong secret", func(t *testing.T) {
+		token := mint(t, now.Add(time.Hour))
+		id, _, _ := strings.Cut(token, ".")
+		_, err := j.Attest(ctx, id+".forged")
+		assert.Error(t, err)
+		// A wrong guess does not consume the token.
+		_, err = j.Attest(ctx, token)
+		assert.NoError(t, err)
+	})
+	t.Run("malformed", func(t *testing.T) {
+		for _, token := range []string{"", "abc", "not-hex-not-hex!.secret", "../../x.secret"} {
+			_, err := j.Attest(ctx, token)
+			assert.Error(t, err)
+		}
+	})
+}
@@ -0,0 +1,204 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ca
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"istio.io/istio/pkg/security"
+)
+
+// IssuedCertificate is a workload certificate issued by the CA server.
+type IssuedCertificate struct {
+	security.CertificateInfo
+	Namespace      string `json:"namespace,omitempty"`
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+	// Requester is the identity of the caller. It differs from the SANs when a node requested
+	// the certificate on behalf of a workload.
+	Requester string `json:"requester,omitempty"`
+	// RequesterAddress is the address of the proxy that requested the certificate.
+	RequesterAddress string    `json:"requesterAddress,omitempty"`
+	IssuedAt         time.Time `json:"issuedAt"`
+}
+
+// CertFilter selects certificates from the inventory. Empty fields match all certificates.
+type CertFilter struct {
+	Namespace    string
+	SerialNumber string
+	// ExpiringWithin selects certificates expiring within the given duration, including expired ones.
+	ExpiringWithin time.Duration
+}
+
+func (f CertFilter) matches(c *IssuedCertificate, now time.Time) bool {
+	if f.Namespace != "" && c.Namespace != f.Namespace {
+		return false
+	}
+	if f.SerialNumber != "" && c.SerialNumber != f.SerialNumber {
+		return false
+	}
+	if f.ExpiringWithin > 0 && c.NotAfter.After(now.Add(f.ExpiringWithin)) {
+		return false
+	}
+	return true
+}
+
+// CertInventoryStatus is the content of the certificate inventory, as exposed on the istiod debug endpoint.
+type CertInventoryStatus struct {
+	// RootFingerprints are the fingerprints of the roots the CA currently publishes.
+	RootFingerprints []string            `json:"rootFingerprints"`
+	Certificates     []IssuedCertificate `json:"certificates"`
+}
+
+// expiredRetention is how long expired certificates are kept in the inventory, so they can be
+// matched against proxies that failed to rotate them.
+const expiredRetention = time.Hour
+
+// CertInventory is a bounded index of the certificates issued by the CA server. Once full, the
+// oldest certificates are evicted first.
+type CertInventory struct {
+	mu    sync.RWMutex
+	size  int
+	certs map[string]*IssuedCertificate
+	// order holds serial numbers by issuance time.
+	order []string
+	// reported are the expiry buckets recorded in the last metrics update.
+	reported map[string]struct{}
+	now      func() time.Time
+}
+
+// NewCertInventory creates an inventory holding at most size certificates.
+func NewCertInventory(size int) *CertInventory {
+	return &CertInventory{
+		size:     size,
+		certs:    map[string]*IssuedCertificate{},
+		reported: map[string]struct{}{},
+		now:      time.Now,
+	}
+}
+
+// Add records an issued certificate.
+func (i *CertInventory) Add(c IssuedCertificate) {
+	if i == nil || i.size <= 0 {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if _, f := i.certs[c.SerialNumber]; !f {
+		i.order = append(i.order, c.SerialNumber)
+	}
+	i.certs[c.SerialNumber] = &c
+	for len(i.certs) > i.size {
+		oldest := i.order[0]
+		i.order = i.order[1:]
+		delete(i.certs, oldest)
+	}
+}
+
+// List returns the certificates matching the filter, soonest to expire first.
+func (i *CertInventory) List(filter CertFilter) []IssuedCertificate {
+	if i == nil {
+		return nil
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	now := i.now()
+	out := []IssuedCertificate{}
+	for _, c := range i.certs {
+		if filter.matches(c, now) {
+			out = append(out, *c)
+		}
+	}
+	sort.Slice(out, func(a, b int) bool {
+		if !out[a].NotAfter.Equal(out[b].NotAfter) {
+			return out[a].NotAfter.Before(out[b].NotAfter)
+		}
+		return out[a].SerialNumber < out[b].SerialNumber
+	})
+	return out
+}
+
+// Run periodically drops long expired certificates and updates the inventory metrics, until stop is closed.
+func (i *CertInventory) Run(stop <-chan struct{}) {
+	t := time.NewTicker(30 * time.Second)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+			i.prune()
+			i.recordMetrics()
+		}
+	}
+}
+
+func (i *CertInventory) prune() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	cutoff := i.now().Add(-expiredRetention)
+	order := i.order[:0]
+	for _, serial := range i.order {
+		c, f := i.certs[serial]
+		if !f {
+			continue
+		}
+		if c.NotAfter.Before(cutoff) {
+			delete(i.certs, serial)
+			continue
+		}
+		order = append(order, serial)
+	}
+	i.order = order
+}
+
+// expiryBucket returns the expiry bucket label of a certificate.
+func expiryBucket(remaining time.Duration) string {
+	switch {
+	case remaining <= 0:
+		return "expired"
+	case remaining < time.Hour:
+		return "1h"
+	case remaining < 24*time.Hour:
+		return "24h"
+	case remaining < 7*24*time.Hour:
+		return "7d"
+	}
+	return "later"
+}
+
+func (i *CertInventory) recordMetrics() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	now := i.now()
+	// The metric is not broken down by namespace, as its cardinality would be unbounded. Per namespace
+	// counts are available from the inventory itself.
+	counts := map[string]int{}
+	for _, c := range i.certs {
+		counts[expiryBucket(c.NotAfter.Sub(now))]++
+	}
+	// Reset buckets which no longer hold any certificate.
+	for b := range i.reported {
+		if _, f := counts[b]; !f {
+			issuedCerts.With(expiryTag.Value(b)).Record(0)
+			delete(i.reported, b)
+		}
+	}
+	for b, n := range counts {
+		issuedCerts.With(expiryTag.Value(b)).Record(float64(n))
+		i.reported[b] = struct{}{}
+	}
+}
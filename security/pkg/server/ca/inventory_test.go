@@ -0,0 +1,130 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ca
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	pb "istio.io/api/security/v1alpha1"
+	"istio.io/istio/pkg/monitoring/monitortest"
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/pkg/slices"
+	"istio.io/istio/pkg/test/util/assert"
+	mockca "istio.io/istio/security/pkg/pki/ca/mock"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+func issued(serial, namespace string, notAfter time.Time) IssuedCertificate {
+	return IssuedCertificate{
+		CertificateInfo: security.CertificateInfo{SerialNumber: serial, NotAfter: notAfter},
+		Namespace:       namespace,
+	}
+}
+
+func serials(certs []IssuedCertificate) []string {
+	return slices.Map(certs, func(c IssuedCertificate) string { return c.SerialNumber })
+}
+
+func TestCertInventory(t *testing.T) {
+	now := time.Now()
+	inv := NewCertInventory(3)
+	inv.now = func() time.Time { return now }
+
+	inv.Add(issued("1", "a", now.Add(48*time.Hour)))
+	inv.Add(issued("2", "a", now.Add(30*time.Minute)))
+	inv.Add(issued("3", "b", now.Add(-2*time.Hour)))
+	assert.Equal(t, serials(inv.List(CertFilter{})), []string{"3", "2", "1"})
+
+	// The oldest certificate is evicted once the inventory is full.
+	inv.Add(issued("4", "b", now.Add(2*time.Hour)))
+	assert.Equal(t, serials(inv.List(CertFilter{})), []string{"3", "2", "4"})
+
+	assert.Equal(t, serials(inv.List(CertFilter{Namespace: "b"})), []string{"3", "4"})
+	assert.Equal(t, serials(inv.List(CertFilter{SerialNumber: "2"})), []string{"2"})
+	assert.Equal(t, serials(inv.List(CertFilter{ExpiringWithin: time.Hour})), []string{"3", "2"})
+
+	mt := monitortest.New(t)
+	inv.recordMetrics()
+	mt.Assert(issuedCerts.Name(), map[string]string{"expiry": "1h"}, monitortest.Exactly(1))
+	mt.Assert(issuedCerts.Name(), map[string]string{"expiry": "expired"}, monitortest.Exactly(1))
+	mt.Assert(issuedCerts.Name(), map[string]string{"expiry": "24h"}, monitortest.Exactly(1))
+
+	// Long expired certificates are dropped, and their metrics reset.
+	inv.prune()
+	inv.recordMetrics()
+	assert.Equal(t, serials(inv.List(CertFilter{})), []string{"2", "4"})
+	mt.Assert(issuedCerts.Name(), map[string]string{"expiry": "expired"}, monitortest.Exactly(0))
+
+	// Eviction keeps working after pruning.
+	inv.Add(issued("5", "a", now.Add(time.Hour)))
+	inv.Add(issued("6", "a", now.Add(time.Hour)))
+	assert.Equal(t, serials(inv.List(CertFilter{})), []string{"5", "6", "4"})
+}
+
+func TestCreateCertificateRecordsInventory(t *testing.T) {
+	rootCertBytes, rootKeyBytes, err := util.GenCertKeyFromOptions(util.CertOptions{
+		Org:          "MyOrg",
+		IsCA:         true,
+		IsSelfSigned: true,
+		TTL:          time.Hour,
+		RSAKeySize:   2048,
+	})
+	assert.NoError(t, err)
+	rootCert, err := util.ParsePemEncodedCertificate(rootCertBytes)
+	assert.NoError(t, err)
+	rootKey, err := util.ParsePemEncodedKey(rootKeyBytes)
+	assert.NoError(t, err)
+	leafBytes, _, err := util.GenCertKeyFromOptions(util.CertOptions{
+		Host:       "spiffe://cluster.local/ns/foo/sa/bar",
+		TTL:        time.Hour / 2,
+		RSAKeySize: 2048,
+		SignerCert: rootCert,
+		SignerPriv: rootKey,
+	})
+	assert.NoError(t, err)
+	leaf, err := util.ParsePemEncodedCertificate(leafBytes)
+	assert.NoError(t, err)
+
+	server := &Server{
+		ca: &mockca.FakeCA{
+			SignedCert:    leafBytes,
+			KeyCertBundle: util.NewKeyCertBundleFromPem(nil, nil, nil, rootCertBytes, nil),
+		},
+		Authenticators: []security.Authenticator{&mockAuthenticator{identities: []string{"spiffe://cluster.local/ns/node/sa/ztunnel"}}},
+		monitoring:     newMonitoringMetrics(),
+		inventory:      NewCertInventory(10),
+	}
+	p := &peer.Peer{Addr: &net.IPAddr{IP: net.IPv4(192, 168, 1, 1)}, AuthInfo: credentials.TLSInfo{}}
+	_, err = server.CreateCertificate(peer.NewContext(context.Background(), p), &pb.IstioCertificateRequest{Csr: "dumb CSR"})
+	assert.NoError(t, err)
+
+	status := server.IssuedCertificates(CertFilter{})
+	assert.Equal(t, len(status.Certificates), 1)
+	got := status.Certificates[0]
+	assert.Equal(t, got.SerialNumber, leaf.SerialNumber.Text(16))
+	assert.Equal(t, got.NotAfter, leaf.NotAfter)
+	assert.Equal(t, got.Namespace, "foo")
+	assert.Equal(t, got.ServiceAccount, "bar")
+	assert.Equal(t, got.Requester, "spiffe://cluster.local/ns/node/sa/ztunnel")
+	assert.Equal(t, got.RequesterAddress, "192.168.1.1")
+	assert.Equal(t, got.RootFingerprint, security.CertFingerprint(rootCert))
+	assert.Equal(t, status.RootFingerprints, []string{security.CertFingerprint(rootCert)})
+}
@@ -0,0 +1,279 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ca
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// JWTKeySecret is the name of the secret, in the istiod namespace, holding the keys JWT-SVIDs are signed with.
+const JWTKeySecret = "istio-jwt-svid-keys"
+
+const (
+	jwtKeysField = "keys.json"
+	// jwtKeyRefresh is how often the keys are reloaded from the secret, and rotated if needed.
+	jwtKeyRefresh = time.Minute
+	// jwtKeyActivation is how long a new key is published in the JWT bundle before it signs JWT-SVIDs,
+	// so that every istiod instance publishes it before the first JWT-SVID signed with it is presented.
+	jwtKeyActivation = 2 * jwtKeyRefresh
+	// jwtKeyPersistAttempts is how many times persisting the keys is attempted, when another istiod
+	// instance updated them concurrently.
+	jwtKeyPersistAttempts = 3
+)
+
+// jwtKey is a JWT-SVID signing key, as stored in the secret.
+type jwtKey struct {
+	ID      string    `json:"kid"`
+	Created time.Time `json:"created"`
+	// Key is the PKCS#8 encoded private key.
+	Key []byte `json:"key"`
+
+	signer *ecdsa.PrivateKey
+}
+
+// JWTKeyStore holds the keys JWT-SVIDs are signed with. These keys are dedicated to JWT-SVIDs, rather than
+// the CA signing key, and rotate on their own schedule: a new key is generated every rotation period, and a
+// retired key stays in the JWT bundle until the JWT-SVIDs it signed have expired.
+//
+// The keys are persisted in a secret, so they survive restarts and are shared by all istiod instances.
+// Without a client, they are only kept in memory.
+type JWTKeyStore struct {
+	client    kubernetes.Interface
+	namespace string
+	rotation  time.Duration
+	ttl       time.Duration
+	now       func() time.Time
+
+	mu sync.RWMutex
+	// keys are ordered oldest first.
+	keys []*jwtKey
+}
+
+// NewJWTKeyStore creates a key store persisting the keys in the given namespace. rotation is how often a new
+// key is generated, and ttl the lifetime of the JWT-SVIDs signed with the keys.
+func NewJWTKeyStore(client kubernetes.Interface, namespace string, rotation, ttl time.Duration) *JWTKeyStore {
+	return &JWTKeyStore{
+		client:    client,
+		namespace: namespace,
+		rotation:  rotation,
+		ttl:       ttl,
+		now:       time.Now,
+	}
+}
+
+// Run keeps the keys loaded and rotated, until stop is closed.
+func (s *JWTKeyStore) Run(stop <-chan struct{}) {
+	t := time.NewTicker(jwtKeyRefresh)
+	defer t.Stop()
+	for {
+		if err := s.Refresh(); err != nil {
+			serverCaLog.Errorf("failed to refresh the JWT-SVID signing keys: %v", err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Refresh loads the keys from the secret, and rotates them if they are due.
+func (s *JWTKeyStore) Refresh() error {
+	var err error
+	for range jwtKeyPersistAttempts {
+		if err = s.refresh(); !kerrors.IsConflict(err) && !kerrors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func (s *JWTKeyStore) refresh() error {
+	if s.client == nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		keys, _, err := s.rotate(s.keys)
+		if err == nil {
+			s.keys = keys
+		}
+		return err
+	}
+
+	secrets := s.client.CoreV1().Secrets(s.namespace)
+	secret, err := secrets.Get(context.Background(), JWTKeySecret, metav1.GetOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	var keys []*jwtKey
+	if err == nil {
+		if keys, err = decodeJWTKeys(secret.Data[jwtKeysField]); err != nil {
+			return fmt.Errorf("invalid secret %s/%s: %v", s.namespace, JWTKeySecret, err)
+		}
+	} else {
+		secret = nil
+	}
+	keys, changed, err := s.rotate(keys)
+	if err != nil {
+		return err
+	}
+	if changed {
+		data, err := json.Marshal(keys)
+		if err != nil {
+			return err
+		}
+		if secret == nil {
+			_, err = secrets.Create(context.Background(), &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: JWTKeySecret, Namespace: s.namespace},
+				Data:       map[string][]byte{jwtKeysField: data},
+			}, metav1.CreateOptions{})
+		} else {
+			secret = secret.DeepCopy()
+			if secret.Data == nil {
+				secret.Data = map[string][]byte{}
+			}
+			secret.Data[jwtKeysField] = data
+			_, err = secrets.Update(context.Background(), secret, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return err
+		}
+		serverCaLog.Infof("rotated the JWT-SVID signing keys, now %d keys with kid %s signing", len(keys), signingJWTKey(keys, s.now()).ID)
+	}
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+	return nil
+}
+
+// rotate adds a key if the newest one is older than the rotation period, and removes the keys which
+// cannot have signed unexpired JWT-SVIDs anymore.
+func (s *JWTKeyStore) rotate(keys []*jwtKey) ([]*jwtKey, bool, error) {
+	now := s.now()
+	changed := false
+	if len(keys) == 0 || now.Sub(keys[len(keys)-1].Created) >= s.rotation {
+		k, err := newJWTKey(now)
+		if err != nil {
+			return nil, false, err
+		}
+		keys = append(keys, k)
+		changed = true
+	}
+	// A key stops signing once its successor is active, so it is retired once its successor has been
+	// active for the lifetime of JWT-SVIDs.
+	for len(keys) > 1 && now.Sub(keys[1].Created) >= jwtKeyActivation+s.ttl {
+		keys = keys[1:]
+		changed = true
+	}
+	return keys, changed, nil
+}
+
+// SigningKey returns the key to sign JWT-SVIDs with, and its key ID.
+func (s *JWTKeyStore) SigningKey() (jose.SigningKey, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.keys) == 0 {
+		return jose.SigningKey{}, "", fmt.Errorf("no JWT-SVID signing key is available")
+	}
+	k := signingJWTKey(s.keys, s.now())
+	return jose.SigningKey{Algorithm: jose.ES256, Key: k.signer}, k.ID, nil
+}
+
+// Bundle returns the public keys of all the keys which may have signed unexpired JWT-SVIDs.
+func (s *JWTKeyStore) Bundle() jose.JSONWebKeySet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	set := jose.JSONWebKeySet{}
+	for _, k := range s.keys {
+		set.Keys = append(set.Keys, jose.JSONWebKey{
+			Key:       k.signer.Public(),
+			KeyID:     k.ID,
+			Algorithm: string(jose.ES256),
+			Use:       "jwt-svid",
+		})
+	}
+	return set
+}
+
+// signingJWTKey returns the newest key which has been published for long enough. The oldest key signs
+// if none has, as is the case when the first key was just generated.
+func signingJWTKey(keys []*jwtKey, now time.Time) *jwtKey {
+	for i := len(keys) - 1; i >= 0; i-- {
+		if now.Sub(keys[i].Created) >= jwtKeyActivation {
+			return keys[i]
+		}
+	}
+	return keys[0]
+}
+
+func newJWTKey(now time.Time) (*jwtKey, error) {
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate a JWT-SVID signing key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		return nil, err
+	}
+	k := &jwtKey{Created: now, Key: der, signer: signer}
+	if k.ID, err = jwtKeyID(signer); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func decodeJWTKeys(data []byte) ([]*jwtKey, error) {
+	var keys []*jwtKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		parsed, err := x509.ParsePKCS8PrivateKey(k.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %s: %v", k.ID, err)
+		}
+		signer, ok := parsed.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("key %s is a %T, not an ECDSA key", k.ID, parsed)
+		}
+		k.signer = signer
+	}
+	return keys, nil
+}
+
+// jwtKeyID derives the key ID from the public key.
+func jwtKeyID(key *ecdsa.PrivateKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,121 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ca
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/pkg/slices"
+	"istio.io/istio/pkg/spiffe"
+)
+
+// jwtSVIDServer mints JWT-SVIDs for authenticated workloads. It implements the JWT methods of the
+// SPIFFE Workload API, so the istio-agent can serve them to the workload as is. JWT-SVIDs are
+// signed with the dedicated keys of the key store, never with the CA signing key, and the JWT bundle
+// holds the public keys of the key store.
+type jwtSVIDServer struct {
+	workload.UnimplementedSpiffeWorkloadAPIServer
+	server *Server
+	keys   *JWTKeyStore
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// FetchJWTSVID mints a JWT-SVID for each identity of the caller, or for the requested one.
+func (j *jwtSVIDServer) FetchJWTSVID(ctx context.Context, req *workload.JWTSVIDRequest) (*workload.JWTSVIDResponse, error) {
+	if len(req.Audience) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "audience must be specified")
+	}
+	ids, err := j.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.SpiffeId != "" {
+		if !slices.Contains(ids, req.SpiffeId) {
+			return nil, status.Errorf(codes.PermissionDenied, "caller is not entitled to %s", req.SpiffeId)
+		}
+		ids = []string{req.SpiffeId}
+	}
+	key, kid, err := j.keys.SigningKey()
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	signer, err := jose.NewSigner(key, (&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", kid))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create JWT signer: %v", err)
+	}
+	now := j.now()
+	resp := &workload.JWTSVIDResponse{}
+	for _, id := range ids {
+		token, err := jwt.Signed(signer).Claims(jwt.Claims{
+			Subject:  id,
+			Audience: req.Audience,
+			IssuedAt: jwt.NewNumericDate(now),
+			Expiry:   jwt.NewNumericDate(now.Add(j.ttl)),
+		}).Serialize()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to sign JWT-SVID: %v", err)
+		}
+		resp.Svids = append(resp.Svids, &workload.JWTSVID{SpiffeId: id, Svid: token})
+	}
+	serverCaLog.Debugf("minted JWT-SVIDs for %v, audience %v", ids, req.Audience)
+	return resp, nil
+}
+
+// FetchJWTBundles sends the JWT bundle of the caller's trust domain. Unlike the Workload API served to
+// workloads, the stream ends after the bundle is sent; clients fetch it again when needed.
+func (j *jwtSVIDServer) FetchJWTBundles(_ *workload.JWTBundlesRequest, stream workload.SpiffeWorkloadAPI_FetchJWTBundlesServer) error {
+	ids, err := j.authenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+	bundle, err := json.Marshal(j.keys.Bundle())
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to encode JWT bundle: %v", err)
+	}
+	resp := &workload.JWTBundlesResponse{Bundles: map[string][]byte{}}
+	for _, id := range ids {
+		if parsed, err := spiffe.ParseIdentity(id); err == nil {
+			resp.Bundles[spiffe.URIPrefix+parsed.TrustDomain] = bundle
+		}
+	}
+	return stream.Send(resp)
+}
+
+// authenticate returns the SPIFFE identities of the caller.
+func (j *jwtSVIDServer) authenticate(ctx context.Context) ([]string, error) {
+	caller, err := security.Authenticate(ctx, j.server.Authenticators)
+	if caller == nil || err != nil {
+		j.server.monitoring.AuthnError.Increment()
+		return nil, status.Error(codes.Unauthenticated, "request authenticate failure")
+	}
+	ids := slices.Filter(caller.Identities, func(id string) bool {
+		_, err := spiffe.ParseIdentity(id)
+		return err == nil
+	})
+	if len(ids) == 0 {
+		return nil, status.Error(codes.PermissionDenied, "caller has no SPIFFE identity")
+	}
+	return ids, nil
+}
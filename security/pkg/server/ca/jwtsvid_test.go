@@ -0,0 +1,174 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ca
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/pkg/slices"
+	"istio.io/istio/pkg/test/util/assert"
+	mockca "istio.io/istio/security/pkg/pki/ca/mock"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+type fakeJWTBundlesStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses []*workload.JWTBundlesResponse
+}
+
+func (f *fakeJWTBundlesStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeJWTBundlesStream) Send(resp *workload.JWTBundlesResponse) error {
+	f.responses = append(f.responses, resp)
+	return nil
+}
+
+func TestJWTSVID(t *testing.T) {
+	id := "spiffe://cluster.local/ns/foo/sa/bar"
+	certPEM, keyPEM, err := util.GenCertKeyFromOptions(util.CertOptions{
+		Org:          "MyOrg",
+		IsCA:         true,
+		IsSelfSigned: true,
+		TTL:          time.Hour,
+		RSAKeySize:   2048,
+	})
+	assert.NoError(t, err)
+	now := time.Now()
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.IPAddr{IP: net.IPv4(192, 168, 1, 1)}, AuthInfo: credentials.TLSInfo{}})
+	keys := NewJWTKeyStore(nil, "istio-system", time.Hour, 5*time.Minute)
+	j := &jwtSVIDServer{
+		server: &Server{
+			ca:             &mockca.FakeCA{KeyCertBundle: util.NewKeyCertBundleFromPem(certPEM, keyPEM, nil, certPEM, nil)},
+			Authenticators: []security.Authenticator{&mockAuthenticator{identities: []string{id}}},
+			monitoring:     newMonitoringMetrics(),
+		},
+		keys: keys,
+		ttl:  5 * time.Minute,
+		now:  func() time.Time { return now },
+	}
+
+	// No JWT-SVID is minted before the keys are loaded.
+	_, err = j.FetchJWTSVID(ctx, &workload.JWTSVIDRequest{Audience: []string{"db"}})
+	assert.Equal(t, status.Code(err), codes.Unavailable)
+	assert.NoError(t, keys.Refresh())
+
+	_, err = j.FetchJWTSVID(ctx, &workload.JWTSVIDRequest{})
+	assert.Equal(t, status.Code(err), codes.InvalidArgument)
+	_, err = j.FetchJWTSVID(ctx, &workload.JWTSVIDRequest{Audience: []string{"db"}, SpiffeId: "spiffe://cluster.local/ns/foo/sa/other"})
+	assert.Equal(t, status.Code(err), codes.PermissionDenied)
+
+	resp, err := j.FetchJWTSVID(ctx, &workload.JWTSVIDRequest{Audience: []string{"db"}})
+	assert.NoError(t, err)
+	assert.Equal(t, len(resp.Svids), 1)
+	assert.Equal(t, resp.Svids[0].SpiffeId, id)
+
+	stream := &fakeJWTBundlesStream{ctx: ctx}
+	assert.NoError(t, j.FetchJWTBundles(&workload.JWTBundlesRequest{}, stream))
+	assert.Equal(t, len(stream.responses), 1)
+	var bundle jose.JSONWebKeySet
+	assert.NoError(t, json.Unmarshal(stream.responses[0].Bundles["spiffe://cluster.local"], &bundle))
+
+	// The JWT-SVID verifies with the bundle.
+	tok, err := jwt.ParseSigned(resp.Svids[0].Svid, []jose.SignatureAlgorithm{jose.ES256})
+	assert.NoError(t, err)
+	matching := bundle.Key(tok.Headers[0].KeyID)
+	assert.Equal(t, len(matching), 1)
+	var claims jwt.Claims
+	assert.NoError(t, tok.Claims(matching[0].Key, &claims))
+	assert.Equal(t, claims.Subject, id)
+	assert.NoError(t, claims.ValidateWithLeeway(jwt.Expected{AnyAudience: jwt.Audience{"db"}, Time: now.Add(time.Minute)}, 0))
+	assert.Error(t, claims.ValidateWithLeeway(jwt.Expected{AnyAudience: jwt.Audience{"db"}, Time: now.Add(10 * time.Minute)}, 0))
+
+	// The CA key is neither used to sign nor published.
+	caKey, err := util.ParsePemEncodedKey(keyPEM)
+	assert.NoError(t, err)
+	assert.Error(t, tok.Claims(caKey.(crypto.Signer).Public(), &claims))
+	assert.Equal(t, len(keys.Bundle().Keys), 1)
+
+	// Callers without a SPIFFE identity are rejected.
+	j.server.Authenticators = []security.Authenticator{&mockAuthenticator{identities: []string{"not-spiffe"}}}
+	_, err = j.FetchJWTSVID(ctx, &workload.JWTSVIDRequest{Audience: []string{"db"}})
+	assert.Equal(t, status.Code(err), codes.PermissionDenied)
+}
+
+func TestJWTKeyStore(t *testing.T) {
+	client := kube.NewFakeClient()
+	now := time.Now()
+	newStore := func() *JWTKeyStore {
+		s := NewJWTKeyStore(client.Kube(), "istio-system", 24*time.Hour, 5*time.Minute)
+		s.now = func() time.Time { return now }
+		return s
+	}
+	kids := func(s *JWTKeyStore) []string {
+		return slices.Map(s.Bundle().Keys, func(k jose.JSONWebKey) string { return k.KeyID })
+	}
+	signing := func(s *JWTKeyStore) string {
+		_, kid, err := s.SigningKey()
+		assert.NoError(t, err)
+		return kid
+	}
+
+	first := newStore()
+	assert.NoError(t, first.Refresh())
+	assert.Equal(t, len(kids(first)), 1)
+	k1 := kids(first)[0]
+	assert.Equal(t, signing(first), k1)
+
+	// Another instance, or a restarted one, loads the same key.
+	second := newStore()
+	assert.NoError(t, second.Refresh())
+	assert.Equal(t, kids(second), []string{k1})
+
+	// A new key is published once the rotation period passed, but only signs once it is activated.
+	now = now.Add(24 * time.Hour)
+	assert.NoError(t, first.Refresh())
+	assert.Equal(t, len(kids(first)), 2)
+	k2 := kids(first)[1]
+	assert.Equal(t, signing(first), k1)
+	assert.NoError(t, second.Refresh())
+	assert.Equal(t, kids(second), []string{k1, k2})
+
+	now = now.Add(jwtKeyActivation)
+	assert.Equal(t, signing(second), k2)
+
+	// The retired key is removed once the JWT-SVIDs it signed have expired.
+	now = now.Add(4 * time.Minute)
+	assert.NoError(t, first.Refresh())
+	assert.Equal(t, kids(first), []string{k1, k2})
+	now = now.Add(time.Minute)
+	assert.NoError(t, first.Refresh())
+	assert.Equal(t, kids(first), []string{k2})
+	assert.NoError(t, second.Refresh())
+	assert.Equal(t, kids(second), []string{k2})
+}
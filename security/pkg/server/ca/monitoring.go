@@ -23,7 +23,8 @@ const (
 )
 
 var (
-	errorTag = monitoring.CreateLabel(errorlabel)
+	errorTag  = monitoring.CreateLabel(errorlabel)
+	expiryTag = monitoring.CreateLabel("expiry")
 
 	csrCounts = monitoring.NewSum(
 		"citadel_server_csr_count",
@@ -68,6 +69,11 @@ var (
 		"citadel_server_cert_chain_expiry_timestamp",
 		"The unix timestamp, in seconds, when Istio generated cert chain will expire.",
 	)
+	issuedCerts = monitoring.NewGauge(
+		"citadel_server_issued_certs",
+		"The number of workload certificates issued by Citadel server, by time until expiry "+
+			"(expired, 1h, 24h, 7d or later).",
+	)
 	certChainExpirySeconds = monitoring.NewDerivedGauge(
 		"citadel_server_cert_chain_expiry_seconds",
 		"The time remaining, in seconds, before the Istio Generated cert chain will expire. "+
@@ -16,8 +16,10 @@ package ca
 
 import (
 	"context"
+	"strings"
 	"time"
 
+	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -28,6 +30,7 @@ import (
 	"istio.io/istio/pkg/kube/multicluster"
 	"istio.io/istio/pkg/log"
 	"istio.io/istio/pkg/security"
+	"istio.io/istio/pkg/spiffe"
 	"istio.io/istio/security/pkg/pki/ca"
 	caerror "istio.io/istio/security/pkg/pki/error"
 	"istio.io/istio/security/pkg/pki/util"
@@ -55,6 +58,14 @@ type Server struct {
 	serverCertTTL  time.Duration
 
 	nodeAuthorizer *MulticlusterNodeAuthorizor
+	inventory      *CertInventory
+	// rootFinder finds the root of issued certificates, without verifying every certificate.
+	rootFinder security.RootFinder
+
+	// IsRevoked returns true if the identity is revoked, in which case no certificate is issued for it.
+	IsRevoked func(identity string) bool
+	// JWTKeys holds the keys JWT-SVIDs are signed with. JWT-SVIDs are only minted if it is set.
+	JWTKeys *JWTKeyStore
 }
 
 type SaNode struct {
@@ -107,6 +118,15 @@ func (s *Server) CreateCertificate(ctx context.Context, request *pb.IstioCertifi
 		// Node is authorized to impersonate; overwrite the SAN to the impersonated identity.
 		sans = []string{impersonatedIdentity}
 	}
+	if s.IsRevoked != nil {
+		for _, san := range sans {
+			if s.IsRevoked(san) {
+				s.monitoring.AuthnError.Increment()
+				serverCaLog.Warnf("refusing to issue a certificate for revoked identity %s", san)
+				return nil, status.Error(codes.PermissionDenied, "identity is revoked")
+			}
+		}
+	}
 	serverCaLog.Debugf("generating a certificate, sans: %v, requested ttl: %s", sans, time.Duration(request.ValidityDuration*int64(time.Second)))
 	certSigner := crMetadata[security.CertSigner].GetStringValue()
 	_, _, certChainBytes, rootCertBytes := s.ca.GetCAKeyCertBundle().GetAll()
@@ -154,12 +174,63 @@ func (s *Server) CreateCertificate(ctx context.Context, request *pb.IstioCertifi
 		response.CertChain = append(response.CertChain, string(rootCertBytes))
 	}
 
+	if caller.Consume != nil {
+		if err := caller.Consume(ctx); err != nil {
+			s.monitoring.AuthnError.Increment()
+			serverCaLog.Warnf("failed to consume the credentials of %v: %v", sans, err)
+			return nil, status.Error(codes.Unauthenticated, "request authenticate failure")
+		}
+	}
+
 	serverCaLog.Debugf("Responding with cert chain, %q", response.CertChain)
+	s.recordIssued(ctx, caller, respCertChain, rootCertBytes)
 	s.monitoring.Success.Increment()
 	serverCaLog.Debugf("CSR successfully signed, sans %v.", sans)
 	return response, nil
 }
 
+// recordIssued adds an issued certificate to the inventory.
+func (s *Server) recordIssued(ctx context.Context, caller *security.Caller, certChain []string, rootCertBytes []byte) {
+	if s.inventory == nil || len(certChain) == 0 {
+		return
+	}
+	info, err := s.rootFinder.Summarize([]byte(strings.Join(certChain, "\n")), rootCertBytes)
+	if err != nil {
+		serverCaLog.Debugf("failed to summarize issued certificate: %v", err)
+		return
+	}
+	issued := IssuedCertificate{
+		CertificateInfo:  *info,
+		RequesterAddress: security.GetConnectionAddress(ctx),
+		IssuedAt:         time.Now(),
+	}
+	if len(caller.Identities) > 0 {
+		issued.Requester = caller.Identities[0]
+	}
+	for _, san := range info.SANs {
+		if id, err := spiffe.ParseIdentity(san); err == nil {
+			issued.Namespace, issued.ServiceAccount = id.Namespace, id.ServiceAccount
+			break
+		}
+	}
+	s.inventory.Add(issued)
+}
+
+// IssuedCertificates returns the issued certificates matching the filter, and the roots the CA currently publishes.
+func (s *Server) IssuedCertificates(filter CertFilter) CertInventoryStatus {
+	return CertInventoryStatus{
+		RootFingerprints: security.RootFingerprints(s.ca.GetCAKeyCertBundle().GetRootCertPem()),
+		Certificates:     s.inventory.List(filter),
+	}
+}
+
+// RunInventory maintains the issued certificate inventory and its metrics, until stop is closed.
+func (s *Server) RunInventory(stop <-chan struct{}) {
+	if s.inventory != nil {
+		s.inventory.Run(stop)
+	}
+}
+
 // RecordCertsExpiry updates the certificate-expiration related metrics given a new keycertbundle
 func RecordCertsExpiry(keyCertBundle *util.KeyCertBundle) {
 	// Expiry of the first root cert in trust bundle
@@ -187,6 +258,9 @@ func RecordCertsExpiry(keyCertBundle *util.KeyCertBundle) {
 // Register registers a GRPC server on the specified port.
 func (s *Server) Register(grpcServer *grpc.Server) {
 	pb.RegisterIstioCertificateServiceServer(grpcServer, s)
+	if features.EnableCAJWTSVID && s.JWTKeys != nil {
+		workload.RegisterSpiffeWorkloadAPIServer(grpcServer, &jwtSVIDServer{server: s, keys: s.JWTKeys, ttl: features.CAJWTSVIDTTL, now: time.Now})
+	}
 }
 
 // New creates a new instance of `IstioCAServiceServer`
@@ -207,6 +281,9 @@ func New(
 		ca:             ca,
 		monitoring:     newMonitoringMetrics(),
 	}
+	if features.CertInventorySize > 0 {
+		server.inventory = NewCertInventory(features.CertInventorySize)
+	}
 
 	if len(features.CATrustedNodeAccounts) > 0 {
 		// TODO: do we need some way to delayed readiness until this is synced? Probably
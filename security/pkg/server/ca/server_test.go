@@ -42,6 +42,7 @@ import (
 	"istio.io/istio/pkg/monitoring/monitortest"
 	"istio.io/istio/pkg/security"
 	"istio.io/istio/pkg/test"
+	"istio.io/istio/pkg/test/util/assert"
 	"istio.io/istio/pkg/util/sets"
 	mockca "istio.io/istio/security/pkg/pki/ca/mock"
 	caerror "istio.io/istio/security/pkg/pki/error"
@@ -79,6 +80,7 @@ type mockAuthenticator struct {
 	authSource     security.AuthSource
 	identities     []string
 	kubernetesInfo security.KubernetesInfo
+	consume        func(ctx context.Context) error
 	errMsg         string
 }
 
@@ -95,6 +97,7 @@ func (authn *mockAuthenticator) Authenticate(_ security.AuthContext) (*security.
 		AuthSource:     authn.authSource,
 		Identities:     authn.identities,
 		KubernetesInfo: authn.kubernetesInfo,
+		Consume:        authn.consume,
 	}, nil
 }
 
@@ -225,6 +228,7 @@ func TestCreateCertificate(t *testing.T) {
 	testCases := map[string]struct {
 		authenticators []security.Authenticator
 		ca             CertificateAuthority
+		revoked        string
 		certChain      []string
 		code           codes.Code
 	}{
@@ -260,6 +264,12 @@ func TestCreateCertificate(t *testing.T) {
 			ca:             &mockca.FakeCA{SignErr: caerror.NewError(caerror.CertGenError, fmt.Errorf("cannot sign"))},
 			code:           codes.Internal,
 		},
+		"Revoked identity": {
+			authenticators: []security.Authenticator{&mockAuthenticator{identities: []string{"test-identity"}}},
+			ca:             &mockca.FakeCA{},
+			revoked:        "test-identity",
+			code:           codes.PermissionDenied,
+		},
 		"Successful signing": {
 			authenticators: []security.Authenticator{&mockAuthenticator{identities: []string{"test-identity"}}},
 			ca: &mockca.FakeCA{
@@ -291,6 +301,7 @@ func TestCreateCertificate(t *testing.T) {
 			ca:             c.ca,
 			Authenticators: c.authenticators,
 			monitoring:     newMonitoringMetrics(),
+			IsRevoked:      func(identity string) bool { return identity == c.revoked },
 		}
 		request := &pb.IstioCertificateRequest{Csr: "dumb CSR"}
 
@@ -315,6 +326,44 @@ func TestCreateCertificate(t *testing.T) {
 	}
 }
 
+func TestCreateCertificateConsumesCredentials(t *testing.T) {
+	p := &peer.Peer{Addr: &net.IPAddr{IP: net.IPv4(192, 168, 1, 1)}, AuthInfo: credentials.TLSInfo{}}
+	ctx := peer.NewContext(context.Background(), p)
+	request := &pb.IstioCertificateRequest{Csr: "dumb CSR"}
+	consumed := 0
+	consume := func(context.Context) error {
+		consumed++
+		if consumed > 1 {
+			return fmt.Errorf("already used")
+		}
+		return nil
+	}
+	newServer := func(ca CertificateAuthority) *Server {
+		return &Server{
+			ca:             ca,
+			Authenticators: []security.Authenticator{&mockAuthenticator{identities: []string{"test-identity"}, consume: consume}},
+			monitoring:     newMonitoringMetrics(),
+		}
+	}
+
+	// A failed request does not consume the credentials.
+	_, err := newServer(&mockca.FakeCA{SignErr: caerror.NewError(caerror.CSRError, fmt.Errorf("cannot sign"))}).CreateCertificate(ctx, request)
+	assert.Equal(t, status.Code(err), codes.InvalidArgument)
+	assert.Equal(t, consumed, 0)
+
+	server := newServer(&mockca.FakeCA{
+		SignedCert:    []byte(testCert),
+		KeyCertBundle: util.NewKeyCertBundleFromPem(nil, nil, []byte(testCertChain), []byte(testRootCert), nil),
+	})
+	_, err = server.CreateCertificate(ctx, request)
+	assert.NoError(t, err)
+	assert.Equal(t, consumed, 1)
+
+	// Credentials which cannot be consumed again fail the request.
+	_, err = server.CreateCertificate(ctx, request)
+	assert.Equal(t, status.Code(err), codes.Unauthenticated)
+}
+
 func TestCreateCertificateE2EWithImpersonateIdentity(t *testing.T) {
 	allowZtunnel := sets.Set[types.NamespacedName]{
 		{Name: "ztunnel", Namespace: "istio-system"}: {},
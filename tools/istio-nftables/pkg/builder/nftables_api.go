@@ -22,11 +22,15 @@ import (
 )
 
 // NftablesAPI defines the interface for interacting with nftables.
-// It supports creating a transaction, running it, listing elements, and optionally dumping the config (mainly for testing).
+// It supports creating a transaction, running it, listing objects, and optionally dumping the config (mainly for testing).
 type NftablesAPI interface {
 	NewTransaction() *knftables.Transaction
 	Run(ctx context.Context, tx *knftables.Transaction) error
 	Dump(tx *knftables.Transaction) string
+	// List returns a list of the names of the objects of objectType ("chain", "set", "map" or "counter") in the table.
+	List(ctx context.Context, objectType string) ([]string, error)
+	// ListRules returns a list of the rules in a chain, in order.
+	ListRules(ctx context.Context, chain string) ([]*knftables.Rule, error)
 	// ListElements returns a list of the elements in a set or map. (objectType should be "set" or "map".)
 	ListElements(ctx context.Context, objectType, name string) ([]*knftables.Element, error)
 }
@@ -66,6 +70,16 @@ func (r *NftImpl) ListElements(ctx context.Context, objectType, name string) ([]
 	return r.nft.ListElements(ctx, objectType, name)
 }
 
+// List returns a list of the names of the objects of objectType in the table using the real knftables interface.
+func (r *NftImpl) List(ctx context.Context, objectType string) ([]string, error) {
+	return r.nft.List(ctx, objectType)
+}
+
+// ListRules returns a list of the rules in a chain using the real knftables interface.
+func (r *NftImpl) ListRules(ctx context.Context, chain string) ([]*knftables.Rule, error) {
+	return r.nft.ListRules(ctx, chain)
+}
+
 // MockNftables is a mock implementation of NftablesAPI for use in unit tests.
 // It uses knftables.Fake to simulate nftables behavior without making changes to the system.
 type MockNftables struct {